@@ -32,10 +32,14 @@ func main() {
 	root.AddCommand(cli.NewValidateCommand())
 	root.AddCommand(cli.NewListCommand())
 	root.AddCommand(cli.NewShowCommand())
+	root.AddCommand(cli.NewImpactCommand())
+	root.AddCommand(cli.NewPathCommand())
 	root.AddCommand(cli.NewQueryCommand())
+	root.AddCommand(cli.NewSearchCommand())
 	root.AddCommand(cli.NewHistoryCommand())
 	root.AddCommand(cli.NewGraphCommand())
 	root.AddCommand(cli.NewDiffCommand())
+	root.AddCommand(cli.NewRestoreCommand())
 	root.AddCommand(cli.NewStatsCommand())
 	root.AddCommand(cli.NewReviewCommand())
 	root.AddCommand(cli.NewSyncCommand())
@@ -43,6 +47,10 @@ func main() {
 	root.AddCommand(cli.NewLLMHelpCommand())
 	root.AddCommand(cli.NewNewCommand())
 	root.AddCommand(cli.NewExportCommand())
+	root.AddCommand(cli.NewMergeDriverCommand())
+	root.AddCommand(cli.NewKeysCommand())
+	root.AddCommand(cli.NewChangelogCommand())
+	root.AddCommand(cli.NewReleaseCommand())
 
 	if err := root.Execute(); err != nil {
 		// Check for ExitError with custom exit code
@@ -0,0 +1,174 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/replay"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/Toernblom/deco/internal/storage/objects"
+	"github.com/spf13/cobra"
+)
+
+// asOfHelp documents --as-of in the Long text of read commands.
+const asOfHelp = `--as-of shows the project as it was at a time (RFC3339, YYYY-MM-DD, or
+2h/1d/1w ago) or git revision. History is replayed backwards from the
+current nodes; nodes whose content changed or that were deleted since are
+taken from the snapshot history recorded at that time, or when there is
+none, from the git commit at that time.`
+
+// addAsOfFlag registers --as-of on a read command.
+func addAsOfFlag(cmd *cobra.Command, asOf *string) {
+	cmd.Flags().StringVar(asOf, "as-of", "", "Show the project as of a time or git revision")
+}
+
+// loadNodes loads the project's nodes, or with asOf set, the nodes as they
+// were at that time or git revision.
+func loadNodes(cfg config.Config, dir, asOf string) ([]domain.Node, error) {
+	nodesDir := config.ResolveNodesPath(cfg, dir)
+	nodes, err := node.NewYAMLRepository(nodesDir).LoadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes: %w", err)
+	}
+	if asOf == "" {
+		return nodes, nil
+	}
+
+	t, rev, err := resolveAsOf(dir, asOf)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := history.NewYAMLRepository(config.ResolveHistoryPath(cfg, dir)).Query(history.Filter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+
+	result := replay.AsOf(nodes, entries, t)
+	nodes, incomplete := fillFromSnapshots(result.Nodes, result.Incomplete, entries, t, objects.NewStore(config.ResolveObjectsPath(cfg, dir)))
+	if len(incomplete) > 0 {
+		nodes = fillFromGit(nodes, incomplete, nodesDir, t, rev)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
+// resolveAsOf parses an --as-of value. A git revision resolves to its
+// commit time and is returned so the git fallback reads that exact commit.
+func resolveAsOf(dir, value string) (time.Time, string, error) {
+	if t, err := parseSince(value); err == nil {
+		return t, "", nil
+	}
+	t, err := resolveSinceOrRevision(dir, value)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid --as-of value: %w", err)
+	}
+	return t, value, nil
+}
+
+// fillFromSnapshots replaces the nodes history could not reconstruct with
+// the snapshot of their last entry at or before t, and returns the IDs
+// still missing a snapshot.
+func fillFromSnapshots(nodes []domain.Node, incomplete []string, entries []domain.AuditEntry, t time.Time, store *objects.Store) ([]domain.Node, []string) {
+	latest := make(map[string]domain.AuditEntry)
+	for _, e := range entries {
+		if !e.Timestamp.After(t) {
+			latest[e.NodeID] = e
+		}
+	}
+
+	var remaining []string
+	for _, id := range incomplete {
+		e, ok := latest[id]
+		if !ok || e.Snapshot == "" {
+			remaining = append(remaining, id)
+			continue
+		}
+		n, err := store.Get(e.Snapshot)
+		if err != nil {
+			remaining = append(remaining, id)
+			continue
+		}
+		n.ID = id
+		nodes = replaceNode(nodes, n)
+	}
+	return nodes, remaining
+}
+
+// replaceNode swaps in n for the node with the same ID, or appends it.
+func replaceNode(nodes []domain.Node, n domain.Node) []domain.Node {
+	for i := range nodes {
+		if nodes[i].ID == n.ID {
+			nodes[i] = n
+			return nodes
+		}
+	}
+	return append(nodes, n)
+}
+
+// fillFromGit replaces the nodes history could not reconstruct with their
+// version at a git revision (rev, or the last commit at or before t).
+// Nodes git cannot provide keep their best-effort state, with a warning.
+func fillFromGit(nodes []domain.Node, incomplete []string, nodesDir string, t time.Time, rev string) []domain.Node {
+	var gitNodes []domain.Node
+	var err error
+	if rev == "" {
+		rev, err = node.RevisionAt(nodesDir, t)
+	}
+	if err == nil {
+		gitNodes, err = node.LoadAllAtRevision(nodesDir, rev)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: history does not record all changes to %s and no git revision could be read (%v); showing best-effort state\n",
+			strings.Join(incomplete, ", "), err)
+		return nodes
+	}
+
+	fromGit := make(map[string]domain.Node, len(gitNodes))
+	for _, n := range gitNodes {
+		fromGit[n.ID] = n
+	}
+
+	var missing []string
+	for _, id := range incomplete {
+		n, ok := fromGit[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		nodes = replaceNode(nodes, n)
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s not found at git revision %s; showing best-effort state\n",
+			strings.Join(missing, ", "), shortRevision(rev))
+	}
+	return nodes
+}
+
+// shortRevision abbreviates a full commit hash for messages.
+func shortRevision(rev string) string {
+	if len(rev) == 40 && strings.Trim(rev, "0123456789abcdef") == "" {
+		return rev[:7]
+	}
+	return rev
+}
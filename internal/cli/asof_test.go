@@ -0,0 +1,157 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/node"
+)
+
+// setupTimeTravelProject creates systems/combat on 2026-01-01 (optionally
+// committed to git on 2026-01-02), then on 2026-02-01 retitles it through
+// a sync and creates systems/magic.
+func setupTimeTravelProject(t *testing.T, withGit bool) string {
+	t.Helper()
+	dir := setupDecoProject(t)
+	nodeRepo := node.NewYAMLRepository(filepath.Join(dir, ".deco", "nodes"))
+	historyRepo := history.NewYAMLRepository(filepath.Join(dir, ".deco", "history.jsonl"))
+	day := func(month, d int) time.Time { return time.Date(2026, time.Month(month), d, 12, 0, 0, 0, time.UTC) }
+	log := func(ts time.Time, id, op string, before map[string]interface{}) {
+		if err := historyRepo.Append(domain.AuditEntry{Timestamp: ts, NodeID: id, Operation: op, User: "alice", Before: before}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	combat := domain.Node{ID: "systems/combat", Kind: "system", Version: 1, Status: "draft", Title: "Melee combat"}
+	if err := nodeRepo.Save(combat); err != nil {
+		t.Fatal(err)
+	}
+	log(day(1, 1), combat.ID, "create", nil)
+
+	if withGit {
+		for _, args := range [][]string{
+			{"init", "-q"},
+			{"add", "-A"},
+			{"-c", "user.name=alice", "-c", "user.email=alice@example.com", "commit", "-q", "-m", "milestone 1"},
+			{"tag", "milestone-1"},
+		} {
+			cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+			cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE=2026-01-02T12:00:00Z", "GIT_COMMITTER_DATE=2026-01-02T12:00:00Z")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+	}
+
+	combat.Title = "Combat"
+	combat.Version = 2
+	if err := nodeRepo.Save(combat); err != nil {
+		t.Fatal(err)
+	}
+	log(day(2, 1), combat.ID, "sync", map[string]interface{}{"version": 1, "status": "draft"})
+
+	magic := domain.Node{ID: "systems/magic", Kind: "system", Version: 1, Status: "draft", Title: "Magic"}
+	if err := nodeRepo.Save(magic); err != nil {
+		t.Fatal(err)
+	}
+	log(day(2, 1), magic.ID, "create", nil)
+	return dir
+}
+
+func TestAsOf_HistoryWithGitFallback(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := setupTimeTravelProject(t, true)
+	columns := []string{"--format", "csv", "--columns", "id,version,title"}
+
+	tests := []struct {
+		asOf string
+		want string
+	}{
+		// The retitle is only in git; the version comes from history
+		{"2026-01-15", "id,version,title\nsystems/combat,1,Melee combat\n"},
+		{"milestone-1", "id,version,title\nsystems/combat,1,Melee combat\n"},
+		{"2026-03-01", "id,version,title\nsystems/combat,2,Combat\nsystems/magic,1,Magic\n"},
+		{"2025-12-01", "id,version,title\n"},
+	}
+	for _, tt := range tests {
+		out, err := runListCmd(t, append([]string{dir, "--as-of", tt.asOf}, columns...)...)
+		if err != nil {
+			t.Fatalf("--as-of %s: %v", tt.asOf, err)
+		}
+		if out != tt.want {
+			t.Errorf("--as-of %s: got:\n%s\nwant:\n%s", tt.asOf, out, tt.want)
+		}
+	}
+
+	out, err := runQueryCmd(t, "--kind", "system", "--as-of", "2026-01-15", "--format", "csv", "--columns", "id,title", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out != "id,title\nsystems/combat,Melee combat\n" {
+		t.Errorf("Unexpected query output:\n%s", out)
+	}
+
+	var showErr error
+	out = captureStdout(t, func() {
+		showErr = runShow("systems/combat", &showFlags{targetDir: dir, asOf: "2026-01-15"})
+	})
+	if showErr != nil || !strings.Contains(out, "Melee combat") {
+		t.Errorf("Expected the old title from show, got %v:\n%s", showErr, out)
+	}
+	if err := runShow("systems/magic", &showFlags{targetDir: dir, asOf: "2026-01-15"}); err == nil ||
+		!strings.Contains(err.Error(), "not found as of 2026-01-15") {
+		t.Errorf("Expected magic to be missing before its creation, got %v", err)
+	}
+}
+
+func TestAsOf_WithoutGit(t *testing.T) {
+	dir := setupTimeTravelProject(t, false)
+
+	// History alone reverts the version and the creation but not the title
+	out, err := runListCmd(t, dir, "--as-of", "2026-01-15", "--format", "csv", "--columns", "id,version,title")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out != "id,version,title\nsystems/combat,1,Combat\n" {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+
+	if _, err := runListCmd(t, dir, "--as-of", "not-a-time"); err == nil || !strings.Contains(err.Error(), "invalid --as-of value") {
+		t.Errorf("Expected an invalid --as-of error, got %v", err)
+	}
+}
+
+func TestGraphCommand_AsOf(t *testing.T) {
+	dir := setupTimeTravelProject(t, false)
+	var buf bytes.Buffer
+	if err := runGraph(&buf, &graphFlags{format: "mermaid", direction: "both", targetDir: dir, asOf: "2026-01-15"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "systems_combat") || strings.Contains(buf.String(), "systems_magic") {
+		t.Errorf("Expected only combat in the graph, got:\n%s", buf.String())
+	}
+}
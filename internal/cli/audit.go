@@ -24,6 +24,11 @@ import (
 	"sort"
 
 	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/markdown"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/git"
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/objects"
 	"gopkg.in/yaml.v3"
 )
 
@@ -47,6 +52,12 @@ type contentFields struct {
 	Custom      domain.SortedInterfaceMap `yaml:"custom,omitempty"`
 }
 
+// snapshotNode saves the full node in the object store next to the history
+// file and returns the hash to record as AuditEntry.Snapshot.
+func snapshotNode(historyPath string, n domain.Node) (string, error) {
+	return objects.NewStore(config.ObjectsPathFor(historyPath)).Put(n)
+}
+
 // ComputeContentHash computes a SHA-256 hash of the content fields.
 // Returns 16 hex characters (first 64 bits of the hash).
 // Used by all mutation commands to record content state in history.
@@ -62,7 +73,32 @@ func ComputeContentHash(n domain.Node) string {
 // ComputeContentHashWithDir computes a content hash that includes referenced doc files.
 // When projectRoot is non-empty, the contents of referenced .md files are included
 // in the hash so that changes to doc files trigger version bumps during sync.
+// For anchored paths (docs/auth.md#token-rotation) only the anchored section is
+// hashed, so edits elsewhere in the file don't bump the node.
 func ComputeContentHashWithDir(n domain.Node, projectRoot string) string {
+	sum := contentDigest(n, projectRoot)
+	if sum == nil {
+		return ""
+	}
+	return hex.EncodeToString(sum[:8])
+}
+
+// ComputeFullContentHash returns the full SHA-256 of the content fields
+// as 64 hex characters. Approval signatures cover it: the 64-bit
+// ComputeContentHash is enough to detect edits but not to resist a
+// deliberately crafted collision. Referenced doc files are not included.
+func ComputeFullContentHash(n domain.Node) string {
+	sum := contentDigest(n, "")
+	if sum == nil {
+		return ""
+	}
+	return hex.EncodeToString(sum)
+}
+
+// contentDigest hashes the content fields of a node, followed by its
+// referenced doc files when projectRoot is non-empty. It returns nil if
+// the fields cannot be encoded.
+func contentDigest(n domain.Node, projectRoot string) []byte {
 	fields := contentFields{
 		Kind:        n.Kind,
 		Title:       n.Title,
@@ -81,7 +117,7 @@ func ComputeContentHashWithDir(n domain.Node, projectRoot string) string {
 
 	data, err := yaml.Marshal(fields)
 	if err != nil {
-		return ""
+		return nil
 	}
 
 	h := sha256.New()
@@ -91,16 +127,33 @@ func ComputeContentHashWithDir(n domain.Node, projectRoot string) string {
 	if projectRoot != "" {
 		docPaths := collectDocPaths(n)
 		for _, p := range docPaths {
-			fullPath := filepath.Join(projectRoot, p)
-			if content, err := os.ReadFile(fullPath); err == nil {
+			if content, ok := readDocForHash(projectRoot, p); ok {
 				h.Write([]byte(p)) // include path as separator
 				h.Write(content)
 			}
-			// Missing files are silently skipped (validation catches them)
+			// Missing files and anchors are silently skipped (validation catches them)
 		}
 	}
 
-	return hex.EncodeToString(h.Sum(nil)[:8])
+	return h.Sum(nil)
+}
+
+// readDocForHash returns the bytes of a doc reference that feed the content hash:
+// the whole file, or only the anchored section when the path has an anchor.
+func readDocForHash(projectRoot, path string) ([]byte, bool) {
+	file, anchor := markdown.SplitAnchor(path)
+	content, err := os.ReadFile(filepath.Join(projectRoot, file))
+	if err != nil {
+		return nil, false
+	}
+	if anchor == "" {
+		return content, true
+	}
+	section, ok := markdown.Section(string(content), anchor)
+	if !ok {
+		return nil, false
+	}
+	return []byte(section), true
 }
 
 // collectDocPaths gathers all doc file paths from node-level docs and doc blocks,
@@ -136,10 +189,37 @@ func collectDocPaths(n domain.Node) []string {
 	return paths
 }
 
-// GetCurrentUser returns the current system username, or "unknown" if unavailable.
-func GetCurrentUser() string {
+// GetCurrentUser returns the identity to record for changes to the project
+// containing dir: the git user.name and user.email when configured, else
+// the system username, or "unknown" if neither is available.
+func GetCurrentUser(dir string) string {
+	if id := git.Identity(dir); id != "" {
+		return id
+	}
 	if u, err := user.Current(); err == nil {
 		return u.Username
 	}
 	return "unknown"
 }
+
+// historyWriter appends a command's history entries through one repository
+// and records the user resolved on the first entry, so a command writing
+// an entry per node runs git once rather than once per entry.
+type historyWriter struct {
+	path string
+	repo *history.JSONLRepository
+	user string
+}
+
+func newHistoryWriter(historyPath string, repo *history.JSONLRepository) *historyWriter {
+	return &historyWriter{path: historyPath, repo: repo}
+}
+
+// append stamps the entry with the current user and appends it.
+func (hw *historyWriter) append(entry domain.AuditEntry) error {
+	if hw.user == "" {
+		hw.user = GetCurrentUser(filepath.Dir(hw.path))
+	}
+	entry.User = hw.user
+	return hw.repo.Append(entry)
+}
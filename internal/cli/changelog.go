@@ -0,0 +1,411 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/changelog"
+	"github.com/Toernblom/deco/internal/services/diff"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/git"
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/Toernblom/deco/internal/storage/objects"
+	"github.com/spf13/cobra"
+)
+
+type changelogFlags struct {
+	since     string
+	until     string
+	format    string
+	exclude   []string
+	title     string
+	targetDir string
+}
+
+// changelogFormats are the formats accepted by deco changelog --format.
+var changelogFormats = []string{"markdown", "json", "keepachangelog"}
+
+// NewChangelogCommand creates the changelog subcommand
+func NewChangelogCommand() *cobra.Command {
+	flags := &changelogFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "changelog [directory]",
+		Short: "Summarize history as a changelog",
+		Long: `Summarize the history recorded since a time or git tag as a changelog.
+
+Changes are grouped by node kind and node: nodes created, deleted, moved,
+approved and deprecated, version bumps with the fields they changed, and
+issues opened or resolved. Field changes compare the versions recorded in
+history snapshots, or in git for versions without one.
+
+--since and --until take a date, an RFC3339 time, a duration back from now
+(2h, 3d, 1w) or a git revision such as a tag. --format keepachangelog
+writes a release section (Added, Changed, Deprecated, Removed, Fixed) to
+paste into CHANGELOG.md.
+
+--exclude leaves out operations, such as sync and baseline entries; the
+default comes from changelog.exclude in .deco/config.yaml.
+
+Examples:
+  deco changelog --since milestone-1
+  deco changelog --since 2026-01-01 --until milestone-2 -f json
+  deco changelog --since v1.0 -f keepachangelog --title 1.1.0
+  deco changelog --since 2w --exclude sync,baseline`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				flags.targetDir = args[0]
+			} else {
+				flags.targetDir = "."
+			}
+			return runChangelog(cmd.OutOrStdout(), flags, cmd.Flags().Changed("exclude"))
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.since, "since", "", "Start of the changelog: time or git revision (required)")
+	cmd.Flags().StringVar(&flags.until, "until", "", "End of the changelog: time or git revision (default now)")
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "markdown", "Output format (markdown, json, keepachangelog)")
+	cmd.Flags().StringSliceVar(&flags.exclude, "exclude", nil, "Operations to leave out (e.g., sync,baseline)")
+	cmd.Flags().StringVar(&flags.title, "title", "", "Heading of the changelog or release section")
+	cmd.MarkFlagRequired("since")
+
+	return cmd
+}
+
+func runChangelog(w io.Writer, flags *changelogFlags, excludeSet bool) error {
+	if !containsString(changelogFormats, flags.format) {
+		return fmt.Errorf("invalid --format %q: must be one of %s", flags.format, strings.Join(changelogFormats, ", "))
+	}
+
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	exclude := cfg.Changelog.Exclude
+	if excludeSet {
+		exclude = flags.exclude
+	}
+	for _, op := range exclude {
+		if !slices.Contains(domain.Operations, op) {
+			return fmt.Errorf("invalid operation %q to exclude: must be one of %s", op, strings.Join(domain.Operations, ", "))
+		}
+	}
+
+	since, err := changelogBound(flags.targetDir, flags.since)
+	if err != nil {
+		return fmt.Errorf("invalid --since value: %w", err)
+	}
+	var until changelog.Bound
+	if flags.until != "" {
+		if until, err = changelogBound(flags.targetDir, flags.until); err != nil {
+			return fmt.Errorf("invalid --until value: %w", err)
+		}
+		if until.Time.Before(since.Time) {
+			return fmt.Errorf("--since is after --until")
+		}
+	}
+
+	entries, err := history.NewYAMLRepository(config.ResolveHistoryPath(cfg, flags.targetDir)).Query(history.Filter{})
+	if err != nil {
+		return fmt.Errorf("failed to query history: %w", err)
+	}
+	lookup, err := changelogLookup(cfg, flags.targetDir, entries)
+	if err != nil {
+		return err
+	}
+	log := changelog.Build(entries, changelog.Options{Since: since, Until: until, Exclude: exclude}, lookup)
+
+	switch flags.format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(log)
+	case "keepachangelog":
+		printKeepAChangelog(w, log, flags)
+	default:
+		printChangelogMarkdown(w, log, flags)
+	}
+	return nil
+}
+
+// changelogBound resolves --since or --until. Entries recorded at a git
+// commit are placed by ancestry: those written at a commit before the
+// revision belong before it. Entries without a commit fall back to time,
+// and as git commit times have whole seconds a revision stands for the
+// end of its second.
+func changelogBound(dir, value string) (changelog.Bound, error) {
+	if t, err := parseSince(value); err == nil {
+		return changelog.Bound{Time: t}, nil
+	}
+	t, err := resolveSinceOrRevision(dir, value)
+	if err != nil {
+		return changelog.Bound{}, err
+	}
+	commits, err := git.Ancestors(dir, value)
+	if err != nil {
+		return changelog.Bound{}, err
+	}
+	return changelog.Bound{Time: t.Add(time.Second - time.Nanosecond), Commits: commits}, nil
+}
+
+// changelogLookup returns node states from the working tree, history
+// snapshots and, for versions without a snapshot, git.
+func changelogLookup(cfg config.Config, dir string, entries []domain.AuditEntry) (changelog.Lookup, error) {
+	nodesDir := config.ResolveNodesPath(cfg, dir)
+	nodes, err := node.NewYAMLRepository(nodesDir).LoadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes: %w", err)
+	}
+	current := make(map[string]domain.Node, len(nodes))
+	for _, n := range nodes {
+		current[n.ID] = n
+	}
+
+	// Snapshot hashes by node and version; a move carries the versions
+	// recorded under the old ID over to the new one
+	snapshots := make(map[string]map[int]string)
+	store := objects.NewStore(config.ResolveObjectsPath(cfg, dir))
+	for _, e := range entries {
+		if from, _ := e.Before["id"].(string); e.Operation == "move" && from != "" {
+			for v, hash := range snapshots[from] {
+				if snapshots[e.NodeID] == nil {
+					snapshots[e.NodeID] = make(map[int]string)
+				}
+				snapshots[e.NodeID][v] = hash
+			}
+		}
+		if e.Snapshot == "" {
+			continue
+		}
+		n, err := store.Get(e.Snapshot)
+		if err != nil {
+			// A missing object only hides that version
+			continue
+		}
+		if snapshots[e.NodeID] == nil {
+			snapshots[e.NodeID] = make(map[int]string)
+		}
+		snapshots[e.NodeID][n.Version] = e.Snapshot
+	}
+
+	return func(id string, version int) (domain.Node, bool) {
+		if n, ok := current[id]; ok && (version == 0 || n.Version == version) {
+			return n, true
+		}
+		if version == 0 {
+			for v := range snapshots[id] {
+				version = max(version, v)
+			}
+		}
+		if hash, ok := snapshots[id][version]; ok {
+			if n, err := store.Get(hash); err == nil {
+				n.ID = id
+				return n, true
+			}
+		}
+		if version == 0 {
+			return domain.Node{}, false
+		}
+		n, _, err := node.LoadVersionAtRevisions(nodesDir, id, version)
+		if err != nil {
+			return domain.Node{}, false
+		}
+		n.ID = id
+		return n, true
+	}, nil
+}
+
+// changelogRange describes the range a changelog covers.
+func changelogRange(flags *changelogFlags) string {
+	until := "now"
+	if flags.until != "" {
+		until = flags.until
+	}
+	return fmt.Sprintf("Changes from %s to %s.", flags.since, until)
+}
+
+func printChangelogMarkdown(w io.Writer, log changelog.Changelog, flags *changelogFlags) {
+	title := flags.title
+	if title == "" {
+		title = "Changelog"
+	}
+	fmt.Fprintf(w, "# %s\n\n%s\n", title, changelogRange(flags))
+	if log.Empty() {
+		fmt.Fprintln(w, "\nNo changes.")
+		return
+	}
+
+	for _, k := range log.Kinds {
+		fmt.Fprintf(w, "\n## %s\n", k.Kind)
+		for _, n := range k.Nodes {
+			fmt.Fprintf(w, "\n### %s\n\n", nodeLabel(n))
+			if n.Created {
+				fmt.Fprintln(w, "- Created")
+			}
+			if n.MovedFrom != "" {
+				fmt.Fprintf(w, "- Moved from `%s`\n", n.MovedFrom)
+			}
+			if n.ToVersion > 0 && !n.Created {
+				fmt.Fprintf(w, "- Version %d → %d\n", n.FromVersion, n.ToVersion)
+				for _, c := range n.Changes {
+					fmt.Fprintf(w, "  - %s\n", changeSummary(c))
+				}
+			}
+			if n.Approved {
+				fmt.Fprintln(w, "- Approved")
+			}
+			if n.Deprecated {
+				fmt.Fprintln(w, "- Deprecated")
+			}
+			if n.Deleted {
+				fmt.Fprintln(w, "- Deleted")
+			}
+			for _, i := range n.IssuesOpened {
+				fmt.Fprintf(w, "- Opened issue %s\n", issueLabel(i))
+			}
+			for _, i := range n.IssuesResolved {
+				fmt.Fprintf(w, "- Resolved issue %s\n", issueLabel(i))
+			}
+		}
+	}
+}
+
+// printKeepAChangelog writes a release section in the Keep a Changelog
+// layout (https://keepachangelog.com).
+func printKeepAChangelog(w io.Writer, log changelog.Changelog, flags *changelogFlags) {
+	title := flags.title
+	if title == "" {
+		title = "Unreleased"
+		if flags.until != "" {
+			title = flags.until
+		}
+	}
+	date := time.Now()
+	if !log.Until.IsZero() {
+		date = log.Until
+	}
+	fmt.Fprintf(w, "## [%s] - %s\n", title, date.Format("2006-01-02"))
+
+	sections := []struct {
+		name  string
+		items []string
+	}{{name: "Added"}, {name: "Changed"}, {name: "Deprecated"}, {name: "Removed"}, {name: "Fixed"}}
+	add := func(section int, format string, args ...interface{}) {
+		sections[section].items = append(sections[section].items, fmt.Sprintf(format, args...))
+	}
+	for _, k := range log.Kinds {
+		for _, n := range k.Nodes {
+			label := nodeLabel(n) + " (" + k.Kind + ")"
+			if n.Created {
+				add(0, "%s", label)
+			}
+			if n.MovedFrom != "" {
+				add(1, "%s moved from `%s`", label, n.MovedFrom)
+			}
+			if n.ToVersion > 0 && !n.Created {
+				var paths []string
+				for _, c := range n.Changes {
+					paths = append(paths, c.Kind+" `"+c.Path+"`")
+				}
+				text := fmt.Sprintf("%s version %d → %d", label, n.FromVersion, n.ToVersion)
+				if len(paths) > 0 {
+					text += ": " + strings.Join(paths, ", ")
+				}
+				add(1, "%s", text)
+			}
+			if n.Approved {
+				add(1, "%s approved", label)
+			}
+			for _, i := range n.IssuesOpened {
+				add(1, "%s opened issue %s", label, issueLabel(i))
+			}
+			if n.Deprecated {
+				add(2, "%s", label)
+			}
+			if n.Deleted {
+				add(3, "%s", label)
+			}
+			for _, i := range n.IssuesResolved {
+				add(4, "%s resolved issue %s", label, issueLabel(i))
+			}
+		}
+	}
+
+	for _, s := range sections {
+		if len(s.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n### %s\n\n", s.name)
+		for _, item := range s.items {
+			fmt.Fprintf(w, "- %s\n", item)
+		}
+	}
+}
+
+func nodeLabel(n changelog.Node) string {
+	if n.Title == "" {
+		return "`" + n.ID + "`"
+	}
+	return "`" + n.ID + "` " + n.Title
+}
+
+func issueLabel(i domain.Issue) string {
+	return "`" + i.ID + "`: " + i.Description
+}
+
+// changeSummaryWidth is the longest value a change summary quotes.
+const changeSummaryWidth = 60
+
+// changeSummary describes a field change in one line, quoting short
+// scalar values.
+func changeSummary(c diff.Change) string {
+	text := strings.ToUpper(c.Kind[:1]) + c.Kind[1:] + " `" + c.Path + "`"
+	if c.Kind == diff.Moved {
+		return text + " (" + moveText(c) + ")"
+	}
+	before, after := shortValue(c.Before), shortValue(c.After)
+	switch {
+	case c.Kind == diff.Modified && before != "" && after != "":
+		return text + ": " + before + " → " + after
+	case c.Kind == diff.Added && after != "":
+		return text + ": " + after
+	}
+	return text
+}
+
+// shortValue renders a scalar that fits on a line, or "".
+func shortValue(v interface{}) string {
+	switch v.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return ""
+	}
+	s := valueText(v)
+	if strings.Contains(s, "\n") || len([]rune(s)) > changeSummaryWidth {
+		return ""
+	}
+	return s
+}
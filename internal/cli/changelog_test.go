@@ -0,0 +1,176 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/changelog"
+	"github.com/Toernblom/deco/internal/storage/node"
+)
+
+// setupChangelogProject syncs two nodes, tags the commit v1, then edits
+// one node, moves the other and approves the edit.
+func setupChangelogProject(t *testing.T) string {
+	t.Helper()
+	dir := setupDecoProject(t)
+	nodesDir := filepath.Join(dir, ".deco", "nodes")
+	nodeRepo := node.NewYAMLRepository(nodesDir)
+	sync := func() {
+		if _, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	combat := domain.Node{ID: "systems/combat", Kind: "system", Version: 1, Status: "draft", Title: "Combat", Summary: "Players fight with swords",
+		Issues: []domain.Issue{{ID: "balance", Description: "Balance damage", Severity: "medium", Location: "summary"}}}
+	old := domain.Node{ID: "lore/old", Kind: "lore", Version: 1, Status: "draft", Title: "Old world"}
+	for _, n := range []domain.Node{combat, old} {
+		if err := nodeRepo.Save(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sync()
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "v1")
+	runGit(t, dir, "tag", "v1")
+
+	combat.Summary = "Players fight with swords and bows"
+	combat.Issues = []domain.Issue{
+		{ID: "balance", Description: "Balance damage", Severity: "medium", Location: "summary", Resolved: true},
+		{ID: "range", Description: "Bow range", Severity: "low", Location: "summary"},
+	}
+	if err := nodeRepo.Save(combat); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(nodesDir, "lore", "old.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	old.ID = "lore/world"
+	if err := nodeRepo.Save(old); err != nil {
+		t.Fatal(err)
+	}
+	sync()
+
+	if err := runSubmit(&reviewFlags{targetDir: dir, nodeID: "systems/combat", quiet: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runApprove(&approveFlags{targetDir: dir, nodeID: "systems/combat", quiet: true}); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestChangelogCommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := setupChangelogProject(t)
+
+	run := func(flags *changelogFlags, excludeSet bool) string {
+		t.Helper()
+		flags.targetDir = dir
+		if flags.format == "" {
+			flags.format = "markdown"
+		}
+		var out bytes.Buffer
+		if err := runChangelog(&out, flags, excludeSet); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	want := "# Changelog\n\nChanges from v1 to now.\n" +
+		"\n## lore\n\n### `lore/world` Old world\n\n- Moved from `lore/old`\n" +
+		"\n## system\n\n### `systems/combat` Combat\n\n" +
+		"- Version 1 → 2\n" +
+		"  - Modified `summary`: Players fight with swords → Players fight with swords and bows\n" +
+		"- Approved\n" +
+		"- Opened issue `range`: Bow range\n" +
+		"- Resolved issue `balance`: Balance damage\n"
+	if got := run(&changelogFlags{since: "v1"}, false); got != want {
+		t.Errorf("markdown:\n%s\nwant:\n%s", got, want)
+	}
+
+	got := run(&changelogFlags{since: "v1", format: "keepachangelog", title: "1.1.0"}, false)
+	for _, section := range []string{"## [1.1.0] - ", "### Changed", "- `systems/combat` Combat (system) version 1 → 2: modified `summary`", "### Fixed", "resolved issue `balance`"} {
+		if !strings.Contains(got, section) {
+			t.Errorf("keepachangelog output missing %q:\n%s", section, got)
+		}
+	}
+	if strings.Contains(got, "### Added") {
+		t.Errorf("expected no additions since v1:\n%s", got)
+	}
+
+	var log changelog.Changelog
+	if err := json.Unmarshal([]byte(run(&changelogFlags{since: "v1", format: "json"}, false)), &log); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Kinds) != 2 || log.Kinds[1].Nodes[0].ToVersion != 2 {
+		t.Errorf("unexpected json changelog: %+v", log)
+	}
+
+	// Excluding sync leaves out the version bump and the issues it carried
+	got = run(&changelogFlags{since: "v1", exclude: []string{"sync"}}, true)
+	if strings.Contains(got, "Version") || strings.Contains(got, "issue") || !strings.Contains(got, "- Approved") {
+		t.Errorf("expected only the move and approval without sync:\n%s", got)
+	}
+
+	// Before v1, both nodes were created
+	got = run(&changelogFlags{since: "2000-01-01", until: "v1"}, false)
+	if strings.Count(got, "- Created") != 2 || strings.Contains(got, "Approved") {
+		t.Errorf("expected two creations up to v1:\n%s", got)
+	}
+}
+
+func TestChangelogCommand_Errors(t *testing.T) {
+	dir := setupDecoProject(t)
+	tests := []struct {
+		flags *changelogFlags
+		want  string
+	}{
+		{&changelogFlags{since: "1w", format: "html"}, "invalid --format"},
+		{&changelogFlags{since: "not-a-time", format: "markdown"}, "invalid --since"},
+		{&changelogFlags{since: "1d", until: "1w", format: "markdown"}, "--since is after --until"},
+		{&changelogFlags{since: "1w", format: "markdown", exclude: []string{"noise"}}, "invalid operation \"noise\""},
+	}
+	for _, tt := range tests {
+		tt.flags.targetDir = dir
+		err := runChangelog(&bytes.Buffer{}, tt.flags, true)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expected error containing %q, got %v", tt.want, err)
+		}
+	}
+
+	// The config default applies unless --exclude is given
+	configPath := filepath.Join(dir, ".deco", "config.yaml")
+	data, _ := os.ReadFile(configPath)
+	os.WriteFile(configPath, append(data, "changelog:\n  exclude: [noise]\n"...), 0644)
+	if err := runChangelog(&bytes.Buffer{}, &changelogFlags{since: "1w", format: "markdown", targetDir: dir}, false); err == nil {
+		t.Error("expected the configured exclude list to be checked")
+	}
+	if err := runChangelog(&bytes.Buffer{}, &changelogFlags{since: "1w", format: "markdown", targetDir: dir}, true); err != nil {
+		t.Errorf("expected --exclude to override the config, got %v", err)
+	}
+}
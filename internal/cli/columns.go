@@ -0,0 +1,585 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/query"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// outputFlags are the result-shaping flags shared by list and query.
+type outputFlags struct {
+	format  string
+	columns []string
+	sort    string
+	limit   int
+}
+
+// outputFormats are the formats accepted by --format.
+var outputFormats = []string{"table", "json", "jsonl", "csv", "yaml"}
+
+// defaultNodeColumns reproduce the classic table; structured formats add
+// version and tags since they cost nothing to parse.
+var (
+	defaultNodeColumns    = []string{"id", "kind", "status", "title"}
+	structuredNodeColumns = []string{"id", "kind", "version", "status", "title", "tags"}
+	fixedBlockColumns     = []string{"node", "section", "index", "type"}
+)
+
+// maxTableCellWidth truncates long cells to keep tables readable.
+const maxTableCellWidth = 50
+
+// nodeColumnHelp lists the node columns accepted by --columns and --sort.
+const nodeColumnHelp = `  id, kind, version, status, title, summary, llm_context
+  tags, contracts, reviewers, sections   Lists (names)
+  refs.uses, refs.related                Lists of target IDs
+  refs.emits_events, refs.vocabulary     Lists
+  issues, issues.open, issues.resolved   Issue counts
+  blocks                                 Number of content blocks
+  custom.<key>[.<key>...]                Custom field, nested keys with dots
+  <list column>.count                    Length of a list column, e.g. refs.uses.count`
+
+func addOutputFlags(cmd *cobra.Command, flags *outputFlags, formatShorthand string) {
+	cmd.Flags().StringVarP(&flags.format, "format", formatShorthand, "table", "Output format (table, json, jsonl, csv, yaml)")
+	cmd.Flags().StringSliceVar(&flags.columns, "columns", nil, "Columns to output, comma-separated (e.g. id,status,custom.owner)")
+	cmd.Flags().StringVar(&flags.sort, "sort", "", "Sort by columns, comma-separated, each optionally :asc or :desc (e.g. version:desc)")
+	cmd.Flags().IntVar(&flags.limit, "limit", 0, "Maximum number of results (0 for all)")
+}
+
+// validate checks the format and limit before any work is done.
+func (f *outputFlags) validate() error {
+	if !containsString(outputFormats, f.format) {
+		return fmt.Errorf("unknown format: %s (supported: %s)", f.format, strings.Join(outputFormats, ", "))
+	}
+	if f.limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	return nil
+}
+
+// customized reports whether any flag changes the classic output.
+func (f *outputFlags) customized() bool {
+	return f.format != "table" || len(f.columns) > 0 || f.sort != "" || f.limit > 0
+}
+
+// sortKey is one --sort entry.
+type sortKey struct {
+	column string
+	desc   bool
+}
+
+func parseSortKeys(spec string) ([]sortKey, error) {
+	var keys []sortKey
+	if spec == "" {
+		return nil, nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		key := sortKey{column: part}
+		if i := strings.LastIndexByte(part, ':'); i >= 0 {
+			key.column = part[:i]
+			switch part[i+1:] {
+			case "asc":
+			case "desc":
+				key.desc = true
+			default:
+				return nil, fmt.Errorf("invalid --sort direction %q (supported: asc, desc)", part[i+1:])
+			}
+		}
+		if key.column == "" {
+			return nil, fmt.Errorf("invalid --sort value %q", spec)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// tabular is a resolved result set: one row per node or block.
+type tabular struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+// record pairs a row with the values it is sorted by.
+type record struct {
+	row  []interface{}
+	keys []interface{}
+}
+
+// buildTabular resolves columns and sort keys for n items, sorts and limits.
+func buildTabular(n int, columns []string, flags *outputFlags, value func(i int, col string) (interface{}, error)) (tabular, error) {
+	keys, err := parseSortKeys(flags.sort)
+	if err != nil {
+		return tabular{}, err
+	}
+
+	records := make([]record, n)
+	for i := 0; i < n; i++ {
+		r := record{row: make([]interface{}, len(columns)), keys: make([]interface{}, len(keys))}
+		for c, col := range columns {
+			if r.row[c], err = value(i, col); err != nil {
+				return tabular{}, err
+			}
+		}
+		for k, key := range keys {
+			if r.keys[k], err = value(i, key.column); err != nil {
+				return tabular{}, err
+			}
+		}
+		records[i] = r
+	}
+
+	sort.SliceStable(records, func(a, b int) bool {
+		for k, key := range keys {
+			c := compareValues(records[a].keys[k], records[b].keys[k])
+			if c == 0 {
+				continue
+			}
+			// Missing values always sort last
+			if records[a].keys[k] == nil || records[b].keys[k] == nil {
+				return records[b].keys[k] == nil
+			}
+			if key.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	if flags.limit > 0 && len(records) > flags.limit {
+		records = records[:flags.limit]
+	}
+
+	t := tabular{columns: columns, rows: make([][]interface{}, len(records))}
+	for i, r := range records {
+		t.rows[i] = r.row
+	}
+	return t, nil
+}
+
+// compareValues orders numbers numerically and everything else by its
+// display text. nil compares greater than any value.
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(cellText(a, ", "), cellText(b, ", "))
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// cellText renders a value for table and CSV cells; lists are joined with sep.
+func cellText(v interface{}, sep string) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []string:
+		return strings.Join(t, sep)
+	case []interface{}:
+		parts := make([]string, len(t))
+		for i, item := range t {
+			parts[i] = cellText(item, sep)
+		}
+		return strings.Join(parts, sep)
+	case map[string]interface{}:
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// render writes the result set in the requested format. noun names the
+// rows in the table footer ("node", "block").
+func (t tabular) render(w io.Writer, format, noun string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(t.objects()); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		for _, obj := range t.objects() {
+			if err := encoder.Encode(obj); err != nil {
+				return fmt.Errorf("failed to encode JSON: %w", err)
+			}
+		}
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(t.columns); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		for _, row := range t.rows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				cells[i] = cellText(v, ";")
+			}
+			if err := cw.Write(cells); err != nil {
+				return fmt.Errorf("failed to write CSV: %w", err)
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	case "yaml":
+		return t.renderYAML(w)
+	default:
+		t.renderTable(w, noun)
+	}
+	return nil
+}
+
+// orderedRow is a row that marshals to a JSON object in column order.
+type orderedRow struct {
+	columns []string
+	values  []interface{}
+}
+
+func (r orderedRow) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, col := range r.columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(col)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+func (t tabular) objects() []orderedRow {
+	objs := make([]orderedRow, len(t.rows))
+	for i, row := range t.rows {
+		objs[i] = orderedRow{columns: t.columns, values: row}
+	}
+	return objs
+}
+
+func (t tabular) renderYAML(w io.Writer) error {
+	doc := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, row := range t.rows {
+		m := &yaml.Node{Kind: yaml.MappingNode}
+		for i, col := range t.columns {
+			val := &yaml.Node{}
+			if err := val.Encode(row[i]); err != nil {
+				return fmt.Errorf("failed to encode YAML: %w", err)
+			}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: col}, val)
+		}
+		doc.Content = append(doc.Content, m)
+	}
+	if len(doc.Content) == 0 {
+		doc.Style = yaml.FlowStyle
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return encoder.Close()
+}
+
+func (t tabular) renderTable(w io.Writer, noun string) {
+	cells := make([][]string, len(t.rows))
+	widths := make([]int, len(t.columns))
+	for i, col := range t.columns {
+		widths[i] = len(col)
+	}
+	for r, row := range t.rows {
+		cells[r] = make([]string, len(row))
+		for i, v := range row {
+			s := cellText(v, ", ")
+			if len(s) > maxTableCellWidth {
+				s = s[:maxTableCellWidth-3] + "..."
+			}
+			cells[r][i] = s
+			widths[i] = max(widths[i], len(s))
+		}
+	}
+
+	headers := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		headers[i] = fmt.Sprintf("%-*s", widths[i], strings.ToUpper(col))
+	}
+	header := strings.Join(headers, "  ")
+	fmt.Fprintln(w, style.Header.Sprint(header))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", len(header))))
+
+	for _, row := range cells {
+		parts := make([]string, len(row))
+		for i, s := range row {
+			padded := fmt.Sprintf("%-*s", widths[i], s)
+			switch t.columns[i] {
+			case "status":
+				if c := style.StatusColor(s); c != nil {
+					padded = c.Sprint(padded)
+				}
+			case "kind", "type":
+				padded = style.Muted.Sprint(padded)
+			}
+			parts[i] = padded
+		}
+		fmt.Fprintln(w, strings.Join(parts, "  "))
+	}
+
+	fmt.Fprintf(w, "\n%s %d %s(s)\n", style.Muted.Sprint("Total:"), len(t.rows), noun)
+}
+
+// nodeTabular resolves node columns; unknown columns are an error.
+func nodeTabular(nodes []domain.Node, flags *outputFlags) (tabular, error) {
+	columns := flags.columns
+	if len(columns) == 0 {
+		columns = defaultNodeColumns
+		if flags.format != "table" {
+			columns = structuredNodeColumns
+		}
+	}
+	return buildTabular(len(nodes), columns, flags, func(i int, col string) (interface{}, error) {
+		return nodeColumn(nodes[i], col)
+	})
+}
+
+// nodeColumn returns the value of a column for a node.
+func nodeColumn(n domain.Node, col string) (interface{}, error) {
+	if base, ok := strings.CutSuffix(col, ".count"); ok && !strings.HasPrefix(col, "custom.") {
+		v, err := nodeColumn(n, base)
+		if err != nil {
+			return nil, err
+		}
+		switch list := v.(type) {
+		case []string:
+			return len(list), nil
+		case []interface{}:
+			return len(list), nil
+		}
+		return nil, fmt.Errorf("column %q: %s is not a list", col, base)
+	}
+	if key, ok := strings.CutPrefix(col, "custom."); ok {
+		v := lookupPath(n.Custom, key)
+		if base, ok := strings.CutSuffix(key, ".count"); ok && v == nil {
+			if list, ok := lookupPath(n.Custom, base).([]interface{}); ok {
+				return len(list), nil
+			}
+		}
+		return v, nil
+	}
+
+	switch col {
+	case "id":
+		return n.ID, nil
+	case "kind":
+		return n.Kind, nil
+	case "version":
+		return n.Version, nil
+	case "status":
+		return n.Status, nil
+	case "title":
+		return n.Title, nil
+	case "summary":
+		return n.Summary, nil
+	case "llm_context":
+		return n.LLMContext, nil
+	case "tags":
+		return nonNil(n.Tags), nil
+	case "refs.uses":
+		return refTargets(n.Refs.Uses), nil
+	case "refs.related":
+		return refTargets(n.Refs.Related), nil
+	case "refs.emits_events":
+		return nonNil(n.Refs.EmitsEvents), nil
+	case "refs.vocabulary":
+		return nonNil(n.Refs.Vocabulary), nil
+	case "contracts":
+		names := []string{}
+		for _, c := range n.Contracts {
+			names = append(names, c.Name)
+		}
+		return names, nil
+	case "reviewers":
+		names := []string{}
+		for _, r := range n.Reviewers {
+			names = append(names, r.Name)
+		}
+		return names, nil
+	case "sections":
+		names := []string{}
+		if n.Content != nil {
+			for _, s := range n.Content.Sections {
+				names = append(names, s.Name)
+			}
+		}
+		return names, nil
+	case "blocks":
+		count := 0
+		if n.Content != nil {
+			for _, s := range n.Content.Sections {
+				count += len(s.Blocks)
+			}
+		}
+		return count, nil
+	case "issues", "issues.open", "issues.resolved":
+		count := 0
+		for _, issue := range n.Issues {
+			switch {
+			case col == "issues",
+				col == "issues.open" && !issue.Resolved,
+				col == "issues.resolved" && issue.Resolved:
+				count++
+			}
+		}
+		return count, nil
+	}
+	return nil, fmt.Errorf("unknown column %q\nSupported columns:\n%s", col, nodeColumnHelp)
+}
+
+// blockTabular resolves block columns. Without --columns every field used
+// by any matching block gets its own column, after node, section, index
+// and type. node.<column> reads a column of the owning node.
+func blockTabular(matches []query.BlockMatch, nodes []domain.Node, flags *outputFlags) (tabular, error) {
+	owners := make(map[string]domain.Node, len(nodes))
+	for _, n := range nodes {
+		owners[n.ID] = n
+	}
+
+	columns := flags.columns
+	if len(columns) == 0 {
+		seen := make(map[string]bool)
+		var fields []string
+		for _, m := range matches {
+			for k := range m.Block.Data {
+				if !seen[k] && !containsString(fixedBlockColumns, k) {
+					seen[k] = true
+					fields = append(fields, k)
+				}
+			}
+		}
+		sort.Strings(fields)
+		columns = append(append([]string{}, fixedBlockColumns...), fields...)
+	}
+
+	return buildTabular(len(matches), columns, flags, func(i int, col string) (interface{}, error) {
+		m := matches[i]
+		switch col {
+		case "node":
+			return m.NodeID, nil
+		case "section":
+			return m.SectionName, nil
+		case "index":
+			return m.BlockIndex, nil
+		case "type":
+			return m.Block.Type, nil
+		}
+		if nodeCol, ok := strings.CutPrefix(col, "node."); ok {
+			return nodeColumn(owners[m.NodeID], nodeCol)
+		}
+		return lookupPath(m.Block.Data, col), nil
+	})
+}
+
+// lookupPath follows a dotted path through nested maps.
+func lookupPath(m map[string]interface{}, path string) interface{} {
+	var cur interface{} = m
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if cur, ok = obj[key]; !ok {
+			return nil
+		}
+	}
+	return cur
+}
+
+func refTargets(links []domain.RefLink) []string {
+	targets := []string{}
+	for _, l := range links {
+		targets = append(targets, l.Target)
+	}
+	return targets
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/query"
+)
+
+func columnTestNode() domain.Node {
+	return domain.Node{
+		ID: "items/sword", Kind: "item", Version: 3, Status: "approved", Title: "Sword",
+		Tags: []string{"weapon", "combat"},
+		Refs: domain.Ref{
+			Uses:    []domain.RefLink{{Target: "items/iron"}, {Target: "items/wood"}},
+			Related: []domain.RefLink{{Target: "systems/combat"}},
+		},
+		Issues: []domain.Issue{
+			{ID: "a", Resolved: false},
+			{ID: "b", Resolved: true},
+			{ID: "c", Resolved: false},
+		},
+		Content: &domain.Content{Sections: []domain.Section{
+			{Name: "Stats", Blocks: []domain.Block{{Type: "table"}, {Type: "rule"}}},
+			{Name: "Lore", Blocks: []domain.Block{{Type: "rule"}}},
+		}},
+		Custom: map[string]interface{}{
+			"owner":   "design",
+			"balance": map[string]interface{}{"damage": 12},
+			"owners":  []interface{}{"ann", "bo"},
+		},
+	}
+}
+
+func TestNodeColumn(t *testing.T) {
+	n := columnTestNode()
+	tests := []struct {
+		col  string
+		want interface{}
+	}{
+		{"id", "items/sword"},
+		{"version", 3},
+		{"tags", []string{"weapon", "combat"}},
+		{"tags.count", 2},
+		{"refs.uses", []string{"items/iron", "items/wood"}},
+		{"refs.uses.count", 2},
+		{"refs.related.count", 1},
+		{"refs.vocabulary.count", 0},
+		{"issues", 3},
+		{"issues.open", 2},
+		{"issues.resolved", 1},
+		{"sections", []string{"Stats", "Lore"}},
+		{"blocks", 3},
+		{"custom.owner", "design"},
+		{"custom.balance.damage", 12},
+		{"custom.owners.count", 2},
+		{"custom.missing", nil},
+	}
+	for _, tt := range tests {
+		got, err := nodeColumn(n, tt.col)
+		if err != nil {
+			t.Errorf("nodeColumn(%q) failed: %v", tt.col, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("nodeColumn(%q) = %#v, want %#v", tt.col, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"bogus", "title.count", "refs"} {
+		if _, err := nodeColumn(n, bad); err == nil {
+			t.Errorf("nodeColumn(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseSortKeys(t *testing.T) {
+	keys, err := parseSortKeys("kind, version:desc,title:asc")
+	if err != nil {
+		t.Fatalf("parseSortKeys failed: %v", err)
+	}
+	want := []sortKey{{"kind", false}, {"version", true}, {"title", false}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("got %+v, want %+v", keys, want)
+	}
+
+	for _, bad := range []string{"version:down", ":desc", "a,,b"} {
+		if _, err := parseSortKeys(bad); err == nil {
+			t.Errorf("parseSortKeys(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBuildTabular_SortAndLimit(t *testing.T) {
+	nodes := []domain.Node{
+		{ID: "a", Version: 2, Custom: map[string]interface{}{"cost": 5}},
+		{ID: "b", Version: 10},
+		{ID: "c", Version: 2, Custom: map[string]interface{}{"cost": 40}},
+		{ID: "d", Version: 1, Custom: map[string]interface{}{"cost": 7.5}},
+	}
+	ids := func(tab tabular) string {
+		var out []string
+		for _, row := range tab.rows {
+			out = append(out, row[0].(string))
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		sort  string
+		limit int
+		want  string
+	}{
+		{"version:desc", 0, "b,a,c,d"}, // numeric, stable for ties
+		{"version,id:desc", 0, "d,c,a,b"},
+		{"custom.cost:desc", 0, "c,d,a,b"}, // missing values last
+		{"custom.cost", 0, "a,d,c,b"},      // missing values last in both directions
+		{"version:desc", 2, "b,a"},
+	}
+	for _, tt := range tests {
+		flags := &outputFlags{format: "table", columns: []string{"id"}, sort: tt.sort, limit: tt.limit}
+		tab, err := nodeTabular(nodes, flags)
+		if err != nil {
+			t.Fatalf("nodeTabular(%q) failed: %v", tt.sort, err)
+		}
+		if got := ids(tab); got != tt.want {
+			t.Errorf("sort %q limit %d: got %s, want %s", tt.sort, tt.limit, got, tt.want)
+		}
+	}
+
+	if _, err := nodeTabular(nodes, &outputFlags{sort: "nope"}); err == nil {
+		t.Error("sorting by an unknown column should fail")
+	}
+}
+
+func TestTabularRender(t *testing.T) {
+	n := columnTestNode()
+	flags := &outputFlags{columns: []string{"id", "tags", "custom.balance", "refs.uses.count"}}
+
+	render := func(format string) string {
+		t.Helper()
+		flags.format = format
+		tab, err := nodeTabular([]domain.Node{n}, flags)
+		if err != nil {
+			t.Fatalf("nodeTabular failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := tab.render(&buf, format, "node"); err != nil {
+			t.Fatalf("render(%s) failed: %v", format, err)
+		}
+		return buf.String()
+	}
+
+	if got := render("json"); !strings.Contains(got, `"id": "items/sword",`) ||
+		strings.Index(got, `"tags"`) > strings.Index(got, `"custom.balance"`) {
+		t.Errorf("JSON should keep column order, got:\n%s", got)
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal([]byte(render("json")), &rows); err != nil || rows[0]["refs.uses.count"] != 2.0 {
+		t.Errorf("JSON should keep typed values, got %v (%v)", rows, err)
+	}
+
+	if got := render("jsonl"); got != `{"id":"items/sword","tags":["weapon","combat"],"custom.balance":{"damage":12},"refs.uses.count":2}`+"\n" {
+		t.Errorf("unexpected JSONL:\n%s", got)
+	}
+
+	want := "id,tags,custom.balance,refs.uses.count\nitems/sword,weapon;combat,\"{\"\"damage\"\":12}\",2\n"
+	if got := render("csv"); got != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := render("yaml"); !strings.HasPrefix(got, "- id: items/sword\n  tags:\n") || !strings.Contains(got, "refs.uses.count: 2") {
+		t.Errorf("unexpected YAML:\n%s", got)
+	}
+
+	got := render("table")
+	if !strings.Contains(got, "ID           TAGS") || !strings.Contains(got, "weapon, combat") || !strings.Contains(got, "Total: 1 node(s)") {
+		t.Errorf("unexpected table:\n%s", got)
+	}
+}
+
+func TestBlockTabular(t *testing.T) {
+	nodes := []domain.Node{{ID: "n1", Status: "draft"}, {ID: "n2", Status: "approved"}}
+	matches := []query.BlockMatch{
+		{NodeID: "n1", SectionName: "S", BlockIndex: 0, Block: domain.Block{Type: "recipe", Data: map[string]interface{}{"output": "Sword", "materials": []interface{}{"Iron"}}}},
+		{NodeID: "n2", SectionName: "T", BlockIndex: 2, Block: domain.Block{Type: "recipe", Data: map[string]interface{}{"output": "Axe", "time": 3, "meta": map[string]interface{}{"tier": 2}}}},
+	}
+
+	tab, err := blockTabular(matches, nodes, &outputFlags{format: "csv"})
+	if err != nil {
+		t.Fatalf("blockTabular failed: %v", err)
+	}
+	want := []string{"node", "section", "index", "type", "materials", "meta", "output", "time"}
+	if !reflect.DeepEqual(tab.columns, want) {
+		t.Errorf("columns = %v, want %v", tab.columns, want)
+	}
+
+	tab, err = blockTabular(matches, nodes, &outputFlags{columns: []string{"output", "meta.tier", "node.status"}, sort: "output"})
+	if err != nil {
+		t.Fatalf("blockTabular failed: %v", err)
+	}
+	if !reflect.DeepEqual(tab.rows, [][]interface{}{{"Axe", 2, "approved"}, {"Sword", nil, "draft"}}) {
+		t.Errorf("unexpected rows: %v", tab.rows)
+	}
+
+	if _, err := blockTabular(matches, nodes, &outputFlags{columns: []string{"node.bogus"}}); err == nil {
+		t.Error("unknown node column should fail")
+	}
+}
@@ -16,7 +16,9 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -31,6 +33,9 @@ import (
 type diffFlags struct {
 	since     string
 	last      int
+	versions  string
+	gitRange  string
+	format    string
 	targetDir string
 }
 
@@ -39,25 +44,75 @@ func NewDiffCommand() *cobra.Command {
 	flags := &diffFlags{}
 
 	cmd := &cobra.Command{
-		Use:   "diff <id> [directory]",
+		Use:   "diff <id> [vA..vB] [directory]",
 		Short: "Show changes to a node over time",
-		Long: `Show the change history for a specific node.
+		Long: `Show the change history for a specific node, or compare two versions.
 
-Displays before/after values for each change to the node.
-Use filters to limit the output.
+Without a version range, displays before/after values for each change to
+the node. Use filters to limit the output.
+
+With a range (v3..v7, or v3.. and v3 for up to the current version), the
+two versions are compared field by field: sections are matched by name,
+blocks by their id field, reordering is reported as a move, and text
+fields get a word-level diff ([-removed-]{+added+}). Versions come from
+the working tree, history snapshots, or the last git commit of that
+version when history has no snapshot.
+
+With --git and no node ID, the whole project is compared between two git
+revisions: nodes added, removed, moved (renamed) and changed, with the
+same field-level detail. A..B compares two commits, an empty side means
+HEAD, and a single revision is compared with the working tree.
 
 Examples:
   deco diff player-001                    # Show all changes to player-001
   deco diff player-001 --last 5           # Show last 5 changes
   deco diff player-001 --since 2024-01-01 # Changes since date
-  deco diff player-001 --since 2h         # Changes in last 2 hours`,
-		Args: cobra.RangeArgs(1, 2),
+  deco diff player-001 --since 2h         # Changes in last 2 hours
+  deco diff systems/combat v3..v7         # Structural diff of two versions
+  deco diff systems/combat v3 --format side-by-side
+  deco diff systems/combat v3..v7 --format json
+  deco diff --git main..feature           # Semantic diff for a pull request
+  deco diff --git HEAD~3                  # Since three commits ago, incl. uncommitted`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("git") {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+			return cobra.RangeArgs(1, 3)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.targetDir = "."
+			if !containsString(diffFormats, flags.format) {
+				return fmt.Errorf("unknown format: %s (supported: %s)", flags.format, strings.Join(diffFormats, ", "))
+			}
+			if cmd.Flags().Changed("git") {
+				if len(args) > 0 {
+					flags.targetDir = args[0]
+				}
+				if flags.since != "" || flags.last > 0 {
+					return fmt.Errorf("--since and --last filter history entries and cannot be combined with --git")
+				}
+				if flags.format == "side-by-side" {
+					return fmt.Errorf("--format side-by-side needs a version range (e.g. v3..v7)")
+				}
+				return runGitDiff(cmd.OutOrStdout(), flags)
+			}
+
 			nodeID := args[0]
-			if len(args) > 1 {
-				flags.targetDir = args[1]
-			} else {
-				flags.targetDir = "."
+			for _, arg := range args[1:] {
+				if _, _, ok := parseVersionRange(arg); ok && flags.versions == "" {
+					flags.versions = arg
+				} else {
+					flags.targetDir = arg
+				}
+			}
+			if flags.versions != "" {
+				if flags.since != "" || flags.last > 0 {
+					return fmt.Errorf("--since and --last filter history entries and cannot be combined with a version range")
+				}
+				return runVersionDiff(cmd.OutOrStdout(), nodeID, flags)
+			}
+			if flags.format == "side-by-side" {
+				return fmt.Errorf("--format side-by-side needs a version range (e.g. v3..v7)")
 			}
 			return runDiff(nodeID, flags)
 		},
@@ -65,6 +120,8 @@ Examples:
 
 	cmd.Flags().StringVar(&flags.since, "since", "", "Show changes since timestamp (RFC3339 or relative: 2h, 1d, 1w)")
 	cmd.Flags().IntVar(&flags.last, "last", 0, "Show only the last N changes (0 = all)")
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "unified", "Output format (unified, side-by-side, json)")
+	cmd.Flags().StringVar(&flags.gitRange, "git", "", "Compare the project between git revisions (A..B, or A for the working tree)")
 
 	return cmd
 }
@@ -125,6 +182,11 @@ func runDiff(nodeID string, flags *diffFlags) error {
 	}
 
 	// Display changes
+	if flags.format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
 	printDiff(nodeID, entries)
 	return nil
 }
@@ -186,11 +248,15 @@ func printDiff(nodeID string, entries []domain.AuditEntry) {
 	fmt.Println(strings.Repeat("=", 60))
 
 	for i, entry := range entries {
-		fmt.Printf("\n[%d] %s - %s by %s\n",
+		fmt.Printf("\n[%d] %s - %s by %s",
 			i+1,
 			entry.Timestamp.Format("2006-01-02 15:04:05"),
 			entry.Operation,
 			entry.User)
+		if entry.Commit != "" {
+			fmt.Printf(" at %s", shortRevision(entry.Commit))
+		}
+		fmt.Println()
 		fmt.Println(strings.Repeat("-", 40))
 
 		// Show before/after based on operation
@@ -0,0 +1,148 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/diff"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/git"
+	"github.com/Toernblom/deco/internal/storage/node"
+)
+
+// parseGitRange splits a revision range the way git diff reads it: A..B
+// compares two commits, an empty side means HEAD, and a single revision is
+// compared with the working tree (to is then empty).
+func parseGitRange(s string) (from, to string, err error) {
+	if strings.Contains(s, "...") {
+		return "", "", fmt.Errorf("symmetric ranges (A...B) are not supported; use A..B")
+	}
+	from, to, ok := strings.Cut(s, "..")
+	if !ok {
+		return s, "", nil
+	}
+	if from == "" {
+		from = "HEAD"
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	return from, to, nil
+}
+
+// gitSide is one end of a revision comparison.
+type gitSide struct {
+	Rev    string `json:"rev"`
+	Commit string `json:"commit,omitempty"`
+}
+
+func (s gitSide) String() string {
+	if s.Commit == "" {
+		return s.Rev
+	}
+	return fmt.Sprintf("%s (%s)", s.Rev, shortRevision(s.Commit))
+}
+
+// runGitDiff compares the nodes committed at two git revisions, or at one
+// revision and the working tree.
+func runGitDiff(w io.Writer, flags *diffFlags) error {
+	from, to, err := parseGitRange(flags.gitRange)
+	if err != nil {
+		return err
+	}
+
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+	nodesDir := config.ResolveNodesPath(cfg, flags.targetDir)
+
+	var sides [2]gitSide
+	var trees [2][]domain.Node
+	for i, rev := range []string{from, to} {
+		if rev == "" {
+			sides[i] = gitSide{Rev: "working tree"}
+			if trees[i], err = node.NewYAMLRepository(nodesDir).LoadAll(); err != nil {
+				return fmt.Errorf("failed to load nodes: %w", err)
+			}
+			continue
+		}
+		commit, err := git.ResolveRevision(flags.targetDir, rev)
+		if err != nil {
+			return err
+		}
+		sides[i] = gitSide{Rev: rev, Commit: commit}
+		if trees[i], err = node.LoadAllAtRevision(nodesDir, commit); err != nil {
+			return fmt.Errorf("failed to load nodes at %s: %w", rev, err)
+		}
+	}
+	changes := diff.Project(trees[0], trees[1])
+
+	if flags.format == "json" {
+		if changes == nil {
+			changes = []diff.NodeChange{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			From  gitSide           `json:"from"`
+			To    gitSide           `json:"to"`
+			Nodes []diff.NodeChange `json:"nodes"`
+		}{sides[0], sides[1], changes})
+	}
+	printProjectDiff(w, sides, changes)
+	return nil
+}
+
+func printProjectDiff(w io.Writer, sides [2]gitSide, changes []diff.NodeChange) {
+	fmt.Fprintf(w, "%s\n", style.Header.Sprintf("%s..%s", sides[0].Rev, sides[1].Rev))
+	fmt.Fprintf(w, "%s\n", style.Muted.Sprintf("--- %s", sides[0]))
+	fmt.Fprintf(w, "%s\n", style.Muted.Sprintf("+++ %s", sides[1]))
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "\nNo differences")
+		return
+	}
+	fmt.Fprintln(w)
+
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Kind]++
+		switch c.Kind {
+		case diff.Moved:
+			fmt.Fprintf(w, "%s %s: moved from %s\n", changeMarker(c.Kind), c.ID, c.From)
+		case diff.Added, diff.Removed:
+			fmt.Fprintf(w, "%s %s  %s\n", changeMarker(c.Kind), c.ID, style.Muted.Sprint(c.Title))
+		default:
+			fmt.Fprintf(w, "%s %s\n", changeMarker(c.Kind), c.ID)
+		}
+		printChanges(w, "    ", c.Changes)
+	}
+
+	var parts []string
+	for _, kind := range []string{diff.Added, diff.Removed, diff.Moved, diff.Modified} {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[kind], kind))
+		}
+	}
+	fmt.Fprintf(w, "\n%s %d node(s): %s\n", style.Muted.Sprint("Total:"), len(changes), strings.Join(parts, ", "))
+}
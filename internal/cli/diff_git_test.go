@@ -0,0 +1,225 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/node"
+)
+
+// runGit runs git in dir with a fixed identity and fails the test on error.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=alice", "-c", "user.email=alice@example.com"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestParseGitRange(t *testing.T) {
+	tests := []struct{ in, from, to string }{
+		{"main..feature", "main", "feature"},
+		{"main..", "main", "HEAD"},
+		{"..feature", "HEAD", "feature"},
+		{"HEAD~3", "HEAD~3", ""},
+	}
+	for _, tt := range tests {
+		from, to, err := parseGitRange(tt.in)
+		if err != nil || from != tt.from || to != tt.to {
+			t.Errorf("parseGitRange(%q) = %q, %q, %v; want %q, %q", tt.in, from, to, err, tt.from, tt.to)
+		}
+	}
+	if _, _, err := parseGitRange("main...feature"); err == nil {
+		t.Error("expected an error for a symmetric range")
+	}
+}
+
+func TestDiffCommand_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// milestone-1 has combat at v1; the working tree retitles it and adds magic
+	dir := setupTimeTravelProject(t, true)
+
+	t.Run("revision against working tree", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := runGitDiff(&buf, &diffFlags{targetDir: dir, gitRange: "milestone-1", format: "unified"}); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		for _, want := range []string{
+			"milestone-1..working tree",
+			"~ systems/combat",
+			"    ~ title",
+			"[-Melee combat-]{+Combat+}",
+			"+ systems/magic  Magic",
+			"Total: 2 node(s): 1 added, 1 modified",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected %q in:\n%s", want, out)
+			}
+		}
+	})
+
+	// Rename magic and commit, so the range covers a move
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "magic")
+	runGit(t, dir, "tag", "milestone-2")
+	nodeRepo := node.NewYAMLRepository(filepath.Join(dir, ".deco", "nodes"))
+	magic, err := nodeRepo.Load("systems/magic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeRepo.Delete("systems/magic"); err != nil {
+		t.Fatal(err)
+	}
+	magic.ID = "systems/spells"
+	if err := nodeRepo.Save(magic); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "rename magic")
+
+	t.Run("two revisions as json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := runGitDiff(&buf, &diffFlags{targetDir: dir, gitRange: "milestone-2..", format: "json"}); err != nil {
+			t.Fatal(err)
+		}
+		var result struct {
+			From, To struct{ Rev, Commit string }
+			Nodes    []struct{ ID, Kind, From string }
+		}
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		if result.To.Rev != "HEAD" || len(result.To.Commit) != 40 {
+			t.Errorf("expected HEAD resolved to a commit, got %+v", result.To)
+		}
+		if len(result.Nodes) != 1 || result.Nodes[0].ID != "systems/spells" || result.Nodes[0].Kind != "moved" || result.Nodes[0].From != "systems/magic" {
+			t.Errorf("expected one move, got %+v", result.Nodes)
+		}
+	})
+
+	t.Run("no differences", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := runGitDiff(&buf, &diffFlags{targetDir: dir, gitRange: "HEAD", format: "unified"}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "No differences") {
+			t.Errorf("expected no differences, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		err := runGitDiff(&bytes.Buffer{}, &diffFlags{targetDir: dir, gitRange: "nope..HEAD", format: "unified"})
+		if err == nil || !strings.Contains(err.Error(), `unknown git revision "nope"`) {
+			t.Errorf("expected an unknown revision error, got %v", err)
+		}
+	})
+
+	t.Run("command line", func(t *testing.T) {
+		cmd := NewDiffCommand()
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{"--git", "milestone-2..HEAD", dir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "> systems/spells: moved from systems/magic") {
+			t.Errorf("unexpected output:\n%s", buf.String())
+		}
+	})
+}
+
+func TestAuditAttribution_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := setupDecoProject(t)
+	createTestNode(t, dir, "systems/combat")
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "config", "user.name", "Alice Smith")
+	runGit(t, dir, "config", "user.email", "alice@example.com")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	head := runGit(t, dir, "rev-parse", "HEAD")
+
+	if got := GetCurrentUser(dir); got != "Alice Smith <alice@example.com>" {
+		t.Errorf("GetCurrentUser() = %q", got)
+	}
+
+	if _, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := history.NewYAMLRepository(filepath.Join(dir, ".deco", "history.jsonl")).Query(history.Filter{User: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("expected entries attributed to the git identity")
+	}
+	for _, e := range entries {
+		if e.User != "Alice Smith <alice@example.com>" || e.Commit != head {
+			t.Errorf("entry %s by %q at %q, want the git identity at %s", e.Operation, e.User, e.Commit, head)
+		}
+	}
+}
+
+func TestAuditAttribution_GitCallsPerCommand(t *testing.T) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil || runtime.GOOS == "windows" {
+		t.Skip("needs git and a POSIX shell")
+	}
+	dir := setupDecoProject(t)
+	for i := 0; i < 10; i++ {
+		createTestNode(t, dir, fmt.Sprintf("systems/node-%d", i))
+	}
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "config", "user.name", "Alice Smith")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	// Count the git processes sync starts with a wrapper first on PATH
+	bin := t.TempDir()
+	calls := filepath.Join(bin, "calls")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$*\" >> %q\nexec %q \"$@\"\n", calls, gitPath)
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	if _, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) > 2 {
+		t.Errorf("expected sync of 10 nodes to run git at most twice, got %d:\n%s", len(lines), data)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/diff"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"gopkg.in/yaml.v3"
+)
+
+// diffFormats are the formats accepted by deco diff --format.
+var diffFormats = []string{"unified", "side-by-side", "json"}
+
+// sideBySideWidth is the width of each column in side-by-side output.
+const sideBySideWidth = 40
+
+// versionRangePattern matches v3..v7, 3..7, v3.. and v3.
+var versionRangePattern = regexp.MustCompile(`^(?:v?(\d+)\.\.(?:v?(\d+))?|v(\d+))$`)
+
+// parseVersionRange parses a version range argument. to is 0 when the
+// range ends at the current version.
+func parseVersionRange(s string) (from, to int, ok bool) {
+	m := versionRangePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	if m[3] != "" {
+		from, _ = strconv.Atoi(m[3])
+		return from, 0, true
+	}
+	from, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		to, _ = strconv.Atoi(m[2])
+	}
+	return from, to, true
+}
+
+// diffSide is one end of a version comparison.
+type diffSide struct {
+	Version int    `json:"version"`
+	Source  string `json:"source"`
+	node    domain.Node
+}
+
+// runVersionDiff compares two versions of a node field by field.
+func runVersionDiff(w io.Writer, nodeID string, flags *diffFlags) error {
+	from, to, _ := parseVersionRange(flags.versions)
+
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	var current *domain.Node
+	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
+	if n, err := nodeRepo.Load(nodeID); err == nil {
+		current = &n
+	}
+	if to == 0 {
+		if current == nil {
+			return fmt.Errorf("node %q not found; give both versions (e.g. v%d..v%d)", nodeID, from, from+1)
+		}
+		to = current.Version
+	}
+
+	var sides [2]diffSide
+	for i, v := range []int{from, to} {
+		n, source, err := resolveVersion(cfg, flags.targetDir, nodeID, v, current)
+		if err != nil {
+			return err
+		}
+		sides[i] = diffSide{Version: v, Source: source, node: n}
+	}
+	changes := diff.Nodes(sides[0].node, sides[1].node)
+
+	switch flags.format {
+	case "json":
+		if changes == nil {
+			changes = []diff.Change{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Node    string        `json:"node"`
+			From    diffSide      `json:"from"`
+			To      diffSide      `json:"to"`
+			Changes []diff.Change `json:"changes"`
+		}{nodeID, sides[0], sides[1], changes})
+	case "side-by-side":
+		printSideBySide(w, nodeID, sides, changes)
+	default:
+		printUnified(w, nodeID, sides, changes)
+	}
+	return nil
+}
+
+// changeMarker returns the marker and color of a change kind.
+func changeMarker(kind string) string {
+	switch kind {
+	case diff.Added:
+		return style.Success.Sprint("+")
+	case diff.Removed:
+		return style.Error.Sprint("-")
+	case diff.Moved:
+		return style.Info.Sprint(">")
+	}
+	return style.Warning.Sprint("~")
+}
+
+func printDiffHeader(w io.Writer, nodeID string, sides [2]diffSide) {
+	fmt.Fprintf(w, "%s\n", style.Header.Sprintf("%s v%d..v%d", nodeID, sides[0].Version, sides[1].Version))
+	fmt.Fprintf(w, "%s\n", style.Muted.Sprintf("--- v%d (%s)", sides[0].Version, sides[0].Source))
+	fmt.Fprintf(w, "%s\n", style.Muted.Sprintf("+++ v%d (%s)", sides[1].Version, sides[1].Source))
+}
+
+func printUnified(w io.Writer, nodeID string, sides [2]diffSide, changes []diff.Change) {
+	printDiffHeader(w, nodeID, sides)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "\nNo differences")
+		return
+	}
+	fmt.Fprintln(w)
+	printChanges(w, "", changes)
+	fmt.Fprintf(w, "\n%s %d change(s)\n", style.Muted.Sprint("Total:"), len(changes))
+}
+
+// printChanges writes field changes one per line, with their values
+// indented below them.
+func printChanges(w io.Writer, indent string, changes []diff.Change) {
+	for _, c := range changes {
+		switch c.Kind {
+		case diff.Moved:
+			fmt.Fprintf(w, "%s%s %s: %s\n", indent, changeMarker(c.Kind), c.Path, moveText(c))
+		case diff.Added:
+			fmt.Fprintf(w, "%s%s %s\n", indent, changeMarker(c.Kind), c.Path)
+			printIndented(w, indent, "+ ", c.After, style.Success.Sprint)
+		case diff.Removed:
+			fmt.Fprintf(w, "%s%s %s\n", indent, changeMarker(c.Kind), c.Path)
+			printIndented(w, indent, "- ", c.Before, style.Error.Sprint)
+		default:
+			fmt.Fprintf(w, "%s%s %s\n", indent, changeMarker(c.Kind), c.Path)
+			if len(c.Words) > 0 {
+				printIndented(w, indent, "", wordText(c.Words, true, true), fmt.Sprint)
+				continue
+			}
+			printIndented(w, indent, "- ", c.Before, style.Error.Sprint)
+			printIndented(w, indent, "+ ", c.After, style.Success.Sprint)
+		}
+	}
+}
+
+func printSideBySide(w io.Writer, nodeID string, sides [2]diffSide, changes []diff.Change) {
+	printDiffHeader(w, nodeID, sides)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "\nNo differences")
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "    %s | %s\n", padColumn(style.Header.Sprintf("v%d", sides[0].Version)), style.Header.Sprintf("v%d", sides[1].Version))
+
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s %s\n", changeMarker(c.Kind), c.Path)
+		var left, right string
+		switch {
+		case c.Kind == diff.Moved:
+			left, right = moveEnd(c.Before), moveEnd(c.After)
+		case len(c.Words) > 0:
+			left, right = wordText(c.Words, true, false), wordText(c.Words, false, true)
+		default:
+			left, right = valueText(c.Before), valueText(c.After)
+		}
+		l, r := wrapColumn(left), wrapColumn(right)
+		for i := 0; i < len(l) || i < len(r); i++ {
+			var ls, rs string
+			if i < len(l) {
+				ls = l[i]
+			}
+			if i < len(r) {
+				rs = r[i]
+			}
+			fmt.Fprintf(w, "    %s | %s\n", padColumn(ls), rs)
+		}
+	}
+	fmt.Fprintf(w, "\n%s %d change(s)\n", style.Muted.Sprint("Total:"), len(changes))
+}
+
+// moveText describes a move: a new position in the same list, or the path
+// a block came from.
+func moveText(c diff.Change) string {
+	if from, ok := c.Before.(string); ok {
+		return "moved from " + from
+	}
+	return fmt.Sprintf("moved from position %v to %v", c.Before, c.After)
+}
+
+func moveEnd(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("position %v", v)
+}
+
+// wordText renders a word diff with [-deleted-] and {+inserted+} markers,
+// keeping the old side, the new side or both.
+func wordText(words []diff.Word, old, new bool) string {
+	var b strings.Builder
+	for _, word := range words {
+		switch word.Op {
+		case diff.Equal:
+			b.WriteString(word.Text)
+		case diff.Delete:
+			if old {
+				b.WriteString(style.Error.Sprint("[-" + word.Text + "-]"))
+			}
+		case diff.Insert:
+			if new {
+				b.WriteString(style.Success.Sprint("{+" + word.Text + "+}"))
+			}
+		}
+	}
+	return b.String()
+}
+
+// valueText renders a value: scalars as text, collections as YAML.
+func valueText(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case map[string]interface{}, []interface{}:
+		data, err := yaml.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return strings.TrimRight(string(data), "\n")
+	}
+	return fmt.Sprint(v)
+}
+
+// printIndented writes a value under a change, one prefixed line per line.
+func printIndented(w io.Writer, indent, prefix string, v interface{}, color func(...interface{}) string) {
+	for _, line := range strings.Split(valueText(v), "\n") {
+		fmt.Fprintf(w, "%s    %s\n", indent, color(prefix+line))
+	}
+}
+
+var escapePattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// padColumn pads text to sideBySideWidth, ignoring color escape codes.
+func padColumn(text string) string {
+	width := len([]rune(escapePattern.ReplaceAllString(text, "")))
+	if width >= sideBySideWidth {
+		return text
+	}
+	return text + strings.Repeat(" ", sideBySideWidth-width)
+}
+
+// wrapColumn splits text into lines of at most sideBySideWidth runes.
+// Colored text is left unwrapped so escape codes are not split.
+func wrapColumn(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		if style.IsEnabled() || len(runes) <= sideBySideWidth {
+			lines = append(lines, line)
+			continue
+		}
+		for len(runes) > sideBySideWidth {
+			lines = append(lines, string(runes[:sideBySideWidth]))
+			runes = runes[sideBySideWidth:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
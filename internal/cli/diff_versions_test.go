@@ -0,0 +1,163 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseVersionRange(t *testing.T) {
+	tests := []struct {
+		in       string
+		from, to int
+		ok       bool
+	}{
+		{"v3..v7", 3, 7, true},
+		{"3..7", 3, 7, true},
+		{"v3..", 3, 0, true},
+		{"v3", 3, 0, true},
+		{"3", 0, 0, false},
+		{"v3..v", 0, 0, false},
+		{"./project", 0, 0, false},
+	}
+	for _, tt := range tests {
+		from, to, ok := parseVersionRange(tt.in)
+		if from != tt.from || to != tt.to || ok != tt.ok {
+			t.Errorf("parseVersionRange(%q) = %d, %d, %v; want %d, %d, %v", tt.in, from, to, ok, tt.from, tt.to, tt.ok)
+		}
+	}
+}
+
+func TestDiffCommand_VersionRange(t *testing.T) {
+	dir, _ := setupVersionedNode(t)
+
+	t.Run("unified", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := runVersionDiff(&buf, "systems/combat", &diffFlags{targetDir: dir, versions: "v1..v3", format: "unified"}); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		for _, want := range []string{
+			"systems/combat v1..v3",
+			"--- v1 (history snapshot)",
+			"+++ v3 (working tree)",
+			"~ version",
+			"~ title",
+			"[-Test-]{+Combat+} [-Node-]{+v3+}",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected %q in:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("open range ends at the current version", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := runVersionDiff(&buf, "systems/combat", &diffFlags{targetDir: dir, versions: "v2", format: "unified"}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "systems/combat v2..v3") {
+			t.Errorf("expected v2..v3, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("side-by-side", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := runVersionDiff(&buf, "systems/combat", &diffFlags{targetDir: dir, versions: "v2..v3", format: "side-by-side"}); err != nil {
+			t.Fatal(err)
+		}
+		want := "    " + padColumn("Combat") + " | Combat{+ v3+}"
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, buf.String())
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := runVersionDiff(&buf, "systems/combat", &diffFlags{targetDir: dir, versions: "v1..v2", format: "json"}); err != nil {
+			t.Fatal(err)
+		}
+		var result struct {
+			From    struct{ Version int }
+			To      struct{ Version int }
+			Changes []struct {
+				Path, Kind    string
+				Before, After interface{}
+			}
+		}
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		if result.From.Version != 1 || result.To.Version != 2 {
+			t.Errorf("unexpected range %d..%d", result.From.Version, result.To.Version)
+		}
+		var paths []string
+		for _, c := range result.Changes {
+			paths = append(paths, c.Kind+" "+c.Path)
+		}
+		if got := strings.Join(paths, ","); got != "modified version,modified title" {
+			t.Errorf("changes = %s", got)
+		}
+	})
+
+	t.Run("same version", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := runVersionDiff(&buf, "systems/combat", &diffFlags{targetDir: dir, versions: "v2..v2", format: "unified"}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "No differences") {
+			t.Errorf("expected no differences, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		err := runVersionDiff(&bytes.Buffer{}, "systems/combat", &diffFlags{targetDir: dir, versions: "v1..v9", format: "unified"})
+		if err == nil || !strings.Contains(err.Error(), "available: 1, 2, 3") {
+			t.Errorf("expected the available versions in the error, got %v", err)
+		}
+	})
+
+	t.Run("range with history filters", func(t *testing.T) {
+		cmd := NewDiffCommand()
+		cmd.SetArgs([]string{"systems/combat", "v1..v2", dir, "--last", "2"})
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		if err := cmd.Execute(); err == nil {
+			t.Error("expected an error combining a version range with --last")
+		}
+	})
+}
+
+func TestDiffCommand_VersionFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Version 1 predates snapshots and is only in git
+	dir := setupTimeTravelProject(t, true)
+
+	var buf bytes.Buffer
+	if err := runVersionDiff(&buf, "systems/combat", &diffFlags{targetDir: dir, versions: "v1..v2", format: "unified"}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "--- v1 (git ") || !strings.Contains(out, "[-Melee combat-]{+Combat+}") {
+		t.Errorf("expected v1 from git, got:\n%s", out)
+	}
+}
@@ -39,6 +39,9 @@ type exportFlags struct {
 	kind      string
 	status    string
 	tag       string
+	query     string   // saved query limiting the exported nodes
+	params    []string // name=value pairs for the saved query
+	release   string   // release whose frozen nodes are exported
 }
 
 // NewExportCommand creates the export subcommand
@@ -60,6 +63,15 @@ Compact mode (--compact) produces LLM-optimized dense output:
   deco export --compact systems/combat --follow    # Node + dependencies
   deco export --compact --kind system --follow uses --depth 2
 
+A saved query (see 'deco query --list-saved') limits the export to the
+nodes it selects; block queries select the nodes containing the blocks:
+  deco export --query @bronze-buildings --param age=iron
+  deco export --compact --query @open-balance-issues
+
+With --release, the nodes frozen in a release (see 'deco release') are
+exported instead of the working tree:
+  deco export --release v1.2 --output docs/v1.2/
+
 Examples:
   deco export systems/combat              # Single node to stdout
   deco export                             # All nodes to stdout
@@ -100,6 +112,9 @@ Examples:
 	cmd.Flags().StringVarP(&flags.kind, "kind", "k", "", "Filter by node kind")
 	cmd.Flags().StringVarP(&flags.status, "status", "s", "", "Filter by status")
 	cmd.Flags().StringVarP(&flags.tag, "tag", "t", "", "Filter by tag")
+	cmd.Flags().StringVar(&flags.query, "query", "", "Only export nodes selected by a saved query (@name)")
+	cmd.Flags().StringArrayVar(&flags.params, "param", nil, "Saved query parameter (name=value, repeatable)")
+	cmd.Flags().StringVar(&flags.release, "release", "", "Export the nodes frozen in a release")
 	cmd.Flags().Lookup("follow").NoOptDefVal = "uses"
 
 	return cmd
@@ -110,8 +125,14 @@ func runExport(nodeID string, flags *exportFlags) error {
 	if !flags.compact && (flags.follow != "" || flags.depth != 1) {
 		return fmt.Errorf("--follow and --depth require --compact")
 	}
+	if flags.query == "" && len(flags.params) > 0 {
+		return fmt.Errorf("--param requires --query")
+	}
 
 	if flags.obsidian {
+		if flags.release != "" {
+			return fmt.Errorf("--release cannot be combined with --obsidian")
+		}
 		return runObsidianExport(flags)
 	}
 
@@ -127,21 +148,50 @@ func runExport(nodeID string, flags *exportFlags) error {
 	}
 
 	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
+	loadAll := nodeRepo.LoadAll
+	load := nodeRepo.Load
+	if flags.release != "" {
+		_, frozen, err := loadRelease(cfg, flags.targetDir, flags.release)
+		if err != nil {
+			return err
+		}
+		loadAll = func() ([]domain.Node, error) { return frozen, nil }
+		load = func(id string) (domain.Node, error) {
+			for _, n := range frozen {
+				if n.ID == id {
+					return n, nil
+				}
+			}
+			return domain.Node{}, fmt.Errorf("not in release %s", flags.release)
+		}
+	}
 
 	var nodes []domain.Node
 	if nodeID != "" {
-		n, err := nodeRepo.Load(nodeID)
+		n, err := load(nodeID)
 		if err != nil {
 			return fmt.Errorf("node %q not found: %w", nodeID, err)
 		}
 		nodes = []domain.Node{n}
 	} else {
-		nodes, err = nodeRepo.LoadAll()
+		nodes, err = loadAll()
 		if err != nil {
 			return fmt.Errorf("failed to load nodes: %w", err)
 		}
 	}
 
+	if flags.query != "" {
+		allNodes := nodes
+		if nodeID != "" {
+			if allNodes, err = loadAll(); err != nil {
+				return fmt.Errorf("failed to load nodes: %w", err)
+			}
+		}
+		if nodes, err = filterBySavedQuery(nodes, allNodes, cfg, flags); err != nil {
+			return err
+		}
+	}
+
 	if len(nodes) == 0 {
 		fmt.Println("No nodes found.")
 		return nil
@@ -171,11 +221,17 @@ func runCompactExport(nodeID string, flags *exportFlags) error {
 		return fmt.Errorf(".deco directory not found or invalid: %w", err)
 	}
 
-	// Load all nodes
-	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
-	allNodes, err := nodeRepo.LoadAll()
-	if err != nil {
-		return fmt.Errorf("failed to load nodes: %w", err)
+	// Load all nodes, from the release when one is given
+	var allNodes []domain.Node
+	if flags.release != "" {
+		if _, allNodes, err = loadRelease(cfg, flags.targetDir, flags.release); err != nil {
+			return err
+		}
+	} else {
+		nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
+		if allNodes, err = nodeRepo.LoadAll(); err != nil {
+			return fmt.Errorf("failed to load nodes: %w", err)
+		}
 	}
 
 	// Determine root nodes
@@ -208,6 +264,12 @@ func runCompactExport(nodeID string, flags *exportFlags) error {
 		rootNodes = qe.Filter(allNodes, criteria)
 	}
 
+	if flags.query != "" {
+		if rootNodes, err = filterBySavedQuery(rootNodes, allNodes, cfg, flags); err != nil {
+			return err
+		}
+	}
+
 	if len(rootNodes) == 0 {
 		fmt.Println("No nodes found.")
 		return nil
@@ -256,6 +318,22 @@ func runCompactExport(nodeID string, flags *exportFlags) error {
 	return nil
 }
 
+// filterBySavedQuery keeps the nodes selected by the saved query given
+// with --query, evaluated against all nodes.
+func filterBySavedQuery(nodes, allNodes []domain.Node, cfg config.Config, flags *exportFlags) ([]domain.Node, error) {
+	ids, err := savedQueryNodeIDs(flags.query, flags.params, cfg, allNodes)
+	if err != nil {
+		return nil, err
+	}
+	var kept []domain.Node
+	for _, n := range nodes {
+		if ids[n.ID] {
+			kept = append(kept, n)
+		}
+	}
+	return kept, nil
+}
+
 func exportToDirectory(nodes []domain.Node, outputDir string) error {
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -17,19 +17,32 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 
 	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/graph"
+	"github.com/Toernblom/deco/internal/services/query"
 	"github.com/Toernblom/deco/internal/storage/config"
-	"github.com/Toernblom/deco/internal/storage/node"
 	"github.com/spf13/cobra"
 )
 
 type graphFlags struct {
-	format    string
-	ascii     bool
-	targetDir string
+	format     string
+	ascii      bool
+	roots      []string
+	depth      int
+	direction  string
+	kind       string
+	status     string
+	tag        string
+	refTypes   []string
+	linkBase   string
+	blocks     bool
+	blockTypes []string
+	asOf       string
+	targetDir  string
 }
 
 // NewGraphCommand creates the graph subcommand
@@ -39,20 +52,56 @@ func NewGraphCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "graph [directory]",
 		Short: "Output dependency graph",
-		Long: `Output the node dependency graph in DOT or Mermaid format.
+		Long: `Output the node dependency graph for visualization tools.
 
-Edges are created from refs.uses and refs.related fields.
+Edges are created from refs.uses, refs.related, @node refs in contract
+steps, and block cross-references (ref constraints on custom block types).
+Nodes are clustered by ID prefix ("systems/combat" goes in "systems").
+Draft nodes are drawn grey; deprecated and archived nodes are dashed.
 
 Formats:
-  dot      Graphviz DOT format (default)
-  mermaid  Mermaid flowchart for Markdown embedding
-  ascii    Simple ASCII layered view grouped by category
+  dot        Graphviz DOT format (default)
+  mermaid    Mermaid flowchart for Markdown embedding
+  ascii      Simple ASCII layered view grouped by category
+  graphml    GraphML XML for yEd, Gephi and similar tools
+  cytoscape  Cytoscape.js JSON (elements with compound cluster nodes)
+  d2         D2 diagram source
+  plantuml   PlantUML diagram source
+  html       Self-contained interactive explorer (pan, zoom, search,
+             kind/tag filters, node details); use --link-base to link
+             nodes to pages written by "deco export --output"
+
+Subgraph selection:
+  --root ID --depth N   Only nodes within N hops of the root(s)
+  --direction DIR       Hop direction from the root: out (dependencies),
+                        in (dependents) or both (default)
+  --kind, --status, --tag
+                        Only nodes matching all given filters (roots are
+                        always kept)
+  --ref-type TYPES      Only these edge types (uses, related, contract,
+                        crossref)
+
+Block graph:
+  --blocks              Graph of content blocks instead of nodes: vertices
+                        are blocks (node#section/block-id), edges are ref
+                        constraints declared on custom block fields, e.g. a
+                        crafting or tech tree. Supports dot, mermaid, json.
+  --block-type TYPES    Only blocks of these types
+
+` + asOfHelp + `
 
 Examples:
   deco graph
   deco graph --format mermaid
   deco graph --ascii
-  deco graph | dot -Tpng -o graph.png`,
+  deco graph --root systems/combat --depth 2
+  deco graph --root systems/combat --direction in --format d2
+  deco graph --kind system --status approved --format graphml > design.graphml
+  deco graph --ref-type uses | dot -Tpng -o graph.png
+  deco graph --format html --link-base docs/ > graph.html
+  deco graph --blocks --block-type recipe,resource
+  deco graph --blocks --format json
+  deco graph --as-of v1.0 --format mermaid`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
@@ -63,17 +112,65 @@ Examples:
 			if flags.ascii {
 				flags.format = "ascii"
 			}
-			return runGraph(flags)
+			return runGraph(cmd.OutOrStdout(), flags)
 		},
 	}
 
-	cmd.Flags().StringVarP(&flags.format, "format", "f", "dot", "Output format (dot, mermaid, ascii)")
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "dot", "Output format (dot, mermaid, ascii, graphml, cytoscape, d2, plantuml, html)")
 	cmd.Flags().BoolVar(&flags.ascii, "ascii", false, "Shorthand for --format ascii")
+	cmd.Flags().StringSliceVar(&flags.roots, "root", nil, "Only include nodes reachable from these node IDs")
+	cmd.Flags().IntVar(&flags.depth, "depth", 0, "Maximum hops from --root (0 = unlimited)")
+	cmd.Flags().StringVar(&flags.direction, "direction", graph.DirectionBoth, "Hop direction from --root (out, in, both)")
+	cmd.Flags().StringVarP(&flags.kind, "kind", "k", "", "Only include nodes of this kind")
+	cmd.Flags().StringVarP(&flags.status, "status", "s", "", "Only include nodes with this status")
+	cmd.Flags().StringVarP(&flags.tag, "tag", "t", "", "Only include nodes with this tag")
+	cmd.Flags().StringSliceVar(&flags.refTypes, "ref-type", nil, "Edge types to include: uses, related, contract, crossref (default: all)")
+	cmd.Flags().BoolVar(&flags.blocks, "blocks", false, "Graph blocks linked by ref constraints instead of nodes")
+	cmd.Flags().StringSliceVar(&flags.blockTypes, "block-type", nil, "With --blocks: only include these block types")
+	addAsOfFlag(cmd, &flags.asOf)
+	cmd.Flags().StringVar(&flags.linkBase, "link-base", "", "HTML format: path or URL prefix of exported node pages (<prefix><id>.md)")
 
 	return cmd
 }
 
-func runGraph(flags *graphFlags) error {
+func runGraph(w io.Writer, flags *graphFlags) error {
+	if flags.blocks {
+		switch flags.format {
+		case "dot", "mermaid", "json":
+		default:
+			return fmt.Errorf("unknown format for --blocks: %s (supported: dot, mermaid, json)", flags.format)
+		}
+		if len(flags.roots) > 0 || len(flags.refTypes) > 0 {
+			return fmt.Errorf("--root and --ref-type cannot be used with --blocks")
+		}
+	} else {
+		switch flags.format {
+		case "dot", "mermaid", "ascii", "graphml", "cytoscape", "d2", "plantuml", "html":
+		default:
+			return fmt.Errorf("unknown format: %s (supported: dot, mermaid, ascii, graphml, cytoscape, d2, plantuml, html)", flags.format)
+		}
+		if len(flags.blockTypes) > 0 {
+			return fmt.Errorf("--block-type requires --blocks")
+		}
+	}
+	switch flags.direction {
+	case graph.DirectionOut, graph.DirectionIn, graph.DirectionBoth:
+	default:
+		return fmt.Errorf("unknown direction: %s (supported: out, in, both)", flags.direction)
+	}
+	allowed := make(map[string]bool)
+	for _, t := range flags.refTypes {
+		switch t {
+		case graph.EdgeUses, graph.EdgeRelated, graph.EdgeContract, graph.EdgeCrossRef:
+			allowed[t] = true
+		default:
+			return fmt.Errorf("unknown ref type: %s (supported: uses, related, contract, crossref)", t)
+		}
+	}
+	if err := validateStatus(flags.status); err != nil {
+		return err
+	}
+
 	// Load config to verify project exists
 	configRepo := config.NewYAMLRepository(flags.targetDir)
 	cfg, err := configRepo.Load()
@@ -82,121 +179,269 @@ func runGraph(flags *graphFlags) error {
 	}
 
 	// Load all nodes
-	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
-	nodes, err := nodeRepo.LoadAll()
+	nodes, err := loadNodes(cfg, flags.targetDir, flags.asOf)
 	if err != nil {
-		return fmt.Errorf("failed to load nodes: %w", err)
+		return err
 	}
 
 	if len(nodes) == 0 {
-		fmt.Println("No nodes found")
+		fmt.Fprintln(w, "No nodes found")
 		return nil
 	}
+	if err := validateKind(flags.kind, nodes); err != nil {
+		return err
+	}
+	if flags.blocks {
+		return runBlockGraph(w, nodes, cfg, flags)
+	}
+
+	builder := graph.NewBuilder()
+	g, err := builder.Build(nodes)
+	if err != nil {
+		return fmt.Errorf("failed to build graph: %w", err)
+	}
+	for _, id := range flags.roots {
+		if _, ok := g.Get(id); !ok {
+			return fmt.Errorf("node '%s' not found", id)
+		}
+	}
 
 	// Build edges
-	edges := buildEdges(nodes)
+	var all []graph.Edge
+	for _, e := range builder.BuildEdges(g, cfg.CustomBlockTypes) {
+		if len(allowed) == 0 || allowed[e.RefType] {
+			all = append(all, e)
+		}
+	}
+
+	nodes, edges := selectSubgraph(builder, g, all, flags)
 
 	// Output in requested format
 	switch flags.format {
 	case "dot":
-		outputDOT(nodes, edges)
+		outputDOT(w, nodes, edges)
 	case "mermaid":
-		outputMermaid(nodes, edges)
+		outputMermaid(w, nodes, edges)
 	case "ascii":
-		outputASCII(nodes, edges)
-	default:
-		return fmt.Errorf("unknown format: %s (supported: dot, mermaid, ascii)", flags.format)
+		outputASCII(w, nodes, edges)
+	case "graphml":
+		outputGraphML(w, nodes, edges)
+	case "cytoscape":
+		return outputCytoscape(w, nodes, edges)
+	case "d2":
+		outputD2(w, nodes, edges)
+	case "plantuml":
+		outputPlantUML(w, nodes, edges)
+	case "html":
+		return outputHTML(w, cfg.ProjectName, nodes, edges, flags.linkBase)
 	}
 
 	return nil
 }
 
+// selectSubgraph applies the root/depth/direction and kind/status/tag
+// selection, returning the kept nodes sorted by ID and the edges between them.
+func selectSubgraph(builder *graph.Builder, g *domain.Graph, edges []graph.Edge, flags *graphFlags) ([]domain.Node, []edge) {
+	var reachable map[string]bool
+	if len(flags.roots) > 0 {
+		reachable = builder.Neighborhood(edges, flags.roots, flags.depth, flags.direction)
+	}
+	isRoot := make(map[string]bool)
+	for _, id := range flags.roots {
+		isRoot[id] = true
+	}
+
+	criteria := query.FilterCriteria{}
+	if flags.kind != "" {
+		criteria.Kind = &flags.kind
+	}
+	if flags.status != "" {
+		criteria.Status = &flags.status
+	}
+	if flags.tag != "" {
+		criteria.Tags = []string{flags.tag}
+	}
+
+	var candidates []domain.Node
+	for _, n := range g.All() {
+		if (reachable == nil || reachable[n.ID]) && !isRoot[n.ID] {
+			candidates = append(candidates, n)
+		}
+	}
+
+	nodes := query.New().Filter(candidates, criteria)
+	for _, id := range flags.roots {
+		if n, ok := g.Get(id); ok && !containsNode(nodes, id) {
+			nodes = append(nodes, n)
+		}
+	}
+	kept := make(map[string]bool)
+	for _, n := range nodes {
+		kept[n.ID] = true
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	var result []edge
+	for _, e := range edges {
+		if kept[e.From] && kept[e.To] {
+			result = append(result, edge{from: e.From, to: e.To, refType: e.RefType, context: e.Context})
+		}
+	}
+	return nodes, result
+}
+
+func containsNode(nodes []domain.Node, id string) bool {
+	for _, n := range nodes {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 type edge struct {
 	from    string
 	to      string
-	refType string // "uses" or "related"
+	refType string // "uses", "related", "contract" or "crossref"
+	context string
 }
 
-func buildEdges(nodes []domain.Node) []edge {
-	var edges []edge
-
+// clusterNodes groups nodes by ID prefix. Nodes without a prefix are
+// returned separately. Cluster names are sorted.
+func clusterNodes(nodes []domain.Node) (names []string, clusters map[string][]domain.Node, loose []domain.Node) {
+	clusters = make(map[string][]domain.Node)
 	for _, n := range nodes {
-		// Add edges from uses refs
-		for _, ref := range n.Refs.Uses {
-			edges = append(edges, edge{
-				from:    n.ID,
-				to:      ref.Target,
-				refType: "uses",
-			})
+		if !strings.Contains(n.ID, "/") {
+			loose = append(loose, n)
+			continue
 		}
-
-		// Add edges from related refs
-		for _, ref := range n.Refs.Related {
-			edges = append(edges, edge{
-				from:    n.ID,
-				to:      ref.Target,
-				refType: "related",
-			})
+		cat := getCategory(n.ID)
+		if _, ok := clusters[cat]; !ok {
+			names = append(names, cat)
 		}
+		clusters[cat] = append(clusters[cat], n)
 	}
+	sort.Strings(names)
+	return names, clusters, loose
+}
 
-	return edges
+// isRetired reports whether a node status is drawn dashed.
+func isRetired(status string) bool {
+	return status == "deprecated" || status == "archived"
 }
 
-func outputDOT(nodes []domain.Node, edges []edge) {
-	fmt.Println("digraph deco {")
-	fmt.Println("  rankdir=LR;")
-	fmt.Println("  node [shape=box];")
-	fmt.Println()
+func outputDOT(w io.Writer, nodes []domain.Node, edges []edge) {
+	fmt.Fprintln(w, "digraph deco {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	fmt.Fprintln(w)
 
-	// Declare nodes with labels
-	for _, n := range nodes {
+	// Declare nodes with labels, clustered by ID prefix
+	declare := func(n domain.Node, indent string) {
 		label := strings.ReplaceAll(n.Title, "\"", "\\\"")
-		nodeID := dotID(n.ID)
-		fmt.Printf("  %s [label=\"%s\\n(%s)\"];\n", nodeID, label, n.ID)
+		attrs := fmt.Sprintf("label=\"%s\\n(%s)\"", label, n.ID)
+		if n.Status == "draft" {
+			attrs += ", color=grey, fontcolor=grey"
+		}
+		if isRetired(n.Status) {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(w, "%s%s [%s];\n", indent, dotID(n.ID), attrs)
+	}
+
+	names, clusters, loose := clusterNodes(nodes)
+	for _, name := range names {
+		fmt.Fprintf(w, "  subgraph %s {\n", dotID("cluster_"+name))
+		fmt.Fprintf(w, "    label=%s;\n", dotID(name))
+		for _, n := range clusters[name] {
+			declare(n, "    ")
+		}
+		fmt.Fprintln(w, "  }")
+	}
+	for _, n := range loose {
+		declare(n, "  ")
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	// Declare edges
 	for _, e := range edges {
 		fromID := dotID(e.from)
 		toID := dotID(e.to)
 		style := ""
-		if e.refType == "related" {
+		switch e.refType {
+		case "related":
 			style = " [style=dashed]"
+		case "contract", "crossref":
+			style = fmt.Sprintf(" [style=dotted, label=\"%s\"]", e.refType)
 		}
-		fmt.Printf("  %s -> %s%s;\n", fromID, toID, style)
+		fmt.Fprintf(w, "  %s -> %s%s;\n", fromID, toID, style)
 	}
 
-	fmt.Println("}")
+	fmt.Fprintln(w, "}")
 }
 
-func outputMermaid(nodes []domain.Node, edges []edge) {
-	fmt.Println("```mermaid")
-	fmt.Println("flowchart LR")
+func outputMermaid(w io.Writer, nodes []domain.Node, edges []edge) {
+	fmt.Fprintln(w, "```mermaid")
+	fmt.Fprintln(w, "flowchart LR")
 
-	// Declare nodes with labels
-	for _, n := range nodes {
+	// Declare nodes with labels, clustered by ID prefix
+	declare := func(n domain.Node, indent string) {
 		label := strings.ReplaceAll(n.Title, "\"", "'")
-		nodeID := mermaidID(n.ID)
-		fmt.Printf("  %s[\"%s\"]\n", nodeID, label)
+		fmt.Fprintf(w, "%s%s[\"%s\"]\n", indent, mermaidID(n.ID), label)
+	}
+
+	names, clusters, loose := clusterNodes(nodes)
+	for _, name := range names {
+		fmt.Fprintf(w, "  subgraph %s [\"%s\"]\n", mermaidID("cluster/"+name), name)
+		for _, n := range clusters[name] {
+			declare(n, "    ")
+		}
+		fmt.Fprintln(w, "  end")
+	}
+	for _, n := range loose {
+		declare(n, "  ")
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	// Declare edges
 	for _, e := range edges {
 		fromID := mermaidID(e.from)
 		toID := mermaidID(e.to)
-		if e.refType == "related" {
-			fmt.Printf("  %s -.-> %s\n", fromID, toID)
-		} else {
-			fmt.Printf("  %s --> %s\n", fromID, toID)
+		switch e.refType {
+		case "related":
+			fmt.Fprintf(w, "  %s -.-> %s\n", fromID, toID)
+		case "contract", "crossref":
+			fmt.Fprintf(w, "  %s -.->|%s| %s\n", fromID, e.refType, toID)
+		default:
+			fmt.Fprintf(w, "  %s --> %s\n", fromID, toID)
+		}
+	}
+
+	// Status styling
+	var draft, retired []string
+	for _, n := range nodes {
+		if n.Status == "draft" {
+			draft = append(draft, mermaidID(n.ID))
+		}
+		if isRetired(n.Status) {
+			retired = append(retired, mermaidID(n.ID))
+		}
+	}
+	if len(draft) > 0 || len(retired) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "  classDef draft stroke:#999,color:#999")
+		fmt.Fprintln(w, "  classDef retired stroke-dasharray:5 5")
+		if len(draft) > 0 {
+			fmt.Fprintf(w, "  class %s draft\n", strings.Join(draft, ","))
+		}
+		if len(retired) > 0 {
+			fmt.Fprintf(w, "  class %s retired\n", strings.Join(retired, ","))
 		}
 	}
 
-	fmt.Println("```")
+	fmt.Fprintln(w, "```")
 }
 
 // dotID converts a node ID to a valid DOT identifier
@@ -212,7 +457,7 @@ func mermaidID(id string) string {
 }
 
 // outputASCII renders an ASCII graph grouped by category with branching connectors
-func outputASCII(nodes []domain.Node, edges []edge) {
+func outputASCII(w io.Writer, nodes []domain.Node, edges []edge) {
 	if len(nodes) == 0 {
 		return
 	}
@@ -244,20 +489,20 @@ func outputASCII(nodes []domain.Node, edges []edge) {
 	layers := topoSortCategories(categories, catEdges)
 
 	// Render
-	fmt.Println()
+	fmt.Fprintln(w)
 	for i, layer := range layers {
 		// Render category header
-		renderCategoryHeader(layer)
+		renderCategoryHeader(w, layer)
 
 		// Render nodes in each category
-		renderCategoryNodes(layer, categories)
+		renderCategoryNodes(w, layer, categories)
 
 		// Render connectors
 		if i < len(layers)-1 {
-			renderCatConnectors(layer, layers[i+1], catEdges)
+			renderCatConnectors(w, layer, layers[i+1], catEdges)
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // getCategory extracts the category (folder) from a node ID
@@ -318,7 +563,7 @@ func topoSortCategories(categories map[string][]string, catEdges map[string]map[
 }
 
 // renderCategoryHeader prints category names
-func renderCategoryHeader(layer []string) {
+func renderCategoryHeader(w io.Writer, layer []string) {
 	var names []string
 	for _, cat := range layer {
 		names = append(names, strings.Title(cat))
@@ -328,11 +573,11 @@ func renderCategoryHeader(layer []string) {
 	if pad < 0 {
 		pad = 0
 	}
-	fmt.Printf("%s%s\n", strings.Repeat(" ", pad), line)
+	fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", pad), line)
 }
 
 // renderCategoryNodes prints nodes under each category
-func renderCategoryNodes(layer []string, categories map[string][]string) {
+func renderCategoryNodes(w io.Writer, layer []string, categories map[string][]string) {
 	// Collect all node labels for this layer
 	var allLabels []string
 	for _, cat := range layer {
@@ -352,7 +597,7 @@ func renderCategoryNodes(layer []string, categories map[string][]string) {
 		if pad < 0 {
 			pad = 0
 		}
-		fmt.Printf("%s%s\n", strings.Repeat(" ", pad), line)
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", pad), line)
 	} else {
 		// Show all node names
 		line := strings.Join(allLabels, "   ")
@@ -360,12 +605,12 @@ func renderCategoryNodes(layer []string, categories map[string][]string) {
 		if pad < 0 {
 			pad = 0
 		}
-		fmt.Printf("%s%s\n", strings.Repeat(" ", pad), line)
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", pad), line)
 	}
 }
 
 // renderCatConnectors draws connectors between category layers
-func renderCatConnectors(fromLayer, toLayer []string, catEdges map[string]map[string]bool) {
+func renderCatConnectors(w io.Writer, fromLayer, toLayer []string, catEdges map[string]map[string]bool) {
 	// Count connections
 	hasConnection := false
 	for _, from := range fromLayer {
@@ -383,7 +628,7 @@ func renderCatConnectors(fromLayer, toLayer []string, catEdges map[string]map[st
 	}
 
 	if !hasConnection {
-		fmt.Println()
+		fmt.Fprintln(w)
 		return
 	}
 
@@ -393,13 +638,13 @@ func renderCatConnectors(fromLayer, toLayer []string, catEdges map[string]map[st
 
 	if fromCount == 1 && toCount == 1 {
 		// Straight line
-		fmt.Printf("%s|\n", strings.Repeat(" ", center))
-		fmt.Printf("%sv\n", strings.Repeat(" ", center))
+		fmt.Fprintf(w, "%s|\n", strings.Repeat(" ", center))
+		fmt.Fprintf(w, "%sv\n", strings.Repeat(" ", center))
 	} else if fromCount == 1 && toCount > 1 {
 		// Fan out: one source to multiple targets
-		fmt.Printf("%s|\n", strings.Repeat(" ", center))
+		fmt.Fprintf(w, "%s|\n", strings.Repeat(" ", center))
 		half := (toCount - 1) * 4
-		fmt.Printf("%s/%s\\\n", strings.Repeat(" ", center-half), strings.Repeat("-", half*2-1))
+		fmt.Fprintf(w, "%s/%s\\\n", strings.Repeat(" ", center-half), strings.Repeat("-", half*2-1))
 		arrows := ""
 		for i := 0; i < toCount; i++ {
 			arrows += "v"
@@ -411,7 +656,7 @@ func renderCatConnectors(fromLayer, toLayer []string, catEdges map[string]map[st
 		if pad < 0 {
 			pad = 0
 		}
-		fmt.Printf("%s%s\n", strings.Repeat(" ", pad), arrows)
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", pad), arrows)
 	} else if fromCount > 1 && toCount == 1 {
 		// Fan in: multiple sources to one target
 		bars := ""
@@ -425,13 +670,13 @@ func renderCatConnectors(fromLayer, toLayer []string, catEdges map[string]map[st
 		if pad < 0 {
 			pad = 0
 		}
-		fmt.Printf("%s%s\n", strings.Repeat(" ", pad), bars)
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", pad), bars)
 		half := (fromCount - 1) * 4
-		fmt.Printf("%s\\%s/\n", strings.Repeat(" ", center-half), strings.Repeat("-", half*2-1))
-		fmt.Printf("%sv\n", strings.Repeat(" ", center))
+		fmt.Fprintf(w, "%s\\%s/\n", strings.Repeat(" ", center-half), strings.Repeat("-", half*2-1))
+		fmt.Fprintf(w, "%sv\n", strings.Repeat(" ", center))
 	} else {
 		// Complex case: just show simple connector
-		fmt.Printf("%s|\n", strings.Repeat(" ", center))
-		fmt.Printf("%sv\n", strings.Repeat(" ", center))
+		fmt.Fprintf(w, "%s|\n", strings.Repeat(" ", center))
+		fmt.Fprintf(w, "%sv\n", strings.Repeat(" ", center))
 	}
 }
@@ -0,0 +1,134 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/graph"
+	"github.com/Toernblom/deco/internal/services/query"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+func runBlockGraph(w io.Writer, nodes []domain.Node, cfg config.Config, flags *graphFlags) error {
+	for _, t := range flags.blockTypes {
+		if err := validateBlockType(t, cfg.CustomBlockTypes); err != nil {
+			return err
+		}
+	}
+
+	criteria := query.FilterCriteria{}
+	if flags.kind != "" {
+		criteria.Kind = &flags.kind
+	}
+	if flags.status != "" {
+		criteria.Status = &flags.status
+	}
+	if flags.tag != "" {
+		criteria.Tags = []string{flags.tag}
+	}
+	nodes = query.New().Filter(nodes, criteria)
+
+	bg := graph.NewBuilder().BuildBlockGraph(nodes, cfg.CustomBlockTypes, flags.blockTypes)
+
+	switch flags.format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(bg); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+	case "mermaid":
+		outputBlockMermaid(w, bg)
+	default:
+		outputBlockDOT(w, bg)
+	}
+	return nil
+}
+
+// blockClusters groups vertex indexes by owning node, preserving order.
+func blockClusters(bg graph.BlockGraph) (order []string, members map[string][]int) {
+	members = make(map[string][]int)
+	for i, v := range bg.Vertices {
+		if _, ok := members[v.NodeID]; !ok {
+			order = append(order, v.NodeID)
+		}
+		members[v.NodeID] = append(members[v.NodeID], i)
+	}
+	return order, members
+}
+
+func outputBlockDOT(w io.Writer, bg graph.BlockGraph) {
+	esc := func(s string) string { return strings.ReplaceAll(s, "\"", "\\\"") }
+
+	fmt.Fprintln(w, "digraph blocks {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	fmt.Fprintln(w)
+
+	order, members := blockClusters(bg)
+	for _, nodeID := range order {
+		fmt.Fprintf(w, "  subgraph %s {\n", dotID("cluster_"+esc(nodeID)))
+		fmt.Fprintf(w, "    label=%s;\n", dotID(esc(nodeID)))
+		for _, i := range members[nodeID] {
+			v := bg.Vertices[i]
+			fmt.Fprintf(w, "    %s [label=\"%s\\n(%s)\"];\n", dotID(esc(v.ID)), esc(v.Label), esc(v.Type))
+		}
+		fmt.Fprintln(w, "  }")
+	}
+
+	fmt.Fprintln(w)
+
+	for _, e := range bg.Edges {
+		fmt.Fprintf(w, "  %s -> %s [label=\"%s\"];\n", dotID(esc(e.From)), dotID(esc(e.To)), esc(e.Field))
+	}
+
+	fmt.Fprintln(w, "}")
+}
+
+func outputBlockMermaid(w io.Writer, bg graph.BlockGraph) {
+	// Block IDs contain '#', spaces and slashes, so use positional identifiers
+	ids := make(map[string]string)
+	for i, v := range bg.Vertices {
+		ids[v.ID] = fmt.Sprintf("b%d", i)
+	}
+	label := func(s string) string { return strings.ReplaceAll(s, "\"", "'") }
+
+	fmt.Fprintln(w, "```mermaid")
+	fmt.Fprintln(w, "flowchart LR")
+
+	order, members := blockClusters(bg)
+	for c, nodeID := range order {
+		fmt.Fprintf(w, "  subgraph n%d [\"%s\"]\n", c, label(nodeID))
+		for _, i := range members[nodeID] {
+			v := bg.Vertices[i]
+			fmt.Fprintf(w, "    %s[\"%s<br/><small>%s</small>\"]\n", ids[v.ID], label(v.Label), label(v.Type))
+		}
+		fmt.Fprintln(w, "  end")
+	}
+
+	fmt.Fprintln(w)
+
+	for _, e := range bg.Edges {
+		fmt.Fprintf(w, "  %s -->|%s| %s\n", ids[e.From], label(e.Field), ids[e.To])
+	}
+
+	fmt.Fprintln(w, "```")
+}
@@ -0,0 +1,282 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+// graphMLKeys are the node and edge attributes declared in GraphML output.
+var graphMLKeys = []struct{ id, target string }{
+	{"title", "node"},
+	{"kind", "node"},
+	{"status", "node"},
+	{"tags", "node"},
+	{"cluster", "node"},
+	{"ref_type", "edge"},
+	{"context", "edge"},
+}
+
+func outputGraphML(w io.Writer, nodes []domain.Node, edges []edge) {
+	esc := func(s string) string {
+		var b strings.Builder
+		if err := xml.EscapeText(&b, []byte(s)); err != nil {
+			return s
+		}
+		return b.String()
+	}
+	data := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "      <data key=\"%s\">%s</data>\n", key, esc(value))
+		}
+	}
+
+	fmt.Fprintln(w, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(w, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	for _, k := range graphMLKeys {
+		fmt.Fprintf(w, "  <key id=\"%s\" for=\"%s\" attr.name=\"%s\" attr.type=\"string\"/>\n", k.id, k.target, k.id)
+	}
+	fmt.Fprintln(w, `  <graph id="deco" edgedefault="directed">`)
+
+	for _, n := range nodes {
+		fmt.Fprintf(w, "    <node id=\"%s\">\n", esc(n.ID))
+		data("title", n.Title)
+		data("kind", n.Kind)
+		data("status", n.Status)
+		data("tags", strings.Join(n.Tags, ","))
+		if strings.Contains(n.ID, "/") {
+			data("cluster", getCategory(n.ID))
+		}
+		fmt.Fprintln(w, "    </node>")
+	}
+	for i, e := range edges {
+		fmt.Fprintf(w, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", i, esc(e.from), esc(e.to))
+		data("ref_type", e.refType)
+		data("context", e.context)
+		fmt.Fprintln(w, "    </edge>")
+	}
+
+	fmt.Fprintln(w, "  </graph>")
+	fmt.Fprintln(w, "</graphml>")
+}
+
+// cytoscapeElement is a Cytoscape.js element; clusters become compound parent nodes.
+type cytoscapeElement struct {
+	Data    map[string]interface{} `json:"data"`
+	Classes string                 `json:"classes,omitempty"`
+}
+
+type cytoscapeGraph struct {
+	Elements struct {
+		Nodes []cytoscapeElement `json:"nodes"`
+		Edges []cytoscapeElement `json:"edges"`
+	} `json:"elements"`
+}
+
+func outputCytoscape(w io.Writer, nodes []domain.Node, edges []edge) error {
+	var out cytoscapeGraph
+	out.Elements.Nodes = []cytoscapeElement{}
+	out.Elements.Edges = []cytoscapeElement{}
+
+	names, _, _ := clusterNodes(nodes)
+	for _, name := range names {
+		out.Elements.Nodes = append(out.Elements.Nodes, cytoscapeElement{
+			Data:    map[string]interface{}{"id": "cluster:" + name, "label": name},
+			Classes: "cluster",
+		})
+	}
+
+	for _, n := range nodes {
+		d := map[string]interface{}{
+			"id":     n.ID,
+			"label":  n.Title,
+			"kind":   n.Kind,
+			"status": n.Status,
+		}
+		if len(n.Tags) > 0 {
+			d["tags"] = n.Tags
+		}
+		if strings.Contains(n.ID, "/") {
+			d["parent"] = "cluster:" + getCategory(n.ID)
+		}
+		out.Elements.Nodes = append(out.Elements.Nodes, cytoscapeElement{Data: d, Classes: n.Status})
+	}
+
+	for i, e := range edges {
+		d := map[string]interface{}{
+			"id":       fmt.Sprintf("e%d", i),
+			"source":   e.from,
+			"target":   e.to,
+			"ref_type": e.refType,
+		}
+		if e.context != "" {
+			d["context"] = e.context
+		}
+		out.Elements.Edges = append(out.Elements.Edges, cytoscapeElement{Data: d, Classes: e.refType})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+func outputD2(w io.Writer, nodes []domain.Node, edges []edge) {
+	// Clustered nodes live inside a container named after their prefix,
+	// so edges must use the container path.
+	path := make(map[string]string)
+	for _, n := range nodes {
+		if strings.Contains(n.ID, "/") {
+			path[n.ID] = d2Key(getCategory(n.ID)) + "." + d2Key(strings.TrimPrefix(n.ID, getCategory(n.ID)+"/"))
+		} else {
+			path[n.ID] = d2Key(n.ID)
+		}
+	}
+
+	declare := func(n domain.Node, key, indent string) {
+		var styles []string
+		if n.Status == "draft" {
+			styles = append(styles, `style.stroke: "#999999"`, `style.font-color: "#999999"`)
+		}
+		if isRetired(n.Status) {
+			styles = append(styles, "style.stroke-dash: 5")
+		}
+		line := fmt.Sprintf("%s%s: %s", indent, key, d2Key(n.Title))
+		if len(styles) > 0 {
+			line += " {\n"
+			for _, s := range styles {
+				line += indent + "  " + s + "\n"
+			}
+			line += indent + "}"
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	names, clusters, loose := clusterNodes(nodes)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s: {\n", d2Key(name))
+		for _, n := range clusters[name] {
+			declare(n, d2Key(strings.TrimPrefix(n.ID, name+"/")), "  ")
+		}
+		fmt.Fprintln(w, "}")
+	}
+	for _, n := range loose {
+		declare(n, d2Key(n.ID), "")
+	}
+
+	if len(edges) > 0 {
+		fmt.Fprintln(w)
+	}
+	for _, e := range edges {
+		switch e.refType {
+		case "related":
+			fmt.Fprintf(w, "%s -> %s: {style.stroke-dash: 3}\n", path[e.from], path[e.to])
+		case "contract", "crossref":
+			fmt.Fprintf(w, "%s -> %s: %s {style.stroke-dash: 1}\n", path[e.from], path[e.to], e.refType)
+		default:
+			fmt.Fprintf(w, "%s -> %s\n", path[e.from], path[e.to])
+		}
+	}
+}
+
+// d2Key quotes a D2 key or label.
+func d2Key(s string) string {
+	return "\"" + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + "\""
+}
+
+var plantUMLUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// plantUMLAliases gives each node a PlantUML alias. Unsafe characters
+// become underscores; IDs that then collide (systems/core-x, systems/core_x)
+// get a numeric suffix in ID order.
+func plantUMLAliases(nodes []domain.Node) map[string]string {
+	ids := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		ids = append(ids, n.ID)
+	}
+	sort.Strings(ids)
+
+	alias := make(map[string]string, len(ids))
+	used := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		base := plantUMLUnsafe.ReplaceAllString(id, "_")
+		a := base
+		for i := 2; used[a]; i++ {
+			a = fmt.Sprintf("%s_%d", base, i)
+		}
+		used[a] = true
+		alias[id] = a
+	}
+	return alias
+}
+
+func outputPlantUML(w io.Writer, nodes []domain.Node, edges []edge) {
+	alias := plantUMLAliases(nodes)
+
+	fmt.Fprintln(w, "@startuml")
+	fmt.Fprintln(w, "left to right direction")
+
+	declare := func(n domain.Node, indent string) {
+		label := strings.ReplaceAll(n.Title, "\"", "'") + "\\n(" + n.ID + ")"
+		var styles []string
+		if n.Status == "draft" {
+			styles = append(styles, "line:grey", "text:grey")
+		}
+		if isRetired(n.Status) {
+			styles = append(styles, "line.dashed")
+		}
+		spec := ""
+		if len(styles) > 0 {
+			spec = " #" + strings.Join(styles, ";")
+		}
+		fmt.Fprintf(w, "%srectangle \"%s\" as %s%s\n", indent, label, alias[n.ID], spec)
+	}
+
+	names, clusters, loose := clusterNodes(nodes)
+	for _, name := range names {
+		fmt.Fprintf(w, "package \"%s\" {\n", name)
+		for _, n := range clusters[name] {
+			declare(n, "  ")
+		}
+		fmt.Fprintln(w, "}")
+	}
+	for _, n := range loose {
+		declare(n, "")
+	}
+
+	for _, e := range edges {
+		switch e.refType {
+		case "related":
+			fmt.Fprintf(w, "%s ..> %s\n", alias[e.from], alias[e.to])
+		case "contract", "crossref":
+			fmt.Fprintf(w, "%s ..> %s : %s\n", alias[e.from], alias[e.to], e.refType)
+		default:
+			fmt.Fprintf(w, "%s --> %s\n", alias[e.from], alias[e.to])
+		}
+	}
+
+	fmt.Fprintln(w, "@enduml")
+}
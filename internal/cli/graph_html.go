@@ -0,0 +1,399 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+// Layout dimensions for the HTML explorer, in SVG user units.
+const (
+	htmlNodeWidth    = 200
+	htmlNodeHeight   = 40
+	htmlNodeGap      = 12
+	htmlColumnWidth  = 280
+	htmlClusterPad   = 10
+	htmlClusterTitle = 24
+	htmlClusterGap   = 30
+)
+
+type htmlIssue struct {
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+type htmlNode struct {
+	ID      string      `json:"id"`
+	Title   string      `json:"title"`
+	Kind    string      `json:"kind"`
+	Status  string      `json:"status"`
+	Summary string      `json:"summary,omitempty"`
+	Tags    []string    `json:"tags,omitempty"`
+	Issues  []htmlIssue `json:"issues,omitempty"`
+	Link    string      `json:"link,omitempty"`
+	X       int         `json:"x"`
+	Y       int         `json:"y"`
+}
+
+type htmlEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	RefType string `json:"refType"`
+	Context string `json:"context,omitempty"`
+}
+
+type htmlCluster struct {
+	Name   string `json:"name"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type htmlGraph struct {
+	Project    string        `json:"project"`
+	NodeWidth  int           `json:"nodeWidth"`
+	NodeHeight int           `json:"nodeHeight"`
+	Nodes      []htmlNode    `json:"nodes"`
+	Edges      []htmlEdge    `json:"edges"`
+	Clusters   []htmlCluster `json:"clusters"`
+	Kinds      []string      `json:"kinds"`
+	Tags       []string      `json:"tags"`
+}
+
+// outputHTML writes a self-contained interactive graph explorer. Nodes are
+// laid out in columns of category layers (see topoSortCategories), with each
+// category's nodes stacked inside a cluster box. linkBase, when set, is the
+// prefix for links to exported node pages (deco export --output).
+func outputHTML(w io.Writer, project string, nodes []domain.Node, edges []edge, linkBase string) error {
+	data := layoutHTMLGraph(nodes, edges, linkBase)
+	data.Project = project
+
+	if err := graphHTMLTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return nil
+}
+
+func layoutHTMLGraph(nodes []domain.Node, edges []edge, linkBase string) htmlGraph {
+	g := htmlGraph{
+		NodeWidth:  htmlNodeWidth,
+		NodeHeight: htmlNodeHeight,
+		Nodes:      []htmlNode{},
+		Edges:      []htmlEdge{},
+		Clusters:   []htmlCluster{},
+		Kinds:      []string{},
+		Tags:       []string{},
+	}
+
+	byID := make(map[string]domain.Node)
+	categories := make(map[string][]string)
+	kinds := make(map[string]bool)
+	tags := make(map[string]bool)
+	for _, n := range nodes {
+		byID[n.ID] = n
+		cat := getCategory(n.ID)
+		categories[cat] = append(categories[cat], n.ID)
+		kinds[n.Kind] = true
+		for _, t := range n.Tags {
+			tags[t] = true
+		}
+	}
+	for cat := range categories {
+		sort.Strings(categories[cat])
+	}
+
+	catEdges := make(map[string]map[string]bool)
+	for _, e := range edges {
+		fromCat, toCat := getCategory(e.from), getCategory(e.to)
+		if fromCat != toCat {
+			if catEdges[fromCat] == nil {
+				catEdges[fromCat] = make(map[string]bool)
+			}
+			catEdges[fromCat][toCat] = true
+		}
+		g.Edges = append(g.Edges, htmlEdge{From: e.from, To: e.to, RefType: e.refType, Context: e.context})
+	}
+
+	for col, layer := range topoSortCategories(categories, catEdges) {
+		x := col * htmlColumnWidth
+		y := 0
+		for _, cat := range layer {
+			ids := categories[cat]
+			height := htmlClusterTitle + len(ids)*(htmlNodeHeight+htmlNodeGap) - htmlNodeGap + htmlClusterPad
+			g.Clusters = append(g.Clusters, htmlCluster{
+				Name:   cat,
+				X:      x - htmlClusterPad,
+				Y:      y,
+				Width:  htmlNodeWidth + 2*htmlClusterPad,
+				Height: height,
+			})
+
+			ny := y + htmlClusterTitle
+			for _, id := range ids {
+				n := byID[id]
+				hn := htmlNode{
+					ID:      n.ID,
+					Title:   n.Title,
+					Kind:    n.Kind,
+					Status:  n.Status,
+					Summary: n.Summary,
+					Tags:    n.Tags,
+					X:       x,
+					Y:       ny,
+				}
+				for _, issue := range n.Issues {
+					if !issue.Resolved {
+						hn.Issues = append(hn.Issues, htmlIssue{Severity: issue.Severity, Description: issue.Description})
+					}
+				}
+				if linkBase != "" {
+					hn.Link = linkBase + n.ID + ".md"
+				}
+				g.Nodes = append(g.Nodes, hn)
+				ny += htmlNodeHeight + htmlNodeGap
+			}
+			y += height + htmlClusterGap
+		}
+	}
+
+	for k := range kinds {
+		g.Kinds = append(g.Kinds, k)
+	}
+	for t := range tags {
+		g.Tags = append(g.Tags, t)
+	}
+	sort.Strings(g.Kinds)
+	sort.Strings(g.Tags)
+
+	return g
+}
+
+var graphHTMLTemplate = template.Must(template.New("graph").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Project}} — design graph</title>
+<style>
+  html, body { margin: 0; height: 100%; font: 13px -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #222; }
+  body { display: flex; flex-direction: column; }
+  header { display: flex; gap: 8px; align-items: center; padding: 8px 12px; border-bottom: 1px solid #ddd; background: #fafafa; }
+  header h1 { font-size: 15px; margin: 0 12px 0 0; }
+  header input, header select { font: inherit; padding: 3px 6px; }
+  main { flex: 1; display: flex; min-height: 0; }
+  #canvas { flex: 1; cursor: grab; background: #fff; }
+  #canvas.dragging { cursor: grabbing; }
+  aside { width: 320px; overflow: auto; border-left: 1px solid #ddd; padding: 12px; background: #fcfcfc; }
+  aside h2 { font-size: 15px; margin: 0 0 4px; }
+  aside .meta { color: #666; margin-bottom: 8px; }
+  aside .tag { display: inline-block; background: #eef; border-radius: 3px; padding: 0 5px; margin: 0 3px 3px 0; }
+  aside ul { padding-left: 18px; }
+  .cluster rect { fill: #f4f6f8; stroke: #d0d7de; }
+  .cluster text { fill: #57606a; font-weight: 600; }
+  .node rect { fill: #fff; stroke: #333; rx: 4; }
+  .node text { pointer-events: none; }
+  .node { cursor: pointer; }
+  .node.draft rect { stroke: #999; }
+  .node.draft text { fill: #999; }
+  .node.deprecated rect, .node.archived rect { stroke-dasharray: 5 4; }
+  .node.selected rect { stroke: #0969da; stroke-width: 2.5; }
+  .node.match rect { fill: #fff8c5; }
+  .node.issues rect { stroke: #cf222e; }
+  .edge { fill: none; stroke: #8c959f; marker-end: url(#arrow); }
+  .edge.related { stroke-dasharray: 6 4; }
+  .edge.contract, .edge.crossref { stroke-dasharray: 2 3; }
+  .edge.active { stroke: #0969da; stroke-width: 2; }
+  .dim { opacity: 0.15; }
+  .hidden { display: none; }
+</style>
+</head>
+<body>
+<header>
+  <h1>{{.Project}}</h1>
+  <input id="search" type="search" placeholder="Search nodes…">
+  <select id="kind"><option value="">All kinds</option></select>
+  <select id="tag"><option value="">All tags</option></select>
+  <button id="reset">Reset view</button>
+</header>
+<main>
+  <svg id="canvas" xmlns="http://www.w3.org/2000/svg">
+    <defs>
+      <marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="7" markerHeight="7" orient="auto-start-reverse">
+        <path d="M 0 0 L 10 5 L 0 10 z" fill="#8c959f"/>
+      </marker>
+    </defs>
+    <g id="viewport"></g>
+  </svg>
+  <aside id="details"><p>Click a node to see its details.</p></aside>
+</main>
+<script>
+(function () {
+  var data = {{.}};
+  var NS = "http://www.w3.org/2000/svg";
+  var svg = document.getElementById("canvas");
+  var vp = document.getElementById("viewport");
+  var byId = {}, nodeEls = {}, edgeEls = [];
+
+  function el(name, attrs, parent) {
+    var e = document.createElementNS(NS, name);
+    for (var k in attrs) e.setAttribute(k, attrs[k]);
+    if (parent) parent.appendChild(e);
+    return e;
+  }
+  function truncate(s, n) { return s.length > n ? s.slice(0, n - 1) + "…" : s; }
+
+  data.clusters.forEach(function (c) {
+    var g = el("g", { "class": "cluster" }, vp);
+    el("rect", { x: c.x, y: c.y, width: c.width, height: c.height, rx: 6 }, g);
+    el("text", { x: c.x + 8, y: c.y + 16 }, g).textContent = c.name;
+  });
+
+  var edgeLayer = el("g", {}, vp);
+  data.nodes.forEach(function (n) { byId[n.id] = n; });
+  data.edges.forEach(function (e) {
+    var a = byId[e.from], b = byId[e.to];
+    if (!a || !b) return;
+    var x1 = a.x + data.nodeWidth, y1 = a.y + data.nodeHeight / 2;
+    var x2 = b.x, y2 = b.y + data.nodeHeight / 2;
+    if (x2 <= a.x) { x1 = a.x; x2 = b.x + data.nodeWidth; }
+    var dx = Math.max(40, Math.abs(x2 - x1) / 2) * (x2 >= x1 ? 1 : -1);
+    var p = el("path", {
+      "class": "edge " + e.refType,
+      d: "M" + x1 + "," + y1 + " C" + (x1 + dx) + "," + y1 + " " + (x2 - dx) + "," + y2 + " " + x2 + "," + y2
+    }, edgeLayer);
+    el("title", {}, p).textContent = e.from + " → " + e.to + " [" + e.refType + "]" + (e.context ? " " + e.context : "");
+    edgeEls.push({ el: p, edge: e });
+  });
+
+  data.nodes.forEach(function (n) {
+    var cls = "node " + n.status + (n.issues && n.issues.length ? " issues" : "");
+    var g = el("g", { "class": cls, transform: "translate(" + n.x + "," + n.y + ")" }, vp);
+    el("rect", { width: data.nodeWidth, height: data.nodeHeight }, g);
+    el("text", { x: 8, y: 16, "font-weight": 600 }, g).textContent = truncate(n.title, 28);
+    el("text", { x: 8, y: 31, fill: "#666", "font-size": 11 }, g).textContent = truncate(n.id, 32);
+    g.addEventListener("click", function (ev) { ev.stopPropagation(); select(n.id); });
+    g.addEventListener("dblclick", function () { if (n.link) window.open(n.link, "_blank"); });
+    nodeEls[n.id] = g;
+  });
+
+  // Details panel
+  var details = document.getElementById("details");
+  function text(tag, value, parent, cls) {
+    var e = document.createElement(tag);
+    e.textContent = value;
+    if (cls) e.className = cls;
+    parent.appendChild(e);
+    return e;
+  }
+  function select(id) {
+    var n = byId[id];
+    for (var k in nodeEls) nodeEls[k].classList.toggle("selected", k === id);
+    edgeEls.forEach(function (x) { x.el.classList.toggle("active", x.edge.from === id || x.edge.to === id); });
+    details.innerHTML = "";
+    text("h2", n.title, details);
+    text("div", n.id + " · " + n.kind + " · " + n.status, details, "meta");
+    if (n.summary) text("p", n.summary, details);
+    (n.tags || []).forEach(function (t) { text("span", t, details, "tag"); });
+    if (n.issues && n.issues.length) {
+      text("h3", "Open issues", details);
+      var ul = text("ul", "", details);
+      n.issues.forEach(function (i) { text("li", "[" + i.severity + "] " + i.description, ul); });
+    }
+    var out = [], inc = [];
+    data.edges.forEach(function (e) {
+      if (e.from === id) out.push(e);
+      if (e.to === id) inc.push(e);
+    });
+    [["References", out, "to"], ["Referenced by", inc, "from"]].forEach(function (sec) {
+      if (!sec[1].length) return;
+      text("h3", sec[0], details);
+      var ul = text("ul", "", details);
+      sec[1].forEach(function (e) {
+        var li = text("li", "", ul);
+        var a = text("a", e[sec[2]], li);
+        a.href = "#";
+        a.addEventListener("click", function (ev) { ev.preventDefault(); select(e[sec[2]]); center(e[sec[2]]); });
+        text("span", " [" + e.refType + "]", li);
+      });
+    });
+    if (n.link) {
+      var p = text("p", "", details);
+      var a = text("a", "Open node page →", p);
+      a.href = n.link;
+      a.target = "_blank";
+    }
+  }
+
+  // Pan and zoom
+  var view = { x: 40, y: 40, k: 1 };
+  function apply() { vp.setAttribute("transform", "translate(" + view.x + "," + view.y + ") scale(" + view.k + ")"); }
+  function center(id) {
+    var n = byId[id], r = svg.getBoundingClientRect();
+    view.x = r.width / 2 - (n.x + data.nodeWidth / 2) * view.k;
+    view.y = r.height / 2 - (n.y + data.nodeHeight / 2) * view.k;
+    apply();
+  }
+  var drag = null;
+  svg.addEventListener("mousedown", function (ev) { drag = { x: ev.clientX - view.x, y: ev.clientY - view.y }; svg.classList.add("dragging"); });
+  window.addEventListener("mousemove", function (ev) { if (drag) { view.x = ev.clientX - drag.x; view.y = ev.clientY - drag.y; apply(); } });
+  window.addEventListener("mouseup", function () { drag = null; svg.classList.remove("dragging"); });
+  svg.addEventListener("wheel", function (ev) {
+    ev.preventDefault();
+    var r = svg.getBoundingClientRect(), mx = ev.clientX - r.left, my = ev.clientY - r.top;
+    var k = Math.min(4, Math.max(0.1, view.k * (ev.deltaY < 0 ? 1.1 : 1 / 1.1)));
+    view.x = mx - (mx - view.x) * k / view.k;
+    view.y = my - (my - view.y) * k / view.k;
+    view.k = k;
+    apply();
+  }, { passive: false });
+  document.getElementById("reset").addEventListener("click", function () { view = { x: 40, y: 40, k: 1 }; apply(); });
+  apply();
+
+  // Search and filters
+  var search = document.getElementById("search"), kindSel = document.getElementById("kind"), tagSel = document.getElementById("tag");
+  data.kinds.forEach(function (k) { text("option", k, kindSel).value = k; });
+  data.tags.forEach(function (t) { text("option", t, tagSel).value = t; });
+  function refresh() {
+    var q = search.value.trim().toLowerCase(), kind = kindSel.value, tag = tagSel.value, visible = {};
+    data.nodes.forEach(function (n) {
+      var show = (!kind || n.kind === kind) && (!tag || (n.tags || []).indexOf(tag) >= 0);
+      var hay = (n.id + " " + n.title + " " + (n.summary || "")).toLowerCase();
+      var match = q && hay.indexOf(q) >= 0;
+      visible[n.id] = show;
+      nodeEls[n.id].classList.toggle("hidden", !show);
+      nodeEls[n.id].classList.toggle("match", !!match);
+      nodeEls[n.id].classList.toggle("dim", !!q && !match);
+    });
+    edgeEls.forEach(function (x) { x.el.classList.toggle("hidden", !visible[x.edge.from] || !visible[x.edge.to]); });
+  }
+  search.addEventListener("input", refresh);
+  search.addEventListener("keydown", function (ev) {
+    if (ev.key !== "Enter") return;
+    var first = document.querySelector(".node.match:not(.hidden)");
+    for (var id in nodeEls) if (nodeEls[id] === first) { select(id); center(id); }
+  });
+  kindSel.addEventListener("change", refresh);
+  tagSel.addEventListener("change", refresh);
+})();
+</script>
+</body>
+</html>
+`))
@@ -17,10 +17,15 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
+	"encoding/xml"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/node"
 )
 
 func TestGraphCommand_Structure(t *testing.T) {
@@ -171,6 +176,205 @@ func TestGraphCommand_WithRootCommand(t *testing.T) {
 	})
 }
 
+func runGraphCmd(t *testing.T, args ...string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	cmd := NewGraphCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return buf.String()
+}
+
+func TestGraphCommand_ClustersAndStatusStyling(t *testing.T) {
+	dir := setupClusteredGraphProject(t)
+
+	out := runGraphCmd(t, dir)
+	for _, want := range []string{
+		`subgraph "cluster_items" {`,
+		`subgraph "cluster_systems" {`,
+		`"items/sword" [label="Sword\n(items/sword)", color=grey, fontcolor=grey];`,
+		`"items/shield" [label="Shield\n(items/shield)", style=dashed];`,
+		`"lore" -> "systems/core" [style=dashed];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected DOT to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	out = runGraphCmd(t, dir, "--format", "mermaid")
+	for _, want := range []string{`subgraph cluster_items ["items"]`, "class items_sword draft", "class items_shield retired"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected Mermaid to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestGraphCommand_ExportFormats(t *testing.T) {
+	dir := setupClusteredGraphProject(t)
+
+	t.Run("graphml is well-formed", func(t *testing.T) {
+		out := runGraphCmd(t, dir, "--format", "graphml")
+		var doc struct {
+			Graph struct {
+				Nodes []struct {
+					ID string `xml:"id,attr"`
+				} `xml:"node"`
+				Edges []struct {
+					Source string `xml:"source,attr"`
+				} `xml:"edge"`
+			} `xml:"graph"`
+		}
+		if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatalf("Invalid GraphML: %v\n%s", err, out)
+		}
+		if len(doc.Graph.Nodes) != 4 || len(doc.Graph.Edges) != 3 {
+			t.Errorf("Expected 4 nodes and 3 edges, got %d and %d", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+		}
+	})
+
+	t.Run("cytoscape uses compound cluster nodes", func(t *testing.T) {
+		out := runGraphCmd(t, dir, "--format", "cytoscape")
+		var doc cytoscapeGraph
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, out)
+		}
+		parents := make(map[string]interface{})
+		for _, n := range doc.Elements.Nodes {
+			parents[n.Data["id"].(string)] = n.Data["parent"]
+		}
+		if parents["items/sword"] != "cluster:items" || parents["lore"] != nil {
+			t.Errorf("Unexpected parents: %v", parents)
+		}
+		if _, ok := parents["cluster:systems"]; !ok {
+			t.Error("Expected cluster:systems parent node")
+		}
+	})
+
+	t.Run("d2", func(t *testing.T) {
+		out := runGraphCmd(t, dir, "--format", "d2")
+		for _, want := range []string{`"items": {`, `"systems"."core" -> "items"."sword"`, "style.stroke-dash: 5"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected D2 to contain %q, got:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("plantuml", func(t *testing.T) {
+		out := runGraphCmd(t, dir, "--format", "plantuml")
+		for _, want := range []string{"@startuml", `package "items" {`, "systems_core --> items_sword", "#line.dashed", "@enduml"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected PlantUML to contain %q, got:\n%s", want, out)
+			}
+		}
+	})
+}
+
+func TestOutputPlantUML_UniqueAliases(t *testing.T) {
+	nodes := []domain.Node{
+		{ID: "systems/core_x", Title: "Underscore"},
+		{ID: "systems/core-x", Title: "Dash"},
+		{ID: "systems/core.x", Title: "Dot"},
+	}
+	edges := []edge{{from: "systems/core-x", to: "systems/core_x", refType: "uses"}}
+
+	var buf bytes.Buffer
+	outputPlantUML(&buf, nodes, edges)
+	out := buf.String()
+	for _, want := range []string{
+		`(systems/core-x)" as systems_core_x`,
+		`(systems/core.x)" as systems_core_x_2`,
+		`(systems/core_x)" as systems_core_x_3`,
+		"systems_core_x --> systems_core_x_3",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected PlantUML to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestGraphCommand_SubgraphSelection(t *testing.T) {
+	dir := setupClusteredGraphProject(t)
+
+	tests := []struct {
+		name    string
+		args    []string
+		present []string
+		absent  []string
+	}{
+		{"root out one hop", []string{"--root", "systems/core", "--depth", "1", "--direction", "out"},
+			[]string{`"items/sword" [`, `"items/shield" [`}, []string{`"lore" [`}},
+		{"root in", []string{"--root", "systems/core", "--direction", "in"},
+			[]string{`"lore" [`}, []string{`"items/sword" [`}},
+		{"kind filter", []string{"--kind", "item"},
+			[]string{`"items/sword" [`}, []string{`"systems/core" [`, `"lore" [`}},
+		{"status filter keeps root", []string{"--root", "systems/core", "--status", "draft"},
+			[]string{`"systems/core" [`, `"items/sword" [`, `"systems/core" -> "items/sword"`}, []string{`"items/shield" [`}},
+		{"tag filter", []string{"--tag", "combat"},
+			[]string{`"items/sword" [`}, []string{`"items/shield" [`}},
+		{"ref type", []string{"--ref-type", "related"},
+			[]string{`"lore" -> "systems/core"`}, []string{`-> "items/sword"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := runGraphCmd(t, append([]string{dir}, tt.args...)...)
+			for _, want := range tt.present {
+				if !strings.Contains(out, want) {
+					t.Errorf("Expected %q in:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range tt.absent {
+				if strings.Contains(out, unwanted) {
+					t.Errorf("Did not expect %q in:\n%s", unwanted, out)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphCommand_SubgraphErrors(t *testing.T) {
+	dir := setupClusteredGraphProject(t)
+
+	for _, args := range [][]string{
+		{"--root", "missing"},
+		{"--direction", "sideways"},
+		{"--ref-type", "owns"},
+		{"--status", "bogus"},
+		{"--kind", "bogus"},
+	} {
+		cmd := NewGraphCommand()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{dir}, args...))
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
+
+// setupClusteredGraphProject creates systems/core -> items/{sword,shield}
+// and an unclustered lore node related to systems/core.
+func setupClusteredGraphProject(t *testing.T) string {
+	t.Helper()
+	dir := setupDecoProject(t)
+	repo := node.NewYAMLRepository(filepath.Join(dir, ".deco", "nodes"))
+	nodes := []domain.Node{
+		{ID: "systems/core", Kind: "system", Version: 1, Status: "approved", Title: "Core",
+			Refs: domain.Ref{Uses: []domain.RefLink{{Target: "items/sword"}, {Target: "items/shield"}}}},
+		{ID: "items/sword", Kind: "item", Version: 1, Status: "draft", Title: "Sword", Tags: []string{"combat"}},
+		{ID: "items/shield", Kind: "item", Version: 1, Status: "deprecated", Title: "Shield"},
+		{ID: "lore", Kind: "doc", Version: 1, Status: "approved", Title: "Lore",
+			Refs: domain.Ref{Related: []domain.RefLink{{Target: "systems/core"}}}},
+	}
+	for _, n := range nodes {
+		if err := repo.Save(n); err != nil {
+			t.Fatalf("Failed to save %s: %v", n.ID, err)
+		}
+	}
+	return dir
+}
+
 // Test helper for projects with refs (graph-specific)
 func setupGraphProjectWithRefs(t *testing.T, dir string) {
 	t.Helper()
@@ -240,3 +444,188 @@ title: Enemy Entity
 		}
 	}
 }
+
+func TestGraphCommand_HTMLOutput(t *testing.T) {
+	dir := setupClusteredGraphProject(t)
+
+	out := runGraphCmd(t, dir, "--format", "html", "--link-base", "pages/")
+	if !strings.HasPrefix(out, "<!DOCTYPE html>") {
+		t.Fatalf("Expected an HTML document, got:\n%.200s", out)
+	}
+	for _, unwanted := range []string{"<script src", "<link ", "@import"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Expected a self-contained file, found %q", unwanted)
+		}
+	}
+	for _, want := range []string{`"id":"items/sword"`, `"link":"pages/items/sword.md"`, `"kinds":["doc","item","system"]`, `"tags":["combat"]`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected embedded data to contain %q", want)
+		}
+	}
+}
+
+func TestLayoutHTMLGraph(t *testing.T) {
+	nodes := []domain.Node{
+		{ID: "systems/core", Kind: "system", Title: "Core </script><b>"},
+		{ID: "items/sword", Kind: "item", Title: "Sword", Issues: []domain.Issue{
+			{ID: "i1", Description: "Balance", Severity: "high"},
+			{ID: "i2", Description: "Done", Severity: "low", Resolved: true},
+		}},
+		{ID: "items/shield", Kind: "item", Title: "Shield"},
+	}
+	edges := []edge{{from: "systems/core", to: "items/sword", refType: "uses"}}
+
+	g := layoutHTMLGraph(nodes, edges, "")
+	pos := make(map[string]htmlNode)
+	for _, n := range g.Nodes {
+		pos[n.ID] = n
+	}
+
+	if pos["systems/core"].X >= pos["items/sword"].X {
+		t.Errorf("Expected systems layer left of items, got %d and %d", pos["systems/core"].X, pos["items/sword"].X)
+	}
+	if pos["items/shield"].Y >= pos["items/sword"].Y {
+		t.Error("Expected items stacked in ID order within their cluster")
+	}
+	if len(pos["items/sword"].Issues) != 1 {
+		t.Errorf("Expected only open issues, got %+v", pos["items/sword"].Issues)
+	}
+	if pos["items/sword"].Link != "" {
+		t.Error("Expected no link without a link base")
+	}
+	if len(g.Clusters) != 2 {
+		t.Errorf("Expected 2 clusters, got %d", len(g.Clusters))
+	}
+
+	var buf bytes.Buffer
+	if err := outputHTML(&buf, "demo", nodes, edges, ""); err != nil {
+		t.Fatalf("outputHTML failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "Core </script>") {
+		t.Error("Expected node titles to be escaped inside the script")
+	}
+}
+
+// setupCraftingProject creates recipe and resource blocks linked by a
+// materials ref constraint.
+func setupCraftingProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		".deco/config.yaml": `version: 1
+project_name: craft
+nodes_path: .deco/nodes
+history_path: .deco/history.jsonl
+custom_block_types:
+  resource:
+    fields:
+      name: {type: string}
+  recipe:
+    fields:
+      output: {type: string}
+      materials:
+        type: list
+        ref:
+          - block_type: resource
+            field: name
+          - block_type: recipe
+            field: output
+`,
+		".deco/nodes/items/basics.yaml": `id: items/basics
+kind: item
+version: 1
+status: draft
+title: Basics
+content:
+  sections:
+    - name: Resources
+      blocks:
+        - type: resource
+          name: Wood
+    - name: Recipes
+      blocks:
+        - type: recipe
+          id: planks
+          output: Planks
+          materials: [Wood]
+`,
+		".deco/nodes/items/tools.yaml": `id: items/tools
+kind: item
+version: 1
+status: draft
+title: Tools
+content:
+  sections:
+    - name: Recipes
+      blocks:
+        - type: recipe
+          output: Axe
+          materials: [Planks]
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestGraphCommand_Blocks(t *testing.T) {
+	dir := setupCraftingProject(t)
+
+	t.Run("dot", func(t *testing.T) {
+		out := runGraphCmd(t, dir, "--blocks")
+		for _, want := range []string{
+			`subgraph "cluster_items/tools" {`,
+			`"items/basics#Recipes/planks" [label="Planks\n(recipe)"];`,
+			`"items/tools#Recipes/0" -> "items/basics#Recipes/planks" [label="materials"];`,
+			`"items/basics#Recipes/planks" -> "items/basics#Resources/0" [label="materials"];`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected %q in:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("mermaid", func(t *testing.T) {
+		out := runGraphCmd(t, dir, "--blocks", "--format", "mermaid")
+		if !strings.Contains(out, "-->|materials|") {
+			t.Errorf("Expected labelled edges, got:\n%s", out)
+		}
+	})
+
+	t.Run("json with block type filter", func(t *testing.T) {
+		out := runGraphCmd(t, dir, "--blocks", "--format", "json", "--block-type", "recipe")
+		var bg struct {
+			Vertices []struct{ ID, Type string }
+			Edges    []struct{ From, To, Value string }
+		}
+		if err := json.Unmarshal([]byte(out), &bg); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, out)
+		}
+		if len(bg.Vertices) != 2 || len(bg.Edges) != 1 || bg.Edges[0].Value != "Planks" {
+			t.Errorf("Unexpected block graph: %+v", bg)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"--blocks", "--format", "graphml"},
+			{"--blocks", "--root", "items/tools"},
+			{"--blocks", "--block-type", "nope"},
+			{"--block-type", "recipe"},
+		} {
+			cmd := NewGraphCommand()
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{dir}, args...))
+			if err := cmd.Execute(); err == nil {
+				t.Errorf("Expected error for %v", args)
+			}
+		}
+	})
+}
@@ -52,24 +52,52 @@ Run 'deco validate' after edits to catch errors. All validation errors include e
 
 Reading & Querying:
   deco list [--kind X] [--status X] [--tag X]   List nodes
+  deco list --format csv --columns id,custom.X   Structured output (json/jsonl/csv/yaml)
   deco show <id> [--json]                        Show node + reverse refs
+  deco show <id> --as-of <time|rev>              Past state (also list, query, graph)
+  deco impact <id> [--depth N] [--format ids]    Transitive dependents
+  deco impact --changed-since <rev|time>         Dependents of recent changes
+  deco path <from> <to> [--all] [--format dot]   Dependency paths between nodes
   deco query [term] [--kind X] [--tag X]         Search/filter nodes
   deco query --block-type X [--field key=val]    Query blocks within nodes
+  deco query --where <cel> [--block-where <cel>] Filter nodes/blocks by expression
+  deco query @name [--param k=v] [--list-saved]  Run a saved query from config
+  deco query --block-type X --group-by f --agg A Aggregate blocks (count, sum:f, ...)
+  deco search <query> [--kind X] [--limit N]     Ranked full-text search, all content
   deco validate [--quiet]                        Check all nodes
   deco issues [--severity X] [--node X]          List open TBDs
   deco stats                                     Project health overview
+  deco stats --format json                       Health and graph metrics as JSON
   deco graph [--format dot|mermaid|ascii]        Show dependency graph
+  deco graph -f graphml|cytoscape|d2|plantuml    Export for other tools
+  deco graph -f html [--link-base docs/]         Offline interactive explorer
+  deco graph --blocks [--block-type X]           Block graph from ref constraints
+  deco graph --root X --depth N [--direction D]  Subgraph around a node
 
 History & Sync:
   deco sync [--dry-run]                          Detect edits, bump versions, track history
   deco history [--node <id>]                     Show audit log
+  deco history --op X --user Y --since 1w        Filter by operation, user, time
+  deco history -f json|jsonl|csv [--stats]       Export entries or weekly stats
+  deco history verify                            Check the audit log hash chain
+  deco history compact [--before 90d]            Pack old history into gzip segments
   deco diff <id> [--since 2h]                    Show changes over time
+  deco diff <id> v3..v7 [-f side-by-side|json]   Field-level diff between versions
+  deco diff --git main..feature                  Semantic project diff between git revisions
+  deco changelog --since <tag>                   Release notes from history
+  deco release create <name> [--approved-only]   Freeze node versions as a release
+  deco release diff <a> [b]                      Compare releases (or with working tree)
+  deco show <id> --version N                     Earlier version from history snapshots
+  deco restore <id> --version N                  Restore it as a new draft version
+  deco merge-driver install                      Semantic git merges of history and nodes
 
 Review:
   deco review submit <id>                        Submit for review
   deco review approve <id> [--note "msg"]        Approve node
   deco review reject <id> --note "reason"        Reject node
   deco review status [<id>]                      Check review status
+  deco review approve <id> --key <file>          Sign the approval (ed25519 key)
+  deco keys add <reviewer> <public-key-file>     Trust a reviewer's key
 
 Setup:
   deco init                                      Initialize new project
@@ -82,6 +110,11 @@ Node-level (default): filters and searches across nodes.
   deco query --kind item --tag combat    # Filter by kind + tag
   deco query "sword" --kind item         # Combine search + filter
 
+Full-text: ranked search of everything (blocks, issues, contracts, glossary, docs).
+  deco search collision                  # Word anywhere, with snippet + location
+  deco search '"tick rate"'              # Exact phrase
+  deco search 'rule:wall issue:balance'  # Field prefixes: issue, rule, glossary, doc, ...
+
 Block-level: filters blocks within node content. Activated by --block-type.
   deco query --block-type building                         # All building blocks
   deco query --block-type building --field age=bronze      # Filter by field value
@@ -94,9 +127,36 @@ Follow mode: traverses ref constraints to find related blocks.
   deco query --block-type building --field age=bronze --follow materials  # Filter + follow
   deco query --block-type recipe --follow inputs                    # Reverse: what resources?
   deco query --block-type building --follow materials:recipe.output # Explicit target
+  deco query --block-type building --follow 'materials>inputs'      # Chain hops: join paths
+  deco query --block-type recipe --follow 'materials*' --max-depth 5 # Repeat last hop (cycles cut)
+
+Expression mode: CEL, same variables as constraints; failing evaluation = no match.
+  deco query --where 'kind == "item" || "combat" in tags'            # OR and membership
+  deco query --where '!(status in ["approved", "deprecated"])'       # Negation
+  deco query --where 'self.custom.cost > 100'                        # Numeric custom field
+  deco query --block-where 'block.type == "recipe" && "Wood" in block.materials'
+
+Aggregation: --group-by fields (or node, section, type), --agg count|count:f|sum:f|avg:f|min:f|max:f.
+  deco query --block-type building --group-by age --agg count --agg sum:cost
+  deco query --block-type endpoint --group-by method --format csv   # List fields: one group per element
+  deco query --block-type building --follow materials --agg max:tier  # Groups = referenced values
+
+Saved queries: queries: in .deco/config.yaml, ${param} placeholders (params.name in where) with defaults.
+  deco query --list-saved                                           # Names, params, flags
+  deco query @bronze-buildings --param age=iron                     # CLI flags override
+  deco export --compact --query @bronze-buildings                   # Limit an export
+  constraints: [{expr: ..., scope: "@bronze-buildings"}]            # Scope a constraint
+
+Structured output (list and query): --format table|json|jsonl|csv|yaml, --columns, --sort, --limit.
+  deco list --format json --columns id,refs.uses.count,issues.open --sort refs.uses.count:desc
+  deco query --block-type building --format csv                     # One column per block field
+  deco query --block-type recipe --columns node,output,node.status --format jsonl
+
+Time travel (list, show, query, graph): --as-of 2026-03-01|1w|<git rev>.
+  History is replayed backwards; hand-edited or deleted nodes are read from git at that time.
 
 Returns block data with context: [node_id > section_name] type + all fields.
-Follow mode groups results by value with reference counts.
+Follow mode groups results by value with reference counts; chains list one join path per route.
 
 ## Node Structure (YAML)
 
@@ -301,7 +361,10 @@ Key errors you'll encounter:
 
 .deco/
   config.yaml              # Project config, custom block types, schema rules
-  history.jsonl            # Append-only audit log
+  history.jsonl            # Append-only audit log (active segment)
+  history/                 # Closed gzip history segments + index
+  releases/                # Frozen releases (deco release create)
+  cache/                   # Derived, git-ignored (search index)
   nodes/
     systems/core.yaml      # id: systems/core
     items/food.yaml        # id: items/food
@@ -318,6 +381,7 @@ Key errors you'll encounter:
    - Union refs: ref as array allows OR validation across multiple block types
 7. Follow refs across types: 'deco query --block-type building --follow materials' traces supply chains
    - Use explicit targets for ad-hoc joins: '--follow materials:recipe.output'
+   - Chain hops with '>' and repeat the last with '*': '--follow materials*' walks recipe trees
 8. Use doc references: Put prose in .md files, reference with docs or doc blocks
 9. Use issues for TBDs: Don't leave unresolved questions in content
 10. Reference other nodes: Use refs.uses for dependencies between nodes
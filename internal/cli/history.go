@@ -16,9 +16,17 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/Toernblom/deco/internal/cli/style"
 	"github.com/Toernblom/deco/internal/domain"
 	"github.com/Toernblom/deco/internal/storage/config"
 	"github.com/Toernblom/deco/internal/storage/history"
@@ -27,10 +35,23 @@ import (
 
 type historyFlags struct {
 	nodeID    string
+	operation string
+	user      string
+	since     string
+	until     string
 	limit     int
+	reverse   bool
+	format    string
+	stats     bool
 	targetDir string
 }
 
+// historyFormats are the formats accepted by deco history --format.
+var historyFormats = []string{"table", "json", "jsonl", "csv"}
+
+// historyColumns are the fields of an entry in structured output.
+var historyColumns = []string{"timestamp", "node_id", "operation", "user", "commit", "content_hash", "before", "after", "snapshot", "prev_hash", "entry_hash"}
+
 // NewHistoryCommand creates the history subcommand
 func NewHistoryCommand() *cobra.Command {
 	flags := &historyFlags{}
@@ -41,13 +62,34 @@ func NewHistoryCommand() *cobra.Command {
 		Long: `Show the audit log history for the project.
 
 The audit log tracks all changes to nodes including creates, updates, and deletes.
-Use filters to narrow down the results.
+Use filters to narrow down the results. --node accepts a glob on node IDs
+(systems/*). --since and --until take a date, an RFC3339 time or a
+duration back from now (2h, 3d, 1w); a date given to --until includes
+that whole day.
+
+--format json, jsonl and csv write every field of each entry, including
+before/after values and hashes. --stats counts operations per user per
+ISO week instead of listing entries.
+
+Each entry is sealed with a hash of its content and of the entry before
+it. 'deco history verify' checks this chain to show the log has not been
+edited.
+
+When history.jsonl grows large it is closed into a gzip segment under
+.deco/history/. A sidecar index of node IDs and time ranges per segment
+lets queries read only the segments they need.
 
 Examples:
   deco history                       # Show all history
   deco history --node sword-001      # Show history for specific node
   deco history --limit 10            # Show last 10 entries
-  deco history -n hero-001 -l 5      # Combined filters`,
+  deco history -n hero-001 -l 5      # Combined filters
+  deco history --node 'systems/*' --op approve --since 2026-01-01
+  deco history --user alice --until 30d --reverse
+  deco history --format csv > audit.csv
+  deco history --stats --since 12w   # Operations per user per week
+  deco history verify                # Check the hash chain
+  deco history compact --before 90d  # Pack old entries into segments`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
@@ -55,17 +97,228 @@ Examples:
 			} else {
 				flags.targetDir = "."
 			}
-			return runHistory(flags)
+			return runHistory(cmd.OutOrStdout(), flags)
 		},
 	}
 
-	cmd.Flags().StringVarP(&flags.nodeID, "node", "n", "", "Filter by node ID")
+	cmd.Flags().StringVarP(&flags.nodeID, "node", "n", "", "Filter by node ID or glob (e.g., systems/*)")
+	cmd.Flags().StringVar(&flags.operation, "op", "", "Filter by operation (e.g., sync, approve)")
+	cmd.Flags().StringVar(&flags.user, "user", "", "Filter by user")
+	cmd.Flags().StringVar(&flags.since, "since", "", "Only entries at or after this time (e.g., 2h, 1w, 2026-01-01)")
+	cmd.Flags().StringVar(&flags.until, "until", "", "Only entries at or before this time; a date includes the whole day (e.g., 30d, 2026-01-01)")
 	cmd.Flags().IntVarP(&flags.limit, "limit", "l", 0, "Limit number of entries (0 = no limit)")
+	cmd.Flags().BoolVar(&flags.reverse, "reverse", false, "Newest entries first")
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "table", "Output format (table, json, jsonl, csv)")
+	cmd.Flags().BoolVar(&flags.stats, "stats", false, "Count operations per user per week")
+
+	cmd.AddCommand(newHistoryVerifyCommand())
+	cmd.AddCommand(newHistoryCompactCommand())
+
+	return cmd
+}
+
+func newHistoryVerifyCommand() *cobra.Command {
+	var quiet bool
+
+	cmd := &cobra.Command{
+		Use:   "verify [directory]",
+		Short: "Check that the audit log has not been altered",
+		Long: `Check the hash chain of the audit log.
+
+Every entry carries an entry_hash over its content and the prev_hash of
+the entry before it. Editing an entry changes its hash; deleting,
+inserting or reordering entries breaks a prev_hash link. The first broken
+link is reported with its line number. Entries written before the chain
+was introduced are counted but cannot be checked.
+
+Removing entries from the end of the log leaves a valid chain; compare
+the last entry_hash with a trusted copy (for example in git) to detect
+truncation.
+
+Exit codes:
+  0: Chain intact
+  1: Chain broken
+
+Examples:
+  deco history verify
+  deco history verify --quiet && echo "history intact"`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return runHistoryVerify(cmd.OutOrStdout(), dir, quiet)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress output (exit code only)")
 
 	return cmd
 }
 
-func runHistory(flags *historyFlags) error {
+func runHistoryVerify(w io.Writer, dir string, quiet bool) error {
+	configRepo := config.NewYAMLRepository(dir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	historyPath := config.ResolveHistoryPath(cfg, dir)
+	historyRepo := history.NewYAMLRepository(historyPath)
+	report, err := historyRepo.Verify()
+	if err != nil {
+		return err
+	}
+
+	if report.Break != nil {
+		if !quiet {
+			b := report.Break
+			fmt.Fprintf(w, "%s History chain broken at line %d", style.ErrorIcon(), b.Line)
+			if b.File != historyPath {
+				// Closed segment: name it relative to the history file
+				if rel, err := filepath.Rel(filepath.Dir(historyPath), b.File); err == nil {
+					fmt.Fprintf(w, " of %s", rel)
+				}
+			}
+			if b.Entry != nil {
+				fmt.Fprintf(w, " (%s %s, %s)", b.Entry.Operation, b.Entry.NodeID, b.Entry.Timestamp.Format(time.RFC3339))
+			}
+			fmt.Fprintf(w, "\n  %s\n", b.Reason)
+			fmt.Fprintf(w, "  %d entr%s before it verified\n", report.Verified, pluralY(report.Verified))
+		}
+		return NewExitError(ExitCodeError, fmt.Sprintf("history chain broken at line %d", report.Break.Line))
+	}
+
+	if !quiet {
+		fmt.Fprintf(w, "%s History chain intact: %d entr%s verified\n", style.SuccessIcon(), report.Verified, pluralY(report.Verified))
+		if report.Legacy > 0 {
+			fmt.Fprintf(w, "  %s\n", style.Muted.Sprintf("%d earlier entr%s predate the hash chain and are not covered", report.Legacy, pluralY(report.Legacy)))
+		}
+	}
+	return nil
+}
+
+type historyCompactFlags struct {
+	before string
+	dryRun bool
+	force  bool
+}
+
+func newHistoryCompactCommand() *cobra.Command {
+	flags := &historyCompactFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "compact [directory]",
+		Short: "Pack old history into compressed segments",
+		Long: `Rewrite the audit log into compressed segments.
+
+Entries older than --before (all entries by default) are packed into gzip
+segments under .deco/history/ and the sidecar index is rebuilt. Entries
+that repeat their node's previous content hash without recording a change
+are dropped. Every other entry keeps its content hash and snapshot, so
+sync change detection, --version and --as-of are unaffected.
+
+Compaction seals the hash chain again from the first entry, which also
+covers entries written before the chain existed. The old and new head
+hashes are printed: update any trusted copy of the last entry_hash.
+A chain that fails 'deco history verify' is refused, since sealing it
+again would hide the edit; --force compacts it as it stands.
+
+Examples:
+  deco history compact
+  deco history compact --before 90d
+  deco history compact --before 2026-01-01 --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return runHistoryCompact(cmd.OutOrStdout(), dir, flags)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.before, "before", "", "Only pack entries older than this (e.g., 90d, 2026-01-01)")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Show what would change without writing")
+	cmd.Flags().BoolVar(&flags.force, "force", false, "Compact even if the hash chain is broken, sealing entries as they stand")
+
+	return cmd
+}
+
+func runHistoryCompact(w io.Writer, dir string, flags *historyCompactFlags) error {
+	configRepo := config.NewYAMLRepository(dir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	if !flags.dryRun {
+		unlock, err := lockProject(dir, "history compact")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	var opts history.CompactOptions
+	opts.DryRun = flags.dryRun
+	opts.Force = flags.force
+	if flags.before != "" {
+		opts.Before, err = parseSince(flags.before)
+		if err != nil {
+			return fmt.Errorf("invalid --before value: %w", err)
+		}
+	}
+
+	historyRepo := historyForWrite(config.ResolveHistoryPath(cfg, dir))
+	result, err := historyRepo.Compact(opts)
+	if errors.Is(err, history.ErrChainBroken) {
+		return fmt.Errorf("refusing to compact history: %w (inspect with 'deco history verify'; --force seals it as it stands)", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+
+	verb := "Compacted"
+	if flags.dryRun {
+		verb = "Would compact"
+	}
+	fmt.Fprintf(w, "%s %s history: %d entr%s in %d segment(s), %d left in the active file\n",
+		style.SuccessIcon(), verb, result.Entries-result.Active, pluralY(result.Entries-result.Active), result.Segments, result.Active)
+	if result.Dropped > 0 {
+		fmt.Fprintf(w, "  %d redundant entr%s dropped\n", result.Dropped, pluralY(result.Dropped))
+	}
+	if !flags.dryRun && result.OldHead != result.NewHead {
+		fmt.Fprintf(w, "  %s\n", style.Muted.Sprintf("chain head %s -> %s", shortHash(result.OldHead), result.NewHead))
+	}
+	return nil
+}
+
+// shortHash abbreviates a hash for display.
+func shortHash(hash string) string {
+	if hash == "" {
+		return "(none)"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// pluralY returns the ending of "entry"/"entries" for n.
+func pluralY(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
+
+func runHistory(w io.Writer, flags *historyFlags) error {
+	if !containsString(historyFormats, flags.format) {
+		return fmt.Errorf("unknown format: %s (supported: %s)", flags.format, strings.Join(historyFormats, ", "))
+	}
+
 	// Load config to verify project exists
 	configRepo := config.NewYAMLRepository(flags.targetDir)
 	cfg, err := configRepo.Load()
@@ -73,27 +326,188 @@ func runHistory(flags *historyFlags) error {
 		return fmt.Errorf(".deco directory not found or invalid: %w", err)
 	}
 
-	// Query history
-	historyRepo := history.NewYAMLRepository(config.ResolveHistoryPath(cfg, flags.targetDir))
-	filter := history.Filter{
-		NodeID: flags.nodeID,
-		Limit:  flags.limit,
+	filter, nodeGlob, err := historyFilter(flags)
+	if err != nil {
+		return err
 	}
+
+	// Query history. The limit is applied here once globs and --reverse
+	// have been taken into account.
+	historyRepo := history.NewYAMLRepository(config.ResolveHistoryPath(cfg, flags.targetDir))
 	entries, err := historyRepo.Query(filter)
 	if err != nil {
 		return fmt.Errorf("failed to query history: %w", err)
 	}
+	if nodeGlob != "" {
+		matched := entries[:0]
+		for _, e := range entries {
+			if ok, _ := path.Match(nodeGlob, e.NodeID); ok {
+				matched = append(matched, e)
+			}
+		}
+		entries = matched
+	}
+
+	if flags.stats {
+		return printHistoryStats(w, entries, flags)
+	}
+
+	if flags.reverse {
+		slices.Reverse(entries)
+	}
+	if flags.limit > 0 && len(entries) > flags.limit {
+		entries = entries[:flags.limit]
+	}
+
+	if flags.format != "table" {
+		return historyTabular(entries).render(w, flags.format, "entry")
+	}
 
 	// Display results
 	if len(entries) == 0 {
-		fmt.Println("No history entries found")
+		fmt.Fprintln(w, "No history entries found")
 		return nil
 	}
 
-	printHistoryTable(entries)
+	printHistoryTable(w, entries)
 	return nil
 }
 
+// historyFilter builds the repository filter from the flags. A --node value
+// with glob characters is returned separately and matched after the query.
+func historyFilter(flags *historyFlags) (history.Filter, string, error) {
+	filter := history.Filter{
+		Operation: flags.operation,
+		User:      flags.user,
+	}
+
+	nodeGlob := ""
+	if strings.ContainsAny(flags.nodeID, "*?[") {
+		if _, err := path.Match(flags.nodeID, ""); err != nil {
+			return filter, "", fmt.Errorf("invalid --node pattern %q: %w", flags.nodeID, err)
+		}
+		nodeGlob = flags.nodeID
+	} else {
+		filter.NodeID = flags.nodeID
+	}
+
+	if flags.since != "" {
+		t, err := parseSince(flags.since)
+		if err != nil {
+			return filter, "", fmt.Errorf("invalid --since value: %w", err)
+		}
+		filter.Since = t.Unix()
+	}
+	if flags.until != "" {
+		t, err := parseSince(flags.until)
+		if err != nil {
+			return filter, "", fmt.Errorf("invalid --until value: %w", err)
+		}
+		if _, err := time.Parse("2006-01-02", flags.until); err == nil {
+			// A date includes the whole day
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		filter.Until = t.Unix()
+	}
+	if filter.Since > 0 && filter.Until > 0 && filter.Since > filter.Until {
+		return filter, "", fmt.Errorf("--since is after --until")
+	}
+	return filter, nodeGlob, nil
+}
+
+// historyTabular lays entries out for the structured formats. Empty
+// fields are left null rather than "".
+func historyTabular(entries []domain.AuditEntry) tabular {
+	t := tabular{columns: historyColumns, rows: make([][]interface{}, len(entries))}
+	for i, e := range entries {
+		t.rows[i] = []interface{}{
+			e.Timestamp.Format(time.RFC3339Nano),
+			e.NodeID,
+			e.Operation,
+			e.User,
+			nonEmpty(e.Commit),
+			nonEmpty(e.ContentHash),
+			nonEmptyMap(e.Before),
+			nonEmptyMap(e.After),
+			nonEmpty(e.Snapshot),
+			nonEmpty(e.PrevHash),
+			nonEmpty(e.EntryHash),
+		}
+	}
+	return t
+}
+
+func nonEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nonEmptyMap(m map[string]interface{}) interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// printHistoryStats counts operations per ISO week and user, with one
+// column per operation that occurs.
+func printHistoryStats(w io.Writer, entries []domain.AuditEntry, flags *historyFlags) error {
+	type bucket struct{ week, user string }
+	counts := make(map[bucket]map[string]int)
+	var buckets []bucket
+	opSet := make(map[string]bool)
+	for _, e := range entries {
+		year, week := e.Timestamp.ISOWeek()
+		b := bucket{week: fmt.Sprintf("%d-W%02d", year, week), user: e.User}
+		if counts[b] == nil {
+			counts[b] = make(map[string]int)
+			buckets = append(buckets, b)
+		}
+		counts[b][e.Operation]++
+		opSet[e.Operation] = true
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].week != buckets[j].week {
+			if flags.reverse {
+				return buckets[i].week > buckets[j].week
+			}
+			return buckets[i].week < buckets[j].week
+		}
+		return buckets[i].user < buckets[j].user
+	})
+	if flags.limit > 0 && len(buckets) > flags.limit {
+		buckets = buckets[:flags.limit]
+	}
+
+	ops := make([]string, 0, len(opSet))
+	for op := range opSet {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	t := tabular{columns: append([]string{"week", "user", "total"}, ops...)}
+	for _, b := range buckets {
+		row := []interface{}{b.week, b.user, 0}
+		total := 0
+		for _, op := range ops {
+			n := counts[b][op]
+			total += n
+			row = append(row, n)
+		}
+		row[2] = total
+		t.rows = append(t.rows, row)
+	}
+
+	if flags.format == "table" && len(t.rows) == 0 {
+		fmt.Fprintln(w, "No history entries found")
+		return nil
+	}
+	return t.render(w, flags.format, "row")
+}
+
 type historyRow struct {
 	time      string
 	nodeID    string
@@ -101,7 +515,7 @@ type historyRow struct {
 	user      string
 }
 
-func printHistoryTable(entries []domain.AuditEntry) {
+func printHistoryTable(w io.Writer, entries []domain.AuditEntry) {
 	// Calculate column widths
 	maxTimeLen := 4 // "TIME"
 	maxNodeLen := 4 // "NODE"
@@ -138,15 +552,15 @@ func printHistoryTable(entries []domain.AuditEntry) {
 		maxNodeLen, "NODE",
 		maxOpLen, "OPERATION",
 		maxUserLen, "USER")
-	fmt.Println(header)
+	fmt.Fprintln(w, header)
 
 	// Print separator
 	separator := strings.Repeat("-", len(header))
-	fmt.Println(separator)
+	fmt.Fprintln(w, separator)
 
 	// Print rows
 	for _, row := range rows {
-		fmt.Printf("%-*s  %-*s  %-*s  %-*s\n",
+		fmt.Fprintf(w, "%-*s  %-*s  %-*s  %-*s\n",
 			maxTimeLen, row.time,
 			maxNodeLen, row.nodeID,
 			maxOpLen, row.operation,
@@ -154,5 +568,5 @@ func printHistoryTable(entries []domain.AuditEntry) {
 	}
 
 	// Print summary
-	fmt.Printf("\nTotal: %d entry/entries\n", len(entries))
+	fmt.Fprintf(w, "\nTotal: %d entry/entries\n", len(entries))
 }
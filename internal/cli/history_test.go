@@ -16,11 +16,18 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/history"
 )
 
 func TestHistoryCommand_Structure(t *testing.T) {
@@ -208,6 +215,272 @@ func TestHistoryCommand_WithRootCommand(t *testing.T) {
 	})
 }
 
+func TestHistoryCommand_FullFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupProjectWithHistory(t, tmpDir)
+
+	run := func(flags historyFlags) []map[string]interface{} {
+		t.Helper()
+		flags.targetDir = tmpDir
+		flags.format = "jsonl"
+		var out bytes.Buffer
+		if err := runHistory(&out, &flags); err != nil {
+			t.Fatalf("runHistory(%+v) failed: %v", flags, err)
+		}
+		var rows []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var row map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &row); err != nil {
+				t.Fatalf("invalid JSONL line %q: %v", line, err)
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	}
+
+	tests := []struct {
+		name  string
+		flags historyFlags
+		want  []string // node_id/operation per row
+	}{
+		{"operation and user", historyFlags{operation: "update", user: "alice"}, []string{"sword-001/update"}},
+		{"node glob", historyFlags{nodeID: "sword-*"}, []string{"sword-001/create", "sword-001/update"}},
+		{"since", historyFlags{since: "90m"}, []string{"sword-001/update", "hero-001/update"}},
+		{"until", historyFlags{until: "90m"}, []string{"sword-001/create", "hero-001/create"}},
+		{"reverse with limit", historyFlags{reverse: true, limit: 2}, []string{"hero-001/update", "sword-001/update"}},
+		{"no match", historyFlags{user: "carol"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, row := range run(tt.flags) {
+				got = append(got, fmt.Sprintf("%v/%v", row["node_id"], row["operation"]))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := runHistory(&out, &historyFlags{targetDir: tmpDir, format: "csv", operation: "create"}); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if lines[0] != "timestamp,node_id,operation,user,commit,content_hash,before,after,snapshot,prev_hash,entry_hash" || len(lines) != 3 {
+			t.Errorf("unexpected CSV:\n%s", out.String())
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, flags := range []historyFlags{
+			{format: "xml"},
+			{since: "yesterday"},
+			{since: "1h", until: "2h"},
+			{nodeID: "systems/[a"},
+		} {
+			flags.targetDir = tmpDir
+			if flags.format == "" {
+				flags.format = "table"
+			}
+			if err := runHistory(&bytes.Buffer{}, &flags); err == nil {
+				t.Errorf("expected an error for %+v", flags)
+			}
+		}
+	})
+}
+
+func TestHistoryFilter_UntilDateIncludesDay(t *testing.T) {
+	filter, _, err := historyFilter(&historyFlags{since: "2026-01-01", until: "2026-01-01"})
+	if err != nil {
+		t.Fatalf("a single-day range should be valid: %v", err)
+	}
+	want := time.Date(2026, 1, 1, 23, 59, 59, 0, time.UTC).Unix()
+	if filter.Until != want {
+		t.Errorf("Until = %s, want the end of the day", time.Unix(filter.Until, 0).UTC())
+	}
+
+	filter, _, err = historyFilter(&historyFlags{until: "2026-01-01T12:00:00Z"})
+	if err != nil || filter.Until != time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC).Unix() {
+		t.Errorf("an exact time should be kept as given, got %d, %v", filter.Until, err)
+	}
+}
+
+func TestHistoryCommand_Stats(t *testing.T) {
+	dir := setupDecoProject(t)
+	repo := history.NewYAMLRepository(filepath.Join(dir, ".deco", "history.jsonl"))
+	monday := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC) // 2026-W10
+	for _, e := range []struct {
+		days int
+		user string
+		op   string
+	}{
+		{0, "alice", "sync"},
+		{1, "alice", "sync"},
+		{2, "bob", "approve"},
+		{7, "alice", "approve"},
+		{8, "alice", "sync"},
+	} {
+		if err := repo.Append(domain.AuditEntry{
+			Timestamp: monday.AddDate(0, 0, e.days),
+			NodeID:    "systems/combat",
+			Operation: e.op,
+			User:      e.user,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := runHistory(&out, &historyFlags{targetDir: dir, format: "csv", stats: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "week,user,total,approve,sync\n" +
+		"2026-W10,alice,2,0,2\n" +
+		"2026-W10,bob,1,1,0\n" +
+		"2026-W11,alice,2,1,1\n"
+	if out.String() != want {
+		t.Errorf("stats =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := runHistory(&out, &historyFlags{targetDir: dir, format: "table", stats: true, reverse: true, user: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if first := strings.Index(out.String(), "2026-W11"); first < 0 || first > strings.Index(out.String(), "2026-W10") {
+		t.Errorf("expected newest week first:\n%s", out.String())
+	}
+}
+
+func TestHistoryVerify(t *testing.T) {
+	dir := setupDecoProject(t)
+	createTestNode(t, dir, "systems/combat")
+	createTestNode(t, dir, "systems/magic")
+	if code, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil || code != syncExitClean {
+		t.Fatalf("sync failed: code=%d err=%v", code, err)
+	}
+
+	var out bytes.Buffer
+	if err := runHistoryVerify(&out, dir, false); err != nil {
+		t.Fatalf("verify on untouched history: %v", err)
+	}
+	if !strings.Contains(out.String(), "History chain intact") {
+		t.Errorf("expected intact report, got:\n%s", out.String())
+	}
+
+	historyPath := filepath.Join(dir, ".deco", "history.jsonl")
+	data, err := os.ReadFile(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected at least 2 history entries, got %d", len(lines))
+	}
+	lines[1] = strings.Replace(lines[1], `"user":"`, `"user":"mallory-`, 1)
+	if err := os.WriteFile(historyPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	err = runHistoryVerify(&out, dir, false)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitCodeError {
+		t.Fatalf("expected exit error for tampered history, got %v", err)
+	}
+	if !strings.Contains(out.String(), "broken at line 2") {
+		t.Errorf("expected break at line 2, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "was modified") {
+		t.Errorf("expected modification reason, got:\n%s", out.String())
+	}
+}
+
+func TestHistoryCompact(t *testing.T) {
+	dir := setupDecoProject(t)
+	createTestNode(t, dir, "systems/combat")
+	createTestNode(t, dir, "systems/magic")
+	if code, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil || code != syncExitClean {
+		t.Fatalf("sync failed: code=%d err=%v", code, err)
+	}
+
+	var out bytes.Buffer
+	if err := runHistoryCompact(&out, dir, &historyCompactFlags{dryRun: true}); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !strings.Contains(out.String(), "Would compact history: 2 entries in 1 segment(s)") {
+		t.Errorf("unexpected dry run output:\n%s", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".deco", "history")); !os.IsNotExist(err) {
+		t.Fatalf("dry run wrote segments")
+	}
+
+	out.Reset()
+	if err := runHistoryCompact(&out, dir, &historyCompactFlags{}); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".deco", "history", "000001.jsonl.gz")); err != nil {
+		t.Errorf("expected a compressed segment: %v", err)
+	}
+
+	// Sync still recognizes the unchanged nodes from the compacted log
+	out.Reset()
+	if err := runHistoryVerify(&out, dir, false); err != nil {
+		t.Fatalf("verify after compact: %v\n%s", err, out.String())
+	}
+	if code, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil || code != syncExitClean {
+		t.Errorf("expected a clean sync after compact, got code=%d err=%v", code, err)
+	}
+	entries, err := history.NewYAMLRepository(filepath.Join(dir, ".deco", "history.jsonl")).Query(history.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected the 2 baseline entries to survive, got %d", len(entries))
+	}
+}
+
+func TestHistoryCompact_RefusesTamperedHistory(t *testing.T) {
+	dir := setupDecoProject(t)
+	createTestNode(t, dir, "systems/combat")
+	createTestNode(t, dir, "systems/magic")
+	if code, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil || code != syncExitClean {
+		t.Fatalf("sync failed: code=%d err=%v", code, err)
+	}
+
+	historyPath := filepath.Join(dir, ".deco", "history.jsonl")
+	data, err := os.ReadFile(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"user":"`, `"user":"mallory-`, 1)
+	if err := os.WriteFile(historyPath, []byte(tampered), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = runHistoryCompact(&out, dir, &historyCompactFlags{})
+	if !errors.Is(err, history.ErrChainBroken) || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected compaction to be refused, got %v", err)
+	}
+	if err := runHistoryVerify(&out, dir, false); err == nil {
+		t.Error("expected verify to still report the edit")
+	}
+
+	out.Reset()
+	if err := runHistoryCompact(&out, dir, &historyCompactFlags{force: true}); err != nil {
+		t.Fatalf("forced compact: %v", err)
+	}
+	out.Reset()
+	if err := runHistoryVerify(&out, dir, false); err != nil {
+		t.Errorf("expected an intact chain after --force, got %v\n%s", err, out.String())
+	}
+}
+
 // Test helpers
 
 func setupProjectWithHistory(t *testing.T, dir string) {
@@ -0,0 +1,335 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/graph"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/git"
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/spf13/cobra"
+)
+
+type impactFlags struct {
+	depth        int
+	refTypes     []string
+	format       string
+	changedSince string
+	targetDir    string
+}
+
+// NewImpactCommand creates the impact subcommand
+func NewImpactCommand() *cobra.Command {
+	flags := &impactFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "impact [node-id] [directory]",
+		Short: "Show everything that transitively depends on a node",
+		Long: `Walk reverse references to find every node that transitively depends
+on the given node. Each dependent is shown with its status, approval state
+and open issue count.
+
+With --changed-since, the roots are every node modified in the history
+window instead of a single node; the positional argument is then the
+project directory. The window start can be a timestamp, a date, a relative
+duration (2h, 1d, 1w) or a git revision (its commit time is used).
+
+Formats:
+  tree  Indented dependency tree (default)
+  json  Structured output for tooling
+  ids   One node ID per line
+
+Examples:
+  deco impact systems/auth
+  deco impact systems/auth --depth 2
+  deco impact systems/auth --ref-type uses
+  deco impact systems/auth --format json
+  deco impact --changed-since 1w
+  deco impact --changed-since HEAD~5 --format ids`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var roots []string
+			flags.targetDir = "."
+			if flags.changedSince != "" {
+				if len(args) > 1 {
+					return fmt.Errorf("--changed-since takes at most one argument (the project directory)")
+				}
+				if len(args) == 1 {
+					flags.targetDir = args[0]
+				}
+			} else {
+				if len(args) == 0 {
+					return fmt.Errorf("requires a node ID or --changed-since")
+				}
+				roots = []string{args[0]}
+				if len(args) > 1 {
+					flags.targetDir = args[1]
+				}
+			}
+			return runImpact(cmd.OutOrStdout(), roots, flags)
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.depth, "depth", 0, "Maximum number of hops to follow (0 = unlimited)")
+	cmd.Flags().StringSliceVar(&flags.refTypes, "ref-type", nil, "Reference types to follow: uses, related (default: all)")
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "tree", "Output format (tree, json, ids)")
+	cmd.Flags().StringVar(&flags.changedSince, "changed-since", "", "Use nodes changed since a time or git revision as roots")
+
+	return cmd
+}
+
+// impactNode is a dependent node with the review context needed to judge the impact.
+type impactNode struct {
+	ID                string `json:"id"`
+	Title             string `json:"title"`
+	Kind              string `json:"kind"`
+	Status            string `json:"status"`
+	Depth             int    `json:"depth"`
+	Via               string `json:"via,omitempty"`
+	RefType           string `json:"ref_type,omitempty"`
+	Context           string `json:"context,omitempty"`
+	Approvals         int    `json:"approvals"`
+	RequiredApprovals int    `json:"required_approvals"`
+	OpenIssues        int    `json:"open_issues"`
+}
+
+type impactResult struct {
+	Roots      []impactNode `json:"roots"`
+	Dependents []impactNode `json:"dependents"`
+}
+
+func runImpact(w io.Writer, roots []string, flags *impactFlags) error {
+	for _, t := range flags.refTypes {
+		if t != "uses" && t != "related" {
+			return fmt.Errorf("unknown ref type: %s (supported: uses, related)", t)
+		}
+	}
+	switch flags.format {
+	case "tree", "json", "ids":
+	default:
+		return fmt.Errorf("unknown format: %s (supported: tree, json, ids)", flags.format)
+	}
+
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
+	nodes, err := nodeRepo.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load nodes: %w", err)
+	}
+
+	builder := graph.NewBuilder()
+	g, err := builder.Build(nodes)
+	if err != nil {
+		return fmt.Errorf("failed to build graph: %w", err)
+	}
+
+	if flags.changedSince != "" {
+		since, err := resolveSinceOrRevision(flags.targetDir, flags.changedSince)
+		if err != nil {
+			return fmt.Errorf("invalid --changed-since value: %w", err)
+		}
+		historyRepo := history.NewYAMLRepository(config.ResolveHistoryPath(cfg, flags.targetDir))
+		roots, err = changedNodeIDs(historyRepo, since, g)
+		if err != nil {
+			return err
+		}
+	} else if _, ok := g.Get(roots[0]); !ok {
+		return fmt.Errorf("node '%s' not found", roots[0])
+	}
+
+	entries := builder.Impact(g, roots, graph.ImpactOptions{
+		MaxDepth: flags.depth,
+		RefTypes: flags.refTypes,
+	})
+
+	result := impactResult{
+		Roots:      make([]impactNode, 0, len(roots)),
+		Dependents: make([]impactNode, 0, len(entries)),
+	}
+	for _, id := range roots {
+		n, _ := g.Get(id)
+		result.Roots = append(result.Roots, newImpactNode(n, cfg))
+	}
+	for _, e := range entries {
+		n, _ := g.Get(e.ID)
+		in := newImpactNode(n, cfg)
+		in.Depth = e.Depth
+		in.Via = e.Via
+		in.RefType = e.RefType
+		in.Context = e.Context
+		result.Dependents = append(result.Dependents, in)
+	}
+
+	switch flags.format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+	case "ids":
+		for _, d := range result.Dependents {
+			fmt.Fprintln(w, d.ID)
+		}
+	default:
+		printImpactTree(w, result, flags.changedSince)
+	}
+
+	return nil
+}
+
+func newImpactNode(n domain.Node, cfg config.Config) impactNode {
+	return impactNode{
+		ID:                n.ID,
+		Title:             n.Title,
+		Kind:              n.Kind,
+		Status:            n.Status,
+		Approvals:         currentApprovals(n),
+		RequiredApprovals: cfg.RequiredApprovals,
+		OpenIssues:        openIssueCount(n),
+	}
+}
+
+// currentApprovals counts approvals recorded for the node's current version.
+func currentApprovals(n domain.Node) int {
+	count := 0
+	for _, r := range n.Reviewers {
+		if r.Version == n.Version {
+			count++
+		}
+	}
+	return count
+}
+
+func openIssueCount(n domain.Node) int {
+	count := 0
+	for _, issue := range n.Issues {
+		if !issue.Resolved {
+			count++
+		}
+	}
+	return count
+}
+
+// changedNodeIDs returns the IDs of existing nodes with content-changing
+// history entries at or after since, sorted.
+func changedNodeIDs(repo history.Repository, since time.Time, g *domain.Graph) ([]string, error) {
+	entries, err := repo.Query(history.Filter{Since: since.Unix()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, e := range entries {
+		switch e.Operation {
+		case "submit", "approve", "reject", "baseline":
+			continue // workflow-only, content unchanged
+		}
+		if _, ok := g.Get(e.NodeID); !ok || seen[e.NodeID] {
+			continue
+		}
+		seen[e.NodeID] = true
+		ids = append(ids, e.NodeID)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// resolveSinceOrRevision parses a time value (see parseSince) or, failing that,
+// resolves a git revision in dir to its commit time.
+func resolveSinceOrRevision(dir, value string) (time.Time, error) {
+	if t, err := parseSince(value); err == nil {
+		return t, nil
+	}
+
+	t, err := git.CommitTime(dir, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a time (RFC3339, date, or 2h/1d/1w) nor a git revision", value)
+	}
+	return t, nil
+}
+
+func printImpactTree(w io.Writer, result impactResult, changedSince string) {
+	if changedSince != "" {
+		if len(result.Roots) == 0 {
+			fmt.Fprintf(w, "No nodes changed since %s\n", changedSince)
+			return
+		}
+		fmt.Fprintf(w, "%s %d node(s) changed since %s\n", style.Header.Sprint("Changed:"), len(result.Roots), changedSince)
+	}
+
+	children := make(map[string][]impactNode)
+	for _, d := range result.Dependents {
+		children[d.Via] = append(children[d.Via], d)
+	}
+
+	var walk func(id, prefix string)
+	walk = func(id, prefix string) {
+		kids := children[id]
+		for i, kid := range kids {
+			connector, indent := "├── ", "│   "
+			if i == len(kids)-1 {
+				connector, indent = "└── ", "    "
+			}
+			fmt.Fprintf(w, "%s%s%s %s\n", prefix, connector, kid.ID, impactDetails(kid))
+			walk(kid.ID, prefix+indent)
+		}
+	}
+
+	for _, root := range result.Roots {
+		fmt.Fprintf(w, "%s %s\n", style.Header.Sprint(root.ID), impactDetails(root))
+		walk(root.ID, "")
+	}
+
+	fmt.Fprintf(w, "\nTotal: %d dependent node(s)\n", len(result.Dependents))
+}
+
+func impactDetails(n impactNode) string {
+	status := n.Status
+	if c := style.StatusColor(n.Status); c != nil {
+		status = c.Sprint(n.Status)
+	}
+
+	parts := []string{status, fmt.Sprintf("%d/%d approvals", n.Approvals, n.RequiredApprovals)}
+	if n.OpenIssues > 0 {
+		parts = append(parts, style.Warning.Sprintf("%d open issue(s)", n.OpenIssues))
+	}
+
+	details := "[" + strings.Join(parts, ", ") + "]"
+	if n.RefType != "" {
+		details += style.Muted.Sprintf(" via %s", n.RefType)
+		if n.Context != "" {
+			details += style.Muted.Sprintf(" (%s)", n.Context)
+		}
+	}
+	return details
+}
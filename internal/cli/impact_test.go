@@ -0,0 +1,158 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/history"
+)
+
+func TestImpactCommand_Structure(t *testing.T) {
+	cmd := NewImpactCommand()
+	if !strings.HasPrefix(cmd.Use, "impact") {
+		t.Errorf("Expected Use to start with 'impact', got %q", cmd.Use)
+	}
+	for _, name := range []string{"depth", "ref-type", "format", "changed-since"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("Expected --%s flag to be defined", name)
+		}
+	}
+}
+
+// setupImpactProject creates core <- mid <- leaf (uses refs)
+func setupImpactProject(t *testing.T) string {
+	t.Helper()
+	dir := setupDecoProject(t)
+	createTestNode(t, dir, "core")
+	createTestNodeWithRefs(t, dir, "mid", []string{"core"})
+	createTestNodeWithRefs(t, dir, "leaf", []string{"mid"})
+	return dir
+}
+
+func TestImpactCommand_Tree(t *testing.T) {
+	dir := setupImpactProject(t)
+
+	var buf bytes.Buffer
+	cmd := NewImpactCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"core", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "└── mid") || !strings.Contains(out, "    └── leaf") {
+		t.Errorf("Expected nested tree output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Total: 2 dependent node(s)") {
+		t.Errorf("Expected total line, got:\n%s", out)
+	}
+}
+
+func TestImpactCommand_DepthAndIDs(t *testing.T) {
+	dir := setupImpactProject(t)
+
+	var buf bytes.Buffer
+	cmd := NewImpactCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"core", dir, "--depth", "1", "--format", "ids"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := strings.TrimSpace(buf.String()); got != "mid" {
+		t.Errorf("Expected only 'mid', got %q", got)
+	}
+}
+
+func TestImpactCommand_JSON(t *testing.T) {
+	dir := setupImpactProject(t)
+
+	var buf bytes.Buffer
+	cmd := NewImpactCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"core", dir, "--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var result impactResult
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(result.Roots) != 1 || result.Roots[0].ID != "core" {
+		t.Errorf("Unexpected roots: %+v", result.Roots)
+	}
+	if len(result.Dependents) != 2 || result.Dependents[1].ID != "leaf" || result.Dependents[1].Depth != 2 {
+		t.Errorf("Unexpected dependents: %+v", result.Dependents)
+	}
+	if result.Dependents[0].Status != "draft" || result.Dependents[0].RequiredApprovals == 0 {
+		t.Errorf("Expected status and approval requirements, got %+v", result.Dependents[0])
+	}
+}
+
+func TestImpactCommand_ChangedSince(t *testing.T) {
+	dir := setupImpactProject(t)
+
+	repo := history.NewYAMLRepository(dir + "/.deco/history.jsonl")
+	old := time.Now().Add(-72 * time.Hour)
+	repo.Append(domain.AuditEntry{Timestamp: old, NodeID: "core", Operation: "update", User: "a"})
+	repo.Append(domain.AuditEntry{Timestamp: time.Now(), NodeID: "mid", Operation: "update", User: "a"})
+	repo.Append(domain.AuditEntry{Timestamp: time.Now(), NodeID: "core", Operation: "approve", User: "a"})
+
+	var buf bytes.Buffer
+	cmd := NewImpactCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{dir, "--changed-since", "1d", "--format", "ids"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := strings.TrimSpace(buf.String()); got != "leaf" {
+		t.Errorf("Expected only 'leaf' (dependent of changed 'mid'), got %q", got)
+	}
+}
+
+func TestImpactCommand_Errors(t *testing.T) {
+	dir := setupImpactProject(t)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing node", []string{"nope", dir}},
+		{"no node or window", []string{}},
+		{"bad ref type", []string{"core", dir, "--ref-type", "owns"}},
+		{"bad format", []string{"core", dir, "--format", "svg"}},
+		{"bad window", []string{dir, "--changed-since", "not-a-rev"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewImpactCommand()
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/services/signing"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/spf13/cobra"
+)
+
+type keysFlags struct {
+	targetDir string
+	force     bool
+}
+
+// NewKeysCommand creates the keys command and its subcommands
+func NewKeysCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage trusted reviewer keys for signed approvals",
+		Long: `Manage the public keys trusted to sign approvals.
+
+Subcommands:
+  add      - Trust a reviewer's public key
+  list     - List trusted reviewer keys
+  remove   - Stop trusting a reviewer's key
+
+Keys are stored under trusted_keys in .deco/config.yaml, by reviewer name.
+The name must match the reviewer name 'deco review approve' records, which
+is the git identity ("Name <email>") when one is configured. Set
+require_signed_approvals: true in the config to make 'deco validate' count
+only approvals signed by these keys.`,
+	}
+
+	cmd.AddCommand(newKeysAddCommand())
+	cmd.AddCommand(newKeysListCommand())
+	cmd.AddCommand(newKeysRemoveCommand())
+
+	return cmd
+}
+
+func newKeysAddCommand() *cobra.Command {
+	flags := &keysFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "add <reviewer> <public-key-file> [directory]",
+		Short: "Trust a reviewer's public key",
+		Long: `Trust a reviewer's ed25519 public key for signed approvals.
+
+The key file is an OpenSSH public key (id_ed25519.pub) or a PEM public key.
+
+Examples:
+  deco keys add "Alice <alice@example.com>" alice.pub
+  deco keys add "Alice <alice@example.com>" ~/.ssh/id_ed25519.pub --force`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.targetDir = "."
+			if len(args) > 2 {
+				flags.targetDir = args[2]
+			}
+			return runKeysAdd(cmd.OutOrStdout(), args[0], args[1], flags)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flags.force, "force", "f", false, "Replace the reviewer's existing key")
+
+	return cmd
+}
+
+func runKeysAdd(w io.Writer, reviewer, keyFile string, flags *keysFlags) error {
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+	unlock, err := lockProject(flags.targetDir, "keys add")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %w", err)
+	}
+	key, err := signing.ParsePublicKey(data)
+	if err != nil {
+		return fmt.Errorf("invalid public key %s: %w", keyFile, err)
+	}
+	formatted := signing.FormatPublicKey(key)
+
+	if existing, ok := cfg.TrustedKeys[reviewer]; ok {
+		if existing == formatted {
+			fmt.Fprintf(w, "Key %s is already trusted for %s\n", signing.Fingerprint(key), reviewer)
+			return nil
+		}
+		if !flags.force {
+			return fmt.Errorf("%s already has a trusted key; use --force to replace it", reviewer)
+		}
+	}
+
+	if cfg.TrustedKeys == nil {
+		cfg.TrustedKeys = make(map[string]string)
+	}
+	cfg.TrustedKeys[reviewer] = formatted
+	if err := configRepo.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Fprintf(w, "%s Trusted %s for %s\n", style.SuccessIcon(), signing.Fingerprint(key), reviewer)
+	return nil
+}
+
+func newKeysListCommand() *cobra.Command {
+	flags := &keysFlags{}
+
+	return &cobra.Command{
+		Use:   "list [directory]",
+		Short: "List trusted reviewer keys",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.targetDir = "."
+			if len(args) > 0 {
+				flags.targetDir = args[0]
+			}
+			return runKeysList(cmd.OutOrStdout(), flags)
+		},
+	}
+}
+
+func runKeysList(w io.Writer, flags *keysFlags) error {
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	if len(cfg.TrustedKeys) == 0 {
+		fmt.Fprintln(w, "No trusted keys. Add one with 'deco keys add <reviewer> <public-key-file>'.")
+		return nil
+	}
+
+	reviewers := make([]string, 0, len(cfg.TrustedKeys))
+	for name := range cfg.TrustedKeys {
+		reviewers = append(reviewers, name)
+	}
+	sort.Strings(reviewers)
+
+	for _, name := range reviewers {
+		fingerprint := style.Error.Sprint("invalid key")
+		if key, err := signing.ParsePublicKey([]byte(cfg.TrustedKeys[name])); err == nil {
+			fingerprint = signing.Fingerprint(key)
+		}
+		fmt.Fprintf(w, "%s  %s\n", fingerprint, name)
+	}
+	if !cfg.RequireSignedApprovals {
+		fmt.Fprintf(w, "\n%s\n", style.Muted.Sprint("Signed approvals are optional (require_signed_approvals is not set)."))
+	}
+	return nil
+}
+
+func newKeysRemoveCommand() *cobra.Command {
+	flags := &keysFlags{}
+
+	return &cobra.Command{
+		Use:   "remove <reviewer> [directory]",
+		Short: "Stop trusting a reviewer's key",
+		Long: `Stop trusting a reviewer's key. Approvals signed with it no longer count
+when require_signed_approvals is set.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.targetDir = "."
+			if len(args) > 1 {
+				flags.targetDir = args[1]
+			}
+			return runKeysRemove(cmd.OutOrStdout(), args[0], flags)
+		},
+	}
+}
+
+func runKeysRemove(w io.Writer, reviewer string, flags *keysFlags) error {
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+	unlock, err := lockProject(flags.targetDir, "keys remove")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := cfg.TrustedKeys[reviewer]; !ok {
+		return fmt.Errorf("no trusted key for %s", reviewer)
+	}
+	delete(cfg.TrustedKeys, reviewer)
+	if err := configRepo.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Fprintf(w, "%s Removed the trusted key for %s\n", style.SuccessIcon(), reviewer)
+	return nil
+}
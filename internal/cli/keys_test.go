@@ -0,0 +1,209 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/services/signing"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/node"
+)
+
+// writeKeyPair writes a new ed25519 key pair as a PEM private key and an
+// OpenSSH public key, returning their paths and the key fingerprint.
+func writeKeyPair(t *testing.T, dir, name string) (string, string, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPath := filepath.Join(dir, name)
+	pubPath := privPath + ".pub"
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, []byte(signing.FormatPublicKey(pub)+" "+name+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return privPath, pubPath, signing.Fingerprint(pub)
+}
+
+// createReviewNodeWithContent creates a node in review with the content
+// an approved node needs to validate.
+func createReviewNodeWithContent(t *testing.T, dir, id string) {
+	t.Helper()
+	content := `id: ` + id + `
+kind: mechanic
+version: 1
+status: review
+title: Combat
+content:
+  sections:
+    - name: Rules
+      blocks:
+        - type: rule
+          id: damage
+          text: Swords deal 10 damage
+`
+	path := filepath.Join(dir, ".deco", "nodes", id+".yaml")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeysCommand(t *testing.T) {
+	dir := t.TempDir()
+	setupReviewProject(t, dir)
+	keyDir := t.TempDir()
+	_, alicePub, aliceFingerprint := writeKeyPair(t, keyDir, "alice")
+	_, otherPub, _ := writeKeyPair(t, keyDir, "other")
+
+	var out bytes.Buffer
+	if err := runKeysAdd(&out, "Alice <alice@example.com>", alicePub, &keysFlags{targetDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.NewYAMLRepository(dir).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(cfg.TrustedKeys["Alice <alice@example.com>"], "ssh-ed25519 ") {
+		t.Errorf("expected the key in config, got %v", cfg.TrustedKeys)
+	}
+
+	// Adding the same key again is a no-op; a different key needs --force
+	out.Reset()
+	if err := runKeysAdd(&out, "Alice <alice@example.com>", alicePub, &keysFlags{targetDir: dir}); err != nil || !strings.Contains(out.String(), "already trusted") {
+		t.Errorf("expected an already trusted note, got %q, %v", out.String(), err)
+	}
+	if err := runKeysAdd(&out, "Alice <alice@example.com>", otherPub, &keysFlags{targetDir: dir}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected replacing a key to need --force, got %v", err)
+	}
+	if err := runKeysAdd(&out, "Bob", filepath.Join(keyDir, "alice"), &keysFlags{targetDir: dir}); err == nil {
+		t.Error("expected a private key file to be rejected as a public key")
+	}
+
+	out.Reset()
+	if err := runKeysList(&out, &keysFlags{targetDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), aliceFingerprint+"  Alice <alice@example.com>") {
+		t.Errorf("list output missing the key:\n%s", out.String())
+	}
+
+	if err := runKeysRemove(&out, "Alice <alice@example.com>", &keysFlags{targetDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runKeysRemove(&out, "Alice <alice@example.com>", &keysFlags{targetDir: dir}); err == nil {
+		t.Error("expected removing an unknown reviewer to fail")
+	}
+}
+
+func TestSignedApprovals(t *testing.T) {
+	dir := t.TempDir()
+	setupReviewProject(t, dir)
+	createReviewNodeWithContent(t, dir, "systems/combat")
+	reviewer := GetCurrentUser(dir)
+	aliceKey, alicePub, aliceFingerprint := writeKeyPair(t, t.TempDir(), "alice")
+
+	if err := runKeysAdd(&bytes.Buffer{}, reviewer, alicePub, &keysFlags{targetDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, ".deco", "config.yaml")
+	data, _ := os.ReadFile(configPath)
+	os.WriteFile(configPath, append(data, "require_signed_approvals: true\n"...), 0644)
+
+	err := runApprove(&approveFlags{targetDir: dir, nodeID: "systems/combat", quiet: true})
+	if err == nil || !strings.Contains(err.Error(), "requires signed approvals") {
+		t.Fatalf("expected unsigned approval to be refused, got %v", err)
+	}
+	if err := runApprove(&approveFlags{targetDir: dir, nodeID: "systems/combat", keyFile: aliceKey, quiet: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	nodeRepo := node.NewYAMLRepository(filepath.Join(dir, ".deco", "nodes"))
+	n, err := nodeRepo.Load("systems/combat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Status != "approved" || len(n.Reviewers) != 1 {
+		t.Fatalf("expected one signed approval, got status %s and %+v", n.Status, n.Reviewers)
+	}
+	if r := n.Reviewers[0]; r.Key != aliceFingerprint || r.Signature == "" {
+		t.Errorf("expected a signature by %s, got %+v", aliceFingerprint, r)
+	}
+
+	// The signature covers the full SHA-256 of the content, not the 64-bit
+	// hash recorded in history
+	pubData, err := os.ReadFile(alicePub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := signing.ParsePublicKey(pubData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := ComputeFullContentHash(n)
+	if len(full) != 64 || !strings.HasPrefix(full, ComputeContentHash(n)) {
+		t.Errorf("full content hash = %q, want 64 hex characters extending %q", full, ComputeContentHash(n))
+	}
+	if !signing.Verify(pub, signing.Payload(n.ID, n.Version, full, reviewer), n.Reviewers[0].Signature) {
+		t.Error("expected the signature to cover the full content hash")
+	}
+
+	validate := func() (string, error) {
+		var err error
+		output := captureStdout(t, func() {
+			err = runValidate(&validateFlags{targetDir: dir})
+		})
+		return output, err
+	}
+	if output, err := validate(); err != nil {
+		t.Fatalf("expected the signed approval to validate, got %v\n%s", err, output)
+	}
+
+	// Editing the approved content by hand invalidates the signature
+	n.Title = "Combat (rebalanced)"
+	if err := nodeRepo.Save(n); err != nil {
+		t.Fatal(err)
+	}
+	output, err := validate()
+	if err == nil || !strings.Contains(output, "E122") || !strings.Contains(output, "E050") {
+		t.Errorf("expected E122 and E050 after tampering, got %v\n%s", err, output)
+	}
+
+	// A hand-written approval without a signature does not count either
+	n.Title = "Combat"
+	n.Reviewers[0].Signature = ""
+	if err := nodeRepo.Save(n); err != nil {
+		t.Fatal(err)
+	}
+	if output, _ := validate(); !strings.Contains(output, "E120") {
+		t.Errorf("expected E120 for an unsigned approval, got:\n%s", output)
+	}
+}
@@ -17,13 +17,11 @@ package cli
 
 import (
 	"fmt"
-	"strings"
+	"io"
 
-	"github.com/Toernblom/deco/internal/cli/style"
 	"github.com/Toernblom/deco/internal/domain"
 	"github.com/Toernblom/deco/internal/services/query"
 	"github.com/Toernblom/deco/internal/storage/config"
-	"github.com/Toernblom/deco/internal/storage/node"
 	"github.com/spf13/cobra"
 )
 
@@ -32,6 +30,8 @@ type listFlags struct {
 	status    string
 	tag       string
 	quiet     bool
+	output    outputFlags
+	asOf      string
 	targetDir string
 }
 
@@ -49,12 +49,28 @@ Filters can be combined to narrow down results:
   --status: Filter by status (draft, review, approved, etc.)
   --tag:    Filter by tag (must have this tag)
 
+Output can be shaped for spreadsheets and scripts:
+  --format:  table (default), json, jsonl, csv or yaml
+  --columns: Columns to output (default: id,kind,status,title)
+  --sort:    Sort keys, e.g. kind,version:desc
+  --limit:   Maximum number of nodes
+
+Columns:
+` + nodeColumnHelp + `
+
+` + asOfHelp + `
+
 Examples:
   deco list
   deco list --kind item
   deco list --status draft
   deco list --kind item --status approved
-  deco list --tag combat`,
+  deco list --tag combat
+  deco list --format csv --columns id,status,custom.owner,issues.open > nodes.csv
+  deco list --format json --columns id,refs.uses.count --sort refs.uses.count:desc --limit 10
+  deco list --sort version:desc --limit 5
+  deco list --as-of 2026-03-01 --status approved
+  deco list --as-of milestone-2`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
@@ -62,7 +78,7 @@ Examples:
 			} else {
 				flags.targetDir = "."
 			}
-			return runList(flags)
+			return runList(cmd.OutOrStdout(), flags)
 		},
 	}
 
@@ -70,11 +86,17 @@ Examples:
 	cmd.Flags().StringVarP(&flags.status, "status", "s", "", "Filter by status")
 	cmd.Flags().StringVarP(&flags.tag, "tag", "t", "", "Filter by tag")
 	cmd.Flags().BoolVarP(&flags.quiet, "quiet", "q", false, "Output node IDs only, one per line")
+	addOutputFlags(cmd, &flags.output, "f")
+	addAsOfFlag(cmd, &flags.asOf)
 
 	return cmd
 }
 
-func runList(flags *listFlags) error {
+func runList(w io.Writer, flags *listFlags) error {
+	if err := flags.output.validate(); err != nil {
+		return err
+	}
+
 	// Load config to verify project exists
 	configRepo := config.NewYAMLRepository(flags.targetDir)
 	cfg, err := configRepo.Load()
@@ -83,10 +105,9 @@ func runList(flags *listFlags) error {
 	}
 
 	// Load all nodes
-	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
-	nodes, err := nodeRepo.LoadAll()
+	nodes, err := loadNodes(cfg, flags.targetDir, flags.asOf)
 	if err != nil {
-		return fmt.Errorf("failed to load nodes: %w", err)
+		return err
 	}
 
 	// Validate filter values
@@ -113,85 +134,32 @@ func runList(flags *listFlags) error {
 	qe := query.New()
 	filtered := qe.Filter(nodes, criteria)
 
-	// Display results
-	quiet := flags.quiet || globalConfig.Quiet
-	if len(filtered) == 0 {
-		if !quiet {
-			fmt.Println("No nodes found")
-		}
-		return nil
-	}
-
-	if quiet {
-		for _, n := range filtered {
-			fmt.Println(n.ID)
-		}
-		return nil
-	}
-
-	printNodesTable(filtered)
-	return nil
+	return printNodes(w, filtered, flags.quiet, &flags.output)
 }
 
-func printNodesTable(nodes []domain.Node) {
-	// Calculate column widths
-	maxIDLen := 2     // "ID"
-	maxKindLen := 4   // "KIND"
-	maxStatusLen := 6 // "STATUS"
-	maxTitleLen := 5  // "TITLE"
-
-	for _, node := range nodes {
-		if len(node.ID) > maxIDLen {
-			maxIDLen = len(node.ID)
-		}
-		if len(node.Kind) > maxKindLen {
-			maxKindLen = len(node.Kind)
-		}
-		if len(node.Status) > maxStatusLen {
-			maxStatusLen = len(node.Status)
+// printNodes writes node results as IDs (quiet), a table or a structured format.
+func printNodes(w io.Writer, nodes []domain.Node, quiet bool, output *outputFlags) error {
+	if quiet || globalConfig.Quiet {
+		// IDs only, still sorted and limited
+		ids := *output
+		ids.columns = []string{"id"}
+		t, err := nodeTabular(nodes, &ids)
+		if err != nil {
+			return err
 		}
-		if len(node.Title) > maxTitleLen {
-			maxTitleLen = len(node.Title)
+		for _, row := range t.rows {
+			fmt.Fprintln(w, row[0])
 		}
+		return nil
 	}
 
-	// Limit maximum title width to keep table readable
-	if maxTitleLen > 50 {
-		maxTitleLen = 50
+	t, err := nodeTabular(nodes, output)
+	if err != nil {
+		return err
 	}
-
-	// Print header with styling
-	header := fmt.Sprintf("%-*s  %-*s  %-*s  %-*s",
-		maxIDLen, "ID",
-		maxKindLen, "KIND",
-		maxStatusLen, "STATUS",
-		maxTitleLen, "TITLE")
-	fmt.Println(style.Header.Sprint(header))
-
-	// Print separator
-	separator := strings.Repeat("─", len(header))
-	fmt.Println(style.Muted.Sprint(separator))
-
-	// Print rows
-	for _, node := range nodes {
-		title := node.Title
-		if len(title) > maxTitleLen {
-			title = title[:maxTitleLen-3] + "..."
-		}
-
-		// Color the status based on its value
-		statusStr := fmt.Sprintf("%-*s", maxStatusLen, node.Status)
-		if c := style.StatusColor(node.Status); c != nil {
-			statusStr = c.Sprint(statusStr)
-		}
-
-		fmt.Printf("%-*s  %-*s  %s  %-*s\n",
-			maxIDLen, node.ID,
-			maxKindLen, style.Muted.Sprint(node.Kind),
-			statusStr,
-			maxTitleLen, title)
+	if len(t.rows) == 0 && output.format == "table" {
+		fmt.Fprintln(w, "No nodes found")
+		return nil
 	}
-
-	// Print summary
-	fmt.Printf("\n%s %d node(s)\n", style.Muted.Sprint("Total:"), len(nodes))
+	return t.render(w, output.format, "node")
 }
@@ -16,6 +16,8 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -256,6 +258,93 @@ func TestListCommand_WithRootCommand(t *testing.T) {
 	})
 }
 
+func runListCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	cmd := NewListCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func TestListCommand_OutputFormats(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupProjectWithMultipleNodes(t, tmpDir)
+
+	t.Run("csv with columns and sort", func(t *testing.T) {
+		out, err := runListCmd(t, tmpDir, "--format", "csv", "--columns", "id,status,tags.count", "--sort", "status,id:desc")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		want := "id,status,tags.count\n" +
+			"potion-001,approved,2\n" +
+			"hero-001,approved,2\n" +
+			"sword-001,draft,2\n" +
+			"quest-001,draft,2\n"
+		if out != want {
+			t.Errorf("Expected:\n%s\ngot:\n%s", want, out)
+		}
+	})
+
+	t.Run("json with default columns and limit", func(t *testing.T) {
+		out, err := runListCmd(t, tmpDir, "--format", "json", "--kind", "item", "--limit", "1")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var rows []map[string]interface{}
+		if err := json.Unmarshal([]byte(out), &rows); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, out)
+		}
+		if len(rows) != 1 || rows[0]["kind"] != "item" || rows[0]["version"] != 1.0 || rows[0]["tags"] == nil {
+			t.Errorf("Expected one item with version and tags, got %v", rows)
+		}
+	})
+
+	t.Run("jsonl emits one object per line", func(t *testing.T) {
+		out, err := runListCmd(t, tmpDir, "-f", "jsonl", "--columns", "id")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 4 || lines[0] != `{"id":"hero-001"}` {
+			t.Errorf("Expected 4 JSON lines, got:\n%s", out)
+		}
+	})
+
+	t.Run("quiet honours sort and limit", func(t *testing.T) {
+		out, err := runListCmd(t, tmpDir, "-q", "--columns", "title", "--sort", "id:desc", "--limit", "2")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if out != "sword-001\nquest-001\n" {
+			t.Errorf("Expected two IDs in descending order, got:\n%s", out)
+		}
+	})
+
+	t.Run("empty structured output", func(t *testing.T) {
+		out, err := runListCmd(t, tmpDir, "--format", "json", "--tag", "no-such-tag")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if strings.TrimSpace(out) != "[]" {
+			t.Errorf("Expected empty JSON array, got %q", out)
+		}
+	})
+
+	t.Run("rejects bad options", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"--format", "xml"},
+			{"--columns", "bogus"},
+			{"--sort", "id:sideways"},
+			{"--limit", "-1"},
+		} {
+			if _, err := runListCmd(t, append([]string{tmpDir}, args...)...); err == nil {
+				t.Errorf("%v: expected error", args)
+			}
+		}
+	})
+}
+
 // Test helper
 
 func setupProjectWithMultipleNodes(t *testing.T, dir string) {
@@ -0,0 +1,45 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/lock"
+)
+
+// lockProject takes the project lock for a command that writes to .deco/,
+// waiting up to --lock-timeout for other deco processes to finish. Call the
+// returned function to release it.
+func lockProject(dir, command string) (func(), error) {
+	l, err := lock.Acquire(filepath.Join(dir, ".deco"), lock.Options{
+		Timeout: globalConfig.LockTimeout,
+		Command: "deco " + command,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() { l.Release() }, nil
+}
+
+// historyForWrite opens the history at historyPath for a command that
+// appends to it, so its writes also wait up to --lock-timeout.
+func historyForWrite(historyPath string) *history.JSONLRepository {
+	repo := history.NewYAMLRepository(historyPath)
+	repo.SetLockTimeout(globalConfig.LockTimeout)
+	return repo
+}
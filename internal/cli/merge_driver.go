@@ -0,0 +1,264 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/merge"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/git"
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// mergeDrivers are the git merge drivers install registers, by name.
+var mergeDrivers = []struct {
+	name, kind, description string
+}{
+	{"deco-history", "history", "deco history union"},
+	{"deco-node", "node", "deco semantic node merge"},
+}
+
+// NewMergeDriverCommand creates the merge-driver command and its subcommands
+func NewMergeDriverCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge-driver",
+		Short: "Git merge drivers for history and node files",
+		Long: `Git merge drivers that merge deco files by meaning instead of by line.
+
+Subcommands:
+  history   - Union two versions of history.jsonl
+  node      - Three-way merge of a node YAML file
+  install   - Register both drivers in .gitattributes and the git config
+
+Git calls the drivers with the ancestor, our and their version of a file
+(%O %A %B) and the path in the repository (%P). The result is written
+over our version. A non-zero exit code tells git the file still has
+conflicts.`,
+	}
+
+	cmd.AddCommand(newMergeDriverHistoryCommand())
+	cmd.AddCommand(newMergeDriverNodeCommand())
+	cmd.AddCommand(newMergeDriverInstallCommand())
+
+	return cmd
+}
+
+func newMergeDriverHistoryCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history <ancestor> <ours> <theirs> [path]",
+		Short: "Merge two versions of history.jsonl",
+		Long: `Merge two versions of history.jsonl that diverged from a common ancestor.
+
+Entries are matched by content, so the same entry on both branches is kept
+once. The result holds every entry of either side, except ancestor entries
+one side packed into a segment or compacted away, sorted by timestamp. The
+hash chain is re-sealed so 'deco history verify' passes on the result.
+Closed segments under .deco/history/ are not merged.
+
+Each side's own chain is checked first. If either side has an entry that
+was edited, removed or inserted outside deco, the driver fails without
+writing, and git leaves the file as a conflict.`,
+		Args: cobra.RangeArgs(3, 4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMergeHistory(cmd.ErrOrStderr(), args[0], args[1], args[2])
+		},
+	}
+}
+
+func runMergeHistory(w io.Writer, ancestorPath, oursPath, theirsPath string) error {
+	var sides [3][]byte
+	for i, path := range []string{ancestorPath, oursPath, theirsPath} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		sides[i] = data
+	}
+	merged, result, err := history.Merge(sides[0], sides[1], sides[2])
+	if err != nil {
+		return fmt.Errorf("cannot merge history: %w", err)
+	}
+	if err := os.WriteFile(oursPath, merged, 0644); err != nil {
+		return fmt.Errorf("failed to write merged history: %w", err)
+	}
+	fmt.Fprintf(w, "deco: merged history: %d entries (%d ours, %d theirs", result.Entries, result.Ours, result.Theirs)
+	if result.Dropped > 0 {
+		fmt.Fprintf(w, ", %d dropped", result.Dropped)
+	}
+	fmt.Fprintln(w, ")")
+	return nil
+}
+
+func newMergeDriverNodeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "node <ancestor> <ours> <theirs> [path]",
+		Short: "Three-way merge of a node YAML file",
+		Long: `Three-way merge of a node YAML file.
+
+A field changed on one side only takes that side's value. Tags and other
+plain lists merge as sets, refs by target, sections by name, and blocks,
+issues and contracts by their id or name, so edits to different blocks or
+different fields of a block never conflict. The version becomes the
+higher of both sides.
+
+Values both sides changed differently are conflicts: the file is written
+with conflict markers around just those lines, and the exit code is 1.`,
+		Args: cobra.RangeArgs(3, 4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[1]
+			if len(args) > 3 {
+				path = args[3]
+			}
+			return runMergeNode(cmd.ErrOrStderr(), args[0], args[1], args[2], path)
+		},
+	}
+}
+
+func runMergeNode(w io.Writer, ancestorPath, oursPath, theirsPath, path string) error {
+	var sides [3]domain.Node
+	for i, p := range []string{ancestorPath, oursPath, theirsPath} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		// The ancestor is empty when both sides added the file
+		if err := yaml.Unmarshal(data, &sides[i]); err != nil {
+			return fmt.Errorf("cannot merge %s: %w", path, err)
+		}
+	}
+
+	result, err := merge.Nodes(sides[0], sides[1], sides[2])
+	if err != nil {
+		return fmt.Errorf("cannot merge %s: %w", path, err)
+	}
+	data, err := result.YAML()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(oursPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write merged node: %w", err)
+	}
+
+	if len(result.Conflicts) > 0 {
+		fmt.Fprintf(w, "deco: CONFLICT in %s:\n", path)
+		for _, c := range result.Conflicts {
+			fmt.Fprintf(w, "  %s\n", c.Path)
+		}
+		return NewExitErrorf(ExitCodeError, "%d conflict(s) in %s", len(result.Conflicts), path)
+	}
+	return nil
+}
+
+func newMergeDriverInstallCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install [directory]",
+		Short: "Register the merge drivers for this project",
+		Long: `Register the deco merge drivers for this project.
+
+Adds entries for the history file and the node files to .gitattributes
+in the project directory, and defines the drivers in the local git
+config (.git/config). Running it again changes nothing. Each clone needs
+its own install, since git does not share its config.
+
+Examples:
+  deco merge-driver install
+  deco merge-driver install ./my-project`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return runMergeDriverInstall(cmd.OutOrStdout(), dir)
+		},
+	}
+}
+
+func runMergeDriverInstall(w io.Writer, dir string) error {
+	configRepo := config.NewYAMLRepository(dir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+	if _, err := git.Run(dir, "rev-parse", "--git-dir"); err != nil {
+		return fmt.Errorf("%s is not in a git repository", dir)
+	}
+
+	patterns := map[string]string{}
+	for _, target := range []struct{ kind, path, suffix string }{
+		{"history", config.ResolveHistoryPath(cfg, dir), ""},
+		{"node", config.ResolveNodesPath(cfg, dir), "/**/*.yaml"},
+	} {
+		rel, err := filepath.Rel(dir, target.path)
+		if err != nil {
+			return err
+		}
+		patterns[target.kind] = filepath.ToSlash(rel) + target.suffix
+	}
+
+	attrPath := filepath.Join(dir, ".gitattributes")
+	existing, err := os.ReadFile(attrPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .gitattributes: %w", err)
+	}
+	lines := strings.Split(string(existing), "\n")
+	var added []string
+	for _, d := range mergeDrivers {
+		line := patterns[d.kind] + " merge=" + d.name
+		if !containsString(lines, line) {
+			added = append(added, line)
+		}
+	}
+	if len(added) > 0 {
+		content := string(existing)
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += strings.Join(added, "\n") + "\n"
+		if err := os.WriteFile(attrPath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write .gitattributes: %w", err)
+		}
+	}
+
+	for _, d := range mergeDrivers {
+		for key, value := range map[string]string{
+			"name":   d.description,
+			"driver": "deco merge-driver " + d.kind + " %O %A %B %P",
+		} {
+			if _, err := git.Run(dir, "config", "--local", "merge."+d.name+"."+key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, line := range added {
+		fmt.Fprintf(w, "%s Added to .gitattributes: %s\n", style.SuccessIcon(), line)
+	}
+	if len(added) == 0 {
+		fmt.Fprintf(w, "%s .gitattributes already lists the merge drivers\n", style.SuccessIcon())
+	}
+	fmt.Fprintf(w, "%s Registered %s and %s in the git config\n", style.SuccessIcon(), mergeDrivers[0].name, mergeDrivers[1].name)
+	return nil
+}
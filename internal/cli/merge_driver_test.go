@@ -0,0 +1,184 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/history"
+)
+
+func writeMergeSides(t *testing.T, ancestor, ours, theirs string) (string, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"base": ancestor, "ours": ours, "theirs": theirs} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "base"), filepath.Join(dir, "ours"), filepath.Join(dir, "theirs")
+}
+
+const mergeBaseNode = `id: systems/combat
+kind: system
+version: 1
+status: draft
+title: Combat
+tags:
+    - core
+summary: Players fight
+`
+
+func TestMergeDriverNode(t *testing.T) {
+	t.Run("clean", func(t *testing.T) {
+		base, ours, theirs := writeMergeSides(t, mergeBaseNode,
+			strings.Replace(mergeBaseNode, "title: Combat", "title: Melee combat", 1),
+			strings.Replace(mergeBaseNode, "    - core\n", "    - core\n    - pvp\n", 1))
+		var stderr bytes.Buffer
+		if err := runMergeNode(&stderr, base, ours, theirs, ".deco/nodes/systems/combat.yaml"); err != nil {
+			t.Fatalf("expected a clean merge: %v\n%s", err, stderr.String())
+		}
+		data, err := os.ReadFile(ours)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "title: Melee combat\n") || !strings.Contains(string(data), "    - pvp\n") {
+			t.Errorf("expected both changes:\n%s", data)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		base, ours, theirs := writeMergeSides(t, mergeBaseNode,
+			strings.Replace(mergeBaseNode, "Players fight", "Players duel", 1),
+			strings.Replace(mergeBaseNode, "Players fight", "Players brawl", 1))
+		var stderr bytes.Buffer
+		err := runMergeNode(&stderr, base, ours, theirs, ".deco/nodes/systems/combat.yaml")
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != ExitCodeError {
+			t.Fatalf("expected exit code 1, got %v", err)
+		}
+		if !strings.Contains(stderr.String(), "CONFLICT in .deco/nodes/systems/combat.yaml:\n  summary\n") {
+			t.Errorf("unexpected report:\n%s", stderr.String())
+		}
+		data, _ := os.ReadFile(ours)
+		want := "<<<<<<< ours\nsummary: Players duel\n=======\nsummary: Players brawl\n>>>>>>> theirs\n"
+		if !strings.HasSuffix(string(data), want) || strings.Count(string(data), "<<<<<<<") != 1 {
+			t.Errorf("expected markers around the summary only:\n%s", data)
+		}
+	})
+
+	t.Run("unparseable side", func(t *testing.T) {
+		base, ours, theirs := writeMergeSides(t, mergeBaseNode, "<<<<<<< ours\n: [", mergeBaseNode)
+		if err := runMergeNode(&bytes.Buffer{}, base, ours, theirs, "combat.yaml"); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestMergeDriverHistory(t *testing.T) {
+	entry := func(day, user string) string {
+		return `{"timestamp":"2026-03-` + day + `T12:00:00Z","node_id":"systems/combat","operation":"sync","user":"` + user + `"}` + "\n"
+	}
+	base, ours, theirs := writeMergeSides(t, entry("01", "alice"), entry("01", "alice")+entry("03", "alice"), entry("01", "alice")+entry("02", "bob"))
+
+	var stderr bytes.Buffer
+	if err := runMergeHistory(&stderr, base, ours, theirs); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr.String(), "3 entries (1 ours, 1 theirs)") {
+		t.Errorf("unexpected summary: %s", stderr.String())
+	}
+	data, _ := os.ReadFile(ours)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], `"user":"bob"`) || !strings.Contains(lines[2], `"entry_hash"`) {
+		t.Errorf("expected three sorted, sealed entries:\n%s", data)
+	}
+}
+
+func TestMergeDriverHistory_TamperedSide(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.jsonl")
+	repo := history.NewYAMLRepository(path)
+	for _, user := range []string{"alice", "bob"} {
+		if err := repo.Append(domain.AuditEntry{Timestamp: time.Now(), NodeID: "systems/combat", Operation: "sync", User: user}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"user":"bob"`, `"user":"mallory"`, 1)
+	base, ours, theirs := writeMergeSides(t, string(data), string(data), tampered)
+
+	var stderr bytes.Buffer
+	err = runMergeHistory(&stderr, base, ours, theirs)
+	if err == nil || !strings.Contains(err.Error(), "theirs: history hash chain is broken") {
+		t.Fatalf("expected the driver to fail on a tampered side, got %v", err)
+	}
+	if got, _ := os.ReadFile(ours); string(got) != string(data) {
+		t.Error("expected our file to be left for git to report as a conflict")
+	}
+}
+
+func TestMergeDriverInstall(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := setupDecoProject(t)
+
+	if err := runMergeDriverInstall(&bytes.Buffer{}, dir); err == nil || !strings.Contains(err.Error(), "not in a git repository") {
+		t.Errorf("expected an error outside git, got %v", err)
+	}
+
+	runGit(t, dir, "init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("*.png binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		var out bytes.Buffer
+		if err := runMergeDriverInstall(&out, dir); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 && !strings.Contains(out.String(), "already lists") {
+			t.Errorf("expected nothing added the second time:\n%s", out.String())
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".gitattributes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "*.png binary\n.deco/history.jsonl merge=deco-history\n.deco/nodes/**/*.yaml merge=deco-node\n"
+	if string(data) != want {
+		t.Errorf(".gitattributes = %q, want %q", data, want)
+	}
+	if got := runGit(t, dir, "config", "merge.deco-node.driver"); got != "deco merge-driver node %O %A %B %P" {
+		t.Errorf("node driver = %q", got)
+	}
+	if got := runGit(t, dir, "check-attr", "merge", ".deco/nodes/systems/combat.yaml"); !strings.HasSuffix(got, "merge: deco-node") {
+		t.Errorf("check-attr = %q", got)
+	}
+}
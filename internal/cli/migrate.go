@@ -81,6 +81,14 @@ func runMigrate(flags *migrateFlags) error {
 		return nil
 	}
 
+	if !flags.dryRun {
+		unlock, err := lockProject(flags.targetDir, "migrate")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
 	// Show what will happen
 	if !flags.quiet {
 		if flags.dryRun {
@@ -91,10 +99,11 @@ func runMigrate(flags *migrateFlags) error {
 
 	// Execute migration
 	executor := migrations.NewExecutor(migrations.ExecutorOptions{
-		DryRun:    flags.dryRun,
-		NoBackup:  flags.noBackup,
-		Quiet:     flags.quiet,
-		TargetDir: flags.targetDir,
+		DryRun:      flags.dryRun,
+		NoBackup:    flags.noBackup,
+		Quiet:       flags.quiet,
+		TargetDir:   flags.targetDir,
+		LockTimeout: globalConfig.LockTimeout,
 	}, nil)
 
 	result, err := executor.Execute()
@@ -22,7 +22,6 @@ import (
 
 	"github.com/Toernblom/deco/internal/domain"
 	"github.com/Toernblom/deco/internal/storage/config"
-	"github.com/Toernblom/deco/internal/storage/history"
 	"github.com/Toernblom/deco/internal/storage/node"
 	"github.com/spf13/cobra"
 )
@@ -82,6 +81,11 @@ func runNew(nodeID string, flags *newFlags) error {
 	if err != nil {
 		return fmt.Errorf(".deco directory not found or invalid: %w", err)
 	}
+	unlock, err := lockProject(flags.targetDir, "new")
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// Check if node already exists
 	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
@@ -130,12 +134,16 @@ func runNew(nodeID string, flags *newFlags) error {
 
 	// Log creation to history
 	historyPath := config.ResolveHistoryPath(cfg, flags.targetDir)
-	historyRepo := history.NewYAMLRepository(historyPath)
+	historyRepo := historyForWrite(historyPath)
+	snapshot, err := snapshotNode(historyPath, n)
+	if err != nil && !flags.quiet {
+		fmt.Printf("Warning: failed to snapshot node: %v\n", err)
+	}
 	entry := domain.AuditEntry{
 		Timestamp:   time.Now(),
 		NodeID:      nodeID,
 		Operation:   "create",
-		User:        GetCurrentUser(),
+		User:        GetCurrentUser(flags.targetDir),
 		ContentHash: ComputeContentHash(n),
 		After: map[string]interface{}{
 			"kind":    n.Kind,
@@ -143,6 +151,7 @@ func runNew(nodeID string, flags *newFlags) error {
 			"version": n.Version,
 			"status":  n.Status,
 		},
+		Snapshot: snapshot,
 	}
 	if err := historyRepo.Append(entry); err != nil {
 		if !flags.quiet {
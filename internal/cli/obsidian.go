@@ -59,6 +59,11 @@ func runObsidianExport(flags *exportFlags) error {
 		qe := query.New()
 		nodes = qe.Filter(allNodes, criteria)
 	}
+	if flags.query != "" {
+		if nodes, err = filterBySavedQuery(nodes, allNodes, cfg, flags); err != nil {
+			return err
+		}
+	}
 
 	if len(nodes) == 0 {
 		fmt.Println("No nodes found.")
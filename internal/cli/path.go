@@ -0,0 +1,223 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/graph"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/spf13/cobra"
+)
+
+type pathFlags struct {
+	all       bool
+	maxHops   int
+	refTypes  []string
+	format    string
+	targetDir string
+}
+
+// NewPathCommand creates the path subcommand
+func NewPathCommand() *cobra.Command {
+	flags := &pathFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "path <from> <to> [directory]",
+		Short: "Find dependency paths between two nodes",
+		Long: `Find how one node depends on another by following references.
+
+Edges are built from refs.uses, refs.related, @node refs in contract
+steps, and block cross-references declared by ref constraints on custom
+block types. Each hop is printed with the context of the reference.
+
+By default only the shortest path is shown. Use --all to list every simple
+path up to --max-hops hops.
+
+Formats:
+  text     Hop-by-hop listing (default)
+  dot      Graphviz DOT of just the path subgraph
+  mermaid  Mermaid flowchart of just the path subgraph
+
+Examples:
+  deco path ui/checkout billing/db
+  deco path ui/checkout billing/db --all --max-hops 4
+  deco path ui/checkout billing/db --ref-type uses,contract
+  deco path ui/checkout billing/db --format dot | dot -Tpng -o path.png`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 2 {
+				flags.targetDir = args[2]
+			} else {
+				flags.targetDir = "."
+			}
+			return runPath(cmd.OutOrStdout(), args[0], args[1], flags)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flags.all, "all", "a", false, "Show all simple paths instead of only the shortest")
+	cmd.Flags().IntVar(&flags.maxHops, "max-hops", 6, "Maximum path length in hops when using --all")
+	cmd.Flags().StringSliceVar(&flags.refTypes, "ref-type", nil, "Reference types to follow: uses, related, contract, crossref (default: all)")
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "text", "Output format (text, dot, mermaid)")
+
+	return cmd
+}
+
+func runPath(w io.Writer, from, to string, flags *pathFlags) error {
+	allowed := make(map[string]bool)
+	for _, t := range flags.refTypes {
+		switch t {
+		case graph.EdgeUses, graph.EdgeRelated, graph.EdgeContract, graph.EdgeCrossRef:
+			allowed[t] = true
+		default:
+			return fmt.Errorf("unknown ref type: %s (supported: uses, related, contract, crossref)", t)
+		}
+	}
+	switch flags.format {
+	case "text", "dot", "mermaid":
+	default:
+		return fmt.Errorf("unknown format: %s (supported: text, dot, mermaid)", flags.format)
+	}
+	if flags.all && flags.maxHops <= 0 {
+		return fmt.Errorf("--max-hops must be positive")
+	}
+
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
+	nodes, err := nodeRepo.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load nodes: %w", err)
+	}
+
+	builder := graph.NewBuilder()
+	g, err := builder.Build(nodes)
+	if err != nil {
+		return fmt.Errorf("failed to build graph: %w", err)
+	}
+	for _, id := range []string{from, to} {
+		if _, ok := g.Get(id); !ok {
+			return fmt.Errorf("node '%s' not found", id)
+		}
+	}
+	if from == to {
+		return fmt.Errorf("from and to are the same node")
+	}
+
+	var edges []graph.Edge
+	for _, e := range builder.BuildEdges(g, cfg.CustomBlockTypes) {
+		if len(allowed) == 0 || allowed[e.RefType] {
+			edges = append(edges, e)
+		}
+	}
+
+	var paths [][]graph.Edge
+	if flags.all {
+		paths = builder.AllPaths(edges, from, to, flags.maxHops)
+	} else if p := builder.ShortestPath(edges, from, to); p != nil {
+		paths = [][]graph.Edge{p}
+	}
+
+	if len(paths) == 0 {
+		if flags.all {
+			fmt.Fprintf(w, "No path from %s to %s within %d hops\n", from, to, flags.maxHops)
+		} else {
+			fmt.Fprintf(w, "No path from %s to %s\n", from, to)
+		}
+		return nil
+	}
+
+	switch flags.format {
+	case "dot", "mermaid":
+		subNodes, subEdges := pathSubgraph(g, paths)
+		if flags.format == "dot" {
+			outputDOT(w, subNodes, subEdges)
+		} else {
+			outputMermaid(w, subNodes, subEdges)
+		}
+	default:
+		printPaths(w, from, to, paths, flags.all)
+	}
+
+	return nil
+}
+
+// pathSubgraph returns the nodes and de-duplicated edges touched by the paths.
+func pathSubgraph(g *domain.Graph, paths [][]graph.Edge) ([]domain.Node, []edge) {
+	nodeSeen := make(map[string]bool)
+	edgeSeen := make(map[edge]bool)
+	var ids []string
+	var edges []edge
+
+	addNode := func(id string) {
+		if !nodeSeen[id] {
+			nodeSeen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, path := range paths {
+		for _, e := range path {
+			addNode(e.From)
+			addNode(e.To)
+			pe := edge{from: e.From, to: e.To, refType: e.RefType}
+			if !edgeSeen[pe] {
+				edgeSeen[pe] = true
+				edges = append(edges, pe)
+			}
+		}
+	}
+
+	sort.Strings(ids)
+	nodes := make([]domain.Node, 0, len(ids))
+	for _, id := range ids {
+		n, _ := g.Get(id)
+		nodes = append(nodes, n)
+	}
+	return nodes, edges
+}
+
+func printPaths(w io.Writer, from, to string, paths [][]graph.Edge, all bool) {
+	if all {
+		fmt.Fprintf(w, "%s %d path(s) from %s to %s\n", style.Header.Sprint("Found"), len(paths), from, to)
+	} else {
+		fmt.Fprintf(w, "%s %s → %s (%d hop(s))\n", style.Header.Sprint("Shortest path:"), from, to, len(paths[0]))
+	}
+
+	for i, path := range paths {
+		fmt.Fprintln(w)
+		if all {
+			fmt.Fprintf(w, "%s\n", style.Header.Sprintf("Path %d (%d hop(s)):", i+1, len(path)))
+		}
+		fmt.Fprintf(w, "  %s\n", path[0].From)
+		for _, e := range path {
+			line := fmt.Sprintf("    %s %s %s", style.Muted.Sprint("→"), e.To, style.Info.Sprintf("[%s]", e.RefType))
+			if e.Context != "" {
+				line += " " + style.Muted.Sprint(e.Context)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+}
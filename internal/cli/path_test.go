@@ -0,0 +1,119 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// setupPathProject creates a -> b -> c and a -> c (uses refs)
+func setupPathProject(t *testing.T) string {
+	t.Helper()
+	dir := setupDecoProject(t)
+	createTestNode(t, dir, "c")
+	createTestNodeWithRefs(t, dir, "b", []string{"c"})
+	createTestNodeWithRefs(t, dir, "a", []string{"b", "c"})
+	return dir
+}
+
+func runPathCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	cmd := NewPathCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func TestPathCommand_Shortest(t *testing.T) {
+	dir := setupPathProject(t)
+
+	out, err := runPathCmd(t, "a", "c", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "(1 hop(s))") || !strings.Contains(out, "→ c [uses]") {
+		t.Errorf("Expected direct one-hop path, got:\n%s", out)
+	}
+}
+
+func TestPathCommand_All(t *testing.T) {
+	dir := setupPathProject(t)
+
+	out, err := runPathCmd(t, "a", "c", dir, "--all")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "Found 2 path(s)") || !strings.Contains(out, "Path 2 (2 hop(s)):") {
+		t.Errorf("Expected both paths, got:\n%s", out)
+	}
+}
+
+func TestPathCommand_NoPath(t *testing.T) {
+	dir := setupPathProject(t)
+
+	out, err := runPathCmd(t, "c", "a", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "No path from c to a") {
+		t.Errorf("Expected no-path message, got:\n%s", out)
+	}
+}
+
+func TestPathCommand_SubgraphFormats(t *testing.T) {
+	dir := setupPathProject(t)
+
+	out, err := runPathCmd(t, "a", "c", dir, "--format", "dot")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, `"a" -> "c";`) || strings.Contains(out, `"b"`) {
+		t.Errorf("Expected only the shortest-path subgraph, got:\n%s", out)
+	}
+
+	out, err = runPathCmd(t, "a", "c", dir, "--all", "--format", "mermaid")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "a --> b") || !strings.Contains(out, "b --> c") {
+		t.Errorf("Expected all-paths subgraph, got:\n%s", out)
+	}
+}
+
+func TestPathCommand_Errors(t *testing.T) {
+	dir := setupPathProject(t)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing node", []string{"a", "nope", dir}},
+		{"same node", []string{"a", "a", dir}},
+		{"bad ref type", []string{"a", "c", dir, "--ref-type", "owns"}},
+		{"bad format", []string{"a", "c", dir, "--format", "svg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runPathCmd(t, tt.args...); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
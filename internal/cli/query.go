@@ -17,11 +17,13 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"sort"
 	"strings"
 
+	"github.com/Toernblom/deco/internal/domain"
 	"github.com/Toernblom/deco/internal/services/query"
 	"github.com/Toernblom/deco/internal/storage/config"
-	"github.com/Toernblom/deco/internal/storage/node"
 	"github.com/spf13/cobra"
 )
 
@@ -34,7 +36,26 @@ type queryFlags struct {
 	searchTerm string
 	blockType  string
 	fields     []string // key=value pairs
-	follow     string   // field name, or field:blocktype.field
+	follow     string   // hops separated by ">", each field or field:blocktype.field
+	maxDepth   int      // hop limit along a follow chain
+	where      string   // CEL expression over each node
+	blockWhere string   // CEL expression over each block
+	groupBy    []string // block fields to group by
+	aggs       []string // aggregates such as count or sum:cost
+	output     outputFlags
+	saved      string            // saved query name, from "@name"
+	params     []string          // name=value pairs for the saved query
+	paramMap   map[string]string // resolved saved query parameters, the params of expressions
+	listSaved  bool
+	asOf       string
+
+	// changed reports whether a flag was given on the command line.
+	changed func(name string) bool
+}
+
+// isSet reports whether the named flag was given on the command line.
+func (f *queryFlags) isSet(name string) bool {
+	return f.changed != nil && f.changed(name)
 }
 
 // NewQueryCommand creates the query subcommand
@@ -53,9 +74,57 @@ Filters can be combined with search to narrow down results:
   --tag:        Filter by tag (must have this tag)
   --block-type: Filter by custom block type within content
   --field:      Filter by block field value (key=value, repeatable)
-  --follow:     Follow a field's refs to find related blocks
-
-All filters and search are combined with AND logic.
+  --follow:     Follow a field's refs to find related blocks (chain with >)
+  --where:      CEL expression evaluated per node
+  --block-where: CEL expression evaluated per block
+  --group-by:   Group block results by fields
+  --agg:        Aggregate each group (count, sum, avg, min, max)
+
+All filters and search are combined with AND logic. The search term only
+matches titles and summaries; use 'deco search' for ranked full-text search
+across all content.
+
+Expressions use CEL, the language of node constraints, and must evaluate
+to a boolean. --where sees the same variables as constraints: id, kind,
+version, status, title, summary, tags, custom, self (the full node,
+including self.content and self.refs), refs (all nodes by ID) and allNodes.
+--block-where sees block (its type and fields), section (section name) and
+self (the owning node). A node or block for which the expression fails,
+for example because a field is missing, does not match.
+
+Output is shaped with --format (table, json, jsonl, csv, yaml), --columns,
+--sort (e.g. version:desc) and --limit, using the same node columns as
+'deco list'. Block results get one column per block field after node,
+section, index and type; --columns may name block fields (dotted for
+nested values) and node.<column> for the owning node, e.g. node.status.
+
+--follow can chain several hops with '>': each hop is a field, or
+field:blocktype.field to name its target explicitly. Hops without a target
+use the ref constraints of the block type reached so far. A '*' after the
+last hop repeats it until values run out, for example to walk recipe
+trees. Chains are reported as join paths from each source block; a path
+stops early when a value is unresolved, a block has no value for the next
+hop, a block would be visited twice (cycle), or --max-depth hops were
+taken.
+
+Block results can be summarized with --group-by and --agg instead of
+listed. --group-by takes block fields (dotted for nested values) or node,
+section and type; a list field puts a block in one group per element.
+--agg is repeatable: count, count:field (blocks with the field), and
+sum, avg, min or max with a field. Fields declared as number in the block
+type also accept numeric strings; fields declared with another type
+cannot be summed. With --follow, each followed value becomes a group,
+with refs (referencing blocks) and aggregates over the blocks it resolves
+to. Groups render with --format like other results.
+
+Saved queries are defined under queries: in .deco/config.yaml and run
+as 'deco query @name'. Values may contain ${param} placeholders, filled
+in from the query's params defaults or with --param name=value; where
+and block_where read them as params.name instead, so a value is always
+a string. Flags given on the command line override the saved values.
+List them with --list-saved.
+
+` + asOfHelp + `
 
 Examples:
   deco query sword                              # Search for "sword" in title/summary
@@ -65,9 +134,39 @@ Examples:
   deco query --block-type building              # List all building blocks
   deco query --block-type building --field age=bronze  # Bronze age buildings
   deco query --block-type building --field age=bronze --follow materials  # Follow refs
-  deco query --block-type building --follow materials:recipe.output      # Explicit target`,
+  deco query --block-type building --follow materials:recipe.output      # Explicit target
+  deco query --block-type building --follow 'materials:recipe.output>inputs:resource.name'
+  deco query --block-type building --follow 'materials>inputs*' --max-depth 5
+  deco query --where 'kind == "item" || "combat" in tags'
+  deco query --where '!(status in ["approved", "deprecated"])'
+  deco query --where 'self.custom.cost > 100'
+  deco query --where 'self.content.sections.exists(s, s.blocks.exists(b, b.type == "rule"))'
+  deco query --block-where 'block.type == "building" && block.cost >= 50'
+  deco query --block-where '"Iron" in block.materials' --kind item
+  deco query --kind item --format json --columns id,title,custom.cost --sort custom.cost:desc
+  deco query --block-type building --format csv > buildings.csv
+  deco query --block-type recipe --columns node,output,materials,node.status --format jsonl
+  deco query --block-type building --group-by age --agg count --agg sum:cost
+  deco query --block-type endpoint --group-by method --format csv
+  deco query --block-type building --follow materials --agg max:tier
+  deco query --list-saved
+  deco query @bronze-buildings --param age=iron
+  deco query @bronze-buildings --format csv
+  deco query --kind system --status approved --as-of milestone-2`,
 		Args: cobra.MaximumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.changed = cmd.Flags().Changed
+
+			// A leading @name runs a saved query: @name [directory]
+			if len(args) > 0 && strings.HasPrefix(args[0], "@") {
+				flags.saved = strings.TrimPrefix(args[0], "@")
+				flags.targetDir = "."
+				if len(args) == 2 {
+					flags.targetDir = args[1]
+				}
+				return runQuery(cmd.OutOrStdout(), flags)
+			}
+
 			// Parse arguments: [search-term] [directory]
 			switch len(args) {
 			case 0:
@@ -85,7 +184,7 @@ Examples:
 				flags.searchTerm = args[0]
 				flags.targetDir = args[1]
 			}
-			return runQuery(flags)
+			return runQuery(cmd.OutOrStdout(), flags)
 		},
 	}
 
@@ -95,7 +194,16 @@ Examples:
 	cmd.Flags().BoolVarP(&flags.quiet, "quiet", "q", false, "Output node IDs only, one per line")
 	cmd.Flags().StringVarP(&flags.blockType, "block-type", "b", "", "Filter by block type within content")
 	cmd.Flags().StringArrayVarP(&flags.fields, "field", "f", nil, "Filter by block field (key=value, repeatable)")
-	cmd.Flags().StringVar(&flags.follow, "follow", "", "Follow field refs to related blocks (field or field:blocktype.field)")
+	cmd.Flags().StringVar(&flags.follow, "follow", "", "Follow field refs to related blocks (field or field:blocktype.field, chained with >)")
+	cmd.Flags().IntVar(&flags.maxDepth, "max-depth", query.DefaultMaxJoinDepth, "Maximum hops along a --follow chain")
+	cmd.Flags().StringVar(&flags.where, "where", "", "Only nodes for which this CEL expression is true")
+	cmd.Flags().StringVar(&flags.blockWhere, "block-where", "", "Only blocks for which this CEL expression is true")
+	cmd.Flags().StringSliceVar(&flags.groupBy, "group-by", nil, "Group block results by fields, comma-separated (e.g. age or node)")
+	cmd.Flags().StringArrayVar(&flags.aggs, "agg", nil, "Aggregate per group: count, count:f, sum:f, avg:f, min:f, max:f (repeatable)")
+	cmd.Flags().StringArrayVar(&flags.params, "param", nil, "Saved query parameter (name=value, repeatable)")
+	cmd.Flags().BoolVar(&flags.listSaved, "list-saved", false, "List the saved queries defined in config")
+	addOutputFlags(cmd, &flags.output, "")
+	addAsOfFlag(cmd, &flags.asOf)
 
 	return cmd
 }
@@ -119,7 +227,7 @@ func isDirectory(s string) bool {
 	return false
 }
 
-func runQuery(flags *queryFlags) error {
+func runQuery(w io.Writer, flags *queryFlags) error {
 	// Load config to verify project exists
 	configRepo := config.NewYAMLRepository(flags.targetDir)
 	cfg, err := configRepo.Load()
@@ -127,26 +235,165 @@ func runQuery(flags *queryFlags) error {
 		return fmt.Errorf(".deco directory not found or invalid: %w", err)
 	}
 
+	if flags.listSaved {
+		printSavedQueries(w, cfg)
+		return nil
+	}
+	if flags.saved != "" {
+		if err := applySavedQuery(flags, cfg); err != nil {
+			return err
+		}
+	} else if len(flags.params) > 0 {
+		return fmt.Errorf("--param requires a saved query (deco query @name)")
+	}
+
+	if err := flags.output.validate(); err != nil {
+		return err
+	}
+
 	// Load all nodes
-	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
-	nodes, err := nodeRepo.LoadAll()
+	nodes, err := loadNodes(cfg, flags.targetDir, flags.asOf)
+	if err != nil {
+		return err
+	}
+
+	// Validate --follow requires --block-type
+	if flags.follow != "" && flags.blockType == "" {
+		return fmt.Errorf("--follow requires --block-type")
+	}
+	var hops []query.FollowHop
+	if flags.follow != "" {
+		if hops, err = parseFollowChain(flags.follow); err != nil {
+			return err
+		}
+	}
+	chain := len(hops) > 1 || (len(hops) == 1 && hops[0].Repeat)
+	aggregating := len(flags.groupBy) > 0 || len(flags.aggs) > 0
+	if flags.follow != "" && !chain && flags.output.customized() && !aggregating {
+		return fmt.Errorf("--follow does not support --format, --columns, --sort or --limit with a single hop and no --agg")
+	}
+	if flags.maxDepth < 1 {
+		return fmt.Errorf("--max-depth must be at least 1")
+	}
+	if flags.follow != "" && len(flags.groupBy) > 0 {
+		return fmt.Errorf("--group-by cannot be combined with --follow: followed results are grouped by referenced value")
+	}
+	aggs, err := parseAggregates(flags.aggs)
+	if err != nil {
+		return err
+	}
+	if flags.follow == "" {
+		if err := validateAggregateFields(flags.blockType, flags.groupBy, aggs, cfg); err != nil {
+			return err
+		}
+	}
+
+	qe := query.New()
+	sel, err := selectQuery(qe, flags, cfg, nodes)
+	if err != nil {
+		return err
+	}
+
+	if !sel.blockMode {
+		if aggregating {
+			return fmt.Errorf("--group-by and --agg summarize blocks; add --block-type or --block-where")
+		}
+		return printNodes(w, sel.nodes, flags.quiet, &flags.output)
+	}
+	blockResults := sel.blocks
+
+	if aggregating && flags.follow == "" {
+		groups, err := qe.AggregateBlocks(blockResults, flags.groupBy, aggs, cfg.CustomBlockTypes)
+		if err != nil {
+			return err
+		}
+		t, err := groupTabular(flags.groupBy, groups, aggs, &flags.output)
+		if err != nil {
+			return err
+		}
+		return t.render(w, flags.output.format, "group")
+	}
+
+	// Follow mode
+	if flags.follow != "" {
+		if len(blockResults) == 0 {
+			fmt.Fprintln(w, "No blocks found to follow")
+			return nil
+		}
+		if chain {
+			return printFollowChain(w, qe, blockResults, hops, nodes, cfg, flags, aggregating)
+		}
+		followResults, err := qe.FollowBlocks(blockResults, hops[0].Field, hops[0].Targets, nodes, cfg.CustomBlockTypes)
+		if err != nil {
+			return err
+		}
+		if len(followResults) == 0 {
+			fmt.Fprintln(w, "No followed values found")
+			return nil
+		}
+		if aggregating {
+			return printFollowGroups(w, qe, followResults, aggs, cfg, &flags.output)
+		}
+		printFollowResults(w, followResults)
+		return nil
+	}
+
+	if len(blockResults) == 0 && flags.output.format == "table" {
+		fmt.Fprintln(w, "No blocks found")
+		return nil
+	}
+	if !flags.output.customized() {
+		printBlocksTable(w, blockResults)
+		return nil
+	}
+	t, err := blockTabular(blockResults, nodes, &flags.output)
 	if err != nil {
-		return fmt.Errorf("failed to load nodes: %w", err)
+		return err
 	}
+	return t.render(w, flags.output.format, "block")
+}
+
+// querySelection is what a query matched: nodes, or blocks in block mode.
+type querySelection struct {
+	nodes     []domain.Node
+	blocks    []query.BlockMatch
+	blockMode bool
+}
+
+// nodeIDs returns the IDs of the selected nodes. In block mode these are
+// the nodes containing the selected blocks.
+func (s querySelection) nodeIDs() map[string]bool {
+	ids := make(map[string]bool)
+	if s.blockMode {
+		for _, b := range s.blocks {
+			ids[b.NodeID] = true
+		}
+		return ids
+	}
+	for _, n := range s.nodes {
+		ids[n.ID] = true
+	}
+	return ids
+}
+
+// selectQuery validates the filter flags and applies them to nodes.
+// Following refs and shaping the output are left to the caller.
+func selectQuery(qe *query.QueryEngine, flags *queryFlags, cfg config.Config, nodes []domain.Node) (querySelection, error) {
+	var sel querySelection
 
 	// Validate filter values
 	if err := validateStatus(flags.status); err != nil {
-		return err
+		return sel, err
 	}
 	if err := validateKind(flags.kind, nodes); err != nil {
-		return err
+		return sel, err
 	}
 	if err := validateBlockType(flags.blockType, cfg.CustomBlockTypes); err != nil {
-		return err
+		return sel, err
 	}
 	for _, f := range flags.fields {
 		if err := validateFieldFilter(f); err != nil {
-			return err
+			return sel, err
 		}
 	}
 
@@ -168,71 +415,51 @@ func runQuery(flags *queryFlags) error {
 		criteria.FieldFilters = parseFieldFilters(flags.fields)
 	}
 
-	qe := query.New()
-
-	// Validate --follow requires --block-type
-	if flags.follow != "" && criteria.BlockType == nil {
-		return fmt.Errorf("--follow requires --block-type")
+	// Compile expressions before doing any work
+	var whereExpr, blockWhereExpr *query.Expr
+	var err error
+	if flags.where != "" {
+		if whereExpr, err = qe.CompileWhere(flags.where); err != nil {
+			return sel, fmt.Errorf("--where: %w", err)
+		}
+		whereExpr = whereExpr.WithParams(flags.paramMap)
+	}
+	if flags.blockWhere != "" {
+		if blockWhereExpr, err = qe.CompileBlockWhere(flags.blockWhere); err != nil {
+			return sel, fmt.Errorf("--block-where: %w", err)
+		}
+		blockWhereExpr = blockWhereExpr.WithParams(flags.paramMap)
 	}
 
 	// Block-level query mode
-	if criteria.BlockType != nil {
-		blockResults := qe.FilterBlocks(nodes, criteria)
-
-		// Follow mode
-		if flags.follow != "" {
-			if len(blockResults) == 0 {
-				fmt.Println("No blocks found to follow")
-				return nil
-			}
-			followField, targets, err := parseFollowFlag(flags.follow)
-			if err != nil {
-				return err
+	if criteria.BlockType != nil || blockWhereExpr != nil {
+		sel.blockMode = true
+		searched := nodes
+		if whereExpr != nil {
+			if searched, err = qe.Where(nodes, nodes, whereExpr); err != nil {
+				return sel, err
 			}
-			followResults, err := qe.FollowBlocks(blockResults, followField, targets, nodes, cfg.CustomBlockTypes)
-			if err != nil {
-				return err
-			}
-			if len(followResults) == 0 {
-				fmt.Println("No followed values found")
-				return nil
-			}
-			printFollowResults(followResults)
-			return nil
 		}
-
-		if len(blockResults) == 0 {
-			fmt.Println("No blocks found")
-			return nil
+		sel.blocks = qe.FilterBlocks(searched, criteria)
+		if blockWhereExpr != nil {
+			if sel.blocks, err = qe.WhereBlocks(sel.blocks, nodes, blockWhereExpr); err != nil {
+				return sel, err
+			}
 		}
-		printBlocksTable(blockResults)
-		return nil
+		return sel, nil
 	}
 
 	// Node-level query mode
-	results := qe.Filter(nodes, criteria)
+	sel.nodes = qe.Filter(nodes, criteria)
 	if flags.searchTerm != "" {
-		results = qe.Search(results, flags.searchTerm)
+		sel.nodes = qe.Search(sel.nodes, flags.searchTerm)
 	}
-
-	// Display results
-	quiet := flags.quiet || globalConfig.Quiet
-	if len(results) == 0 {
-		if !quiet {
-			fmt.Println("No nodes found")
+	if whereExpr != nil {
+		if sel.nodes, err = qe.Where(sel.nodes, nodes, whereExpr); err != nil {
+			return sel, err
 		}
-		return nil
 	}
-
-	if quiet {
-		for _, n := range results {
-			fmt.Println(n.ID)
-		}
-		return nil
-	}
-
-	printNodesTable(results)
-	return nil
+	return sel, nil
 }
 
 // parseFieldFilters parses key=value pairs into a map.
@@ -288,37 +515,306 @@ func parseFollowFlag(follow string) (string, []query.FollowTarget, error) {
 	return fieldName, []query.FollowTarget{target}, nil
 }
 
+// parseFollowChain parses --follow hops separated by ">". A "*" after the
+// last hop makes it repeat.
+func parseFollowChain(follow string) ([]query.FollowHop, error) {
+	parts := strings.Split(follow, ">")
+	hops := make([]query.FollowHop, 0, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		hop := query.FollowHop{}
+		if strings.HasSuffix(part, "*") {
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("invalid --follow hop %q: only the last hop can repeat", part)
+			}
+			hop.Repeat = true
+			part = strings.TrimSuffix(part, "*")
+		}
+		field, targets, err := parseFollowFlag(part)
+		if err != nil {
+			return nil, err
+		}
+		if field == "" {
+			return nil, fmt.Errorf("invalid --follow %q: empty hop", follow)
+		}
+		hop.Field = field
+		hop.Targets = targets
+		hops = append(hops, hop)
+	}
+	return hops, nil
+}
+
+// printFollowGroups aggregates followed blocks per referenced value.
+func printFollowGroups(w io.Writer, qe *query.QueryEngine, results []query.FollowResult, aggs []query.Aggregate, cfg config.Config, output *outputFlags) error {
+	groups, err := qe.AggregateFollow(results, aggs, cfg.CustomBlockTypes)
+	if err != nil {
+		return err
+	}
+	for i := range groups {
+		groups[i].Keys = append(groups[i].Keys, results[i].RefCount)
+	}
+	t, err := groupTabular([]string{"value", "refs"}, groups, aggs, output)
+	if err != nil {
+		return err
+	}
+	return t.render(w, output.format, "group")
+}
+
+// printFollowChain follows a multi-hop chain and reports the join paths,
+// or aggregates them by the value each path ends at.
+func printFollowChain(w io.Writer, qe *query.QueryEngine, sources []query.BlockMatch, hops []query.FollowHop, nodes []domain.Node, cfg config.Config, flags *queryFlags, aggregating bool) error {
+	paths, err := qe.FollowChain(sources, hops, nodes, cfg.CustomBlockTypes, flags.maxDepth)
+	if err != nil {
+		return err
+	}
+
+	if aggregating {
+		aggs, err := parseAggregates(flags.aggs)
+		if err != nil {
+			return err
+		}
+		return printFollowGroups(w, qe, joinFollowResults(paths), aggs, cfg, &flags.output)
+	}
+
+	if !flags.output.customized() {
+		printJoinPaths(w, paths)
+		return nil
+	}
+	t, err := joinTabular(paths, &flags.output)
+	if err != nil {
+		return err
+	}
+	return t.render(w, flags.output.format, "path")
+}
+
+// joinFollowResults groups join paths by the value of their last step:
+// refs counts the paths, matches are the distinct blocks they end at.
+func joinFollowResults(paths []query.JoinPath) []query.FollowResult {
+	byValue := make(map[string]*query.FollowResult)
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		if len(p.Steps) == 0 {
+			continue
+		}
+		last := p.Steps[len(p.Steps)-1]
+		r, ok := byValue[last.Value]
+		if !ok {
+			r = &query.FollowResult{Value: last.Value}
+			byValue[last.Value] = r
+		}
+		r.RefCount++
+		if last.Match != nil {
+			key := last.Value + "\x00" + blockLabel(*last.Match)
+			if !seen[key] {
+				seen[key] = true
+				r.Matches = append(r.Matches, *last.Match)
+			}
+		}
+	}
+	results := make([]query.FollowResult, 0, len(byValue))
+	for _, r := range byValue {
+		results = append(results, *r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Value < results[j].Value })
+	return results
+}
+
+// blockLabel names a block by its position: node > section > type id.
+func blockLabel(m query.BlockMatch) string {
+	name := fmt.Sprintf("#%d", m.BlockIndex)
+	if id, ok := m.Block.Data["id"].(string); ok && id != "" {
+		name = id
+	}
+	return fmt.Sprintf("%s > %s > %s %s", m.NodeID, m.SectionName, m.Block.Type, name)
+}
+
+// joinStopText explains why a path ended early.
+func joinStopText(p query.JoinPath) string {
+	switch p.Stop {
+	case query.StopUnresolved:
+		return "not found"
+	case query.StopNoValue:
+		return "no further values"
+	case query.StopCycle:
+		return "cycle"
+	case query.StopDepth:
+		return "max depth reached"
+	}
+	return ""
+}
+
+// printJoinPaths displays each join path as its source block followed by
+// one line per hop.
+func printJoinPaths(w io.Writer, paths []query.JoinPath) {
+	if len(paths) == 0 {
+		fmt.Fprintln(w, "No join paths found")
+		return
+	}
+	for i, p := range paths {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, blockLabel(p.Source))
+		for _, s := range p.Steps {
+			target := "(not found)"
+			if s.Match != nil {
+				target = blockLabel(*s.Match)
+			}
+			fmt.Fprintf(w, "  %s = %s → %s\n", s.Field, s.Value, target)
+		}
+		if p.Stop != "" && p.Stop != query.StopUnresolved {
+			fmt.Fprintf(w, "  (%s)\n", joinStopText(p))
+		}
+	}
+	fmt.Fprintf(w, "\nFound %d join path(s)\n", len(paths))
+}
+
+// joinTabular lays out join paths for structured output.
+func joinTabular(paths []query.JoinPath, flags *outputFlags) (tabular, error) {
+	all := []string{"source", "path", "blocks", "end", "hops", "stop"}
+	columns := []string{"source", "path", "end", "stop"}
+	if len(flags.columns) > 0 {
+		columns = flags.columns
+	}
+	return buildTabular(len(paths), columns, flags, func(i int, col string) (interface{}, error) {
+		p := paths[i]
+		switch col {
+		case "source":
+			return blockLabel(p.Source), nil
+		case "path":
+			steps := []string{}
+			for _, s := range p.Steps {
+				steps = append(steps, s.Field+"="+s.Value)
+			}
+			return steps, nil
+		case "blocks":
+			blocks := []string{blockLabel(p.Source)}
+			for _, s := range p.Steps {
+				if s.Match != nil {
+					blocks = append(blocks, blockLabel(*s.Match))
+				}
+			}
+			return blocks, nil
+		case "end":
+			return blockLabel(p.Last()), nil
+		case "hops":
+			return len(p.Steps), nil
+		case "stop":
+			if p.Stop == "" {
+				return nil, nil
+			}
+			return p.Stop, nil
+		}
+		return nil, fmt.Errorf("unknown column %q (available: %s)", col, strings.Join(all, ", "))
+	})
+}
+
 // printFollowResults displays follow query results grouped by value.
-func printFollowResults(results []query.FollowResult) {
+func printFollowResults(w io.Writer, results []query.FollowResult) {
 	for i, r := range results {
 		// Header: value (referenced by N block(s))
-		fmt.Printf("%s (referenced by %d block(s))\n", r.Value, r.RefCount)
+		fmt.Fprintf(w, "%s (referenced by %d block(s))\n", r.Value, r.RefCount)
 
 		if len(r.Matches) == 0 {
-			fmt.Println("  (no matches found)")
+			fmt.Fprintln(w, "  (no matches found)")
 		} else {
 			for _, m := range r.Matches {
-				fmt.Printf("  %s in %s > %s > block %d\n", m.Block.Type, m.NodeID, m.SectionName, m.BlockIndex)
+				fmt.Fprintf(w, "  %s in %s > %s > block %d\n", m.Block.Type, m.NodeID, m.SectionName, m.BlockIndex)
 				for k, v := range m.Block.Data {
-					fmt.Printf("    %s: %v\n", k, v)
+					fmt.Fprintf(w, "    %s: %v\n", k, v)
 				}
 			}
 		}
 
 		if i < len(results)-1 {
-			fmt.Println()
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// parseAggregates parses --agg values; aggregating without any counts blocks.
+func parseAggregates(specs []string) ([]query.Aggregate, error) {
+	aggs := make([]query.Aggregate, 0, len(specs))
+	for _, spec := range specs {
+		a, err := query.ParseAggregate(spec)
+		if err != nil {
+			return nil, fmt.Errorf("--agg: %w", err)
+		}
+		aggs = append(aggs, a)
+	}
+	if len(aggs) == 0 {
+		aggs = append(aggs, query.Aggregate{Func: "count"})
+	}
+	return aggs, nil
+}
+
+// validateAggregateFields reports group and aggregate fields that the
+// block type does not declare, like unknown filter values.
+func validateAggregateFields(blockType string, groupBy []string, aggs []query.Aggregate, cfg config.Config) error {
+	known := declaredBlockFields(blockType, cfg)
+	if known == nil {
+		return nil
+	}
+	valid := append([]string{"node", "section", "type"}, known...)
+	check := func(field string) error {
+		if field == "" || containsString(valid, field) {
+			return nil
+		}
+		// Nested paths are checked by their top-level field
+		if top, _, _ := strings.Cut(field, "."); containsString(known, top) {
+			return nil
+		}
+		return newFilterError("field", field, valid)
+	}
+	for _, field := range groupBy {
+		if err := check(field); err != nil {
+			return err
 		}
 	}
+	for _, a := range aggs {
+		if err := check(a.Field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupTabular lays out aggregation groups: the key columns followed by
+// one column per aggregate.
+func groupTabular(keyColumns []string, groups []query.Group, aggs []query.Aggregate, flags *outputFlags) (tabular, error) {
+	columns := append([]string{}, keyColumns...)
+	for _, a := range aggs {
+		columns = append(columns, a.Name())
+	}
+	index := make(map[string]int, len(columns))
+	for i, col := range columns {
+		index[col] = i
+	}
+
+	selected := columns
+	if len(flags.columns) > 0 {
+		selected = flags.columns
+	}
+	return buildTabular(len(groups), selected, flags, func(i int, col string) (interface{}, error) {
+		j, ok := index[col]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", col, strings.Join(columns, ", "))
+		}
+		if j < len(keyColumns) {
+			return groups[i].Keys[j], nil
+		}
+		return groups[i].Values[j-len(keyColumns)], nil
+	})
 }
 
 // printBlocksTable displays block query results.
-func printBlocksTable(blocks []query.BlockMatch) {
-	fmt.Printf("Found %d block(s):\n\n", len(blocks))
+func printBlocksTable(w io.Writer, blocks []query.BlockMatch) {
+	fmt.Fprintf(w, "Found %d block(s):\n\n", len(blocks))
 	for _, b := range blocks {
-		fmt.Printf("  [%s > %s] type: %s\n", b.NodeID, b.SectionName, b.Block.Type)
+		fmt.Fprintf(w, "  [%s > %s] type: %s\n", b.NodeID, b.SectionName, b.Block.Type)
 		for k, v := range b.Block.Data {
-			fmt.Printf("    %s: %v\n", k, v)
+			fmt.Fprintf(w, "    %s: %v\n", k, v)
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 }
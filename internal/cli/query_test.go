@@ -375,6 +375,164 @@ func TestQueryCommand_WithRootCommand(t *testing.T) {
 	})
 }
 
+func TestQueryCommand_Where(t *testing.T) {
+	t.Run("filters nodes by expression", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		setupProjectForQuery(t, tmpDir)
+
+		var err error
+		out := captureStdout(t, func() {
+			cmd := NewQueryCommand()
+			cmd.SetArgs([]string{"--where", `kind == "quest" || "healing" in tags`, tmpDir})
+			err = cmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(out, "quest-001") || !strings.Contains(out, "potion-001") {
+			t.Errorf("Expected quest-001 and potion-001, got:\n%s", out)
+		}
+		if strings.Contains(out, "sword-001") || strings.Contains(out, "hero-001") {
+			t.Errorf("Expected only matching nodes, got:\n%s", out)
+		}
+	})
+
+	t.Run("combines with flag filters", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		setupProjectForQuery(t, tmpDir)
+
+		var err error
+		out := captureStdout(t, func() {
+			cmd := NewQueryCommand()
+			cmd.SetArgs([]string{"--kind", "item", "--where", `!(status == "approved")`, tmpDir})
+			err = cmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(out, "sword-001") || strings.Contains(out, "potion-001") {
+			t.Errorf("Expected only sword-001, got:\n%s", out)
+		}
+	})
+
+	t.Run("rejects invalid expression", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		setupProjectForQuery(t, tmpDir)
+
+		cmd := NewQueryCommand()
+		cmd.SetArgs([]string{"--where", "kind ==", tmpDir})
+		err := cmd.Execute()
+		if err == nil {
+			t.Fatal("Expected error for invalid expression, got nil")
+		}
+		if !strings.Contains(err.Error(), "--where") {
+			t.Errorf("Expected error to name --where, got %q", err.Error())
+		}
+	})
+}
+
+func TestQueryCommand_BlockWhere(t *testing.T) {
+	t.Run("filters blocks without --block-type", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		setupProjectWithContent(t, tmpDir)
+
+		var err error
+		out := captureStdout(t, func() {
+			cmd := NewQueryCommand()
+			cmd.SetArgs([]string{"--block-where", `block.type == "rule" && section == "Game Flow"`, tmpDir})
+			err = cmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(out, "type: rule") || strings.Contains(out, "type: table") {
+			t.Errorf("Expected only rule blocks, got:\n%s", out)
+		}
+	})
+
+	t.Run("rejects node variables", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		setupProjectForQuery(t, tmpDir)
+
+		cmd := NewQueryCommand()
+		cmd.SetArgs([]string{"--block-where", `kind == "item"`, tmpDir})
+		if err := cmd.Execute(); err == nil {
+			t.Fatal("Expected error for undeclared variable, got nil")
+		}
+	})
+}
+
+func TestQueryCommand_OutputFormats(t *testing.T) {
+	t.Run("nodes as csv", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		setupProjectForQuery(t, tmpDir)
+
+		var err error
+		out := captureStdout(t, func() {
+			cmd := NewQueryCommand()
+			cmd.SetArgs([]string{"--tag", "combat", "--format", "csv", "--columns", "id,kind", "--sort", "id", tmpDir})
+			err = cmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if out != "id,kind\nhero-001,character\nquest-001,quest\nsword-001,item\n" {
+			t.Errorf("Unexpected CSV:\n%s", out)
+		}
+	})
+
+	t.Run("blocks as csv with one column per field", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		setupProjectWithContent(t, tmpDir)
+
+		var err error
+		out := captureStdout(t, func() {
+			cmd := NewQueryCommand()
+			cmd.SetArgs([]string{"--block-type", "rule", "--format", "csv", tmpDir})
+			err = cmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 2 || lines[0] != "node,section,index,type,id,text" {
+			t.Fatalf("Unexpected CSV:\n%s", out)
+		}
+		if !strings.HasPrefix(lines[1], "systems/core,Game Flow,0,rule,game_over,") {
+			t.Errorf("Unexpected row: %s", lines[1])
+		}
+	})
+
+	t.Run("block columns can read the owning node", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		setupProjectWithContent(t, tmpDir)
+
+		var err error
+		out := captureStdout(t, func() {
+			cmd := NewQueryCommand()
+			cmd.SetArgs([]string{"--block-type", "rule", "--format", "jsonl", "--columns", "id,node.status", tmpDir})
+			err = cmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if strings.TrimSpace(out) != `{"id":"game_over","node.status":"approved"}` {
+			t.Errorf("Unexpected JSONL: %s", out)
+		}
+	})
+
+	t.Run("follow rejects structured output", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		setupProjectWithContent(t, tmpDir)
+
+		cmd := NewQueryCommand()
+		cmd.SetArgs([]string{"--block-type", "rule", "--follow", "text", "--format", "json", tmpDir})
+		if err := cmd.Execute(); err == nil {
+			t.Fatal("Expected error for --follow with --format, got nil")
+		}
+	})
+}
+
 // Test helper
 
 func setupProjectForQuery(t *testing.T, dir string) {
@@ -477,3 +635,121 @@ tags:
 		}
 	}
 }
+
+func TestQueryCommand_Aggregate(t *testing.T) {
+	dir := setupCraftingProject(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"count per node", []string{"--block-type", "recipe", "--group-by", "node", "--format", "csv"},
+			"node,count\nitems/basics,1\nitems/tools,1\n"},
+		{"list values fan out", []string{"--block-type", "recipe", "--group-by", "materials", "--agg", "count", "--agg", "min:output", "--format", "csv"},
+			"materials,count,min(output)\nPlanks,1,Axe\nWood,1,Planks\n"},
+		{"whole result", []string{"--block-where", "true", "--group-by", "type", "--format", "jsonl"},
+			`{"type":"recipe","count":2}` + "\n" + `{"type":"resource","count":1}` + "\n"},
+		{"sorted and limited", []string{"--block-type", "recipe", "--group-by", "output", "--sort", "output:desc", "--limit", "1", "--format", "csv"},
+			"output,count\nPlanks,1\n"},
+		{"follow by referenced value", []string{"--block-type", "recipe", "--follow", "materials", "--agg", "count", "--format", "csv"},
+			"value,refs,count\nPlanks,1,1\nWood,1,1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runQueryCmd(t, append(tt.args, dir)...)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("Unexpected output:\n%s\nwant:\n%s", out, tt.want)
+			}
+		})
+	}
+
+	out, err := runQueryCmd(t, "--block-type", "recipe", "--group-by", "node", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "NODE") || !strings.Contains(out, "Total: 2 group(s)") {
+		t.Errorf("Expected a table of groups, got:\n%s", out)
+	}
+}
+
+func TestQueryCommand_AggregateErrors(t *testing.T) {
+	dir := setupCraftingProject(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--kind", "item", "--agg", "count"}, "add --block-type or --block-where"},
+		{[]string{"--block-type", "recipe", "--group-by", "outptu"}, `Did you mean "output"?`},
+		{[]string{"--block-type", "recipe", "--agg", "sum:output"}, "declared as string"},
+		{[]string{"--block-type", "recipe", "--agg", "total:output"}, "--agg: unknown aggregate"},
+		{[]string{"--block-type", "recipe", "--follow", "materials", "--group-by", "output"}, "--group-by cannot be combined with --follow"},
+		{[]string{"--block-type", "recipe", "--group-by", "node", "--columns", "nodes"}, `unknown column "nodes" (available: node, count)`},
+	}
+	for _, tt := range tests {
+		_, err := runQueryCmd(t, append(tt.args, dir)...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
+
+func TestQueryCommand_FollowChain(t *testing.T) {
+	dir := setupCraftingProject(t)
+
+	out, err := runQueryCmd(t, "--block-type", "recipe", "--field", "output=Axe", "--follow", "materials*", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{
+		"items/tools > Recipes > recipe #0",
+		"  materials = Planks → items/basics > Recipes > recipe planks",
+		"  materials = Wood → items/basics > Resources > resource #0",
+		"Found 1 join path(s)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+
+	out, err = runQueryCmd(t, "--block-type", "recipe", "--follow", "materials>materials", "--columns", "source,hops,stop", "--format", "csv", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "source,hops,stop\n" +
+		"items/basics > Recipes > recipe planks,1,no-value\n" +
+		"items/tools > Recipes > recipe #0,2,\n"
+	if out != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", out, want)
+	}
+
+	out, err = runQueryCmd(t, "--block-type", "recipe", "--field", "output=Axe", "--follow", "materials*", "--max-depth", "1", "--format", "csv", "--columns", "path,stop", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out != "path,stop\nmaterials=Planks,depth\n" {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+
+	// Aggregation groups paths by the value they end at
+	out, err = runQueryCmd(t, "--block-type", "recipe", "--follow", "materials*", "--agg", "count", "--format", "csv", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out != "value,refs,count\nWood,2,1\n" {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+
+	for _, bad := range []struct{ follow, want string }{
+		{"materials*>materials", "only the last hop can repeat"},
+		{"materials>", "empty hop"},
+	} {
+		if _, err := runQueryCmd(t, "--block-type", "recipe", "--follow", bad.follow, dir); err == nil || !strings.Contains(err.Error(), bad.want) {
+			t.Errorf("--follow %q: expected error containing %q, got %v", bad.follow, bad.want, err)
+		}
+	}
+}
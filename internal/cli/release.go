@@ -0,0 +1,350 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/migrations"
+	"github.com/Toernblom/deco/internal/services/diff"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/git"
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/Toernblom/deco/internal/storage/objects"
+	"github.com/Toernblom/deco/internal/storage/release"
+	"github.com/spf13/cobra"
+)
+
+type releaseFlags struct {
+	approvedOnly bool
+	format       string
+	targetDir    string
+}
+
+// NewReleaseCommand creates the release command and its subcommands
+func NewReleaseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Freeze and compare releases of the project",
+		Long: `Freeze the project as a named release and compare releases.
+
+Subcommands:
+  create   - Record the version and content of every node as a release
+  list     - List releases
+  diff     - Show what changed between two releases
+
+A release is written to .deco/releases/<name>.yaml with the ID, version,
+status and content hash of each node. The full content of each node is
+kept in the history object store, so 'deco export --release <name>'
+renders the frozen spec after the nodes have moved on. Commit both.`,
+	}
+
+	cmd.AddCommand(newReleaseCreateCommand())
+	cmd.AddCommand(newReleaseListCommand())
+	cmd.AddCommand(newReleaseDiffCommand())
+
+	return cmd
+}
+
+func newReleaseCreateCommand() *cobra.Command {
+	flags := &releaseFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "create <name> [directory]",
+		Short: "Freeze the project as a release",
+		Long: `Record the ID, version, status and content hash of every node as a release.
+
+The release is refused when the project fails validation, when a node has
+edits that 'deco sync' has not recorded yet, or when a node in the release
+is a draft or in review. With --approved-only, only approved nodes are
+included and the rest are left out. Deprecated nodes are included as
+deprecated otherwise. Releases are frozen: an existing name is not replaced.
+
+Examples:
+  deco release create v1.2
+  deco release create beta-3 --approved-only`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.targetDir = "."
+			if len(args) > 1 {
+				flags.targetDir = args[1]
+			}
+			return runReleaseCreate(cmd.OutOrStdout(), args[0], flags)
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.approvedOnly, "approved-only", false, "Include only approved nodes")
+
+	return cmd
+}
+
+func runReleaseCreate(w io.Writer, name string, flags *releaseFlags) error {
+	if err := release.ValidateName(name); err != nil {
+		return err
+	}
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+	unlock, err := lockProject(flags.targetDir, "release create")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	releaseRepo := release.NewYAMLRepository(config.ResolveReleasesPath(flags.targetDir))
+	if exists, err := releaseRepo.Exists(name); err != nil {
+		return fmt.Errorf("failed to check release %s: %w", name, err)
+	} else if exists {
+		return fmt.Errorf("release %q already exists; releases are frozen, choose another name", name)
+	}
+
+	needsMigration, _, _, err := migrations.NeedsMigration(flags.targetDir)
+	if err != nil {
+		return fmt.Errorf("failed to check schema version: %w", err)
+	}
+	if needsMigration {
+		return fmt.Errorf("schema version mismatch; run 'deco migrate' first")
+	}
+
+	nodes, err := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir)).LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load nodes: %w", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	if collector := validateProject(cfg, flags.targetDir, nodes); collector.HasErrors() {
+		fmt.Fprintf(w, "%s Release not created: %s validation error(s):\n\n", style.ErrorIcon(), style.Error.Sprint(collector.Count()))
+		formatter := domain.NewErrorFormatter()
+		formatter.SetColor(style.IsEnabled())
+		for _, err := range collector.Errors() {
+			fmt.Fprintln(w, formatter.Format(err))
+		}
+		return NewExitError(ExitCodeError, fmt.Sprintf("validation failed with %d error(s)", collector.Count()))
+	}
+
+	var scope []domain.Node
+	var unapproved []string
+	for _, n := range nodes {
+		switch {
+		case n.Status == "approved":
+			scope = append(scope, n)
+		case flags.approvedOnly:
+			// Left out of the release
+		case n.Status == "deprecated":
+			scope = append(scope, n)
+		default:
+			unapproved = append(unapproved, fmt.Sprintf("%s (%s)", n.ID, n.Status))
+		}
+	}
+	if len(unapproved) > 0 {
+		return fmt.Errorf("%d node(s) are not approved: %s; approve them or use --approved-only", len(unapproved), strings.Join(unapproved, ", "))
+	}
+	if len(scope) == 0 {
+		return fmt.Errorf("no nodes to release")
+	}
+
+	// The release must match what history recorded for each node
+	latestHashes, err := history.NewYAMLRepository(config.ResolveHistoryPath(cfg, flags.targetDir)).QueryLatestHashes()
+	if err != nil {
+		return fmt.Errorf("failed to load history: %w", err)
+	}
+	rel := release.Release{
+		Name:         name,
+		Created:      time.Now().UTC(),
+		User:         GetCurrentUser(flags.targetDir),
+		Commit:       git.Head(flags.targetDir),
+		ApprovedOnly: flags.approvedOnly,
+	}
+	var unsynced []string
+	for _, n := range scope {
+		hash := ComputeContentHashWithDir(n, flags.targetDir)
+		if latestHashes[n.ID] != hash {
+			unsynced = append(unsynced, n.ID)
+		}
+		rel.Nodes = append(rel.Nodes, release.Node{ID: n.ID, Version: n.Version, Status: n.Status, ContentHash: hash})
+	}
+	if len(unsynced) > 0 {
+		return fmt.Errorf("%d node(s) have changes not recorded in history: %s; run 'deco sync' first", len(unsynced), strings.Join(unsynced, ", "))
+	}
+
+	store := objects.NewStore(config.ResolveObjectsPath(cfg, flags.targetDir))
+	for i, n := range scope {
+		if rel.Nodes[i].Snapshot, err = store.Put(n); err != nil {
+			return fmt.Errorf("failed to store %s: %w", n.ID, err)
+		}
+	}
+	if err := releaseRepo.Create(rel); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s Created release %s with %d node(s)\n", style.SuccessIcon(), name, len(rel.Nodes))
+	return nil
+}
+
+func newReleaseListCommand() *cobra.Command {
+	flags := &releaseFlags{}
+
+	return &cobra.Command{
+		Use:   "list [directory]",
+		Short: "List releases",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.targetDir = "."
+			if len(args) > 0 {
+				flags.targetDir = args[0]
+			}
+			return runReleaseList(cmd.OutOrStdout(), flags)
+		},
+	}
+}
+
+func runReleaseList(w io.Writer, flags *releaseFlags) error {
+	if _, err := config.NewYAMLRepository(flags.targetDir).Load(); err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	releases, err := release.NewYAMLRepository(config.ResolveReleasesPath(flags.targetDir)).LoadAll()
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		fmt.Fprintln(w, "No releases. Create one with 'deco release create <name>'.")
+		return nil
+	}
+
+	for _, rel := range releases {
+		details := []string{rel.Created.Local().Format("2006-01-02 15:04"), fmt.Sprintf("%d node(s)", len(rel.Nodes))}
+		if rel.Commit != "" {
+			details = append(details, "at "+shortRevision(rel.Commit))
+		}
+		if rel.ApprovedOnly {
+			details = append(details, "approved only")
+		}
+		if rel.User != "" {
+			details = append(details, "by "+rel.User)
+		}
+		fmt.Fprintf(w, "%s  %s\n", style.Header.Sprint(rel.Name), style.Muted.Sprint(strings.Join(details, ", ")))
+	}
+	return nil
+}
+
+func newReleaseDiffCommand() *cobra.Command {
+	flags := &releaseFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "diff <from> [to] [directory]",
+		Short: "Show what changed between two releases",
+		Long: `Show the nodes added, removed, moved and changed between two releases,
+with field-level detail. Without a second release, the first is compared
+with the working tree.
+
+Examples:
+  deco release diff v1.1 v1.2
+  deco release diff v1.2                  # Release against the working tree
+  deco release diff v1.1 v1.2 -f json`,
+		Args: cobra.RangeArgs(1, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var to string
+			flags.targetDir = "."
+			if len(args) > 1 {
+				to = args[1]
+			}
+			if len(args) > 2 {
+				flags.targetDir = args[2]
+			}
+			return runReleaseDiff(cmd.OutOrStdout(), args[0], to, flags)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "unified", "Output format (unified, json)")
+
+	return cmd
+}
+
+func runReleaseDiff(w io.Writer, from, to string, flags *releaseFlags) error {
+	if flags.format != "unified" && flags.format != "json" {
+		return fmt.Errorf("invalid --format %q: must be unified or json", flags.format)
+	}
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	var sides [2]gitSide
+	var trees [2][]domain.Node
+	for i, name := range []string{from, to} {
+		if name == "" {
+			sides[i] = gitSide{Rev: "working tree"}
+			if trees[i], err = node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir)).LoadAll(); err != nil {
+				return fmt.Errorf("failed to load nodes: %w", err)
+			}
+			continue
+		}
+		rel, nodes, err := loadRelease(cfg, flags.targetDir, name)
+		if err != nil {
+			return err
+		}
+		sides[i] = gitSide{Rev: rel.Name, Commit: rel.Commit}
+		trees[i] = nodes
+	}
+	changes := diff.Project(trees[0], trees[1])
+
+	if flags.format == "json" {
+		if changes == nil {
+			changes = []diff.NodeChange{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			From  gitSide           `json:"from"`
+			To    gitSide           `json:"to"`
+			Nodes []diff.NodeChange `json:"nodes"`
+		}{sides[0], sides[1], changes})
+	}
+	printProjectDiff(w, sides, changes)
+	return nil
+}
+
+// loadRelease reads a release and the frozen nodes it records from the
+// object store.
+func loadRelease(cfg config.Config, dir, name string) (release.Release, []domain.Node, error) {
+	rel, err := release.NewYAMLRepository(config.ResolveReleasesPath(dir)).Load(name)
+	if err != nil {
+		return release.Release{}, nil, err
+	}
+	store := objects.NewStore(config.ResolveObjectsPath(cfg, dir))
+	nodes := make([]domain.Node, 0, len(rel.Nodes))
+	for _, entry := range rel.Nodes {
+		n, err := store.Get(entry.Snapshot)
+		if err != nil {
+			return release.Release{}, nil, fmt.Errorf("release %s: %s v%d: %w", name, entry.ID, entry.Version, err)
+		}
+		n.ID = entry.ID
+		nodes = append(nodes, n)
+	}
+	return rel, nodes, nil
+}
@@ -0,0 +1,197 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/Toernblom/deco/internal/storage/release"
+)
+
+func TestReleaseCommand(t *testing.T) {
+	dir := setupDecoProject(t)
+	nodeRepo := node.NewYAMLRepository(filepath.Join(dir, ".deco", "nodes"))
+	sync := func() {
+		t.Helper()
+		if _, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	approve := func(id string) {
+		t.Helper()
+		if err := runSubmit(&reviewFlags{targetDir: dir, nodeID: id, quiet: true}); err != nil {
+			t.Fatal(err)
+		}
+		if err := runApprove(&approveFlags{targetDir: dir, nodeID: id, quiet: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	create := func(name string, approvedOnly bool) (string, error) {
+		var out bytes.Buffer
+		err := runReleaseCreate(&out, name, &releaseFlags{targetDir: dir, approvedOnly: approvedOnly})
+		return out.String(), err
+	}
+
+	// Approved nodes need content
+	content := &domain.Content{Sections: []domain.Section{{Name: "Rules", Blocks: []domain.Block{
+		{Type: "rule", Data: map[string]interface{}{"id": "basics", "text": "Keep it simple"}},
+	}}}}
+	combat := domain.Node{ID: "systems/combat", Kind: "system", Version: 1, Status: "draft", Title: "Combat", Summary: "Players fight with swords", Content: content}
+	magic := domain.Node{ID: "systems/magic", Kind: "system", Version: 1, Status: "draft", Title: "Magic", Content: content}
+	for _, n := range []domain.Node{combat, magic} {
+		if err := nodeRepo.Save(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sync()
+	approve("systems/combat")
+
+	if _, err := create("v1", false); err == nil || !strings.Contains(err.Error(), "systems/magic (draft)") {
+		t.Fatalf("expected unapproved nodes to be refused, got %v", err)
+	}
+	if out, err := create("v1", true); err != nil || !strings.Contains(out, "Created release v1 with 1 node(s)") {
+		t.Fatalf("create --approved-only: %v\n%s", err, out)
+	}
+	if _, err := create("v1", true); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected an existing release to stay frozen, got %v", err)
+	}
+	v1, err := release.NewYAMLRepository(filepath.Join(dir, ".deco", "releases")).Load("v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v1.ApprovedOnly || len(v1.Nodes) != 1 || v1.Nodes[0].ID != "systems/combat" || v1.Nodes[0].Version != 1 ||
+		v1.Nodes[0].ContentHash == "" || v1.Nodes[0].Snapshot == "" {
+		t.Errorf("unexpected release file: %+v", v1)
+	}
+
+	// Edits must be synced before they can be released
+	current, err := nodeRepo.Load("systems/combat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current.Summary = "Players fight with swords and bows"
+	if err := nodeRepo.Save(current); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := create("v2", true); err == nil || !strings.Contains(err.Error(), "run 'deco sync' first") {
+		t.Errorf("expected unsynced edits to be refused, got %v", err)
+	}
+	sync()
+	approve("systems/combat")
+	approve("systems/magic")
+	if _, err := create("v2", false); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runReleaseDiff(&out, "v1", "v2", &releaseFlags{targetDir: dir, format: "unified"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"v1..v2", "~ systems/combat", "summary", "{+ and bows+}", "+ systems/magic", "Total: 2 node(s): 1 added, 1 modified"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("release diff missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runReleaseDiff(&out, "v2", "", &releaseFlags{targetDir: dir, format: "json"}); err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		To    gitSide           `json:"to"`
+		Nodes []json.RawMessage `json:"nodes"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if result.To.Rev != "working tree" || len(result.Nodes) != 0 {
+		t.Errorf("expected v2 to match the working tree, got %s", out.String())
+	}
+
+	out.Reset()
+	if err := runReleaseList(&out, &releaseFlags{targetDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 ||
+		!strings.HasPrefix(lines[0], "v1") || !strings.Contains(lines[0], "approved only") || !strings.HasPrefix(lines[1], "v2") {
+		t.Errorf("unexpected release list:\n%s", out.String())
+	}
+
+	// The export renders the frozen v1 content, not the working tree
+	exportDir := filepath.Join(t.TempDir(), "v1")
+	captureStdout(t, func() {
+		if err := runExport("", &exportFlags{targetDir: dir, format: "markdown", depth: 1, release: "v1", output: exportDir}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	data, err := os.ReadFile(filepath.Join(exportDir, "systems", "combat.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "> Players fight with swords\n") || !strings.Contains(string(data), "v1 | approved") {
+		t.Errorf("expected the frozen v1 content, got:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(exportDir, "systems", "magic.md")); !os.IsNotExist(err) {
+		t.Errorf("magic is not in release v1 and should not be exported")
+	}
+
+	compact := captureStdout(t, func() {
+		if err := runExport("systems/combat", &exportFlags{targetDir: dir, depth: 1, compact: true, release: "v1"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(compact, "Players fight with swords") || strings.Contains(compact, "bows") {
+		t.Errorf("compact export should render v1:\n%s", compact)
+	}
+}
+
+func TestReleaseCommand_Errors(t *testing.T) {
+	dir := setupDecoProject(t)
+	nodeRepo := node.NewYAMLRepository(filepath.Join(dir, ".deco", "nodes"))
+	broken := domain.Node{ID: "systems/combat", Kind: "system", Version: 1, Status: "approved", Title: "Combat",
+		Refs: domain.Ref{Uses: []domain.RefLink{{Target: "systems/missing"}}}}
+	if err := nodeRepo.Save(broken); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err := runReleaseCreate(&out, "v1", &releaseFlags{targetDir: dir})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || !strings.Contains(out.String(), "Release not created") {
+		t.Errorf("expected a project failing validation to be refused, got %v\n%s", err, out.String())
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, ".deco", "releases", "v1.yaml")); !os.IsNotExist(statErr) {
+		t.Error("no release file should be written")
+	}
+
+	if err := runReleaseCreate(&out, "../v1", &releaseFlags{targetDir: dir}); err == nil || !strings.Contains(err.Error(), "invalid release name") {
+		t.Errorf("expected an invalid name to be refused, got %v", err)
+	}
+	if err := runReleaseDiff(&out, "v0", "", &releaseFlags{targetDir: dir, format: "unified"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a missing release to be reported, got %v", err)
+	}
+	if err := runExport("", &exportFlags{targetDir: dir, depth: 1, obsidian: true, release: "v1"}); err == nil {
+		t.Error("expected --release with --obsidian to be refused")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/spf13/cobra"
+)
+
+type restoreFlags struct {
+	version   int
+	quiet     bool
+	targetDir string
+}
+
+// NewRestoreCommand creates the restore subcommand
+func NewRestoreCommand() *cobra.Command {
+	flags := &restoreFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "restore <node-id> [directory]",
+		Short: "Bring back an earlier version of a node",
+		Long: `Bring back an earlier version of a node from the snapshots recorded in
+history.
+
+The restored content is saved as a new version (the current version plus
+one) in draft status without approvals, and logged as a 'rewrite' history
+entry. A deleted node can be restored the same way. The project is
+revalidated afterwards; validation errors are reported but the restore is
+kept.
+
+Examples:
+  deco restore systems/combat --version 2
+  deco show systems/combat --version 2   # Inspect it first`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				flags.targetDir = args[1]
+			} else {
+				flags.targetDir = "."
+			}
+			return runRestore(args[0], flags)
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.version, "version", 0, "Version to restore (required)")
+	cmd.Flags().BoolVarP(&flags.quiet, "quiet", "q", false, "Suppress output")
+	_ = cmd.MarkFlagRequired("version")
+
+	return cmd
+}
+
+func runRestore(nodeID string, flags *restoreFlags) error {
+	// Load config
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+	unlock, err := lockProject(flags.targetDir, "restore")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
+	var current *domain.Node
+	if n, err := nodeRepo.Load(nodeID); err == nil {
+		current = &n
+	}
+	if current != nil && current.Version == flags.version {
+		return fmt.Errorf("%s is already at version %d", nodeID, flags.version)
+	}
+
+	restored, err := loadVersion(cfg, flags.targetDir, nodeID, flags.version, nil)
+	if err != nil {
+		return err
+	}
+
+	// The restore is a new version after everything recorded so far
+	oldVersion, oldStatus := 0, ""
+	if current != nil {
+		oldVersion, oldStatus = current.Version, current.Status
+	} else {
+		versions, err := nodeSnapshots(cfg, flags.targetDir, nodeID)
+		if err != nil {
+			return err
+		}
+		for v := range versions {
+			if v > oldVersion {
+				oldVersion = v
+			}
+		}
+	}
+	restored.Version = oldVersion + 1
+	restored.Status = "draft"
+	restored.Reviewers = nil
+
+	if err := nodeRepo.Save(restored); err != nil {
+		return fmt.Errorf("failed to save node: %w", err)
+	}
+
+	historyPath := config.ResolveHistoryPath(cfg, flags.targetDir)
+	snapshot, err := snapshotNode(historyPath, restored)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot node: %w", err)
+	}
+	entry := domain.AuditEntry{
+		Timestamp:   time.Now(),
+		NodeID:      nodeID,
+		Operation:   "rewrite",
+		User:        GetCurrentUser(flags.targetDir),
+		ContentHash: ComputeContentHashWithDir(restored, flags.targetDir),
+		Before: map[string]interface{}{
+			"version": oldVersion,
+			"status":  oldStatus,
+		},
+		After: map[string]interface{}{
+			"version":       restored.Version,
+			"status":        restored.Status,
+			"restored_from": flags.version,
+		},
+		Snapshot: snapshot,
+	}
+	if current == nil {
+		delete(entry.Before, "status")
+	}
+	if err := historyForWrite(historyPath).Append(entry); err != nil {
+		return fmt.Errorf("failed to log restore: %w", err)
+	}
+
+	if !flags.quiet {
+		fmt.Printf("Restored %s version %d as version %d (status: draft)\n", nodeID, flags.version, restored.Version)
+	}
+
+	return runValidate(&validateFlags{quiet: flags.quiet, targetDir: flags.targetDir})
+}
@@ -0,0 +1,169 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/node"
+)
+
+// setupVersionedNode creates systems/combat and syncs two edits of it, so
+// history holds snapshots of versions 1 ("Test Node"), 2 ("Combat") and
+// 3 ("Combat v3").
+func setupVersionedNode(t *testing.T) (string, *node.YAMLRepository) {
+	t.Helper()
+	dir := setupDecoProject(t)
+	createTestNode(t, dir, "systems/combat")
+	nodeRepo := node.NewYAMLRepository(filepath.Join(dir, ".deco", "nodes"))
+	sync := func() {
+		if _, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	}
+	sync()
+	for _, title := range []string{"Combat", "Combat v3"} {
+		n, err := nodeRepo.Load("systems/combat")
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.Title = title
+		if err := nodeRepo.Save(n); err != nil {
+			t.Fatal(err)
+		}
+		sync()
+	}
+	return dir, nodeRepo
+}
+
+func TestShowCommand_Version(t *testing.T) {
+	dir, _ := setupVersionedNode(t)
+
+	for version, title := range map[int]string{1: "Test Node", 2: "Combat", 3: "Combat v3"} {
+		var err error
+		out := captureStdout(t, func() {
+			err = runShow("systems/combat", &showFlags{targetDir: dir, version: version})
+		})
+		if err != nil {
+			t.Fatalf("version %d: %v", version, err)
+		}
+		if !strings.Contains(out, "Title:   "+title+"\n") {
+			t.Errorf("version %d: expected title %q in:\n%s", version, title, out)
+		}
+	}
+
+	err := runShow("systems/combat", &showFlags{targetDir: dir, version: 7})
+	if err == nil || !strings.Contains(err.Error(), "available: 1, 2, 3") {
+		t.Errorf("Expected the available versions in the error, got %v", err)
+	}
+	if err := runShow("systems/combat", &showFlags{targetDir: dir, version: 1, asOf: "1d"}); err == nil {
+		t.Error("Expected --version and --as-of to be rejected together")
+	}
+}
+
+func TestRestoreCommand(t *testing.T) {
+	dir, nodeRepo := setupVersionedNode(t)
+
+	captureStdout(t, func() {
+		if err := runRestore("systems/combat", &restoreFlags{version: 1, targetDir: dir}); err != nil {
+			t.Fatalf("restore failed: %v", err)
+		}
+	})
+
+	n, err := nodeRepo.Load("systems/combat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Title != "Test Node" || n.Version != 4 || n.Status != "draft" {
+		t.Errorf("expected version 1 content as draft v4, got %q v%d %s", n.Title, n.Version, n.Status)
+	}
+
+	entries, err := history.NewYAMLRepository(filepath.Join(dir, ".deco", "history.jsonl")).Query(history.Filter{NodeID: "systems/combat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := entries[len(entries)-1]
+	if last.Operation != "rewrite" || last.Snapshot == "" || last.After["restored_from"] != float64(1) {
+		t.Errorf("unexpected restore entry %+v", last)
+	}
+
+	// The restore is recorded, so sync sees nothing to do
+	code, err := runSync(&syncFlags{quiet: true, targetDir: dir})
+	if err != nil || code != syncExitClean {
+		t.Errorf("expected a clean sync after restore, got %d (%v)", code, err)
+	}
+
+	if err := runRestore("systems/combat", &restoreFlags{version: 4, quiet: true, targetDir: dir}); err == nil ||
+		!strings.Contains(err.Error(), "already at version 4") {
+		t.Errorf("expected an already-at-version error, got %v", err)
+	}
+}
+
+func TestRestoreCommand_DeletedNode(t *testing.T) {
+	dir, nodeRepo := setupVersionedNode(t)
+	if err := os.Remove(filepath.Join(dir, ".deco", "nodes", "systems", "combat.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runSync(&syncFlags{quiet: true, targetDir: dir}); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	if err := runRestore("systems/combat", &restoreFlags{version: 2, quiet: true, targetDir: dir}); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	n, err := nodeRepo.Load("systems/combat")
+	if err != nil {
+		t.Fatalf("expected the node to be back: %v", err)
+	}
+	if n.Title != "Combat" || n.Version != 4 {
+		t.Errorf("expected version 2 content as v4, got %q v%d", n.Title, n.Version)
+	}
+}
+
+func TestAsOf_UsesSnapshots(t *testing.T) {
+	dir, _ := setupVersionedNode(t)
+
+	// Spread the baseline and the two syncs over three days
+	historyPath := filepath.Join(dir, ".deco", "history.jsonl")
+	entries, err := history.NewYAMLRepository(historyPath).Query(history.Filter{})
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected 3 history entries, got %d (%v)", len(entries), err)
+	}
+	if err := os.Remove(historyPath); err != nil {
+		t.Fatal(err)
+	}
+	repo := history.NewYAMLRepository(historyPath)
+	for i, e := range entries {
+		e.Timestamp = time.Date(2026, 1, 1+i, 12, 0, 0, 0, time.UTC)
+		if err := repo.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// No git repository here: the title comes from the day-two snapshot
+	out, err := runListCmd(t, dir, "--as-of", "2026-01-02T18:00:00Z", "--format", "csv", "--columns", "id,version,title")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out != "id,version,title\nsystems/combat,2,Combat\n" {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+}
@@ -16,13 +16,15 @@
 package cli
 
 import (
+	"crypto/ed25519"
 	"fmt"
-	"os/user"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/signing"
 	"github.com/Toernblom/deco/internal/storage/config"
-	"github.com/Toernblom/deco/internal/storage/history"
 	"github.com/Toernblom/deco/internal/storage/node"
 	"github.com/spf13/cobra"
 )
@@ -86,6 +88,11 @@ func runSubmit(flags *reviewFlags) error {
 	if err != nil {
 		return fmt.Errorf(".deco directory not found or invalid: %w", err)
 	}
+	unlock, err := lockProject(flags.targetDir, "review submit")
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// Load the node
 	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
@@ -110,7 +117,7 @@ func runSubmit(flags *reviewFlags) error {
 
 	// Log submit operation
 	historyPath := config.ResolveHistoryPath(cfg, flags.targetDir)
-	if err := logReviewOperation(historyPath, n.ID, "submit", oldStatus, n.Status, ""); err != nil {
+	if err := logReviewOperation(historyPath, n, "submit", oldStatus, ""); err != nil {
 		fmt.Printf("Warning: failed to log submit operation: %v\n", err)
 	}
 
@@ -121,21 +128,22 @@ func runSubmit(flags *reviewFlags) error {
 	return nil
 }
 
-func logReviewOperation(historyPath, nodeID, operation, oldStatus, newStatus, note string) error {
-	historyRepo := history.NewYAMLRepository(historyPath)
+func logReviewOperation(historyPath string, n domain.Node, operation, oldStatus, note string) error {
+	historyRepo := historyForWrite(historyPath)
 
-	username := "unknown"
-	if u, err := user.Current(); err == nil {
-		username = u.Username
+	snapshot, err := snapshotNode(historyPath, n)
+	if err != nil {
+		return err
 	}
 
 	entry := domain.AuditEntry{
 		Timestamp: time.Now(),
-		NodeID:    nodeID,
+		NodeID:    n.ID,
 		Operation: operation,
-		User:      username,
+		User:      GetCurrentUser(filepath.Dir(historyPath)),
 		Before:    map[string]interface{}{"status": oldStatus},
-		After:     map[string]interface{}{"status": newStatus},
+		After:     map[string]interface{}{"status": n.Status},
+		Snapshot:  snapshot,
 	}
 
 	if note != "" {
@@ -149,6 +157,7 @@ type approveFlags struct {
 	targetDir string
 	nodeID    string
 	note      string
+	keyFile   string
 	quiet     bool
 }
 
@@ -158,8 +167,19 @@ func newApproveCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "approve <node-id> [directory]",
 		Short: "Approve a node under review",
-		Long:  `Approve a node under review. Adds your approval and transitions to 'approved' if requirements are met.`,
-		Args:  cobra.RangeArgs(1, 2),
+		Long: `Approve a node under review. Adds your approval and transitions to 'approved' if requirements are met.
+
+With --key, the approval is signed with an ed25519 private key (an OpenSSH
+key from ssh-keygen -t ed25519, or a PKCS#8 PEM key). The signature covers
+the node ID, version, content hash and your reviewer name, and is checked
+by 'deco validate' against the keys registered with 'deco keys add'.
+Projects with require_signed_approvals set only count signed approvals.
+
+Examples:
+  deco review approve systems/combat
+  deco review approve systems/combat --note "Balance looks right"
+  deco review approve systems/combat --key ~/.ssh/id_ed25519`,
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			flags.nodeID = args[0]
 			if len(args) > 1 {
@@ -172,6 +192,7 @@ func newApproveCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&flags.note, "note", "", "Optional approval note")
+	cmd.Flags().StringVar(&flags.keyFile, "key", "", "Sign the approval with this ed25519 private key file")
 	cmd.Flags().BoolVarP(&flags.quiet, "quiet", "q", false, "Suppress output")
 
 	return cmd
@@ -184,6 +205,11 @@ func runApprove(flags *approveFlags) error {
 	if err != nil {
 		return fmt.Errorf(".deco directory not found or invalid: %w", err)
 	}
+	unlock, err := lockProject(flags.targetDir, "review approve")
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// Load the node
 	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
@@ -197,28 +223,25 @@ func runApprove(flags *approveFlags) error {
 		return fmt.Errorf("cannot approve node %q: status is %q, must be 'review'", flags.nodeID, n.Status)
 	}
 
-	// Get current user
-	username := "unknown"
-	if u, err := user.Current(); err == nil {
-		username = u.Username
+	if cfg.RequireSignedApprovals && flags.keyFile == "" {
+		return fmt.Errorf("this project requires signed approvals: use --key with your private key file")
 	}
 
 	// Add reviewer
 	reviewer := domain.Reviewer{
-		Name:      username,
+		Name:      GetCurrentUser(flags.targetDir),
 		Timestamp: time.Now(),
 		Version:   n.Version,
 		Note:      flags.note,
 	}
-	n.Reviewers = append(n.Reviewers, reviewer)
-
-	// Count approvals for current version
-	validApprovals := 0
-	for _, r := range n.Reviewers {
-		if r.Version == n.Version {
-			validApprovals++
+	if flags.keyFile != "" {
+		if err := signApproval(&reviewer, n, flags.keyFile); err != nil {
+			return err
 		}
 	}
+	n.Reviewers = append(n.Reviewers, reviewer)
+
+	validApprovals := countApprovals(n, cfg.RequireSignedApprovals)
 
 	// Transition to approved if requirements met
 	oldStatus := n.Status
@@ -233,7 +256,7 @@ func runApprove(flags *approveFlags) error {
 
 	// Log approve operation
 	historyPath := config.ResolveHistoryPath(cfg, flags.targetDir)
-	if err := logReviewOperation(historyPath, n.ID, "approve", oldStatus, n.Status, flags.note); err != nil {
+	if err := logReviewOperation(historyPath, n, "approve", oldStatus, flags.note); err != nil {
 		fmt.Printf("Warning: failed to log approve operation: %v\n", err)
 	}
 
@@ -248,6 +271,45 @@ func runApprove(flags *approveFlags) error {
 	return nil
 }
 
+// countApprovals counts the distinct approvers of the node's current
+// version, each reviewer name and signing key once. With signed set only
+// signed approvals count; deco validate checks the signatures in full.
+func countApprovals(n domain.Node, signed bool) int {
+	count := 0
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, r := range n.Reviewers {
+		if r.Version != n.Version || (signed && r.Signature == "") {
+			continue
+		}
+		if names[r.Name] || (r.Key != "" && keys[r.Key]) {
+			continue
+		}
+		names[r.Name] = true
+		if r.Key != "" {
+			keys[r.Key] = true
+		}
+		count++
+	}
+	return count
+}
+
+// signApproval signs an approval of n with the private key in keyFile.
+func signApproval(r *domain.Reviewer, n domain.Node, keyFile string) error {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read signing key: %w", err)
+	}
+	key, err := signing.ParsePrivateKey(data)
+	if err != nil {
+		return fmt.Errorf("invalid signing key %s: %w", keyFile, err)
+	}
+	payload := signing.Payload(n.ID, r.Version, ComputeFullContentHash(n), r.Name)
+	r.Key = signing.Fingerprint(key.Public().(ed25519.PublicKey))
+	r.Signature = signing.Sign(key, payload)
+	return nil
+}
+
 type rejectFlags struct {
 	targetDir string
 	nodeID    string
@@ -292,6 +354,11 @@ func runReject(flags *rejectFlags) error {
 	if err != nil {
 		return fmt.Errorf(".deco directory not found or invalid: %w", err)
 	}
+	unlock, err := lockProject(flags.targetDir, "review reject")
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// Load the node
 	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
@@ -316,7 +383,7 @@ func runReject(flags *rejectFlags) error {
 
 	// Log reject operation
 	historyPath := config.ResolveHistoryPath(cfg, flags.targetDir)
-	if err := logReviewOperation(historyPath, n.ID, "reject", oldStatus, n.Status, flags.note); err != nil {
+	if err := logReviewOperation(historyPath, n, "reject", oldStatus, flags.note); err != nil {
 		fmt.Printf("Warning: failed to log reject operation: %v\n", err)
 	}
 
@@ -444,6 +511,9 @@ func runStatus(cmd *cobra.Command, flags *statusFlags) error {
 			if r.Version != n.Version {
 				versionNote = " (stale - v" + fmt.Sprint(r.Version) + ")"
 			}
+			if r.Signature != "" {
+				versionNote += " (signed " + r.Key + ")"
+			}
 			fmt.Fprintf(out, "  - %s at %s%s\n", r.Name, r.Timestamp.Format("2006-01-02 15:04"), versionNote)
 			if r.Note != "" {
 				fmt.Fprintf(out, "    Note: %s\n", r.Note)
@@ -22,6 +22,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
 )
 
 func TestReviewCommand_Structure(t *testing.T) {
@@ -190,6 +192,22 @@ func TestReviewCommand_Approve(t *testing.T) {
 	})
 }
 
+func TestCountApprovals(t *testing.T) {
+	n := domain.Node{Version: 2, Reviewers: []domain.Reviewer{
+		{Name: "alice", Version: 2, Key: "SHA256:a", Signature: "sig"},
+		{Name: "alice", Version: 2, Key: "SHA256:a", Signature: "sig"}, // copied entry
+		{Name: "eve", Version: 2, Key: "SHA256:a", Signature: "sig"},   // same key, other name
+		{Name: "bob", Version: 1, Key: "SHA256:b", Signature: "sig"},   // older version
+		{Name: "carol", Version: 2},                                    // unsigned
+	}}
+	if got := countApprovals(n, true); got != 1 {
+		t.Errorf("signed count = %d, want 1", got)
+	}
+	if got := countApprovals(n, false); got != 2 {
+		t.Errorf("count = %d, want 2 (alice and carol)", got)
+	}
+}
+
 func TestReviewCommand_Reject(t *testing.T) {
 	t.Run("reject changes status from review to draft", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -17,8 +17,10 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/storage/lock"
 	"github.com/spf13/cobra"
 )
 
@@ -31,6 +33,10 @@ type Config struct {
 	Verbose    bool
 	Quiet      bool
 	Color      string
+
+	// LockTimeout is how long commands that write to the project wait for
+	// other deco processes to finish.
+	LockTimeout time.Duration
 }
 
 var globalConfig Config
@@ -58,6 +64,7 @@ and dependency tracking for game design artifacts.`,
 	cmd.PersistentFlags().BoolVar(&globalConfig.Verbose, "verbose", false, "Enable verbose output")
 	cmd.PersistentFlags().BoolVarP(&globalConfig.Quiet, "quiet", "q", false, "Suppress non-error output")
 	cmd.PersistentFlags().StringVar(&globalConfig.Color, "color", "auto", "Color output: auto, always, never")
+	cmd.PersistentFlags().DurationVar(&globalConfig.LockTimeout, "lock-timeout", lock.DefaultTimeout, "How long to wait for other deco processes writing to the project")
 
 	// Initialize style system based on color flag
 	cobra.OnInitialize(func() {
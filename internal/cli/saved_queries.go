@@ -0,0 +1,497 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/query"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+// savedQueryParam matches ${name} placeholders in saved query values.
+var savedQueryParam = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// savedQueryExprParam matches params.name and params["name"] in where and
+// block_where expressions.
+var savedQueryExprParam = regexp.MustCompile(`(?:^|[^.\w])params(?:\.([A-Za-z_]\w*)|\[\s*["']([^"']*)["']\s*\])`)
+
+// lookupSavedQuery returns the saved query with the given name.
+// A leading "@" is optional.
+func lookupSavedQuery(cfg config.Config, name string) (config.SavedQuery, error) {
+	name = strings.TrimPrefix(name, "@")
+	if sq, ok := cfg.Queries[name]; ok {
+		return sq, nil
+	}
+	if len(cfg.Queries) == 0 {
+		return config.SavedQuery{}, fmt.Errorf("unknown saved query \"@%s\": no queries are defined in .deco/config.yaml", name)
+	}
+	names := savedQueryNames(cfg)
+	for i, n := range names {
+		names[i] = "@" + n
+	}
+	return config.SavedQuery{}, newFilterError("saved query", "@"+name, names)
+}
+
+// savedQueryNames returns the sorted names of all saved queries.
+func savedQueryNames(cfg config.Config) []string {
+	names := make([]string, 0, len(cfg.Queries))
+	for name := range cfg.Queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedKeys returns the keys of a string map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// savedQueryStrings returns every string value of a saved query that may
+// contain placeholders. Expressions read parameters from params instead.
+func savedQueryStrings(sq config.SavedQuery) []string {
+	values := []string{sq.Search, sq.Kind, sq.Status, sq.Tag, sq.BlockType, sq.Follow,
+		sq.Format, sq.Sort}
+	for _, k := range sortedKeys(sq.Fields) {
+		values = append(values, sq.Fields[k])
+	}
+	values = append(values, sq.GroupBy...)
+	values = append(values, sq.Agg...)
+	return append(values, sq.Columns...)
+}
+
+// validateSavedQuery checks a saved query against the project config,
+// reporting unknown block types, fields and statuses the way filter flags
+// do. Values containing placeholders are checked once they are filled in.
+func validateSavedQuery(name string, sq config.SavedQuery, cfg config.Config) error {
+	if err := checkSavedQuery(sq, cfg); err != nil {
+		return fmt.Errorf("saved query @%s: %w", name, err)
+	}
+	return nil
+}
+
+func checkSavedQuery(sq config.SavedQuery, cfg config.Config) error {
+	for _, v := range savedQueryStrings(sq) {
+		for _, m := range savedQueryParam.FindAllStringSubmatch(v, -1) {
+			if _, ok := sq.Params[m[1]]; !ok {
+				return fmt.Errorf("undeclared parameter %q in %q (add it under params)", m[1], v)
+			}
+		}
+	}
+	for _, expr := range []string{sq.Where, sq.BlockWhere} {
+		if m := savedQueryParam.FindStringSubmatch(expr); m != nil {
+			return fmt.Errorf("placeholder ${%s} in expression %q: expressions read parameters as params.%s", m[1], expr, m[1])
+		}
+		for _, m := range savedQueryExprParam.FindAllStringSubmatch(expr, -1) {
+			if _, ok := sq.Params[m[1]+m[2]]; !ok {
+				return fmt.Errorf("undeclared parameter %q in %q (add it under params)", m[1]+m[2], expr)
+			}
+		}
+	}
+	fixed := func(s string) bool { return s != "" && !strings.Contains(s, "${") }
+
+	if fixed(sq.Status) {
+		if err := validateStatus(sq.Status); err != nil {
+			return err
+		}
+	}
+	if fixed(sq.BlockType) {
+		if err := validateBlockType(sq.BlockType, cfg.CustomBlockTypes); err != nil {
+			return err
+		}
+	}
+	if sq.Follow != "" && sq.BlockType == "" {
+		return fmt.Errorf("follow requires block_type")
+	}
+
+	// Field names can be checked when the block type declares its fields
+	if known := declaredBlockFields(sq.BlockType, cfg); known != nil {
+		for _, field := range sortedKeys(sq.Fields) {
+			if !containsString(known, field) {
+				return newFilterError("field", field, known)
+			}
+		}
+		if fixed(sq.Follow) {
+			hops, err := parseFollowChain(sq.Follow)
+			if err != nil {
+				return err
+			}
+			if !containsString(known, hops[0].Field) {
+				return newFilterError("field", hops[0].Field, known)
+			}
+		}
+	}
+
+	var aggs []query.Aggregate
+	for _, spec := range sq.Agg {
+		if !fixed(spec) {
+			continue
+		}
+		a, err := query.ParseAggregate(spec)
+		if err != nil {
+			return fmt.Errorf("agg: %w", err)
+		}
+		aggs = append(aggs, a)
+	}
+	if (len(sq.GroupBy) > 0 || len(sq.Agg) > 0) && sq.BlockType == "" && sq.BlockWhere == "" {
+		return fmt.Errorf("group_by and agg need block_type or block_where")
+	}
+	if len(sq.GroupBy) > 0 && sq.Follow != "" {
+		return fmt.Errorf("group_by cannot be combined with follow")
+	}
+	if sq.Follow == "" && fixed(sq.BlockType) {
+		var groupBy []string
+		for _, g := range sq.GroupBy {
+			if fixed(g) {
+				groupBy = append(groupBy, g)
+			}
+		}
+		if err := validateAggregateFields(sq.BlockType, groupBy, aggs, cfg); err != nil {
+			return err
+		}
+	}
+
+	if fixed(sq.Format) && !containsString(outputFormats, sq.Format) {
+		return fmt.Errorf("unknown format: %s (supported: %s)", sq.Format, strings.Join(outputFormats, ", "))
+	}
+	if fixed(sq.Sort) {
+		if _, err := parseSortKeys(sq.Sort); err != nil {
+			return err
+		}
+	}
+	if sq.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+
+	qe := query.New()
+	if sq.Where != "" {
+		if _, err := qe.CompileWhere(sq.Where); err != nil {
+			return fmt.Errorf("where: %w", err)
+		}
+	}
+	if sq.BlockWhere != "" {
+		if _, err := qe.CompileBlockWhere(sq.BlockWhere); err != nil {
+			return fmt.Errorf("block_where: %w", err)
+		}
+	}
+	return nil
+}
+
+// declaredBlockFields returns the sorted field names declared for a custom
+// block type, or nil when the type does not declare them.
+func declaredBlockFields(blockType string, cfg config.Config) []string {
+	bt, ok := cfg.CustomBlockTypes[blockType]
+	if !ok {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, f := range bt.RequiredFields {
+		seen[f] = true
+	}
+	for _, f := range bt.OptionalFields {
+		seen[f] = true
+	}
+	for f := range bt.Fields {
+		seen[f] = true
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(seen))
+	for f := range seen {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// parseParams parses --param name=value pairs.
+func parseParams(params []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, p := range params {
+		parts := splitFirst(p, '=')
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid --param value %q: expected name=value format (e.g. --param age=iron)", p)
+		}
+		values[parts[0]] = parts[1]
+	}
+	return values, nil
+}
+
+// expandSavedQuery fills in the ${param} placeholders of a saved query from
+// its defaults, overridden by values, and returns the resolved parameters.
+// where and block_where are left as written: they read the parameters from
+// the params variable, so a value cannot change an expression's logic.
+func expandSavedQuery(name string, sq config.SavedQuery, values map[string]string) (config.SavedQuery, map[string]string, error) {
+	resolved := make(map[string]string)
+	for k, v := range sq.Params {
+		resolved[k] = v
+	}
+	for _, k := range sortedKeys(values) {
+		if _, ok := sq.Params[k]; !ok {
+			if len(sq.Params) == 0 {
+				return sq, nil, fmt.Errorf("saved query @%s takes no parameters", name)
+			}
+			return sq, nil, fmt.Errorf("saved query @%s: %w", name, newFilterError("parameter", k, sortedKeys(sq.Params)))
+		}
+		resolved[k] = values[k]
+	}
+	for _, k := range sortedKeys(sq.Params) {
+		if resolved[k] == "" {
+			return sq, nil, fmt.Errorf("saved query @%s requires parameter %q (use --param %s=<value>)", name, k, k)
+		}
+	}
+
+	fill := func(s string) string {
+		return savedQueryParam.ReplaceAllStringFunc(s, func(m string) string {
+			return resolved[m[2:len(m)-1]]
+		})
+	}
+	out := sq
+	out.Search = fill(sq.Search)
+	out.Kind = fill(sq.Kind)
+	out.Status = fill(sq.Status)
+	out.Tag = fill(sq.Tag)
+	out.BlockType = fill(sq.BlockType)
+	out.Follow = fill(sq.Follow)
+	out.Format = fill(sq.Format)
+	out.Sort = fill(sq.Sort)
+	if sq.Fields != nil {
+		out.Fields = make(map[string]string, len(sq.Fields))
+		for k, v := range sq.Fields {
+			out.Fields[k] = fill(v)
+		}
+	}
+	fillAll := func(list []string) []string {
+		if list == nil {
+			return nil
+		}
+		filled := make([]string, len(list))
+		for i, v := range list {
+			filled[i] = fill(v)
+		}
+		return filled
+	}
+	out.Columns = fillAll(sq.Columns)
+	out.GroupBy = fillAll(sq.GroupBy)
+	out.Agg = fillAll(sq.Agg)
+	return out, resolved, nil
+}
+
+// applySavedQuery validates and expands the saved query named in flags and
+// merges it into them. Flags given on the command line take precedence;
+// --field values are merged with the saved fields by key.
+func applySavedQuery(flags *queryFlags, cfg config.Config) error {
+	name := flags.saved
+	sq, err := lookupSavedQuery(cfg, name)
+	if err != nil {
+		return err
+	}
+	if err := validateSavedQuery(name, sq, cfg); err != nil {
+		return err
+	}
+	values, err := parseParams(flags.params)
+	if err != nil {
+		return err
+	}
+	if sq, flags.paramMap, err = expandSavedQuery(name, sq, values); err != nil {
+		return err
+	}
+	// Filled-in values get the same checks as the literal ones
+	if err := validateSavedQuery(name, sq, cfg); err != nil {
+		return err
+	}
+
+	set := func(flag string, dst *string, v string) {
+		if v != "" && !flags.isSet(flag) {
+			*dst = v
+		}
+	}
+	if flags.searchTerm == "" {
+		flags.searchTerm = sq.Search
+	}
+	set("kind", &flags.kind, sq.Kind)
+	set("status", &flags.status, sq.Status)
+	set("tag", &flags.tag, sq.Tag)
+	set("block-type", &flags.blockType, sq.BlockType)
+	set("follow", &flags.follow, sq.Follow)
+	set("where", &flags.where, sq.Where)
+	set("block-where", &flags.blockWhere, sq.BlockWhere)
+	set("format", &flags.output.format, sq.Format)
+	set("sort", &flags.output.sort, sq.Sort)
+	if len(sq.GroupBy) > 0 && !flags.isSet("group-by") {
+		flags.groupBy = sq.GroupBy
+	}
+	if len(sq.Agg) > 0 && !flags.isSet("agg") {
+		flags.aggs = sq.Agg
+	}
+	if len(sq.Columns) > 0 && !flags.isSet("columns") {
+		flags.output.columns = sq.Columns
+	}
+	if sq.Limit > 0 && !flags.isSet("limit") {
+		flags.output.limit = sq.Limit
+	}
+
+	given := parseFieldFilters(flags.fields)
+	var fields []string
+	for _, k := range sortedKeys(sq.Fields) {
+		if _, ok := given[k]; !ok {
+			fields = append(fields, k+"="+sq.Fields[k])
+		}
+	}
+	flags.fields = append(fields, flags.fields...)
+	return nil
+}
+
+// savedQueryNodeIDs runs a saved query and returns the IDs of the nodes it
+// selects. Block queries select the nodes containing the matching blocks;
+// follow is not applied.
+func savedQueryNodeIDs(name string, params []string, cfg config.Config, nodes []domain.Node) (map[string]bool, error) {
+	flags := &queryFlags{saved: name, params: params}
+	if err := applySavedQuery(flags, cfg); err != nil {
+		return nil, err
+	}
+	sel, err := selectQuery(query.New(), flags, cfg, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("saved query @%s: %w", strings.TrimPrefix(name, "@"), err)
+	}
+	return sel.nodeIDs(), nil
+}
+
+// savedQueryScopes runs every saved query that needs no parameters and
+// returns the nodes each one selects, for constraints scoped with "@name".
+// Queries that cannot run are left out and reported by savedQueryErrors.
+func savedQueryScopes(cfg config.Config, nodes []domain.Node) map[string]map[string]bool {
+	scopes := make(map[string]map[string]bool)
+	for _, name := range savedQueryNames(cfg) {
+		if ids, err := savedQueryNodeIDs(name, nil, cfg, nodes); err == nil {
+			scopes[name] = ids
+		}
+	}
+	return scopes
+}
+
+// savedQueryErrors reports saved queries that are invalid as E059 errors.
+func savedQueryErrors(cfg config.Config) []domain.DecoError {
+	var errs []domain.DecoError
+	for _, name := range savedQueryNames(cfg) {
+		if err := validateSavedQuery(name, cfg.Queries[name], cfg); err != nil {
+			errs = append(errs, domain.DecoError{
+				Code:       "E059",
+				Summary:    fmt.Sprintf("Invalid saved query @%s", name),
+				Detail:     err.Error(),
+				Suggestion: "Fix the query under queries: in .deco/config.yaml.",
+				Location:   &domain.Location{File: ".deco/config.yaml"},
+			})
+		}
+	}
+	return errs
+}
+
+// printSavedQueries lists the saved queries with their parameters and the
+// flags they stand for.
+func printSavedQueries(w io.Writer, cfg config.Config) {
+	names := savedQueryNames(cfg)
+	if len(names) == 0 {
+		fmt.Fprintln(w, "No saved queries defined (add them under queries: in .deco/config.yaml)")
+		return
+	}
+
+	for i, name := range names {
+		sq := cfg.Queries[name]
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s", style.Header.Sprintf("@%s", name))
+		if sq.Description != "" {
+			fmt.Fprintf(w, " %s %s", style.Muted.Sprint("—"), sq.Description)
+		}
+		fmt.Fprintln(w)
+		for _, p := range sortedKeys(sq.Params) {
+			if sq.Params[p] == "" {
+				fmt.Fprintf(w, "  param %s (required)\n", p)
+			} else {
+				fmt.Fprintf(w, "  param %s (default: %s)\n", p, sq.Params[p])
+			}
+		}
+		fmt.Fprintf(w, "  %s\n", style.Muted.Sprint(savedQueryCommand(sq)))
+		if err := checkSavedQuery(sq, cfg); err != nil {
+			fmt.Fprintf(w, "  %s %s\n", style.Error.Sprint("invalid:"), strings.ReplaceAll(err.Error(), "\n", "\n    "))
+		}
+	}
+}
+
+// savedQueryCommand renders a saved query as the equivalent command line.
+func savedQueryCommand(sq config.SavedQuery) string {
+	parts := []string{"deco query"}
+	if sq.Search != "" {
+		parts = append(parts, shellQuote(sq.Search))
+	}
+	add := func(flag, v string) {
+		if v != "" {
+			parts = append(parts, "--"+flag+" "+shellQuote(v))
+		}
+	}
+	add("kind", sq.Kind)
+	add("status", sq.Status)
+	add("tag", sq.Tag)
+	add("block-type", sq.BlockType)
+	for _, k := range sortedKeys(sq.Fields) {
+		add("field", k+"="+sq.Fields[k])
+	}
+	add("follow", sq.Follow)
+	add("where", sq.Where)
+	add("block-where", sq.BlockWhere)
+	add("group-by", strings.Join(sq.GroupBy, ","))
+	for _, a := range sq.Agg {
+		add("agg", a)
+	}
+	add("format", sq.Format)
+	add("columns", strings.Join(sq.Columns, ","))
+	add("sort", sq.Sort)
+	if sq.Limit > 0 {
+		add("limit", fmt.Sprint(sq.Limit))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote single-quotes s when it contains anything a shell would
+// interpret.
+func shellQuote(s string) string {
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=,@", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,295 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/migrations"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+const savedQueriesConfig = `queries:
+  recipes-using:
+    description: Recipes that need a material
+    params:
+      material: ""
+    block_type: recipe
+    block_where: params.material in block.materials
+    columns: [node, output]
+    format: csv
+  tools:
+    block_type: recipe
+    fields:
+      output: Axe
+  drafts:
+    params:
+      kind: item
+    kind: ${kind}
+    status: draft
+`
+
+// setupSavedQueryProject extends the crafting project with saved queries.
+func setupSavedQueryProject(t *testing.T) string {
+	t.Helper()
+	dir := setupCraftingProject(t)
+	path := filepath.Join(dir, ".deco", "config.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(data, savedQueriesConfig...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func runQueryCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	cmd := NewQueryCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func TestQueryCommand_SavedQuery(t *testing.T) {
+	dir := setupSavedQueryProject(t)
+
+	out, err := runQueryCmd(t, "@recipes-using", dir, "--param", "material=Wood")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out != "node,output\nitems/basics,Planks\n" {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+
+	// Flags given on the command line win over saved values
+	out, err = runQueryCmd(t, "@recipes-using", dir, "--param", "material=Planks", "--format", "jsonl")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out != `{"node":"items/tools","output":"Axe"}`+"\n" {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+
+	// A value is only ever a string, never part of the expression
+	out, err = runQueryCmd(t, "@recipes-using", dir, "--param", `material=x" || true || "`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out != "node,output\n" {
+		t.Errorf("Expected no matches for an injected value, got:\n%s", out)
+	}
+
+	// Parameter defaults
+	out, err = runQueryCmd(t, "@drafts", dir, "-q")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out != "items/basics\nitems/tools\n" {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+
+	out, err = runQueryCmd(t, "@tools", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "Found 1 block(s)") || !strings.Contains(out, "items/tools") {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+}
+
+func TestQueryCommand_SavedQueryErrors(t *testing.T) {
+	dir := setupSavedQueryProject(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"@recipes-using", dir}, `requires parameter "material" (use --param material=<value>)`},
+		{[]string{"@recipes-using", dir, "--param", "materail=Wood"}, `Did you mean "material"?`},
+		{[]string{"@tools", dir, "--param", "x=1"}, "takes no parameters"},
+		{[]string{"@recipes-using", dir, "--param", "material"}, "expected name=value"},
+		{[]string{"@tool", dir}, `Did you mean "@tools"?`},
+		{[]string{"--param", "a=b", dir}, "--param requires a saved query"},
+		{[]string{"@drafts", dir, "--param", "kind=bogus"}, `unknown kind "bogus"`},
+	}
+	for _, tt := range tests {
+		_, err := runQueryCmd(t, tt.args...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
+
+func TestQueryCommand_ListSaved(t *testing.T) {
+	dir := setupSavedQueryProject(t)
+
+	out, err := runQueryCmd(t, "--list-saved", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{
+		"@recipes-using — Recipes that need a material",
+		"param material (required)",
+		"param kind (default: item)",
+		`deco query --block-type recipe --block-where 'params.material in block.materials' --format csv --columns node,output`,
+		"deco query --block-type recipe --field output=Axe",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "@drafts") > strings.Index(out, "@tools") {
+		t.Errorf("Expected queries sorted by name:\n%s", out)
+	}
+
+	out, err = runQueryCmd(t, "--list-saved", setupCraftingProject(t))
+	if err != nil || !strings.Contains(out, "No saved queries defined") {
+		t.Errorf("Expected empty listing, got %q (%v)", out, err)
+	}
+}
+
+func TestValidateSavedQuery(t *testing.T) {
+	cfg := config.Config{CustomBlockTypes: map[string]config.BlockTypeConfig{
+		"recipe": {Fields: map[string]config.FieldDef{"output": {}, "materials": {}}},
+		"note":   {},
+	}}
+
+	tests := []struct {
+		name string
+		sq   config.SavedQuery
+		want string // empty for valid
+	}{
+		{"valid", config.SavedQuery{BlockType: "recipe", Fields: map[string]string{"output": "Axe"}, Follow: "materials"}, ""},
+		{"placeholder", config.SavedQuery{Params: map[string]string{"s": ""}, Status: "${s}"}, ""},
+		{"undeclared fields", config.SavedQuery{BlockType: "note", Fields: map[string]string{"anything": "x"}}, ""},
+		{"unknown block type", config.SavedQuery{BlockType: "recipie"}, `unknown block-type "recipie"`},
+		{"unknown field", config.SavedQuery{BlockType: "recipe", Fields: map[string]string{"outptu": "Axe"}}, `Did you mean "output"?`},
+		{"unknown follow field", config.SavedQuery{BlockType: "recipe", Follow: "inputs"}, `unknown field "inputs"`},
+		{"follow without block type", config.SavedQuery{Follow: "materials"}, "follow requires block_type"},
+		{"unknown status", config.SavedQuery{Status: "aproved"}, `Did you mean "approved"?`},
+		{"undeclared param", config.SavedQuery{Kind: "${kind}"}, `undeclared parameter "kind"`},
+		{"bad format", config.SavedQuery{Format: "xml"}, "unknown format: xml"},
+		{"bad where", config.SavedQuery{Where: "kind =="}, "where:"},
+		{"where param", config.SavedQuery{Params: map[string]string{"k": "item"}, Where: `kind == params.k && params["k"] != ""`}, ""},
+		{"where placeholder", config.SavedQuery{Params: map[string]string{"k": "item"}, Where: `kind == "${k}"`}, "expressions read parameters as params.k"},
+		{"undeclared where param", config.SavedQuery{Where: "kind == params.k"}, `undeclared parameter "k"`},
+		{"block field named params", config.SavedQuery{BlockWhere: `block.params.size > 1`}, ""},
+		{"grouped", config.SavedQuery{BlockType: "recipe", GroupBy: []string{"node"}, Agg: []string{"count", "max:output"}}, ""},
+		{"unknown group field", config.SavedQuery{BlockType: "recipe", GroupBy: []string{"outptu"}}, `Did you mean "output"?`},
+		{"bad agg", config.SavedQuery{BlockType: "recipe", Agg: []string{"sum"}}, "agg: aggregate"},
+		{"agg without blocks", config.SavedQuery{Agg: []string{"count"}}, "need block_type or block_where"},
+	}
+	for _, tt := range tests {
+		err := validateSavedQuery("q", tt.sq, cfg)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.HasPrefix(err.Error(), "saved query @q: ") {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestValidateCommand_SavedQueryScopes(t *testing.T) {
+	dir := setupSavedQueryProject(t)
+	appendFile := func(name, text string) {
+		t.Helper()
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Both nodes carry the constraint; only the node @tools selects should fail it
+	constraint := `constraints:
+  - expr: version > 5
+    message: Tool recipes need review
+    scope: "@tools"
+`
+	appendFile(".deco/nodes/items/basics.yaml", constraint)
+	appendFile(".deco/nodes/items/tools.yaml", constraint+`  - expr: "true"
+    message: Unknown scope
+    scope: "@nope"
+`)
+	appendFile(".deco/config.yaml", `  broken:
+    block_type: recipe
+    fields:
+      outptu: Axe
+`)
+	cfg, err := config.NewYAMLRepository(dir).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendFile(".deco/config.yaml", "schema_version: "+migrations.ComputeSchemaHash(cfg)+"\n")
+
+	out := captureStdout(t, func() {
+		cmd := NewValidateCommand()
+		cmd.SetArgs([]string{dir})
+		err = cmd.Execute()
+	})
+	if err == nil {
+		t.Fatal("Expected validation to fail")
+	}
+	if strings.Count(out, "Tool recipes need review") != 1 || !strings.Contains(out, "items/tools.yaml") {
+		t.Errorf("Expected the constraint to fail on items/tools only:\n%s", out)
+	}
+	if !strings.Contains(out, `Constraint scope "@nope" does not name a usable saved query`) {
+		t.Errorf("Expected unknown scope error:\n%s", out)
+	}
+	if !strings.Contains(out, "Invalid saved query @broken") || !strings.Contains(out, `unknown field "outptu"`) {
+		t.Errorf("Expected invalid saved query error:\n%s", out)
+	}
+}
+
+func TestExportCommand_SavedQuery(t *testing.T) {
+	t.Chdir(setupSavedQueryProject(t))
+
+	var err error
+	out := captureStdout(t, func() {
+		cmd := NewExportCommand()
+		cmd.SetArgs([]string{"--compact", "--query", "@recipes-using", "--param", "material=Planks"})
+		err = cmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "items/tools") || strings.Contains(out, "items/basics") {
+		t.Errorf("Expected only items/tools:\n%s", out)
+	}
+
+	captureStdout(t, func() {
+		cmd := NewExportCommand()
+		cmd.SetArgs([]string{"--param", "material=Planks"})
+		err = cmd.Execute()
+	})
+	if err == nil || !strings.Contains(err.Error(), "--param requires --query") {
+		t.Errorf("Expected --param without --query to fail, got %v", err)
+	}
+}
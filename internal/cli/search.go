@@ -0,0 +1,269 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/cli/style"
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/query"
+	"github.com/Toernblom/deco/internal/services/search"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/spf13/cobra"
+)
+
+type searchFlags struct {
+	limit     int
+	format    string
+	kind      string
+	status    string
+	tag       string
+	rebuild   bool
+	targetDir string
+}
+
+// NewSearchCommand creates the search subcommand
+func NewSearchCommand() *cobra.Command {
+	flags := &searchFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "search <query> [directory]",
+		Short: "Full-text search across all node content",
+		Long: `Search everything written in the project, ranked by relevance (BM25).
+
+Unlike 'deco query', which matches titles and summaries, search covers
+content blocks (rules, params, mechanics, tables...), issues, contracts,
+glossary entries, custom fields, LLM context and referenced doc files.
+Each hit shows where it was found and a snippet of the matching text.
+
+Query syntax:
+  word                 Match the word anywhere (case-insensitive)
+  "two words"          Match the exact phrase
+  field:word           Match only in one kind of content
+  field:"two words"    Phrase restricted to one kind of content
+
+All words and phrases must match. A piece of content matching them all is
+a hit of its own; a node matching them only across several pieces (say the
+title and a rule) is listed once with each location, ranked lower.
+Fields: title, summary, tag, issue, contract, glossary, doc, custom,
+constraint, llm_context, or any block type (rule, param, mechanic, ...).
+
+The index is stored in .deco/cache/search and updated incrementally:
+only nodes whose content hash changed are re-indexed.
+
+Examples:
+  deco search collision
+  deco search '"tick rate"'
+  deco search 'rule:wall issue:balance'
+  deco search 'glossary:tick' --kind system
+  deco search armor --limit 5 --format json
+  deco search armor --rebuild`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				flags.targetDir = args[1]
+			} else {
+				flags.targetDir = "."
+			}
+			return runSearch(cmd.OutOrStdout(), args[0], flags)
+		},
+	}
+
+	cmd.Flags().IntVarP(&flags.limit, "limit", "n", 20, "Maximum number of hits to show (0 for all)")
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "text", "Output format (text, json)")
+	cmd.Flags().StringVarP(&flags.kind, "kind", "k", "", "Only search nodes of this kind")
+	cmd.Flags().StringVarP(&flags.status, "status", "s", "", "Only search nodes with this status")
+	cmd.Flags().StringVarP(&flags.tag, "tag", "t", "", "Only search nodes with this tag")
+	cmd.Flags().BoolVar(&flags.rebuild, "rebuild", false, "Discard the cached index and rebuild it")
+
+	return cmd
+}
+
+// searchHitJSON is a hit enriched with node metadata for JSON output.
+type searchHitJSON struct {
+	search.Hit
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+}
+
+type searchResultJSON struct {
+	Query string          `json:"query"`
+	Total int             `json:"total"`
+	Hits  []searchHitJSON `json:"hits"`
+}
+
+func runSearch(w io.Writer, q string, flags *searchFlags) error {
+	switch flags.format {
+	case "text", "json":
+	default:
+		return fmt.Errorf("unknown format: %s (supported: text, json)", flags.format)
+	}
+	if flags.limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	parsed, err := search.ParseQuery(q)
+	if err != nil {
+		return err
+	}
+
+	configRepo := config.NewYAMLRepository(flags.targetDir)
+	cfg, err := configRepo.Load()
+	if err != nil {
+		return fmt.Errorf(".deco directory not found or invalid: %w", err)
+	}
+
+	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
+	nodes, err := nodeRepo.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load nodes: %w", err)
+	}
+
+	if flags.status != "" {
+		if err := validateStatus(flags.status); err != nil {
+			return err
+		}
+	}
+	if flags.kind != "" {
+		if err := validateKind(flags.kind, nodes); err != nil {
+			return err
+		}
+	}
+
+	ix, err := loadSearchIndex(flags.targetDir, nodes, flags.rebuild)
+	if err != nil {
+		return err
+	}
+
+	criteria := query.FilterCriteria{}
+	if flags.kind != "" {
+		criteria.Kind = &flags.kind
+	}
+	if flags.status != "" {
+		criteria.Status = &flags.status
+	}
+	if flags.tag != "" {
+		criteria.Tags = []string{flags.tag}
+	}
+	byID := make(map[string]domain.Node)
+	for _, n := range query.New().Filter(nodes, criteria) {
+		byID[n.ID] = n
+	}
+
+	var hits []search.Hit
+	for _, h := range ix.Search(parsed) {
+		if _, ok := byID[h.NodeID]; ok {
+			hits = append(hits, h)
+		}
+	}
+	total := len(hits)
+	if flags.limit > 0 && len(hits) > flags.limit {
+		hits = hits[:flags.limit]
+	}
+
+	if flags.format == "json" {
+		out := searchResultJSON{Query: q, Total: total, Hits: []searchHitJSON{}}
+		for _, h := range hits {
+			n := byID[h.NodeID]
+			out.Hits = append(out.Hits, searchHitJSON{Hit: h, Title: n.Title, Kind: n.Kind})
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(out); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return nil
+	}
+
+	printSearchHits(w, hits, total, byID)
+	return nil
+}
+
+// loadSearchIndex loads the cached index, refreshes it against the current
+// nodes using content hashes, and saves it back when anything changed.
+func loadSearchIndex(dir string, nodes []domain.Node, rebuild bool) (*search.Index, error) {
+	cacheDir := config.ResolveCachePath(dir, "search")
+	ix := search.New()
+	if !rebuild {
+		ix = search.Load(cacheDir)
+	}
+
+	hashes := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		hashes[n.ID] = ComputeContentHashWithDir(n, dir)
+	}
+	ix.Update(nodes, hashes, dir)
+
+	if ix.Changed() || rebuild {
+		if err := ix.Save(cacheDir); err != nil {
+			return nil, err
+		}
+		ignoreCacheDir(dir)
+	}
+	return ix, nil
+}
+
+// ignoreCacheDir keeps the cache directory out of version control.
+func ignoreCacheDir(dir string) {
+	path := filepath.Join(dir, config.CachePath, ".gitignore")
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+	_ = os.WriteFile(path, []byte("*\n"), 0644)
+}
+
+func printSearchHits(w io.Writer, hits []search.Hit, total int, nodes map[string]domain.Node) {
+	if total == 0 {
+		fmt.Fprintln(w, "No matches found")
+		return
+	}
+
+	for _, h := range hits {
+		n := nodes[h.NodeID]
+		where := h.Field
+		if h.Location != "" {
+			where = h.Location
+		}
+		fmt.Fprintf(w, "%s %s %s\n", style.Header.Sprint(h.NodeID), style.Muted.Sprint("—"), n.Title)
+		fmt.Fprintf(w, "  %s %s\n", style.Info.Sprintf("[%s]", where), style.Muted.Sprintf("score %.2f", h.Score))
+		fmt.Fprintf(w, "  %s\n\n", highlightSnippet(h))
+	}
+
+	if len(hits) < total {
+		fmt.Fprintf(w, "Showing %d of %d match(es)\n", len(hits), total)
+	} else {
+		fmt.Fprintf(w, "Found %d match(es)\n", total)
+	}
+}
+
+// highlightSnippet emphasizes the matched words in a hit's snippet.
+func highlightSnippet(h search.Hit) string {
+	var b strings.Builder
+	last := 0
+	for _, r := range h.Highlights {
+		b.WriteString(h.Snippet[last:r[0]])
+		b.WriteString(style.Warning.Sprint(h.Snippet[r[0]:r[1]]))
+		last = r[1]
+	}
+	b.WriteString(h.Snippet[last:])
+	return b.String()
+}
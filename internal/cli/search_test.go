@@ -0,0 +1,164 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runSearchCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	cmd := NewSearchCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func TestSearchCommand_FindsBlockContent(t *testing.T) {
+	dir := t.TempDir()
+	setupProjectWithContent(t, dir)
+
+	out, err := runSearchCmd(t, "reaches", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "systems/core") || !strings.Contains(out, "[Game Flow > rule game_over]") {
+		t.Errorf("Expected rule hit with location, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Found 1 match(es)") {
+		t.Errorf("Expected match count, got:\n%s", out)
+	}
+}
+
+func TestSearchCommand_FieldPrefixAndPhrase(t *testing.T) {
+	dir := t.TempDir()
+	setupProjectWithContent(t, dir)
+
+	out, err := runSearchCmd(t, `table:"fire projectile"`, dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "[Controls > table controls]") {
+		t.Errorf("Expected table hit, got:\n%s", out)
+	}
+
+	out, err = runSearchCmd(t, `rule:projectile`, dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "No matches found") {
+		t.Errorf("Expected no rule match, got:\n%s", out)
+	}
+}
+
+func TestSearchCommand_JSON(t *testing.T) {
+	dir := t.TempDir()
+	setupProjectWithContent(t, dir)
+
+	out, err := runSearchCmd(t, "player", dir, "--format", "json", "--limit", "1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var result searchResultJSON
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if result.Total < 2 || len(result.Hits) != 1 {
+		t.Errorf("Expected several matches limited to 1 hit, got total=%d hits=%d", result.Total, len(result.Hits))
+	}
+	if h := result.Hits[0]; h.NodeID != "systems/core" || h.Title != "Core Gameplay" || h.Snippet == "" {
+		t.Errorf("Unexpected hit: %+v", h)
+	}
+}
+
+func TestSearchCommand_KindFilter(t *testing.T) {
+	dir := setupDecoProject(t)
+	createTestNode(t, dir, "a")
+
+	out, err := runSearchCmd(t, "test", dir, "--kind", "test")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "a —") {
+		t.Errorf("Expected node a, got:\n%s", out)
+	}
+
+	if _, err := runSearchCmd(t, "test", dir, "--kind", "missing"); err == nil {
+		t.Error("Expected error for unknown kind")
+	}
+}
+
+func TestSearchCommand_IndexCache(t *testing.T) {
+	dir := t.TempDir()
+	setupProjectWithContent(t, dir)
+
+	if _, err := runSearchCmd(t, "alien", dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".deco", "cache", "search", "index.json")); err != nil {
+		t.Errorf("Expected index to be written: %v", err)
+	}
+	ignore, err := os.ReadFile(filepath.Join(dir, ".deco", "cache", ".gitignore"))
+	if err != nil || strings.TrimSpace(string(ignore)) != "*" {
+		t.Errorf("Expected cache to be git-ignored, got %q (%v)", ignore, err)
+	}
+
+	// Edits are picked up through the content hash
+	nodePath := filepath.Join(dir, ".deco", "nodes", "systems", "core.yaml")
+	data, err := os.ReadFile(nodePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "any alien reaches", "any invader reaches", 1))
+	if err := os.WriteFile(nodePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runSearchCmd(t, "invader", dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(out, "Found 1 match(es)") {
+		t.Errorf("Expected edited content to be indexed, got:\n%s", out)
+	}
+}
+
+func TestSearchCommand_Errors(t *testing.T) {
+	dir := t.TempDir()
+	setupProjectWithContent(t, dir)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"x", dir, "--format", "xml"}, "unknown format"},
+		{[]string{`""`, dir}, "empty search query"},
+		{[]string{"x", dir, "--limit", "-1"}, "--limit"},
+		{[]string{"x", t.TempDir()}, ".deco directory not found"},
+	}
+	for _, tt := range tests {
+		_, err := runSearchCmd(t, tt.args...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
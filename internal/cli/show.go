@@ -25,7 +25,6 @@ import (
 	"github.com/Toernblom/deco/internal/domain"
 	"github.com/Toernblom/deco/internal/services/graph"
 	"github.com/Toernblom/deco/internal/storage/config"
-	"github.com/Toernblom/deco/internal/storage/node"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -33,6 +32,8 @@ import (
 type showFlags struct {
 	jsonOutput bool
 	full       bool
+	asOf       string
+	version    int
 	targetDir  string
 }
 
@@ -52,10 +53,17 @@ func NewShowCommand() *cobra.Command {
 
 Output can be formatted as human-readable text (default) or JSON.
 
+--version N shows an earlier version from the snapshots recorded in
+history; reverse references are those of the current project.
+
+` + asOfHelp + `
+
 Examples:
   deco show sword-001
   deco show character-hero --json
-  deco show quest-001 /path/to/project`,
+  deco show quest-001 /path/to/project
+  deco show sword-001 --as-of 1w
+  deco show sword-001 --version 2`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			nodeID := args[0]
@@ -70,11 +78,17 @@ Examples:
 
 	cmd.Flags().BoolVarP(&flags.jsonOutput, "json", "j", false, "Output as JSON")
 	cmd.Flags().BoolVar(&flags.full, "full", false, "Expand content blocks inline")
+	addAsOfFlag(cmd, &flags.asOf)
+	cmd.Flags().IntVar(&flags.version, "version", 0, "Show an earlier version of the node from history snapshots")
 
 	return cmd
 }
 
 func runShow(nodeID string, flags *showFlags) error {
+	if flags.version != 0 && flags.asOf != "" {
+		return fmt.Errorf("--version and --as-of cannot be combined")
+	}
+
 	// Load config to verify project exists
 	configRepo := config.NewYAMLRepository(flags.targetDir)
 	cfg, err := configRepo.Load()
@@ -83,10 +97,9 @@ func runShow(nodeID string, flags *showFlags) error {
 	}
 
 	// Load all nodes (needed for reverse references)
-	nodeRepo := node.NewYAMLRepository(config.ResolveNodesPath(cfg, flags.targetDir))
-	nodes, err := nodeRepo.LoadAll()
+	nodes, err := loadNodes(cfg, flags.targetDir, flags.asOf)
 	if err != nil {
-		return fmt.Errorf("failed to load nodes: %w", err)
+		return err
 	}
 
 	// Find the requested node
@@ -98,7 +111,18 @@ func runShow(nodeID string, flags *showFlags) error {
 		}
 	}
 
+	if flags.version != 0 {
+		versioned, err := loadVersion(cfg, flags.targetDir, nodeID, flags.version, targetNode)
+		if err != nil {
+			return err
+		}
+		targetNode = &versioned
+	}
+
 	if targetNode == nil {
+		if flags.asOf != "" {
+			return fmt.Errorf("node '%s' not found as of %s", nodeID, flags.asOf)
+		}
 		return fmt.Errorf("node '%s' not found", nodeID)
 	}
 
@@ -16,19 +16,23 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 
 	"github.com/Toernblom/deco/internal/cli/style"
 	"github.com/Toernblom/deco/internal/domain"
-	"github.com/Toernblom/deco/internal/services/validator"
+	"github.com/Toernblom/deco/internal/services/graph"
 	"github.com/Toernblom/deco/internal/storage/config"
 	"github.com/Toernblom/deco/internal/storage/node"
 	"github.com/spf13/cobra"
 )
 
 type statsFlags struct {
+	format    string
+	top       int
 	targetDir string
 }
 
@@ -45,10 +49,18 @@ func NewStatsCommand() *cobra.Command {
   - Open issues by severity
   - Reference health (dangling refs)
   - Constraint violations
+  - Graph structure: orphans, fan-in/fan-out leaders, connected
+    components, cycles, the longest dependency chain, bottleneck nodes
+    (betweenness centrality) and a kind-to-kind edge matrix
+
+Graph metrics use every reference between nodes: refs.uses, refs.related,
+contract @refs and block cross-references.
 
 Examples:
   deco stats
-  deco stats /path/to/project`,
+  deco stats /path/to/project
+  deco stats --top 10
+  deco stats --format json`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
@@ -56,14 +68,21 @@ Examples:
 			} else {
 				flags.targetDir = "."
 			}
-			return runStats(flags)
+			return runStats(cmd.OutOrStdout(), flags)
 		},
 	}
 
+	cmd.Flags().StringVarP(&flags.format, "format", "f", "text", "Output format (text, json)")
+	cmd.Flags().IntVar(&flags.top, "top", 5, "Number of entries in leader lists (0 = all)")
+
 	return cmd
 }
 
-func runStats(flags *statsFlags) error {
+func runStats(w io.Writer, flags *statsFlags) error {
+	if flags.format != "text" && flags.format != "json" {
+		return fmt.Errorf("unknown format: %s (supported: text, json)", flags.format)
+	}
+
 	// Load config to verify project exists
 	configRepo := config.NewYAMLRepository(flags.targetDir)
 	cfg, err := configRepo.Load()
@@ -78,21 +97,31 @@ func runStats(flags *statsFlags) error {
 		return fmt.Errorf("failed to load nodes: %w", err)
 	}
 
-	if len(nodes) == 0 {
+	if len(nodes) == 0 && flags.format == "text" {
 		if !globalConfig.Quiet {
-			fmt.Println("No nodes found in project")
+			fmt.Fprintln(w, "No nodes found in project")
 		}
 		return nil
 	}
 
 	// Gather statistics
-	stats := gatherStats(nodes, cfg)
+	stats, err := gatherStats(nodes, cfg, flags.targetDir, flags.top)
+	if err != nil {
+		return err
+	}
 
 	// Print statistics
-	if globalConfig.Quiet {
-		printStatsQuiet(stats)
-	} else {
-		printStats(stats)
+	switch {
+	case flags.format == "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(stats.toJSON()); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+	case globalConfig.Quiet:
+		printStatsQuiet(w, stats)
+	default:
+		printStats(w, stats)
 	}
 
 	return nil
@@ -107,9 +136,37 @@ type projectStats struct {
 	danglingRefs          int
 	totalValidationErrors int
 	validationByCategory  map[string]int
+	graph                 graph.Analytics
+}
+
+// statsJSON is the JSON form of projectStats.
+type statsJSON struct {
+	TotalNodes           int             `json:"total_nodes"`
+	NodesByKind          map[string]int  `json:"nodes_by_kind"`
+	NodesByStatus        map[string]int  `json:"nodes_by_status"`
+	OpenIssues           int             `json:"open_issues"`
+	OpenIssuesBySeverity map[string]int  `json:"open_issues_by_severity"`
+	DanglingRefs         int             `json:"dangling_refs"`
+	ValidationErrors     int             `json:"validation_errors"`
+	ValidationByCategory map[string]int  `json:"validation_by_category"`
+	Graph                graph.Analytics `json:"graph"`
+}
+
+func (s projectStats) toJSON() statsJSON {
+	return statsJSON{
+		TotalNodes:           s.totalNodes,
+		NodesByKind:          s.nodesByKind,
+		NodesByStatus:        s.nodesByStatus,
+		OpenIssues:           s.totalOpenIssues,
+		OpenIssuesBySeverity: s.openIssuesBySev,
+		DanglingRefs:         s.danglingRefs,
+		ValidationErrors:     s.totalValidationErrors,
+		ValidationByCategory: s.validationByCategory,
+		Graph:                s.graph,
+	}
 }
 
-func gatherStats(nodes []domain.Node, cfg config.Config) projectStats {
+func gatherStats(nodes []domain.Node, cfg config.Config, dir string, top int) (projectStats, error) {
 	stats := projectStats{
 		totalNodes:           len(nodes),
 		nodesByKind:          make(map[string]int),
@@ -154,12 +211,7 @@ func gatherStats(nodes []domain.Node, cfg config.Config) projectStats {
 	}
 
 	// Run full validation to count all errors
-	orchestrator := validator.NewOrchestratorWithFullConfig(
-		cfg.RequiredApprovals,
-		cfg.CustomBlockTypes,
-		cfg.SchemaRules,
-	)
-	collector := orchestrator.ValidateAll(nodes)
+	collector := validateProject(cfg, dir, nodes)
 	registry := domain.NewErrorCodeRegistry()
 	for _, err := range collector.Errors() {
 		stats.totalValidationErrors++
@@ -170,82 +222,189 @@ func gatherStats(nodes []domain.Node, cfg config.Config) projectStats {
 		}
 	}
 
-	return stats
+	// Graph structure over every kind of reference
+	builder := graph.NewBuilder()
+	g, err := builder.Build(nodes)
+	if err != nil {
+		return stats, fmt.Errorf("failed to build graph: %w", err)
+	}
+	stats.graph = builder.Analyze(g, builder.BuildEdges(g, cfg.CustomBlockTypes), top)
+
+	return stats, nil
 }
 
-func printStats(stats projectStats) {
-	fmt.Println(style.Header.Sprint("PROJECT STATISTICS"))
-	fmt.Println(style.Muted.Sprint(strings.Repeat("═", 50)))
+func printStats(w io.Writer, stats projectStats) {
+	fmt.Fprintln(w, style.Header.Sprint("PROJECT STATISTICS"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("═", 50)))
 
 	// Total nodes
-	fmt.Printf("\n%s %d\n", style.Muted.Sprint("Total nodes:"), stats.totalNodes)
+	fmt.Fprintf(w, "\n%s %d\n", style.Muted.Sprint("Total nodes:"), stats.totalNodes)
 
 	// Nodes by kind
-	fmt.Printf("\n%s\n", style.Header.Sprint("NODES BY KIND"))
-	fmt.Println(style.Muted.Sprint(strings.Repeat("─", 30)))
-	printSortedMap(stats.nodesByKind)
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("NODES BY KIND"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
+	printSortedMap(w, stats.nodesByKind)
 
 	// Nodes by status
-	fmt.Printf("\n%s\n", style.Header.Sprint("NODES BY STATUS"))
-	fmt.Println(style.Muted.Sprint(strings.Repeat("─", 30)))
-	printSortedMapWithStatus(stats.nodesByStatus)
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("NODES BY STATUS"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
+	printSortedMapWithStatus(w, stats.nodesByStatus)
 
 	// Open issues by severity
-	fmt.Printf("\n%s\n", style.Header.Sprint("OPEN ISSUES BY SEVERITY"))
-	fmt.Println(style.Muted.Sprint(strings.Repeat("─", 30)))
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("OPEN ISSUES BY SEVERITY"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
 	if stats.totalOpenIssues == 0 {
-		fmt.Printf("  %s\n", style.Success.Sprint("No open issues"))
+		fmt.Fprintf(w, "  %s\n", style.Success.Sprint("No open issues"))
 	} else {
 		// Print in severity order
 		severityOrder := []string{"critical", "high", "medium", "low"}
 		for _, sev := range severityOrder {
 			if count, ok := stats.openIssuesBySev[sev]; ok && count > 0 {
 				sevColor := style.SeverityColor(sev)
-				fmt.Printf("  %s %d\n", sevColor.Sprintf("%-12s", sev), count)
+				fmt.Fprintf(w, "  %s %d\n", sevColor.Sprintf("%-12s", sev), count)
 			}
 		}
-		fmt.Printf("  %-12s %d\n", style.Muted.Sprint("Total"), stats.totalOpenIssues)
+		fmt.Fprintf(w, "  %-12s %d\n", style.Muted.Sprint("Total"), stats.totalOpenIssues)
 	}
 
 	// Reference health
-	fmt.Printf("\n%s\n", style.Header.Sprint("REFERENCE HEALTH"))
-	fmt.Println(style.Muted.Sprint(strings.Repeat("─", 30)))
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("REFERENCE HEALTH"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
 	if stats.danglingRefs == 0 {
-		fmt.Printf("  %s\n", style.Success.Sprint("All references valid"))
+		fmt.Fprintf(w, "  %s\n", style.Success.Sprint("All references valid"))
 	} else {
-		fmt.Printf("  %s %d\n", style.Warning.Sprint("Dangling references:"), stats.danglingRefs)
+		fmt.Fprintf(w, "  %s %d\n", style.Warning.Sprint("Dangling references:"), stats.danglingRefs)
 	}
 
 	// Validation health
-	fmt.Printf("\n%s\n", style.Header.Sprint("VALIDATION HEALTH"))
-	fmt.Println(style.Muted.Sprint(strings.Repeat("─", 30)))
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("VALIDATION HEALTH"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
 	if stats.totalValidationErrors == 0 {
-		fmt.Printf("  %s\n", style.Success.Sprint("No errors"))
+		fmt.Fprintf(w, "  %s\n", style.Success.Sprint("No errors"))
 	} else {
-		fmt.Printf("  %s %d\n", style.Error.Sprint("Errors:"), stats.totalValidationErrors)
+		fmt.Fprintf(w, "  %s %d\n", style.Error.Sprint("Errors:"), stats.totalValidationErrors)
 		categoryOrder := []string{"schema", "refs", "validation"}
 		for _, cat := range categoryOrder {
 			if count, ok := stats.validationByCategory[cat]; ok && count > 0 {
-				fmt.Printf("    %-12s %d\n", cat+":", count)
+				fmt.Fprintf(w, "    %-12s %d\n", cat+":", count)
 			}
 		}
 		// Print any extra categories not in the standard order
 		for cat, count := range stats.validationByCategory {
 			if cat != "schema" && cat != "refs" && cat != "validation" && count > 0 {
-				fmt.Printf("    %-12s %d\n", cat+":", count)
+				fmt.Fprintf(w, "    %-12s %d\n", cat+":", count)
 			}
 		}
 	}
+
+	printGraphStats(w, stats.graph)
+}
+
+func printGraphStats(w io.Writer, a graph.Analytics) {
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("GRAPH STRUCTURE"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
+	fmt.Fprintf(w, "  %-20s %d\n", "Edges:", a.Edges)
+	fmt.Fprintf(w, "  %-20s %d\n", "Components:", len(a.WeakComponents))
+	if len(a.WeakComponents) > 0 {
+		fmt.Fprintf(w, "  %-20s %d node(s)\n", "Largest component:", len(a.WeakComponents[0]))
+	}
+	if len(a.Cycles) == 0 {
+		fmt.Fprintf(w, "  %-20s %s\n", "Cycles:", style.Success.Sprint("none"))
+	} else {
+		fmt.Fprintf(w, "  %-20s %s\n", "Cycles:", style.Warning.Sprint(len(a.Cycles)))
+		for _, c := range a.Cycles {
+			fmt.Fprintf(w, "    %s\n", strings.Join(c, ", "))
+		}
+	}
+	if len(a.LongestChain) == 0 {
+		fmt.Fprintf(w, "  %-20s %s\n", "Longest chain:", style.Muted.Sprint("(none)"))
+	} else {
+		fmt.Fprintf(w, "  %-20s %d hop(s): %s\n", "Longest chain:", len(a.LongestChain)-1, strings.Join(a.LongestChain, " → "))
+	}
+	if len(a.Orphans) == 0 {
+		fmt.Fprintf(w, "  %-20s %s\n", "Orphans:", style.Success.Sprint("none"))
+	} else {
+		fmt.Fprintf(w, "  %-20s %s\n", "Orphans:", style.Warning.Sprint(len(a.Orphans)))
+		for _, id := range a.Orphans {
+			fmt.Fprintf(w, "    %s %s\n", style.SymbolBullet, id)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("FAN-IN LEADERS"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
+	printDegrees(w, a.FanIn, func(d graph.Degree) int { return d.In })
+
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("FAN-OUT LEADERS"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
+	printDegrees(w, a.FanOut, func(d graph.Degree) int { return d.Out })
+
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("BOTTLENECKS (BETWEENNESS)"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
+	if len(a.Bottlenecks) == 0 {
+		fmt.Fprintf(w, "  %s\n", style.Muted.Sprint("(none)"))
+	}
+	for _, c := range a.Bottlenecks {
+		fmt.Fprintf(w, "  %-30s %.3f\n", c.ID, c.Score)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", style.Header.Sprint("EDGES BY KIND (FROM → TO)"))
+	fmt.Fprintln(w, style.Muted.Sprint(strings.Repeat("─", 30)))
+	printKindMatrix(w, a.KindMatrix)
+}
+
+func printDegrees(w io.Writer, degrees []graph.Degree, metric func(graph.Degree) int) {
+	if len(degrees) == 0 {
+		fmt.Fprintf(w, "  %s\n", style.Muted.Sprint("(none)"))
+		return
+	}
+	for _, d := range degrees {
+		fmt.Fprintf(w, "  %-30s %d\n", d.ID, metric(d))
+	}
+}
+
+func printKindMatrix(w io.Writer, matrix map[string]map[string]int) {
+	if len(matrix) == 0 {
+		fmt.Fprintf(w, "  %s\n", style.Muted.Sprint("(none)"))
+		return
+	}
+
+	var from []string
+	toSet := make(map[string]bool)
+	for k, row := range matrix {
+		from = append(from, k)
+		for t := range row {
+			toSet[t] = true
+		}
+	}
+	var to []string
+	for t := range toSet {
+		to = append(to, t)
+	}
+	sort.Strings(from)
+	sort.Strings(to)
+
+	header := fmt.Sprintf("  %-12s", "")
+	for _, t := range to {
+		header += fmt.Sprintf(" %12s", t)
+	}
+	fmt.Fprintln(w, style.Muted.Sprint(header))
+	for _, f := range from {
+		line := fmt.Sprintf("  %-12s", f)
+		for _, t := range to {
+			line += fmt.Sprintf(" %12d", matrix[f][t])
+		}
+		fmt.Fprintln(w, line)
+	}
 }
 
-func printStatsQuiet(stats projectStats) {
-	fmt.Printf("nodes=%d issues=%d dangling_refs=%d errors=%d\n",
+func printStatsQuiet(w io.Writer, stats projectStats) {
+	fmt.Fprintf(w, "nodes=%d issues=%d dangling_refs=%d errors=%d\n",
 		stats.totalNodes, stats.totalOpenIssues, stats.danglingRefs, stats.totalValidationErrors)
 }
 
-func printSortedMap(m map[string]int) {
+func printSortedMap(w io.Writer, m map[string]int) {
 	if len(m) == 0 {
-		fmt.Printf("  %s\n", style.Muted.Sprint("(none)"))
+		fmt.Fprintf(w, "  %s\n", style.Muted.Sprint("(none)"))
 		return
 	}
 
@@ -257,13 +416,13 @@ func printSortedMap(m map[string]int) {
 	sort.Strings(keys)
 
 	for _, k := range keys {
-		fmt.Printf("  %-12s %d\n", k, m[k])
+		fmt.Fprintf(w, "  %-12s %d\n", k, m[k])
 	}
 }
 
-func printSortedMapWithStatus(m map[string]int) {
+func printSortedMapWithStatus(w io.Writer, m map[string]int) {
 	if len(m) == 0 {
-		fmt.Printf("  %s\n", style.Muted.Sprint("(none)"))
+		fmt.Fprintf(w, "  %s\n", style.Muted.Sprint("(none)"))
 		return
 	}
 
@@ -279,6 +438,6 @@ func printSortedMapWithStatus(m map[string]int) {
 		if c := style.StatusColor(k); c != nil {
 			label = c.Sprint(label)
 		}
-		fmt.Printf("  %s %d\n", label, m[k])
+		fmt.Fprintf(w, "  %s %d\n", label, m[k])
 	}
 }
@@ -16,10 +16,15 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/node"
 )
 
 func TestStatsCommand_Structure(t *testing.T) {
@@ -147,6 +152,26 @@ func TestStatsCommand_QuietMode(t *testing.T) {
 		}
 	})
 
+	t.Run("quiet line keeps its format", func(t *testing.T) {
+		dir := setupDecoProject(t)
+		createTestNode(t, dir, "lonely")
+
+		oldQuiet := globalConfig.Quiet
+		globalConfig.Quiet = true
+		defer func() { globalConfig.Quiet = oldQuiet }()
+
+		var buf bytes.Buffer
+		cmd := NewStatsCommand()
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{dir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := buf.String(); got != "nodes=1 issues=0 dangling_refs=0 errors=0\n" {
+			t.Errorf("Unexpected quiet output %q", got)
+		}
+	})
+
 	t.Run("quiet mode on empty project suppresses output", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		setupEmptyProject(t, tmpDir)
@@ -309,3 +334,87 @@ constraints:
 		t.Fatalf("Failed to create node: %v", err)
 	}
 }
+
+func TestStatsCommand_GraphMetrics(t *testing.T) {
+	dir := setupDecoProject(t)
+	createTestNode(t, dir, "c")
+	createTestNodeWithRefs(t, dir, "b", []string{"c"})
+	createTestNodeWithRefs(t, dir, "a", []string{"b"})
+	createTestNode(t, dir, "lonely")
+
+	t.Run("text output", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := NewStatsCommand()
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{dir})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{"GRAPH STRUCTURE", "a → b → c", "lonely", "FAN-IN LEADERS", "BOTTLENECKS"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := NewStatsCommand()
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{dir, "--format", "json"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var result statsJSON
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+		}
+		if result.TotalNodes != 4 || result.Graph.Edges != 2 {
+			t.Errorf("Unexpected counts: %+v", result)
+		}
+		if len(result.Graph.Orphans) != 1 || result.Graph.Orphans[0] != "lonely" {
+			t.Errorf("Expected lonely as orphan, got %v", result.Graph.Orphans)
+		}
+		if len(result.Graph.Bottlenecks) != 1 || result.Graph.Bottlenecks[0].ID != "b" {
+			t.Errorf("Expected b as bottleneck, got %+v", result.Graph.Bottlenecks)
+		}
+	})
+
+	t.Run("rejects unknown format", func(t *testing.T) {
+		cmd := NewStatsCommand()
+		cmd.SetArgs([]string{dir, "--format", "xml"})
+		if err := cmd.Execute(); err == nil {
+			t.Error("Expected error for unknown format")
+		}
+	})
+}
+
+func TestStatsCommand_CountsDocLinkErrors(t *testing.T) {
+	dir := setupDecoProject(t)
+	if err := os.WriteFile(filepath.Join(dir, "auth.md"), []byte("# Auth\n\n## Token Rotation\nTokens rotate.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	n := domain.Node{
+		ID: "systems/auth", Kind: "system", Version: 1, Status: "draft", Title: "Auth",
+		Docs: []domain.DocRef{{Path: "auth.md#token-rotaton"}},
+	}
+	if err := node.NewYAMLRepository(filepath.Join(dir, ".deco", "nodes")).Save(n); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	cmd := NewStatsCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{dir, "--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var result statsJSON
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+	}
+	if result.ValidationErrors != 1 {
+		t.Errorf("expected the missing doc anchor (E057) to be counted, got %d error(s)", result.ValidationErrors)
+	}
+}
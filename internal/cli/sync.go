@@ -125,6 +125,13 @@ func runSync(flags *syncFlags) (int, error) {
 	if err != nil {
 		return syncExitError, fmt.Errorf(".deco directory not found or invalid: %w", err)
 	}
+	if !flags.dryRun {
+		unlock, err := lockProject(flags.targetDir, "sync")
+		if err != nil {
+			return syncExitError, err
+		}
+		defer unlock()
+	}
 
 	// Discover all nodes
 	nodesPath := config.ResolveNodesPath(cfg, flags.targetDir)
@@ -143,11 +150,12 @@ func runSync(flags *syncFlags) (int, error) {
 
 	// Load all latest content hashes in a single pass (O(history) instead of O(nodes × history))
 	historyPath := config.ResolveHistoryPath(cfg, flags.targetDir)
-	historyRepo := history.NewYAMLRepository(historyPath)
+	historyRepo := historyForWrite(historyPath)
 	latestHashes, err := historyRepo.QueryLatestHashes()
 	if err != nil {
 		return syncExitError, fmt.Errorf("failed to load history: %w", err)
 	}
+	hw := newHistoryWriter(historyPath, historyRepo)
 
 	nodeRepo := node.NewYAMLRepository(nodesPath)
 
@@ -213,7 +221,7 @@ func runSync(flags *syncFlags) (int, error) {
 	for deletedID := range missingNodeHashes {
 		deletedNodes = append(deletedNodes, deletedID)
 		if !flags.dryRun {
-			if err := logDeleteOperation(historyPath, deletedID); err != nil {
+			if err := logDeleteOperation(hw, deletedID); err != nil {
 				errors = append(errors, fmt.Sprintf("failed to log deletion of %s: %v", deletedID, err))
 				if !flags.quiet {
 					fmt.Fprintf(os.Stderr, "Error: failed to log deletion of %s: %v\n", deletedID, err)
@@ -267,7 +275,13 @@ func runSync(flags *syncFlags) (int, error) {
 				}
 
 				// Log move operation (like deco mv does)
-				if err := logMoveOperation(historyPath, rename.oldID, rename.newID, rename.contentHash); err != nil {
+				var moved domain.Node
+				for _, n := range allNodes {
+					if n.ID == rename.newID {
+						moved = n
+					}
+				}
+				if err := logMoveOperation(hw, rename.oldID, moved, rename.contentHash); err != nil {
 					errors = append(errors, fmt.Sprintf("failed to log rename %s→%s: %v", rename.oldID, rename.newID, err))
 					if !flags.quiet {
 						fmt.Fprintf(os.Stderr, "Error: failed to log rename %s→%s: %v\n", rename.oldID, rename.newID, err)
@@ -312,7 +326,7 @@ func runSync(flags *syncFlags) (int, error) {
 
 			// Genuine new node - baseline it
 			if !flags.dryRun {
-				if err := logBaselineOperation(historyPath, currentNode.ID, currentHash); err != nil {
+				if err := logBaselineOperation(hw, currentNode, currentHash); err != nil {
 					errors = append(errors, fmt.Sprintf("failed to baseline %s: %v", currentNode.ID, err))
 					if !flags.quiet {
 						fmt.Fprintf(os.Stderr, "Error: failed to baseline %s: %v\n", currentNode.ID, err)
@@ -345,7 +359,7 @@ func runSync(flags *syncFlags) (int, error) {
 
 		if !flags.dryRun {
 			nodeCopy := currentNode // copy for modification
-			if err := applySyncWithHash(historyPath, &nodeCopy, nodeRepo, currentHash); err != nil {
+			if err := applySyncWithHash(hw, &nodeCopy, nodeRepo, currentHash); err != nil {
 				errors = append(errors, fmt.Sprintf("failed to sync %s: %v", currentNode.ID, err))
 				if !flags.quiet {
 					fmt.Fprintf(os.Stderr, "Error: failed to sync %s: %v\n", currentNode.ID, err)
@@ -420,22 +434,25 @@ func runSync(flags *syncFlags) (int, error) {
 }
 
 // logBaselineOperation records initial state for a node without modification
-func logBaselineOperation(historyPath, nodeID, contentHash string) error {
-	historyRepo := history.NewYAMLRepository(historyPath)
+func logBaselineOperation(hw *historyWriter, n domain.Node, contentHash string) error {
+	snapshot, err := snapshotNode(hw.path, n)
+	if err != nil {
+		return err
+	}
 
 	entry := domain.AuditEntry{
 		Timestamp:   time.Now(),
-		NodeID:      nodeID,
+		NodeID:      n.ID,
 		Operation:   "baseline",
-		User:        GetCurrentUser(),
 		ContentHash: contentHash,
+		Snapshot:    snapshot,
 	}
 
-	return historyRepo.Append(entry)
+	return hw.append(entry)
 }
 
 // applySyncWithHash applies sync changes and logs with content hash
-func applySyncWithHash(historyPath string, n *domain.Node, nodeRepo *node.YAMLRepository, contentHash string) error {
+func applySyncWithHash(hw *historyWriter, n *domain.Node, nodeRepo *node.YAMLRepository, contentHash string) error {
 	oldVersion := n.Version
 	oldStatus := n.Status
 
@@ -456,30 +473,33 @@ func applySyncWithHash(historyPath string, n *domain.Node, nodeRepo *node.YAMLRe
 	}
 
 	// Log to history with content hash
-	return logSyncOperationWithHash(historyPath, n.ID, oldVersion, n.Version, oldStatus, n.Status, contentHash)
+	return logSyncOperationWithHash(hw, *n, oldVersion, oldStatus, contentHash)
 }
 
 // logSyncOperationWithHash adds a sync entry with content hash
-func logSyncOperationWithHash(historyPath, nodeID string, oldVersion, newVersion int, oldStatus, newStatus, contentHash string) error {
-	historyRepo := history.NewYAMLRepository(historyPath)
+func logSyncOperationWithHash(hw *historyWriter, n domain.Node, oldVersion int, oldStatus, contentHash string) error {
+	snapshot, err := snapshotNode(hw.path, n)
+	if err != nil {
+		return err
+	}
 
 	entry := domain.AuditEntry{
 		Timestamp:   time.Now(),
-		NodeID:      nodeID,
+		NodeID:      n.ID,
 		Operation:   "sync",
-		User:        GetCurrentUser(),
 		ContentHash: contentHash,
 		Before: map[string]interface{}{
 			"version": oldVersion,
 			"status":  oldStatus,
 		},
 		After: map[string]interface{}{
-			"version": newVersion,
-			"status":  newStatus,
+			"version": n.Version,
+			"status":  n.Status,
 		},
+		Snapshot: snapshot,
 	}
 
-	return historyRepo.Append(entry)
+	return hw.append(entry)
 }
 
 // getLastContentHash retrieves the most recent content hash for a node from history
@@ -504,36 +524,36 @@ func getLastContentHash(historyPath, nodeID string) string {
 }
 
 // logMoveOperation records a rename detected during sync (manual rename)
-func logMoveOperation(historyPath, oldID, newID, contentHash string) error {
-	historyRepo := history.NewYAMLRepository(historyPath)
+func logMoveOperation(hw *historyWriter, oldID string, n domain.Node, contentHash string) error {
+	snapshot, err := snapshotNode(hw.path, n)
+	if err != nil {
+		return err
+	}
 
 	entry := domain.AuditEntry{
 		Timestamp:   time.Now(),
-		NodeID:      newID,
+		NodeID:      n.ID,
 		Operation:   "move",
-		User:        GetCurrentUser(),
 		ContentHash: contentHash,
 		Before: map[string]interface{}{
 			"id": oldID,
 		},
 		After: map[string]interface{}{
-			"id": newID,
+			"id": n.ID,
 		},
+		Snapshot: snapshot,
 	}
 
-	return historyRepo.Append(entry)
+	return hw.append(entry)
 }
 
 // logDeleteOperation records a node deletion detected during sync
-func logDeleteOperation(historyPath, nodeID string) error {
-	historyRepo := history.NewYAMLRepository(historyPath)
-
+func logDeleteOperation(hw *historyWriter, nodeID string) error {
 	entry := domain.AuditEntry{
 		Timestamp: time.Now(),
 		NodeID:    nodeID,
 		Operation: "delete",
-		User:      GetCurrentUser(),
 	}
 
-	return historyRepo.Append(entry)
+	return hw.append(entry)
 }
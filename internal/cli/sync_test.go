@@ -16,13 +16,17 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/lock"
 	"github.com/Toernblom/deco/internal/storage/node"
 )
 
@@ -1094,6 +1098,35 @@ func TestComputeContentHashWithDir_IncludesDocFiles(t *testing.T) {
 		}
 	})
 
+	t.Run("anchored doc hashes only its section", func(t *testing.T) {
+		authPath := filepath.Join(dir, "auth.md")
+		os.WriteFile(authPath, []byte("# Auth\n\n## Token Rotation\nRotate daily.\n\n## Sessions\nKeep short.\n"), 0644)
+
+		anchored := domain.Node{
+			ID:      "test/anchored",
+			Kind:    "system",
+			Version: 1,
+			Status:  "draft",
+			Title:   "Test",
+			Docs: []domain.DocRef{
+				{Path: "auth.md#token-rotation"},
+			},
+		}
+
+		hash1 := ComputeContentHashWithDir(anchored, dir)
+		os.WriteFile(authPath, []byte("# Auth\n\n## Token Rotation\nRotate daily.\n\n## Sessions\nKeep them very short.\n"), 0644)
+		hash2 := ComputeContentHashWithDir(anchored, dir)
+		if hash1 != hash2 {
+			t.Error("expected hash to ignore edits outside the anchored section")
+		}
+
+		os.WriteFile(authPath, []byte("# Auth\n\n## Token Rotation\nRotate hourly.\n\n## Sessions\nKeep them very short.\n"), 0644)
+		hash3 := ComputeContentHashWithDir(anchored, dir)
+		if hash2 == hash3 {
+			t.Error("expected hash to change when the anchored section changes")
+		}
+	})
+
 	t.Run("without projectRoot falls back to yaml-only hash", func(t *testing.T) {
 		hash1 := ComputeContentHash(node)
 		hash2 := ComputeContentHashWithDir(node, "")
@@ -1102,3 +1135,37 @@ func TestComputeContentHashWithDir_IncludesDocFiles(t *testing.T) {
 		}
 	})
 }
+
+func TestRunSync_ProjectLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupProjectForSync(t, tmpDir)
+
+	// Another live deco process on this host holds the lock
+	host, _ := os.Hostname()
+	data, err := json.Marshal(lock.Owner{PID: os.Getppid(), Host: host, Command: "deco sync", Acquired: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".deco", lock.FileName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved := globalConfig.LockTimeout
+	globalConfig.LockTimeout = 100 * time.Millisecond
+	defer func() { globalConfig.LockTimeout = saved }()
+
+	exitCode, err := runSync(&syncFlags{targetDir: tmpDir, quiet: true})
+	if !errors.Is(err, lock.ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if exitCode != syncExitError {
+		t.Errorf("Expected exit code %d, got %d", syncExitError, exitCode)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".deco", "history.jsonl")); !os.IsNotExist(err) {
+		t.Error("Expected no history to be written while locked")
+	}
+
+	// A dry run writes nothing and does not wait
+	if _, err := runSync(&syncFlags{targetDir: tmpDir, quiet: true, dryRun: true}); err != nil {
+		t.Errorf("dry run should not need the lock: %v", err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/Toernblom/deco/internal/cli/style"
 	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/errors"
 	"github.com/Toernblom/deco/internal/migrations"
 	"github.com/Toernblom/deco/internal/services/validator"
 	"github.com/Toernblom/deco/internal/storage/config"
@@ -97,9 +98,7 @@ func runValidate(flags *validateFlags) error {
 		return fmt.Errorf("failed to load nodes: %w", err)
 	}
 
-	// Run validation with full config support (custom block types, schema rules, unknown field detection)
-	orchestrator := validator.NewOrchestratorWithFullConfig(cfg.RequiredApprovals, cfg.CustomBlockTypes, cfg.SchemaRules)
-	collector := orchestrator.ValidateAllWithDir(nodes, flags.targetDir)
+	collector := validateProject(cfg, flags.targetDir, nodes)
 
 	// Check if there are errors
 	if !collector.HasErrors() {
@@ -126,6 +125,19 @@ func runValidate(flags *validateFlags) error {
 	return NewExitError(ExitCodeError, fmt.Sprintf("validation failed with %d error(s)", collector.Count()))
 }
 
+// validateProject runs validation with full config support (custom block
+// types, schema rules, unknown field detection, saved queries).
+func validateProject(cfg config.Config, dir string, nodes []domain.Node) *errors.Collector {
+	orchestrator := validator.NewOrchestratorWithFullConfig(cfg.RequiredApprovals, cfg.CustomBlockTypes, cfg.SchemaRules)
+	orchestrator.SetQueryScopes(savedQueryScopes(cfg, nodes))
+	if cfg.RequireSignedApprovals {
+		orchestrator.RequireSignedApprovals(cfg.TrustedKeys, ComputeFullContentHash)
+	}
+	collector := orchestrator.ValidateAllWithDir(nodes, dir)
+	collector.AddBatch(savedQueryErrors(cfg))
+	return collector
+}
+
 // formatSchemaHash formats a schema hash for display.
 func formatSchemaHash(hash string) string {
 	if hash == "" {
@@ -0,0 +1,113 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/Toernblom/deco/internal/storage/objects"
+)
+
+// nodeSnapshots returns the last snapshot recorded in history for each
+// version of a node, following renames back to earlier IDs.
+func nodeSnapshots(cfg config.Config, dir, id string) (map[int]domain.Node, error) {
+	entries, err := history.NewYAMLRepository(config.ResolveHistoryPath(cfg, dir)).Query(history.Filter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	store := objects.NewStore(config.ResolveObjectsPath(cfg, dir))
+
+	ids := map[string]bool{id: true}
+	versions := make(map[int]domain.Node)
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if !ids[e.NodeID] {
+			continue
+		}
+		if e.Operation == "move" {
+			if from, ok := e.Before["id"].(string); ok {
+				ids[from] = true
+			}
+		}
+		if e.Snapshot == "" {
+			continue
+		}
+		n, err := store.Get(e.Snapshot)
+		if err != nil {
+			// A missing object only hides that version
+			continue
+		}
+		if _, seen := versions[n.Version]; !seen {
+			versions[n.Version] = n
+		}
+	}
+	return versions, nil
+}
+
+// loadVersion returns a node as it was at a version: the current node when
+// it is at that version, otherwise the last snapshot of that version, or
+// the last git commit of it when history holds no snapshot.
+func loadVersion(cfg config.Config, dir, id string, version int, current *domain.Node) (domain.Node, error) {
+	n, _, err := resolveVersion(cfg, dir, id, version, current)
+	return n, err
+}
+
+// resolveVersion is loadVersion that also describes where the version came
+// from: "working tree", "history snapshot" or "git <rev>".
+func resolveVersion(cfg config.Config, dir, id string, version int, current *domain.Node) (domain.Node, string, error) {
+	if current != nil && current.Version == version {
+		return *current, "working tree", nil
+	}
+	versions, err := nodeSnapshots(cfg, dir, id)
+	if err != nil {
+		return domain.Node{}, "", err
+	}
+	if n, ok := versions[version]; ok {
+		n.ID = id
+		return n, "history snapshot", nil
+	}
+
+	// Versions recorded before snapshots existed may still be in git
+	if n, rev, err := node.LoadVersionAtRevisions(config.ResolveNodesPath(cfg, dir), id, version); err == nil {
+		n.ID = id
+		return n, "git " + shortRevision(rev), nil
+	}
+
+	if len(versions) == 0 {
+		return domain.Node{}, "", fmt.Errorf("no snapshots of %q in history", id)
+	}
+	return domain.Node{}, "", fmt.Errorf("no snapshot of %q version %d (available: %s)", id, version, versionList(versions))
+}
+
+// versionList formats the versions that have snapshots, in order.
+func versionList(versions map[int]domain.Node) string {
+	nums := make([]int, 0, len(versions))
+	for v := range versions {
+		nums = append(nums, v)
+	}
+	sort.Ints(nums)
+	parts := make([]string, len(nums))
+	for i, v := range nums {
+		parts[i] = fmt.Sprintf("%d", v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -17,9 +17,29 @@ package domain
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
+// Operations are the valid audit entry operations.
+var Operations = []string{
+	"create",
+	"update",
+	"delete",
+	"set",
+	"append",
+	"unset",
+	"move",
+	"submit",   // draft -> review
+	"approve",  // add approval
+	"reject",   // review -> draft
+	"sync",     // auto-fix unversioned edits
+	"baseline", // record current state without modification
+	"migrate",  // schema migration
+	"rewrite",  // full node replacement
+}
+
 // AuditEntry represents a single entry in the audit log.
 // It tracks changes to nodes over time (who, what, when).
 type AuditEntry struct {
@@ -27,9 +47,13 @@ type AuditEntry struct {
 	NodeID      string                 `json:"node_id" yaml:"node_id"`
 	Operation   string                 `json:"operation" yaml:"operation"` // create, update, delete, set, append, unset, move, baseline
 	User        string                 `json:"user" yaml:"user"`
+	Commit      string                 `json:"commit,omitempty" yaml:"commit,omitempty"` // git HEAD of the project when the entry was written
 	ContentHash string                 `json:"content_hash,omitempty" yaml:"content_hash,omitempty"`
 	Before      map[string]interface{} `json:"before,omitempty" yaml:"before,omitempty"`
 	After       map[string]interface{} `json:"after,omitempty" yaml:"after,omitempty"`
+	Snapshot    string                 `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`     // object hash of the full node after the change
+	PrevHash    string                 `json:"prev_hash,omitempty" yaml:"prev_hash,omitempty"`   // EntryHash of the entry before this one
+	EntryHash   string                 `json:"entry_hash,omitempty" yaml:"entry_hash,omitempty"` // seals this entry, PrevHash included
 }
 
 // Validate checks that all required fields are present and valid.
@@ -44,25 +68,8 @@ func (a *AuditEntry) Validate() error {
 		return fmt.Errorf("audit entry User is required")
 	}
 
-	// Validate operation type
-	validOperations := map[string]bool{
-		"create":   true,
-		"update":   true,
-		"delete":   true,
-		"set":      true,
-		"append":   true,
-		"unset":    true,
-		"move":     true,
-		"submit":   true, // draft -> review
-		"approve":  true, // add approval
-		"reject":   true, // review -> draft
-		"sync":     true, // auto-fix unversioned edits
-		"baseline": true, // record current state without modification
-		"migrate":  true, // schema migration
-		"rewrite":  true, // full node replacement
-	}
-	if !validOperations[a.Operation] {
-		return fmt.Errorf("audit entry Operation must be one of: create, update, delete, set, append, unset, move, submit, approve, reject, sync, baseline, migrate, rewrite")
+	if !slices.Contains(Operations, a.Operation) {
+		return fmt.Errorf("audit entry Operation must be one of: %s", strings.Join(Operations, ", "))
 	}
 
 	return nil
@@ -94,9 +94,9 @@ func NewErrorCodeRegistry() *ErrorCodeRegistry {
 	registry.register("E054", "validation", "Cross-reference not found")
 	registry.register("E055", "validation", "Doc file not found")
 	registry.register("E056", "validation", "Missing keyword in doc")
-	registry.register("E057", "validation", "Reserved for future use")
-	registry.register("E058", "validation", "Reserved for future use")
-	registry.register("E059", "validation", "Reserved for future use")
+	registry.register("E057", "validation", "Doc anchor not found")
+	registry.register("E058", "validation", "Broken link in doc")
+	registry.register("E059", "validation", "Invalid saved query")
 
 	// I/O errors: E060-E079
 	registry.register("E060", "io", "File not found")
@@ -164,6 +164,28 @@ func NewErrorCodeRegistry() *ErrorCodeRegistry {
 	registry.register("E118", "contract", "Reserved for future use")
 	registry.register("E119", "contract", "Reserved for future use")
 
+	// Approval errors: E120-E139
+	registry.register("E120", "approval", "Unsigned approval")
+	registry.register("E121", "approval", "Approval signed with untrusted key")
+	registry.register("E122", "approval", "Invalid approval signature")
+	registry.register("E123", "approval", "Reserved for future use")
+	registry.register("E124", "approval", "Reserved for future use")
+	registry.register("E125", "approval", "Reserved for future use")
+	registry.register("E126", "approval", "Reserved for future use")
+	registry.register("E127", "approval", "Reserved for future use")
+	registry.register("E128", "approval", "Reserved for future use")
+	registry.register("E129", "approval", "Reserved for future use")
+	registry.register("E130", "approval", "Reserved for future use")
+	registry.register("E131", "approval", "Reserved for future use")
+	registry.register("E132", "approval", "Reserved for future use")
+	registry.register("E133", "approval", "Reserved for future use")
+	registry.register("E134", "approval", "Reserved for future use")
+	registry.register("E135", "approval", "Reserved for future use")
+	registry.register("E136", "approval", "Reserved for future use")
+	registry.register("E137", "approval", "Reserved for future use")
+	registry.register("E138", "approval", "Reserved for future use")
+	registry.register("E139", "approval", "Reserved for future use")
+
 	return registry
 }
 
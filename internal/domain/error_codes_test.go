@@ -59,6 +59,8 @@ func TestErrorCodeRegistry_CategoryRanges(t *testing.T) {
 		{"validation", "E040", "E059"},
 		{"io", "E060", "E079"},
 		{"graph", "E080", "E099"},
+		{"contract", "E100", "E119"},
+		{"approval", "E120", "E139"},
 	}
 
 	for _, tt := range tests {
@@ -177,6 +177,12 @@ type Reviewer struct {
 	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`           // when approved
 	Version   int       `json:"version" yaml:"version"`               // version that was approved
 	Note      string    `json:"note,omitempty" yaml:"note,omitempty"` // optional comment
+
+	// Key and Signature are set for signed approvals: the fingerprint of
+	// the reviewer's key and a signature over the node ID, version,
+	// content hash and reviewer name.
+	Key       string `json:"key,omitempty" yaml:"key,omitempty"`
+	Signature string `json:"signature,omitempty" yaml:"signature,omitempty"`
 }
 
 // Validate checks that all required fields are present and valid.
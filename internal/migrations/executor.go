@@ -22,8 +22,10 @@ import (
 
 	"github.com/Toernblom/deco/internal/domain"
 	"github.com/Toernblom/deco/internal/storage/config"
+	"github.com/Toernblom/deco/internal/storage/git"
 	"github.com/Toernblom/deco/internal/storage/history"
 	"github.com/Toernblom/deco/internal/storage/node"
+	"github.com/Toernblom/deco/internal/storage/objects"
 )
 
 // ExecutorOptions configures the migration executor.
@@ -36,6 +38,9 @@ type ExecutorOptions struct {
 	Quiet bool
 	// TargetDir is the project root directory.
 	TargetDir string
+	// LockTimeout is how long history writes wait for another process.
+	// Zero means the lock package default.
+	LockTimeout time.Duration
 }
 
 // ExecutorResult contains the result of a migration execution.
@@ -166,17 +171,19 @@ func (e *Executor) Execute() (*ExecutorResult, error) {
 	}
 
 	// Save modified nodes
-	for _, n := range modifiedNodes {
+	for i := range modifiedNodes {
 		// Increment version for modified nodes
-		n.Version++
-		if err := nodeRepo.Save(n); err != nil {
-			return nil, fmt.Errorf("failed to save node %s: %w", n.ID, err)
+		modifiedNodes[i].Version++
+		if err := nodeRepo.Save(modifiedNodes[i]); err != nil {
+			return nil, fmt.Errorf("failed to save node %s: %w", modifiedNodes[i].ID, err)
 		}
 	}
 
 	// Log migration to audit history
 	historyRepo := history.NewYAMLRepository(config.ResolveHistoryPath(cfg, e.opts.TargetDir))
-	user := getUser()
+	historyRepo.SetLockTimeout(e.opts.LockTimeout)
+	store := objects.NewStore(config.ResolveObjectsPath(cfg, e.opts.TargetDir))
+	user := getUser(e.opts.TargetDir)
 
 	for _, n := range modifiedNodes {
 		entry := domain.AuditEntry{
@@ -185,6 +192,11 @@ func (e *Executor) Execute() (*ExecutorResult, error) {
 			Operation: "migrate",
 			User:      user,
 		}
+		if snapshot, err := store.Put(n); err == nil {
+			entry.Snapshot = snapshot
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: failed to snapshot %s: %v\n", n.ID, err)
+		}
 		if err := historyRepo.Append(entry); err != nil {
 			// Log error but don't fail migration
 			fmt.Fprintf(os.Stderr, "Warning: failed to log audit entry for %s: %v\n", n.ID, err)
@@ -209,8 +221,12 @@ func nodesEqual(a, b domain.Node) bool {
 	return a.ID == b.ID && a.Version == b.Version && a.Title == b.Title
 }
 
-// getUser returns the current user for audit logging.
-func getUser() string {
+// getUser returns the current user for audit logging: the git identity of
+// the project when configured, else the USER environment variable.
+func getUser(dir string) string {
+	if id := git.Identity(dir); id != "" {
+		return id
+	}
 	user := os.Getenv("USER")
 	if user == "" {
 		user = os.Getenv("USERNAME")
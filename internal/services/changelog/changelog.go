@@ -0,0 +1,321 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package changelog summarizes the history recorded between two points in
+// time: nodes created, deleted, moved, approved and deprecated, version
+// bumps with the fields they changed, and issues opened or resolved,
+// grouped by node kind.
+package changelog
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/diff"
+)
+
+// Options select the entries a changelog covers.
+type Options struct {
+	Since Bound // entries after this bound
+	Until Bound // entries at or before this bound; the zero Bound is open
+
+	// Exclude lists operations to leave out, such as sync or baseline.
+	Exclude []string
+}
+
+// Bound is one end of a changelog range.
+type Bound struct {
+	Time time.Time
+
+	// Commits, when set, are the git commits before the bound. Entries
+	// recorded at a commit are placed by it rather than by their time, as
+	// git commit times have whole seconds and entries are written between
+	// commits.
+	Commits map[string]bool
+}
+
+// IsZero reports whether the bound is open.
+func (b Bound) IsZero() bool {
+	return b.Time.IsZero() && b.Commits == nil
+}
+
+// includes reports whether an entry was recorded at or before the bound.
+func (b Bound) includes(e domain.AuditEntry) bool {
+	if b.Commits != nil && e.Commit != "" {
+		return b.Commits[e.Commit]
+	}
+	return !e.Timestamp.After(b.Time)
+}
+
+// Lookup returns a node as it was at a version, or its latest known state
+// when version is 0. ok is false when the state is not available.
+type Lookup func(id string, version int) (n domain.Node, ok bool)
+
+// Changelog is the summary of a time range.
+type Changelog struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until,omitzero"`
+	Kinds []Kind    `json:"kinds"`
+}
+
+// Kind groups the changed nodes of one kind.
+type Kind struct {
+	Kind  string `json:"kind"`
+	Nodes []Node `json:"nodes"`
+}
+
+// Node is what happened to one node in the range.
+type Node struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+
+	Created    bool   `json:"created,omitempty"`
+	Deleted    bool   `json:"deleted,omitempty"`
+	Approved   bool   `json:"approved,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+	MovedFrom  string `json:"moved_from,omitempty"`
+
+	// FromVersion and ToVersion are set when the version changed; Changes
+	// lists the fields that changed between them.
+	FromVersion int           `json:"from_version,omitempty"`
+	ToVersion   int           `json:"to_version,omitempty"`
+	Changes     []diff.Change `json:"changes,omitempty"`
+
+	IssuesOpened   []domain.Issue `json:"issues_opened,omitempty"`
+	IssuesResolved []domain.Issue `json:"issues_resolved,omitempty"`
+
+	Users []string `json:"users"`
+}
+
+// Empty reports whether the changelog lists no changes.
+func (c Changelog) Empty() bool {
+	return len(c.Kinds) == 0
+}
+
+// record accumulates the entries of one node.
+type record struct {
+	Node
+	kind        string
+	origin      string // the node's ID at the start of the range
+	entries     int
+	fromVersion int
+	toVersion   int
+	hasVersion  bool
+}
+
+// Build summarizes the entries between opts.Since and opts.Until. Entries
+// must be in chronological order, as history.Repository.Query returns
+// them, and include those before the range, which tell existing nodes from
+// new ones. lookup provides node states for field-level changes.
+func Build(entries []domain.AuditEntry, opts Options, lookup Lookup) Changelog {
+	existed := make(map[string]bool)
+	records := make(map[string]*record)
+	var order []*record
+
+	for _, e := range entries {
+		if opts.Since.includes(e) {
+			existed[e.NodeID] = true
+			continue
+		}
+		if !opts.Until.IsZero() && !opts.Until.includes(e) {
+			continue
+		}
+		if slices.Contains(opts.Exclude, e.Operation) {
+			continue
+		}
+
+		if e.Operation == "move" {
+			from, _ := e.Before["id"].(string)
+			if r, ok := records[from]; ok && from != "" {
+				delete(records, from)
+				r.ID = e.NodeID
+				records[e.NodeID] = r
+			} else if from != "" {
+				r := &record{Node: Node{ID: e.NodeID}, origin: from}
+				records[e.NodeID] = r
+				order = append(order, r)
+			}
+		}
+		r, ok := records[e.NodeID]
+		if !ok {
+			r = &record{Node: Node{ID: e.NodeID}, origin: e.NodeID}
+			records[e.NodeID] = r
+			order = append(order, r)
+		}
+		r.entries++
+		if !slices.Contains(r.Users, e.User) {
+			r.Users = append(r.Users, e.User)
+		}
+
+		switch e.Operation {
+		case "create":
+			r.Created = true
+			r.Deleted = false
+			r.kind, _ = e.After["kind"].(string)
+			r.Title, _ = e.After["title"].(string)
+		case "baseline":
+			// The first entry of a node added without deco new
+			if !existed[r.origin] && r.entries == 1 {
+				r.Created = true
+			}
+		case "delete":
+			r.Deleted = true
+		}
+		switch e.After["status"] {
+		case "approved":
+			r.Approved = r.Approved || e.Operation == "approve"
+		case "deprecated":
+			r.Deprecated = true
+		}
+		if v, ok := version(e.Before); ok && !r.hasVersion {
+			r.fromVersion, r.hasVersion = v, true
+		}
+		if v, ok := version(e.After); ok {
+			r.toVersion, r.hasVersion = v, true
+		}
+	}
+
+	for _, r := range order {
+		if r.origin != r.ID {
+			r.MovedFrom = r.origin
+		}
+		if r.Created {
+			r.fromVersion = 0
+		}
+		complete(r, lookup)
+	}
+
+	groups := make(map[string][]Node)
+	for _, r := range order {
+		if !reportable(r) {
+			continue
+		}
+		kind := r.kind
+		if kind == "" {
+			kind = "unknown"
+		}
+		groups[kind] = append(groups[kind], r.Node)
+	}
+
+	log := Changelog{Since: opts.Since.Time, Until: opts.Until.Time, Kinds: []Kind{}}
+	for kind, nodes := range groups {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+		log.Kinds = append(log.Kinds, Kind{Kind: kind, Nodes: nodes})
+	}
+	sort.Slice(log.Kinds, func(i, j int) bool { return log.Kinds[i].Kind < log.Kinds[j].Kind })
+	return log
+}
+
+// complete fills in a record's kind, title, field changes and issues from
+// the node states before and after the range.
+func complete(r *record, lookup Lookup) {
+	endVersion := 0
+	if r.hasVersion {
+		endVersion = r.toVersion
+	}
+	end, hasEnd := lookup(r.ID, endVersion)
+	if hasEnd {
+		r.kind, r.Title = end.Kind, end.Title
+	}
+
+	var start domain.Node
+	hasStart := false
+	if !r.Created && r.fromVersion > 0 {
+		start, hasStart = lookup(r.ID, r.fromVersion)
+	}
+	if r.hasVersion && r.toVersion > r.fromVersion {
+		r.FromVersion, r.ToVersion = r.fromVersion, r.toVersion
+	}
+	if !hasEnd || r.Deleted {
+		return
+	}
+
+	if hasStart {
+		if r.ToVersion > 0 {
+			r.Changes = fieldChanges(start, end)
+		}
+		r.Deprecated = r.Deprecated || (end.Status == "deprecated" && start.Status != "deprecated")
+	}
+	if hasStart || r.Created {
+		r.IssuesOpened, r.IssuesResolved = issueChanges(start.Issues, end.Issues)
+	}
+}
+
+// fieldChanges diffs two versions, leaving out the fields a changelog
+// reports separately or that change with every version.
+func fieldChanges(a, b domain.Node) []diff.Change {
+	var changes []diff.Change
+	for _, c := range diff.Nodes(a, b) {
+		field, _, _ := strings.Cut(c.Path, "[")
+		field, _, _ = strings.Cut(field, ".")
+		switch field {
+		case "id", "version", "status", "reviewers", "issues":
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// issueChanges returns the issues opened (new or reopened) and resolved
+// (marked resolved or removed) between two issue lists.
+func issueChanges(before, after []domain.Issue) (opened, resolved []domain.Issue) {
+	open := make(map[string]bool)
+	for _, i := range before {
+		open[i.ID] = !i.Resolved
+	}
+	seen := make(map[string]bool)
+	for _, i := range after {
+		seen[i.ID] = true
+		wasOpen, existed := open[i.ID]
+		switch {
+		case !i.Resolved && (!existed || !wasOpen):
+			opened = append(opened, i)
+		case i.Resolved && wasOpen:
+			resolved = append(resolved, i)
+		}
+	}
+	for _, i := range before {
+		if !i.Resolved && !seen[i.ID] {
+			resolved = append(resolved, i)
+		}
+	}
+	return opened, resolved
+}
+
+// reportable reports whether a record has anything a changelog lists.
+// Nodes created and deleted in the same range cancel out.
+func reportable(r *record) bool {
+	if r.Created && r.Deleted {
+		return false
+	}
+	return r.Created || r.Deleted || r.Approved || r.Deprecated || r.MovedFrom != "" ||
+		r.ToVersion > 0 || len(r.IssuesOpened) > 0 || len(r.IssuesResolved) > 0
+}
+
+// version reads a version number from an entry's Before or After map,
+// which holds an int when written and a float64 when read back as JSON.
+func version(fields map[string]interface{}) (int, bool) {
+	switch v := fields["version"].(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package changelog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+var base = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func at(day int) time.Time {
+	return base.Add(time.Duration(day) * 24 * time.Hour)
+}
+
+func entry(day int, id, op string, before, after map[string]interface{}) domain.AuditEntry {
+	return domain.AuditEntry{Timestamp: at(day), NodeID: id, Operation: op, User: "alice", Before: before, After: after}
+}
+
+type fields = map[string]interface{}
+
+// versions holds the states the lookup returns, by ID and version.
+var versions = map[string]map[int]domain.Node{
+	"systems/combat": {
+		2: {ID: "systems/combat", Kind: "system", Version: 2, Title: "Combat", Summary: "Swords",
+			Issues: []domain.Issue{{ID: "balance", Description: "Balance damage"}, {ID: "armor", Description: "Armor values"}}},
+		3: {ID: "systems/combat", Kind: "system", Version: 3, Status: "approved", Title: "Combat", Summary: "Swords and bows",
+			Issues: []domain.Issue{{ID: "balance", Description: "Balance damage", Resolved: true}, {ID: "ranged", Description: "Bow range"}}},
+	},
+	"systems/magic": {
+		1: {ID: "systems/magic", Kind: "system", Version: 1, Title: "Magic", Issues: []domain.Issue{{ID: "mana", Description: "Mana cost"}}},
+	},
+	"lore/world": {
+		1: {ID: "lore/world", Kind: "lore", Version: 1, Title: "World"},
+	},
+	"lore/legacy": {
+		4: {ID: "lore/legacy", Kind: "lore", Version: 4, Status: "deprecated", Title: "Legacy"},
+	},
+	"items/sword": {
+		1: {ID: "items/sword", Kind: "item", Version: 1, Title: "Sword"},
+	},
+}
+
+func lookup(id string, version int) (domain.Node, bool) {
+	byVersion, ok := versions[id]
+	if !ok {
+		return domain.Node{}, false
+	}
+	if version == 0 {
+		for v := range byVersion {
+			version = max(version, v)
+		}
+	}
+	n, ok := byVersion[version]
+	return n, ok
+}
+
+func history() []domain.AuditEntry {
+	return []domain.AuditEntry{
+		// Before the range
+		entry(0, "systems/combat", "create", nil, fields{"kind": "system", "title": "Combat", "version": 1}),
+		entry(1, "systems/combat", "sync", fields{"version": 1}, fields{"version": 2}),
+		entry(1, "lore/old-world", "baseline", nil, nil),
+		entry(2, "lore/legacy", "baseline", nil, nil),
+		entry(2, "items/sword", "baseline", nil, nil),
+
+		// In the range
+		entry(5, "systems/combat", "sync", fields{"version": 2, "status": "draft"}, fields{"version": 3, "status": "draft"}),
+		entry(6, "systems/combat", "submit", fields{"status": "draft"}, fields{"status": "review"}),
+		entry(7, "systems/combat", "approve", fields{"status": "review"}, fields{"status": "approved"}),
+		entry(5, "systems/magic", "baseline", nil, nil),
+		entry(6, "lore/world", "move", fields{"id": "lore/old-world"}, fields{"id": "lore/world"}),
+		entry(6, "lore/legacy", "sync", fields{"version": 3, "status": "approved"}, fields{"version": 4, "status": "deprecated"}),
+		entry(7, "items/sword", "delete", nil, nil),
+		entry(7, "items/shield", "create", nil, fields{"kind": "item", "title": "Shield", "version": 1}),
+		entry(8, "items/shield", "delete", nil, nil),
+
+		// After the range
+		entry(20, "systems/magic", "sync", fields{"version": 1}, fields{"version": 2}),
+	}
+}
+
+// render summarizes a changelog as one line per node.
+func render(log Changelog) string {
+	var lines []string
+	for _, k := range log.Kinds {
+		for _, n := range k.Nodes {
+			var facts []string
+			for _, fact := range []struct {
+				set  bool
+				text string
+			}{
+				{n.Created, "created"},
+				{n.Deleted, "deleted"},
+				{n.Approved, "approved"},
+				{n.Deprecated, "deprecated"},
+				{n.MovedFrom != "", "moved from " + n.MovedFrom},
+				{n.ToVersion > 0, fmt.Sprintf("v%d..v%d", n.FromVersion, n.ToVersion)},
+			} {
+				if fact.set {
+					facts = append(facts, fact.text)
+				}
+			}
+			for _, c := range n.Changes {
+				facts = append(facts, c.Kind+" "+c.Path)
+			}
+			for _, i := range n.IssuesOpened {
+				facts = append(facts, "opened "+i.ID)
+			}
+			for _, i := range n.IssuesResolved {
+				facts = append(facts, "resolved "+i.ID)
+			}
+			lines = append(lines, k.Kind+" "+n.ID+": "+strings.Join(facts, ", "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestBuild(t *testing.T) {
+	log := Build(history(), Options{Since: Bound{Time: at(3)}, Until: Bound{Time: at(10)}}, lookup)
+	got := render(log)
+	want := strings.Join([]string{
+		"item items/sword: deleted",
+		"lore lore/legacy: deprecated, v3..v4",
+		"lore lore/world: moved from lore/old-world",
+		"system systems/combat: approved, v2..v3, modified summary, opened ranged, resolved balance, resolved armor",
+		"system systems/magic: created, opened mana",
+	}, "\n")
+	if got != want {
+		t.Errorf("changelog:\n%s\nwant:\n%s", got, want)
+	}
+	if log.Kinds[0].Nodes[0].Title != "Sword" {
+		t.Errorf("expected the deleted node's title from its last state, got %q", log.Kinds[0].Nodes[0].Title)
+	}
+}
+
+func TestBuild_Exclude(t *testing.T) {
+	log := Build(history(), Options{Since: Bound{Time: at(3)}, Until: Bound{Time: at(10)}, Exclude: []string{"sync", "baseline"}}, lookup)
+	got := render(log)
+	want := strings.Join([]string{
+		"item items/sword: deleted",
+		"lore lore/world: moved from lore/old-world",
+		"system systems/combat: approved",
+	}, "\n")
+	if got != want {
+		t.Errorf("changelog:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBuild_Empty(t *testing.T) {
+	log := Build(history(), Options{Since: Bound{Time: at(30)}}, lookup)
+	if !log.Empty() || log.Kinds == nil {
+		t.Errorf("expected an empty, non-nil changelog, got %+v", log)
+	}
+}
+
+func TestIssueChanges(t *testing.T) {
+	before := []domain.Issue{{ID: "a"}, {ID: "b", Resolved: true}, {ID: "c"}}
+	after := []domain.Issue{{ID: "a", Resolved: true}, {ID: "b"}, {ID: "d"}}
+	opened, resolved := issueChanges(before, after)
+
+	ids := func(issues []domain.Issue) string {
+		var s []string
+		for _, i := range issues {
+			s = append(s, i.ID)
+		}
+		return strings.Join(s, ",")
+	}
+	if ids(opened) != "b,d" || ids(resolved) != "a,c" {
+		t.Errorf("opened %s, resolved %s; want b,d and a,c", ids(opened), ids(resolved))
+	}
+}
+
+func TestBuild_CommitBounds(t *testing.T) {
+	// Entries in the same second as the tagged commit, on both sides of it
+	entries := []domain.AuditEntry{
+		entry(3, "systems/combat", "create", nil, fields{"kind": "system", "title": "Combat", "version": 1}),
+		entry(3, "systems/magic", "create", nil, fields{"kind": "system", "title": "Magic", "version": 1}),
+		entry(3, "lore/world", "create", nil, fields{"kind": "lore", "title": "World", "version": 1}),
+	}
+	entries[0].Commit = "parent"
+	entries[1].Commit = "tagged"
+
+	log := Build(entries, Options{Since: Bound{Time: at(3), Commits: map[string]bool{"parent": true}}}, lookup)
+	if got, want := render(log), "system systems/magic: created, v0..v1, opened mana"; got != want {
+		t.Errorf("changelog:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,583 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package diff compares two versions of a node field by field. Sections are
+// matched by name and blocks by their id field, so reordering shows up as a
+// move rather than a removal and an addition, and text values carry a
+// word-level diff. Project compares whole sets of nodes, pairing removed
+// and added nodes into moves.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+// Change kinds.
+const (
+	Added    = "added"
+	Removed  = "removed"
+	Modified = "modified"
+	Moved    = "moved"
+)
+
+// Change is one difference between two versions of a node.
+//
+// Path locates the value: top-level fields by name, list items and map keys
+// in brackets, sections as content.sections[<name>] and blocks as
+// .blocks[<id>], or .blocks[<index>] for blocks without an id. For a move
+// within the same list, Before and After are the old and new positions; for
+// a block moved to another section they are the old and new paths.
+type Change struct {
+	Path   string      `json:"path"`
+	Kind   string      `json:"kind"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+	Words  []Word      `json:"words,omitempty"` // word-level diff of a modified text value
+}
+
+// fieldOrder lists node fields in the order they are declared in
+// domain.Node, so changes come out in file order.
+var fieldOrder = []string{
+	"id", "kind", "version", "status", "title", "tags", "refs", "content",
+	"issues", "docs", "summary", "glossary", "contracts", "llm_context",
+	"constraints", "reviewers", "custom",
+}
+
+// listKeys are the fields that identify items of a list of objects, tried
+// in order: issues by id, contracts and reviewers by name, refs by target,
+// docs by path.
+var listKeys = []string{"id", "name", "target", "path"}
+
+// Nodes returns the changes that turn a into b.
+func Nodes(a, b domain.Node) []Change {
+	d := &differ{}
+	am, bm := fieldMap(a), fieldMap(b)
+
+	fields := append([]string(nil), fieldOrder...)
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f] = true
+	}
+	var extra []string
+	for _, m := range []map[string]interface{}{am, bm} {
+		for k := range m {
+			if !known[k] {
+				known[k] = true
+				extra = append(extra, k)
+			}
+		}
+	}
+	sort.Strings(extra)
+	fields = append(fields, extra...)
+
+	for _, f := range fields {
+		if f == "content" {
+			d.content(a.Content, b.Content)
+			continue
+		}
+		d.value(f, am[f], bm[f])
+	}
+	return d.changes
+}
+
+// fieldMap returns a node's fields, except content, as generic values.
+func fieldMap(n domain.Node) map[string]interface{} {
+	n.Content = nil
+	data, err := json.Marshal(n)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return map[string]interface{}{}
+	}
+	delete(m, "content")
+	return m
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(c Change) {
+	d.changes = append(d.changes, c)
+}
+
+// value compares two generic values at path.
+func (d *differ) value(path string, a, b interface{}) {
+	switch {
+	case isEmpty(a) && isEmpty(b):
+		return
+	case isEmpty(a):
+		d.add(Change{Path: path, Kind: Added, After: b})
+		return
+	case isEmpty(b):
+		d.add(Change{Path: path, Kind: Removed, Before: a})
+		return
+	}
+
+	if am, ok := a.(map[string]interface{}); ok {
+		if bm, ok := b.(map[string]interface{}); ok {
+			for _, k := range unionKeys(am, bm) {
+				d.value(member(path, k), am[k], bm[k])
+			}
+			return
+		}
+	}
+	if al, ok := a.([]interface{}); ok {
+		if bl, ok := b.([]interface{}); ok {
+			d.list(path, al, bl)
+			return
+		}
+	}
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			if as != bs {
+				c := Change{Path: path, Kind: Modified, Before: as, After: bs}
+				if isText(as) || isText(bs) {
+					c.Words = Words(as, bs)
+				}
+				d.add(c)
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		d.add(Change{Path: path, Kind: Modified, Before: a, After: b})
+	}
+}
+
+// list compares two lists: by identifying field for lists of objects, by
+// value for lists of distinct scalars, and by position otherwise.
+func (d *differ) list(path string, a, b []interface{}) {
+	if key, ok := listKey(a, b); ok {
+		keyOf := func(v interface{}) string {
+			return fmt.Sprint(v.(map[string]interface{})[key])
+		}
+		d.keyed(path, a, b, keyOf, true)
+		return
+	}
+	if distinctScalars(a) && distinctScalars(b) {
+		d.keyed(path, a, b, func(v interface{}) string { return fmt.Sprint(v) }, false)
+		return
+	}
+
+	for i := 0; i < len(a) || i < len(b); i++ {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(b):
+			d.add(Change{Path: p, Kind: Removed, Before: a[i]})
+		case i >= len(a):
+			d.add(Change{Path: p, Kind: Added, After: b[i]})
+		default:
+			d.value(p, a[i], b[i])
+		}
+	}
+}
+
+// keyed compares lists whose items have unique keys. recurse compares
+// matched items field by field.
+func (d *differ) keyed(path string, a, b []interface{}, keyOf func(interface{}) string, recurse bool) {
+	aKeys, bKeys := make([]string, len(a)), make([]string, len(b))
+	aIdx, bIdx := make(map[string]int), make(map[string]int)
+	for i, v := range a {
+		aKeys[i] = keyOf(v)
+		aIdx[aKeys[i]] = i
+	}
+	for j, v := range b {
+		bKeys[j] = keyOf(v)
+		bIdx[bKeys[j]] = j
+	}
+
+	for i, k := range aKeys {
+		if _, ok := bIdx[k]; !ok {
+			d.add(Change{Path: item(path, k), Kind: Removed, Before: a[i]})
+		}
+	}
+	moved := reordered(aKeys, bKeys, aIdx, bIdx)
+	for j, k := range bKeys {
+		i, ok := aIdx[k]
+		if !ok {
+			d.add(Change{Path: item(path, k), Kind: Added, After: b[j]})
+			continue
+		}
+		if moved[k] {
+			d.add(Change{Path: item(path, k), Kind: Moved, Before: i, After: j})
+		}
+		if recurse {
+			d.value(item(path, k), a[i], b[j])
+		}
+	}
+}
+
+// content compares sections by name and the blocks inside them.
+func (d *differ) content(a, b *domain.Content) {
+	var as, bs []domain.Section
+	if a != nil {
+		as = a.Sections
+	}
+	if b != nil {
+		bs = b.Sections
+	}
+	aKeys, bKeys := sectionKeys(as), sectionKeys(bs)
+	aIdx, bIdx := indexOf(aKeys), indexOf(bKeys)
+
+	// Blocks whose id moved from one section to another, when both
+	// sections exist in both versions
+	aHome, bHome := blockHomes(aKeys, as), blockHomes(bKeys, bs)
+	crossed := make(map[string]bool)
+	for id, from := range aHome {
+		to, ok := bHome[id]
+		if !ok || from == to {
+			continue
+		}
+		_, fromKept := bIdx[from]
+		_, toExisted := aIdx[to]
+		if fromKept && toExisted {
+			crossed[id] = true
+		}
+	}
+
+	for i, k := range aKeys {
+		if _, ok := bIdx[k]; !ok {
+			d.add(Change{Path: sectionPath(k), Kind: Removed, Before: sectionValue(as[i])})
+		}
+	}
+	moved := reordered(aKeys, bKeys, aIdx, bIdx)
+	for j, k := range bKeys {
+		i, ok := aIdx[k]
+		if !ok {
+			d.add(Change{Path: sectionPath(k), Kind: Added, After: sectionValue(bs[j])})
+			continue
+		}
+		if moved[k] {
+			d.add(Change{Path: sectionPath(k), Kind: Moved, Before: i, After: j})
+		}
+		d.blocks(sectionPath(k), as[i].Blocks, bs[j].Blocks, as, aKeys, aHome, crossed)
+	}
+}
+
+// blocks compares the blocks of one section. Blocks with an id are matched
+// by id; the others by their order among the blocks without one.
+func (d *differ) blocks(secPath string, a, b []domain.Block, aSections []domain.Section, aSecKeys []string, aHome map[string]string, crossed map[string]bool) {
+	aKeys, bKeys := blockKeys(a), blockKeys(b)
+	aIdx, bIdx := indexOf(aKeys), indexOf(bKeys)
+
+	for i, k := range aKeys {
+		if _, ok := bIdx[k]; ok {
+			continue
+		}
+		if id := blockID(a[i]); id != "" && crossed[id] {
+			continue // reported where it moved to
+		}
+		d.add(Change{Path: blockPath(secPath, a[i], i), Kind: Removed, Before: blockValue(a[i])})
+	}
+
+	moved := reordered(aKeys, bKeys, aIdx, bIdx)
+	for j, k := range bKeys {
+		p := blockPath(secPath, b[j], j)
+		if i, ok := aIdx[k]; ok {
+			if moved[k] {
+				d.add(Change{Path: p, Kind: Moved, Before: i, After: j})
+			}
+			d.value(p, blockValue(a[i]), blockValue(b[j]))
+			continue
+		}
+		if id := blockID(b[j]); id != "" && crossed[id] {
+			from := aHome[id]
+			old, oldIdx := findBlock(aSections[indexOf(aSecKeys)[from]].Blocks, id)
+			d.add(Change{Path: p, Kind: Moved, Before: blockPath(sectionPath(from), old, oldIdx), After: p})
+			d.value(p, blockValue(old), blockValue(b[j]))
+			continue
+		}
+		d.add(Change{Path: p, Kind: Added, After: blockValue(b[j])})
+	}
+}
+
+// reordered returns the keys present in both orders that are not part of
+// their longest common subsequence: the items that moved.
+func reordered(aKeys, bKeys []string, aIdx, bIdx map[string]int) map[string]bool {
+	var aCommon, bCommon []string
+	for _, k := range aKeys {
+		if _, ok := bIdx[k]; ok {
+			aCommon = append(aCommon, k)
+		}
+	}
+	for _, k := range bKeys {
+		if _, ok := aIdx[k]; ok {
+			bCommon = append(bCommon, k)
+		}
+	}
+	kept := lcs(aCommon, bCommon)
+	moved := make(map[string]bool)
+	for _, k := range bCommon {
+		if !kept[k] {
+			moved[k] = true
+		}
+	}
+	return moved
+}
+
+// lcs returns the items of a longest common subsequence of a and b, which
+// hold the same distinct keys.
+func lcs(a, b []string) map[string]bool {
+	n, m := len(a), len(b)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	kept := make(map[string]bool)
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			kept[a[i]] = true
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return kept
+}
+
+// sectionKeys identifies sections by name; repeated names get #2, #3...
+func sectionKeys(sections []domain.Section) []string {
+	keys := make([]string, len(sections))
+	seen := make(map[string]int)
+	for i, s := range sections {
+		seen[s.Name]++
+		keys[i] = s.Name
+		if seen[s.Name] > 1 {
+			keys[i] = fmt.Sprintf("%s#%d", s.Name, seen[s.Name])
+		}
+	}
+	return keys
+}
+
+// blockKeys identifies blocks by id, and blocks without one by their
+// order among those.
+func blockKeys(blocks []domain.Block) []string {
+	keys := make([]string, len(blocks))
+	anon := 0
+	seen := make(map[string]bool)
+	for i, b := range blocks {
+		if id := blockID(b); id != "" && !seen[id] {
+			seen[id] = true
+			keys[i] = "id:" + id
+			continue
+		}
+		anon++
+		keys[i] = fmt.Sprintf("#%d", anon)
+	}
+	return keys
+}
+
+// blockHomes maps each block id that occurs once in the node to the key of
+// its section.
+func blockHomes(secKeys []string, sections []domain.Section) map[string]string {
+	homes := make(map[string]string)
+	count := make(map[string]int)
+	for i, s := range sections {
+		for _, b := range s.Blocks {
+			if id := blockID(b); id != "" {
+				homes[id] = secKeys[i]
+				count[id]++
+			}
+		}
+	}
+	for id, n := range count {
+		if n > 1 {
+			delete(homes, id)
+		}
+	}
+	return homes
+}
+
+func findBlock(blocks []domain.Block, id string) (domain.Block, int) {
+	for i, b := range blocks {
+		if blockID(b) == id {
+			return b, i
+		}
+	}
+	return domain.Block{}, -1
+}
+
+// blockID returns the block's id field as text, or "".
+func blockID(b domain.Block) string {
+	if v, ok := b.Data["id"]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// blockValue returns a block as it is written in YAML: type plus fields.
+func blockValue(b domain.Block) map[string]interface{} {
+	m := make(map[string]interface{}, len(b.Data)+1)
+	for k, v := range b.Data {
+		m[k] = v
+	}
+	m["type"] = b.Type
+	return m
+}
+
+func sectionValue(s domain.Section) map[string]interface{} {
+	blocks := make([]interface{}, len(s.Blocks))
+	for i, b := range s.Blocks {
+		blocks[i] = blockValue(b)
+	}
+	return map[string]interface{}{"name": s.Name, "blocks": blocks}
+}
+
+func sectionPath(key string) string {
+	return "content.sections[" + key + "]"
+}
+
+func blockPath(secPath string, b domain.Block, index int) string {
+	if id := blockID(b); id != "" {
+		return fmt.Sprintf("%s.blocks[%s]", secPath, id)
+	}
+	return fmt.Sprintf("%s.blocks[%d]", secPath, index)
+}
+
+// member appends a map key to a path, bracketing keys that are not plain.
+func member(path, key string) string {
+	if key == "" || strings.ContainsAny(key, ".[] ") {
+		return item(path, key)
+	}
+	return path + "." + key
+}
+
+func item(path, key string) string {
+	return path + "[" + key + "]"
+}
+
+// listKey returns the identifying field shared by all items of both lists,
+// if each item is an object with a unique scalar value for it.
+func listKey(a, b []interface{}) (string, bool) {
+	if len(a) == 0 && len(b) == 0 {
+		return "", false
+	}
+	for _, key := range listKeys {
+		if uniqueField(a, key) && uniqueField(b, key) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func uniqueField(list []interface{}, key string) bool {
+	seen := make(map[string]bool, len(list))
+	for _, v := range list {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		k, ok := m[key]
+		if !ok || k == nil || !isScalar(k) {
+			return false
+		}
+		s := fmt.Sprint(k)
+		if s == "" || seen[s] {
+			return false
+		}
+		seen[s] = true
+	}
+	return true
+}
+
+func distinctScalars(list []interface{}) bool {
+	seen := make(map[string]bool, len(list))
+	for _, v := range list {
+		if !isScalar(v) {
+			return false
+		}
+		s := fmt.Sprint(v)
+		if seen[s] {
+			return false
+		}
+		seen[s] = true
+	}
+	return true
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return true
+}
+
+// isEmpty treats missing, null, empty strings and empty collections alike,
+// matching how omitempty fields disappear from YAML.
+func isEmpty(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	}
+	return false
+}
+
+// isText reports whether a string is prose worth a word-level diff.
+func isText(s string) bool {
+	return strings.ContainsAny(s, " \t\n")
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	set := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		set[k] = true
+	}
+	for k := range b {
+		set[k] = true
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func indexOf(keys []string) map[string]int {
+	idx := make(map[string]int, len(keys))
+	for i, k := range keys {
+		idx[k] = i
+	}
+	return idx
+}
@@ -0,0 +1,226 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+func block(typ string, data map[string]interface{}) domain.Block {
+	return domain.Block{Type: typ, Data: data}
+}
+
+func baseNode() domain.Node {
+	return domain.Node{
+		ID:      "systems/combat",
+		Kind:    "system",
+		Version: 3,
+		Status:  "approved",
+		Title:   "Combat",
+		Tags:    []string{"core", "pvp"},
+		Summary: "Players fight with swords and shields",
+		Issues:  []domain.Issue{{ID: "tbd-1", Description: "Balance damage", Severity: "medium"}},
+		Content: &domain.Content{Sections: []domain.Section{
+			{Name: "Weapons", Blocks: []domain.Block{
+				block("rule", map[string]interface{}{"id": "sword", "text": "Swords deal 10 damage", "damage": 10}),
+				block("rule", map[string]interface{}{"id": "bow", "text": "Bows deal 6 damage"}),
+				block("list", map[string]interface{}{"items": []interface{}{"a", "b"}}),
+			}},
+			{Name: "Armor", Blocks: []domain.Block{
+				block("rule", map[string]interface{}{"id": "shield", "text": "Shields block"}),
+			}},
+		}},
+	}
+}
+
+// summary renders changes as "kind path" lines for compact assertions.
+func summary(changes []Change) string {
+	var lines []string
+	for _, c := range changes {
+		line := c.Kind + " " + c.Path
+		if c.Kind == Moved || c.Kind == Modified {
+			line += fmt.Sprintf(" %v -> %v", c.Before, c.After)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestNodes_Identical(t *testing.T) {
+	if changes := Nodes(baseNode(), baseNode()); len(changes) != 0 {
+		t.Errorf("expected no changes, got:\n%s", summary(changes))
+	}
+}
+
+func TestNodes_Fields(t *testing.T) {
+	a, b := baseNode(), baseNode()
+	b.Version = 7
+	b.Tags = []string{"pvp", "core", "lore"}
+	b.Issues = []domain.Issue{{ID: "tbd-1", Description: "Balance damage", Severity: "medium", Resolved: true}}
+	b.Custom = map[string]interface{}{"owner": "alice"}
+
+	got := summary(Nodes(a, b))
+	want := strings.Join([]string{
+		"modified version 3 -> 7",
+		"moved tags[core] 0 -> 1",
+		"added tags[lore]",
+		"modified issues[tbd-1].resolved false -> true",
+		"added custom",
+	}, "\n")
+	if got != want {
+		t.Errorf("changes:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNodes_SectionsAndBlocks(t *testing.T) {
+	a, b := baseNode(), baseNode()
+	weapons := b.Content.Sections[0]
+	// Reorder sections, reorder blocks, edit a field, move a block across
+	// sections, add and remove blocks
+	weapons.Blocks = []domain.Block{
+		block("rule", map[string]interface{}{"id": "bow", "text": "Bows deal 6 damage"}),
+		block("rule", map[string]interface{}{"id": "sword", "text": "Swords deal 10 damage", "damage": 12}),
+		block("list", map[string]interface{}{"items": []interface{}{"a", "b", "c"}}),
+		block("rule", map[string]interface{}{"id": "shield", "text": "Shields block arrows"}),
+	}
+	b.Content.Sections = []domain.Section{
+		{Name: "Armor", Blocks: nil},
+		weapons,
+		{Name: "Magic", Blocks: []domain.Block{block("rule", map[string]interface{}{"id": "fireball"})}},
+	}
+
+	got := summary(Nodes(a, b))
+	want := strings.Join([]string{
+		"moved content.sections[Weapons] 0 -> 1",
+		"moved content.sections[Weapons].blocks[sword] 0 -> 1",
+		"modified content.sections[Weapons].blocks[sword].damage 10 -> 12",
+		"added content.sections[Weapons].blocks[2].items[c]",
+		"moved content.sections[Weapons].blocks[shield] content.sections[Armor].blocks[shield] -> content.sections[Weapons].blocks[shield]",
+		"modified content.sections[Weapons].blocks[shield].text Shields block -> Shields block arrows",
+		"added content.sections[Magic]",
+	}, "\n")
+	if got != want {
+		t.Errorf("changes:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNodes_RemovedSectionAndBlock(t *testing.T) {
+	a, b := baseNode(), baseNode()
+	b.Content.Sections = b.Content.Sections[:1]
+	b.Content.Sections[0].Blocks = b.Content.Sections[0].Blocks[1:]
+
+	got := summary(Nodes(a, b))
+	want := strings.Join([]string{
+		"removed content.sections[Armor]",
+		"removed content.sections[Weapons].blocks[sword]",
+	}, "\n")
+	if got != want {
+		t.Errorf("changes:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNodes_WordDiff(t *testing.T) {
+	a, b := baseNode(), baseNode()
+	b.Summary = "Players duel with swords and tower shields"
+
+	changes := Nodes(a, b)
+	if len(changes) != 1 || changes[0].Path != "summary" {
+		t.Fatalf("expected one summary change, got:\n%s", summary(changes))
+	}
+	var rendered strings.Builder
+	for _, w := range changes[0].Words {
+		switch w.Op {
+		case Equal:
+			rendered.WriteString(w.Text)
+		case Delete:
+			rendered.WriteString("[-" + w.Text + "-]")
+		case Insert:
+			rendered.WriteString("{+" + w.Text + "+}")
+		}
+	}
+	want := "Players [-fight-]{+duel+} with swords and{+ tower+} shields"
+	if rendered.String() != want {
+		t.Errorf("word diff = %q, want %q", rendered.String(), want)
+	}
+}
+
+func TestWords_Reconstructs(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"", "new text"},
+		{"old text", ""},
+		{"a b c d", "a x c y"},
+		{"same", "same"},
+		{"line one\nline two", "line one\nline 2\nline three"},
+	}
+	for _, tt := range tests {
+		var old, new strings.Builder
+		for _, w := range Words(tt.a, tt.b) {
+			if w.Op != Insert {
+				old.WriteString(w.Text)
+			}
+			if w.Op != Delete {
+				new.WriteString(w.Text)
+			}
+		}
+		if old.String() != tt.a || new.String() != tt.b {
+			t.Errorf("Words(%q, %q) rebuilds %q and %q", tt.a, tt.b, old.String(), new.String())
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	combat := baseNode()
+	magic := domain.Node{ID: "systems/magic", Kind: "system", Version: 1, Title: "Magic"}
+	lore := domain.Node{ID: "lore/old-world", Kind: "lore", Version: 2, Title: "Old World"}
+	retired := domain.Node{ID: "systems/crafting", Kind: "system", Version: 1, Title: "Crafting"}
+	before := []domain.Node{combat, magic, lore, retired}
+
+	edited := baseNode()
+	edited.Version = 4
+	moved := lore
+	moved.ID = "lore/world"
+	renamed := magic
+	renamed.ID = "systems/spells"
+	renamed.Version = 2
+	quests := domain.Node{ID: "systems/quests", Kind: "system", Version: 1, Title: "Quests"}
+	after := []domain.Node{edited, moved, renamed, quests}
+
+	var lines []string
+	for _, c := range Project(before, after) {
+		line := c.Kind + " " + c.ID
+		if c.From != "" {
+			line += " from " + c.From
+		}
+		if len(c.Changes) > 0 {
+			line += " [" + summary(c.Changes) + "]"
+		}
+		lines = append(lines, line)
+	}
+	want := strings.Join([]string{
+		"moved lore/world from lore/old-world",
+		"modified systems/combat [modified version 3 -> 4]",
+		"removed systems/crafting",
+		"added systems/quests",
+		"moved systems/spells from systems/magic [modified version 1 -> 2]",
+	}, "\n")
+	if got := strings.Join(lines, "\n"); got != want {
+		t.Errorf("project changes:\n%s\nwant:\n%s", got, want)
+	}
+}
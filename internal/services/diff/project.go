@@ -0,0 +1,106 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package diff
+
+import (
+	"sort"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+// NodeChange is a change to one node between two states of a project.
+// Added and removed nodes carry no field changes.
+type NodeChange struct {
+	ID      string   `json:"id"`
+	Kind    string   `json:"kind"`
+	Title   string   `json:"title,omitempty"`
+	From    string   `json:"from,omitempty"` // previous ID of a moved node
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Project compares two sets of nodes. Nodes are matched by ID; a node that
+// disappeared is paired with one that appeared as a move when the two are
+// identical apart from their ID, or failing that share kind and title.
+// Unchanged nodes are left out and the result is sorted by ID.
+func Project(before, after []domain.Node) []NodeChange {
+	old := make(map[string]domain.Node, len(before))
+	for _, n := range before {
+		old[n.ID] = n
+	}
+	seen := make(map[string]bool, len(after))
+
+	var out []NodeChange
+	var added []domain.Node
+	for _, n := range after {
+		seen[n.ID] = true
+		prev, ok := old[n.ID]
+		if !ok {
+			added = append(added, n)
+			continue
+		}
+		if changes := Nodes(prev, n); len(changes) > 0 {
+			out = append(out, NodeChange{ID: n.ID, Kind: Modified, Title: n.Title, Changes: changes})
+		}
+	}
+	var removed []domain.Node
+	for _, n := range before {
+		if !seen[n.ID] {
+			removed = append(removed, n)
+		}
+	}
+
+	// Pair moves, exact matches first so a retitled node does not take an
+	// identical one's partner
+	movedTo := make(map[string]string)
+	movedFrom := make(map[string]bool)
+	pair := func(match func(r, a domain.Node) bool) {
+		for _, r := range removed {
+			if movedFrom[r.ID] {
+				continue
+			}
+			for _, a := range added {
+				if _, taken := movedTo[a.ID]; !taken && match(r, a) {
+					movedTo[a.ID] = r.ID
+					movedFrom[r.ID] = true
+					break
+				}
+			}
+		}
+	}
+	pair(func(r, a domain.Node) bool { return len(Nodes(withID(r, a.ID), a)) == 0 })
+	pair(func(r, a domain.Node) bool { return r.Kind == a.Kind && r.Title == a.Title && r.Title != "" })
+
+	for _, a := range added {
+		if from, ok := movedTo[a.ID]; ok {
+			out = append(out, NodeChange{ID: a.ID, Kind: Moved, Title: a.Title, From: from, Changes: Nodes(withID(old[from], a.ID), a)})
+			continue
+		}
+		out = append(out, NodeChange{ID: a.ID, Kind: Added, Title: a.Title})
+	}
+	for _, r := range removed {
+		if !movedFrom[r.ID] {
+			out = append(out, NodeChange{ID: r.ID, Kind: Removed, Title: r.Title})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func withID(n domain.Node, id string) domain.Node {
+	n.ID = id
+	return n
+}
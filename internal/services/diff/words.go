@@ -0,0 +1,132 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package diff
+
+import "regexp"
+
+// Word operations.
+const (
+	Equal  = "="
+	Delete = "-"
+	Insert = "+"
+)
+
+// Word is a run of text kept, deleted or inserted by a word-level diff.
+// Joining the Equal and Delete runs gives the old text; joining the Equal
+// and Insert runs gives the new one.
+type Word struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxWordCells bounds the LCS table. Texts that differ in more words than
+// this allows are reported as one deletion and one insertion.
+const maxWordCells = 4_000_000
+
+var tokenPattern = regexp.MustCompile(`\s+|\S+`)
+
+// Words returns a word-level diff of two texts. Words and the whitespace
+// between them are separate tokens, so the runs reproduce both texts
+// exactly.
+func Words(a, b string) []Word {
+	at, bt := tokenPattern.FindAllString(a, -1), tokenPattern.FindAllString(b, -1)
+
+	// Common prefix and suffix need no table
+	pre := 0
+	for pre < len(at) && pre < len(bt) && at[pre] == bt[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(at)-pre && suf < len(bt)-pre && at[len(at)-1-suf] == bt[len(bt)-1-suf] {
+		suf++
+	}
+
+	var words []Word
+	emit := func(op, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(words); n > 0 && words[n-1].Op == op {
+			words[n-1].Text += text
+			return
+		}
+		words = append(words, Word{Op: op, Text: text})
+	}
+
+	for _, t := range at[:pre] {
+		emit(Equal, t)
+	}
+	am, bm := at[pre:len(at)-suf], bt[pre:len(bt)-suf]
+	if len(am)*len(bm) > maxWordCells {
+		for _, t := range am {
+			emit(Delete, t)
+		}
+		for _, t := range bm {
+			emit(Insert, t)
+		}
+	} else {
+		for _, w := range tokenDiff(am, bm) {
+			emit(w.Op, w.Text)
+		}
+	}
+	for _, t := range at[len(at)-suf:] {
+		emit(Equal, t)
+	}
+	return words
+}
+
+// tokenDiff aligns two token lists on their longest common subsequence.
+// Deletions come before insertions within a changed stretch.
+func tokenDiff(a, b []string) []Word {
+	n, m := len(a), len(b)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	var out, ins []Word
+	flush := func() {
+		out = append(out, ins...)
+		ins = nil
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			flush()
+			out = append(out, Word{Op: Equal, Text: a[i]})
+			i++
+			j++
+		case j >= m || (i < n && table[i+1][j] >= table[i][j+1]):
+			out = append(out, Word{Op: Delete, Text: a[i]})
+			i++
+		default:
+			ins = append(ins, Word{Op: Insert, Text: b[j]})
+			j++
+		}
+	}
+	flush()
+	return out
+}
@@ -0,0 +1,346 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph
+
+import (
+	"sort"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+// Degree is the number of distinct nodes referencing (In) and referenced by (Out) a node.
+type Degree struct {
+	ID  string `json:"id"`
+	In  int    `json:"in"`
+	Out int    `json:"out"`
+}
+
+// Centrality is a node's normalized betweenness centrality (0..1).
+type Centrality struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// Analytics describes the shape of a reference graph.
+type Analytics struct {
+	Nodes int `json:"nodes"`
+	Edges int `json:"edges"` // distinct (from, to) pairs
+
+	// Orphans have no inbound or outbound references.
+	Orphans []string `json:"orphans"`
+
+	// FanIn and FanOut list the nodes with the most inbound and outbound
+	// references, highest first.
+	FanIn  []Degree `json:"fan_in"`
+	FanOut []Degree `json:"fan_out"`
+
+	// WeakComponents groups nodes connected when edge direction is ignored,
+	// largest first. Orphans form single-node components.
+	WeakComponents [][]string `json:"weak_components"`
+
+	// StrongComponents counts strongly connected components; Cycles lists
+	// those with more than one node.
+	StrongComponents int        `json:"strong_components"`
+	Cycles           [][]string `json:"cycles"`
+
+	// LongestChain is the longest dependency chain, following edges from
+	// the first node. Nodes in a cycle count as a single step.
+	LongestChain []string `json:"longest_chain"`
+
+	// Bottlenecks are the nodes with the highest non-zero betweenness
+	// centrality, i.e. nodes that many shortest paths pass through.
+	Bottlenecks []Centrality `json:"bottlenecks"`
+
+	// KindMatrix counts edges by source kind, then target kind.
+	KindMatrix map[string]map[string]int `json:"kind_matrix"`
+}
+
+// Analyze computes structural metrics for the graph over the given edges
+// (typically from BuildEdges). Parallel edges of different reference types
+// count once. Leader lists (FanIn, FanOut, Bottlenecks) are limited to top
+// entries; top <= 0 means unlimited.
+func (b *Builder) Analyze(g *domain.Graph, edges []Edge, top int) Analytics {
+	nodes := g.All()
+	ids := make([]string, 0, len(nodes))
+	kinds := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		ids = append(ids, n.ID)
+		kinds[n.ID] = n.Kind
+	}
+	sort.Strings(ids)
+
+	out := make(map[string][]string)
+	in := make(map[string][]string)
+	seen := make(map[[2]string]bool)
+	for _, e := range edges {
+		key := [2]string{e.From, e.To}
+		_, fromOK := kinds[e.From]
+		_, toOK := kinds[e.To]
+		if seen[key] || !fromOK || !toOK {
+			continue
+		}
+		seen[key] = true
+		out[e.From] = append(out[e.From], e.To)
+		in[e.To] = append(in[e.To], e.From)
+	}
+	for _, id := range ids {
+		sort.Strings(out[id])
+		sort.Strings(in[id])
+	}
+
+	a := Analytics{
+		Nodes:      len(ids),
+		Edges:      len(seen),
+		Orphans:    []string{},
+		KindMatrix: make(map[string]map[string]int),
+	}
+
+	degrees := make([]Degree, 0, len(ids))
+	for _, id := range ids {
+		d := Degree{ID: id, In: len(in[id]), Out: len(out[id])}
+		degrees = append(degrees, d)
+		if d.In == 0 && d.Out == 0 {
+			a.Orphans = append(a.Orphans, id)
+		}
+		for _, to := range out[id] {
+			if a.KindMatrix[kinds[id]] == nil {
+				a.KindMatrix[kinds[id]] = make(map[string]int)
+			}
+			a.KindMatrix[kinds[id]][kinds[to]]++
+		}
+	}
+
+	a.FanIn = leaders(degrees, top, func(d Degree) int { return d.In })
+	a.FanOut = leaders(degrees, top, func(d Degree) int { return d.Out })
+	a.WeakComponents = weakComponents(ids, in, out)
+
+	sccs := strongComponents(ids, out)
+	a.StrongComponents = len(sccs)
+	a.Cycles = [][]string{}
+	for _, c := range sccs {
+		if len(c) > 1 {
+			a.Cycles = append(a.Cycles, c)
+		}
+	}
+	a.LongestChain = longestChain(ids, out, sccs)
+	a.Bottlenecks = bottlenecks(ids, out, top)
+
+	return a
+}
+
+// leaders returns the degrees with a non-zero metric, highest first.
+func leaders(degrees []Degree, top int, metric func(Degree) int) []Degree {
+	result := []Degree{}
+	for _, d := range degrees {
+		if metric(d) > 0 {
+			result = append(result, d)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return metric(result[i]) > metric(result[j]) })
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
+
+func weakComponents(ids []string, in, out map[string][]string) [][]string {
+	visited := make(map[string]bool)
+	components := [][]string{}
+
+	for _, id := range ids {
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		component := []string{}
+		stack := []string{id}
+		for len(stack) > 0 {
+			cur := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component = append(component, cur)
+			for _, next := range append(append([]string{}, out[cur]...), in[cur]...) {
+				if !visited[next] {
+					visited[next] = true
+					stack = append(stack, next)
+				}
+			}
+		}
+		sort.Strings(component)
+		components = append(components, component)
+	}
+
+	sort.SliceStable(components, func(i, j int) bool { return len(components[i]) > len(components[j]) })
+	return components
+}
+
+// strongComponents returns the strongly connected components (Tarjan), each
+// sorted, in reverse topological order of the condensation.
+func strongComponents(ids []string, out map[string][]string) [][]string {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	next := 0
+
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = next
+		low[id] = next
+		next++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, to := range out[id] {
+			if _, ok := index[to]; !ok {
+				visit(to)
+				low[id] = min(low[id], low[to])
+			} else if onStack[to] {
+				low[id] = min(low[id], index[to])
+			}
+		}
+
+		if low[id] == index[id] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == id {
+					break
+				}
+			}
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, id := range ids {
+		if _, ok := index[id]; !ok {
+			visit(id)
+		}
+	}
+	return components
+}
+
+// longestChain finds the longest path through the condensation of the graph,
+// returning the first node of each component on it. Ties keep the
+// lexicographically smallest start.
+func longestChain(ids []string, out map[string][]string, sccs [][]string) []string {
+	comp := make(map[string]int)
+	for i, c := range sccs {
+		for _, id := range c {
+			comp[id] = i
+		}
+	}
+
+	// Tarjan emits components in reverse topological order, so every
+	// successor of component i has a smaller index and is already resolved.
+	length := make([]int, len(sccs))
+	succ := make([]int, len(sccs))
+	for i, c := range sccs {
+		length[i] = 1
+		succ[i] = -1
+		for _, id := range c {
+			for _, to := range out[id] {
+				j := comp[to]
+				if j == i {
+					continue
+				}
+				if length[j]+1 > length[i] || length[j]+1 == length[i] && sccs[j][0] < sccs[succ[i]][0] {
+					length[i] = length[j] + 1
+					succ[i] = j
+				}
+			}
+		}
+	}
+
+	best := -1
+	for _, id := range ids {
+		i := comp[id]
+		if best < 0 || length[i] > length[best] {
+			best = i
+		}
+	}
+	if best < 0 || length[best] < 2 {
+		return []string{}
+	}
+
+	var chain []string
+	for i := best; i >= 0; i = succ[i] {
+		chain = append(chain, sccs[i][0])
+	}
+	return chain
+}
+
+// bottlenecks computes betweenness centrality with Brandes' algorithm and
+// returns the nodes with a non-zero score, highest first.
+func bottlenecks(ids []string, out map[string][]string, top int) []Centrality {
+	n := len(ids)
+	score := make(map[string]float64, n)
+
+	for _, s := range ids {
+		var order []string
+		preds := make(map[string][]string)
+		sigma := map[string]float64{s: 1}
+		dist := map[string]int{s: 0}
+		queue := []string{s}
+
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			order = append(order, v)
+			for _, w := range out[v] {
+				if _, ok := dist[w]; !ok {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+
+		delta := make(map[string]float64)
+		for i := len(order) - 1; i >= 0; i-- {
+			w := order[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				score[w] += delta[w]
+			}
+		}
+	}
+
+	result := []Centrality{}
+	if n < 3 {
+		return result
+	}
+	norm := float64((n - 1) * (n - 2))
+	for _, id := range ids {
+		if score[id] > 0 {
+			result = append(result, Centrality{ID: id, Score: score[id] / norm})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/graph"
+)
+
+func analyticsNode(id, kind string, uses ...string) domain.Node {
+	n := domain.Node{ID: id, Kind: kind, Version: 1, Status: "draft", Title: id}
+	for _, u := range uses {
+		n.Refs.Uses = append(n.Refs.Uses, domain.RefLink{Target: u})
+	}
+	return n
+}
+
+// a -> b -> c -> d, e -> b, x <-> y (cycle), z orphan
+func analyticsGraph(t *testing.T) graph.Analytics {
+	t.Helper()
+	builder := graph.NewBuilder()
+	g, err := builder.Build([]domain.Node{
+		analyticsNode("a", "ui", "b"),
+		analyticsNode("b", "system", "c"),
+		analyticsNode("c", "system", "d"),
+		analyticsNode("d", "data"),
+		analyticsNode("e", "ui", "b"),
+		analyticsNode("x", "system", "y"),
+		analyticsNode("y", "system", "x"),
+		analyticsNode("z", "data"),
+	})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	return builder.Analyze(g, builder.BuildEdges(g, nil), 0)
+}
+
+func TestAnalyze_Counts(t *testing.T) {
+	a := analyticsGraph(t)
+
+	if a.Nodes != 8 || a.Edges != 6 {
+		t.Errorf("expected 8 nodes and 6 edges, got %d and %d", a.Nodes, a.Edges)
+	}
+	if !reflect.DeepEqual(a.Orphans, []string{"z"}) {
+		t.Errorf("orphans = %v", a.Orphans)
+	}
+}
+
+func TestAnalyze_Degrees(t *testing.T) {
+	a := analyticsGraph(t)
+
+	if len(a.FanIn) == 0 || a.FanIn[0].ID != "b" || a.FanIn[0].In != 2 {
+		t.Errorf("expected b to lead fan-in with 2, got %+v", a.FanIn)
+	}
+	for _, d := range a.FanOut {
+		if d.Out == 0 {
+			t.Errorf("fan-out leaders should exclude zero-degree nodes, got %+v", d)
+		}
+	}
+}
+
+func TestAnalyze_Components(t *testing.T) {
+	a := analyticsGraph(t)
+
+	want := [][]string{{"a", "b", "c", "d", "e"}, {"x", "y"}, {"z"}}
+	if !reflect.DeepEqual(a.WeakComponents, want) {
+		t.Errorf("weak components = %v, want %v", a.WeakComponents, want)
+	}
+	if a.StrongComponents != 7 {
+		t.Errorf("expected 7 strong components, got %d", a.StrongComponents)
+	}
+	if !reflect.DeepEqual(a.Cycles, [][]string{{"x", "y"}}) {
+		t.Errorf("cycles = %v", a.Cycles)
+	}
+}
+
+func TestAnalyze_LongestChain(t *testing.T) {
+	a := analyticsGraph(t)
+
+	want := []string{"a", "b", "c", "d"}
+	if !reflect.DeepEqual(a.LongestChain, want) {
+		t.Errorf("longest chain = %v, want %v", a.LongestChain, want)
+	}
+}
+
+func TestAnalyze_Bottlenecks(t *testing.T) {
+	a := analyticsGraph(t)
+
+	if len(a.Bottlenecks) < 2 {
+		t.Fatalf("expected b and c as bottlenecks, got %+v", a.Bottlenecks)
+	}
+	// b sits on a->c, a->d, e->c, e->d; c sits on a->d, b->d, e->d
+	if a.Bottlenecks[0].ID != "b" || a.Bottlenecks[1].ID != "c" {
+		t.Errorf("unexpected bottleneck order: %+v", a.Bottlenecks)
+	}
+	if got := a.Bottlenecks[0].Score; got <= 0 || got > 1 {
+		t.Errorf("expected normalized score in (0,1], got %f", got)
+	}
+}
+
+func TestAnalyze_KindMatrix(t *testing.T) {
+	a := analyticsGraph(t)
+
+	if a.KindMatrix["ui"]["system"] != 2 {
+		t.Errorf("expected 2 ui->system edges, got %d", a.KindMatrix["ui"]["system"])
+	}
+	if a.KindMatrix["system"]["data"] != 1 || a.KindMatrix["system"]["system"] != 3 {
+		t.Errorf("unexpected system row: %v", a.KindMatrix["system"])
+	}
+}
+
+func TestAnalyze_TopLimit(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build([]domain.Node{
+		analyticsNode("a", "x", "c"),
+		analyticsNode("b", "x", "c", "d"),
+		analyticsNode("c", "x"),
+		analyticsNode("d", "x"),
+	})
+
+	a := builder.Analyze(g, builder.BuildEdges(g, nil), 1)
+	if len(a.FanIn) != 1 || a.FanIn[0].ID != "c" {
+		t.Errorf("expected only c in fan-in leaders, got %+v", a.FanIn)
+	}
+}
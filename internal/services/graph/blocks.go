@@ -0,0 +1,179 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+// BlockVertex is a content block taking part in block cross-references.
+type BlockVertex struct {
+	ID      string `json:"id"`       // "<node>#<section>/<block key>", with "#2", "#3"... on repeats
+	NodeID  string `json:"node_id"`  // owning node
+	Section string `json:"section"`  // section name
+	Index   int    `json:"index"`    // position within the section
+	BlockID string `json:"block_id"` // the block's id field, or its index when it has none
+	Type    string `json:"type"`     // block type
+	Label   string `json:"label"`    // name, output, title or id field, falling back to the type
+}
+
+// BlockEdge is a resolved ref constraint: the From block's Field holds Value,
+// which the To block defines in TargetField.
+type BlockEdge struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Field       string `json:"field"`
+	TargetField string `json:"target_field"`
+	Value       string `json:"value"`
+}
+
+// BlockGraph is a graph whose vertices are blocks and whose edges are
+// FieldDef ref constraints resolved against block data.
+type BlockGraph struct {
+	Vertices []BlockVertex `json:"vertices"`
+	Edges    []BlockEdge   `json:"edges"`
+}
+
+// labelFields are tried in order to find a human-readable block label.
+var labelFields = []string{"name", "output", "title", "id"}
+
+// BuildBlockGraph builds the block-level relationship graph. Vertices are
+// blocks whose type declares a ref constraint or is the target of one.
+// When types is non-empty only blocks of those types (and edges between them)
+// are kept. Vertices are ordered by node, section and position; edges by
+// source, target and field.
+func (b *Builder) BuildBlockGraph(nodes []domain.Node, blockTypes map[string]config.BlockTypeConfig, types []string) BlockGraph {
+	// Block types taking part in references, and the ref fields per source type
+	participating := make(map[string]bool)
+	refFields := make(map[string][]string)
+	for name, bt := range blockTypes {
+		for field, def := range bt.Fields {
+			if len(def.Refs) == 0 {
+				continue
+			}
+			participating[name] = true
+			refFields[name] = append(refFields[name], field)
+			for _, ref := range def.Refs {
+				participating[ref.BlockType] = true
+			}
+		}
+		sort.Strings(refFields[name])
+	}
+	if len(types) > 0 {
+		keep := make(map[string]bool)
+		for _, t := range types {
+			keep[t] = participating[t]
+		}
+		participating = keep
+	}
+
+	sorted := append([]domain.Node(nil), nodes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	g := BlockGraph{Vertices: []BlockVertex{}, Edges: []BlockEdge{}}
+	blocks := make(map[string]domain.Block)
+	taken := make(map[string]bool)
+	for _, n := range sorted {
+		if n.Content == nil {
+			continue
+		}
+		for _, section := range n.Content.Sections {
+			for i, block := range section.Blocks {
+				if !participating[block.Type] {
+					continue
+				}
+				key := fmt.Sprint(i)
+				if id, ok := block.Data["id"].(string); ok && id != "" {
+					key = id
+				}
+				// Two blocks with the same id in a section, or an id that
+				// equals another block's index, get an occurrence suffix
+				base := n.ID + "#" + section.Name + "/" + key
+				id := base
+				for k := 2; taken[id]; k++ {
+					id = fmt.Sprintf("%s#%d", base, k)
+				}
+				taken[id] = true
+				v := BlockVertex{
+					ID:      id,
+					NodeID:  n.ID,
+					Section: section.Name,
+					Index:   i,
+					BlockID: key,
+					Type:    block.Type,
+					Label:   block.Type,
+				}
+				for _, f := range labelFields {
+					if s, ok := block.Data[f].(string); ok && s != "" {
+						v.Label = s
+						break
+					}
+				}
+				g.Vertices = append(g.Vertices, v)
+				blocks[v.ID] = block
+			}
+		}
+	}
+
+	// "blockType.field" -> value -> defining vertex IDs
+	definers := make(map[string]map[string][]string)
+	for _, v := range g.Vertices {
+		for field, val := range blocks[v.ID].Data {
+			key := v.Type + "." + field
+			for _, s := range stringValues(val) {
+				if definers[key] == nil {
+					definers[key] = make(map[string][]string)
+				}
+				definers[key][s] = append(definers[key][s], v.ID)
+			}
+		}
+	}
+
+	seen := make(map[BlockEdge]bool)
+	for _, v := range g.Vertices {
+		for _, field := range refFields[v.Type] {
+			for _, value := range stringValues(blocks[v.ID].Data[field]) {
+				for _, ref := range blockTypes[v.Type].Fields[field].Refs {
+					for _, target := range definers[ref.BlockType+"."+ref.Field][value] {
+						e := BlockEdge{From: v.ID, To: target, Field: field, TargetField: ref.Field, Value: value}
+						if target == v.ID || seen[e] {
+							continue
+						}
+						seen[e] = true
+						g.Edges = append(g.Edges, e)
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		a, c := g.Edges[i], g.Edges[j]
+		if a.From != c.From {
+			return a.From < c.From
+		}
+		if a.To != c.To {
+			return a.To < c.To
+		}
+		return a.Field < c.Field
+	})
+
+	return g
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/graph"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+func craftingBlockTypes() map[string]config.BlockTypeConfig {
+	return map[string]config.BlockTypeConfig{
+		"resource": {Fields: map[string]config.FieldDef{"name": {Type: "string"}}},
+		"recipe": {Fields: map[string]config.FieldDef{
+			"output": {Type: "string"},
+			"materials": {Type: "list", Refs: []config.RefConstraint{
+				{BlockType: "resource", Field: "name"},
+				{BlockType: "recipe", Field: "output"},
+			}},
+		}},
+	}
+}
+
+func craftingNodes() []domain.Node {
+	section := func(name string, blocks ...domain.Block) domain.Section {
+		return domain.Section{Name: name, Blocks: blocks}
+	}
+	return []domain.Node{
+		{ID: "items/tools", Content: &domain.Content{Sections: []domain.Section{
+			section("Recipes", domain.Block{Type: "recipe", Data: map[string]interface{}{
+				"output": "Axe", "materials": []interface{}{"Planks", "Stone"},
+			}}),
+		}}},
+		{ID: "items/basics", Content: &domain.Content{Sections: []domain.Section{
+			section("Resources",
+				domain.Block{Type: "resource", Data: map[string]interface{}{"name": "Wood"}},
+				domain.Block{Type: "resource", Data: map[string]interface{}{"name": "Stone"}},
+				domain.Block{Type: "doc", Data: map[string]interface{}{"text": "ignored"}},
+			),
+			section("Recipes", domain.Block{Type: "recipe", Data: map[string]interface{}{
+				"id": "planks", "output": "Planks", "materials": []interface{}{"Wood", "Unknown"},
+			}}),
+		}}},
+	}
+}
+
+func TestBuildBlockGraph(t *testing.T) {
+	bg := graph.NewBuilder().BuildBlockGraph(craftingNodes(), craftingBlockTypes(), nil)
+
+	wantVertices := []string{
+		"items/basics#Resources/0",
+		"items/basics#Resources/1",
+		"items/basics#Recipes/planks",
+		"items/tools#Recipes/0",
+	}
+	if len(bg.Vertices) != len(wantVertices) {
+		t.Fatalf("expected %d vertices, got %+v", len(wantVertices), bg.Vertices)
+	}
+	for i, id := range wantVertices {
+		if bg.Vertices[i].ID != id {
+			t.Errorf("vertex %d = %s, want %s", i, bg.Vertices[i].ID, id)
+		}
+	}
+	if bg.Vertices[2].Label != "Planks" || bg.Vertices[2].BlockID != "planks" {
+		t.Errorf("unexpected recipe vertex: %+v", bg.Vertices[2])
+	}
+
+	want := []graph.BlockEdge{
+		{From: "items/basics#Recipes/planks", To: "items/basics#Resources/0", Field: "materials", TargetField: "name", Value: "Wood"},
+		{From: "items/tools#Recipes/0", To: "items/basics#Recipes/planks", Field: "materials", TargetField: "output", Value: "Planks"},
+		{From: "items/tools#Recipes/0", To: "items/basics#Resources/1", Field: "materials", TargetField: "name", Value: "Stone"},
+	}
+	if len(bg.Edges) != len(want) {
+		t.Fatalf("expected %d edges, got %+v", len(want), bg.Edges)
+	}
+	for i := range want {
+		if bg.Edges[i] != want[i] {
+			t.Errorf("edge %d = %+v, want %+v", i, bg.Edges[i], want[i])
+		}
+	}
+}
+
+func TestBuildBlockGraph_UniqueVertexIDs(t *testing.T) {
+	nodes := []domain.Node{{ID: "items/basics", Content: &domain.Content{Sections: []domain.Section{{
+		Name: "Resources",
+		Blocks: []domain.Block{
+			{Type: "resource", Data: map[string]interface{}{"id": "wood", "name": "Wood"}},
+			{Type: "resource", Data: map[string]interface{}{"id": "wood", "name": "Oak"}},
+			{Type: "resource", Data: map[string]interface{}{"id": "3", "name": "Stone"}},
+			{Type: "resource", Data: map[string]interface{}{"name": "Iron"}},
+			{Type: "recipe", Data: map[string]interface{}{"output": "Axe", "materials": []interface{}{"Oak", "Iron"}}},
+		},
+	}}}}}
+	bg := graph.NewBuilder().BuildBlockGraph(nodes, craftingBlockTypes(), nil)
+
+	want := []string{
+		"items/basics#Resources/wood",
+		"items/basics#Resources/wood#2",
+		"items/basics#Resources/3",
+		"items/basics#Resources/3#2",
+		"items/basics#Resources/4",
+	}
+	if len(bg.Vertices) != len(want) {
+		t.Fatalf("expected %d vertices, got %+v", len(want), bg.Vertices)
+	}
+	for i, id := range want {
+		if bg.Vertices[i].ID != id {
+			t.Errorf("vertex %d = %s, want %s", i, bg.Vertices[i].ID, id)
+		}
+	}
+
+	// Edges go to the blocks that define the values, not to the first
+	// block sharing their key
+	var targets []string
+	for _, e := range bg.Edges {
+		targets = append(targets, e.To)
+	}
+	if len(targets) != 2 || targets[0] != "items/basics#Resources/3#2" || targets[1] != "items/basics#Resources/wood#2" {
+		t.Errorf("unexpected edge targets %v", targets)
+	}
+}
+
+func TestBuildBlockGraph_TypeFilter(t *testing.T) {
+	bg := graph.NewBuilder().BuildBlockGraph(craftingNodes(), craftingBlockTypes(), []string{"recipe"})
+
+	if len(bg.Vertices) != 2 {
+		t.Fatalf("expected only recipe vertices, got %+v", bg.Vertices)
+	}
+	if len(bg.Edges) != 1 || bg.Edges[0].Value != "Planks" {
+		t.Errorf("expected only the recipe->recipe edge, got %+v", bg.Edges)
+	}
+}
+
+func TestBuildBlockGraph_NoRefConstraints(t *testing.T) {
+	bg := graph.NewBuilder().BuildBlockGraph(craftingNodes(), nil, nil)
+	if len(bg.Vertices) != 0 || len(bg.Edges) != 0 {
+		t.Errorf("expected empty graph without ref constraints, got %+v", bg)
+	}
+}
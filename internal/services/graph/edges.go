@@ -0,0 +1,201 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+// Edge reference types produced by BuildEdges.
+const (
+	EdgeUses     = "uses"     // refs.uses
+	EdgeRelated  = "related"  // refs.related
+	EdgeContract = "contract" // @node refs in contract steps
+	EdgeCrossRef = "crossref" // block field values resolved through ref constraints
+)
+
+// Edge is a directed reference from one node to another.
+type Edge struct {
+	From    string
+	To      string
+	RefType string
+	Context string // RefLink.Context, contract step, or cross-ref description
+}
+
+// BuildEdges collects every reference between nodes in the graph: uses and
+// related refs, contract @refs, and block cross-references declared through
+// ref constraints in blockTypes (nil skips cross-references). Edges to nodes
+// outside the graph and self-references are dropped, and each
+// (from, to, type) triple appears once, keeping the first context seen.
+// The result is sorted by from and to, with parallel edges in the order
+// uses, related, contract, crossref.
+func (b *Builder) BuildEdges(g *domain.Graph, blockTypes map[string]config.BlockTypeConfig) []Edge {
+	seen := make(map[string]bool)
+	var edges []Edge
+
+	add := func(e Edge) {
+		if e.From == e.To {
+			return
+		}
+		if _, ok := g.Get(e.To); !ok {
+			return
+		}
+		key := e.From + "\x00" + e.To + "\x00" + e.RefType
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		edges = append(edges, e)
+	}
+
+	nodes := g.All()
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	for _, n := range nodes {
+		for _, link := range n.Refs.Uses {
+			add(Edge{From: n.ID, To: link.Target, RefType: EdgeUses, Context: link.Context})
+		}
+		for _, link := range n.Refs.Related {
+			add(Edge{From: n.ID, To: link.Target, RefType: EdgeRelated, Context: link.Context})
+		}
+		for _, scenario := range domain.ParseContracts(n.Contracts) {
+			for _, step := range scenario.AllSteps() {
+				for _, ref := range step.NodeRefs {
+					add(Edge{
+						From:    n.ID,
+						To:      ref,
+						RefType: EdgeContract,
+						Context: fmt.Sprintf("contract %q: %s", scenario.Name, step.Text),
+					})
+				}
+			}
+		}
+	}
+
+	for _, e := range crossRefEdges(nodes, blockTypes) {
+		add(e)
+	}
+
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		return edgeRank[edges[i].RefType] < edgeRank[edges[j].RefType]
+	})
+
+	return edges
+}
+
+// edgeRank orders parallel edges from the most to the least explicit reference.
+var edgeRank = map[string]int{EdgeUses: 0, EdgeRelated: 1, EdgeContract: 2, EdgeCrossRef: 3}
+
+// crossRefEdges links a node whose block field references a value (via a ref
+// constraint) to every node that defines that value in the target block field.
+func crossRefEdges(nodes []domain.Node, blockTypes map[string]config.BlockTypeConfig) []Edge {
+	if len(blockTypes) == 0 {
+		return nil
+	}
+
+	// "blockType.field" -> value -> defining node IDs (in node order)
+	definers := make(map[string]map[string][]string)
+	for _, n := range nodes {
+		forEachBlock(n, func(block domain.Block) {
+			for field, val := range block.Data {
+				s, ok := val.(string)
+				if !ok {
+					continue
+				}
+				key := block.Type + "." + field
+				if definers[key] == nil {
+					definers[key] = make(map[string][]string)
+				}
+				ids := definers[key][s]
+				if len(ids) == 0 || ids[len(ids)-1] != n.ID {
+					definers[key][s] = append(ids, n.ID)
+				}
+			}
+		})
+	}
+
+	var edges []Edge
+	for _, n := range nodes {
+		forEachBlock(n, func(block domain.Block) {
+			cfg, ok := blockTypes[block.Type]
+			if !ok {
+				return
+			}
+			fields := make([]string, 0, len(cfg.Fields))
+			for name, def := range cfg.Fields {
+				if len(def.Refs) > 0 {
+					fields = append(fields, name)
+				}
+			}
+			sort.Strings(fields)
+
+			for _, field := range fields {
+				for _, value := range stringValues(block.Data[field]) {
+					for _, ref := range cfg.Fields[field].Refs {
+						key := ref.BlockType + "." + ref.Field
+						for _, target := range definers[key][value] {
+							edges = append(edges, Edge{
+								From:    n.ID,
+								To:      target,
+								RefType: EdgeCrossRef,
+								Context: fmt.Sprintf("%s.%s -> %s = %s", block.Type, field, key, value),
+							})
+						}
+					}
+				}
+			}
+		})
+	}
+	return edges
+}
+
+func forEachBlock(n domain.Node, fn func(domain.Block)) {
+	if n.Content == nil {
+		return
+	}
+	for _, section := range n.Content.Sections {
+		for _, block := range section.Blocks {
+			fn(block)
+		}
+	}
+}
+
+// stringValues returns the string values of a scalar or list block field.
+func stringValues(val interface{}) []string {
+	switch v := val.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph
+
+import (
+	"sort"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+// ReverseEdge is an incoming reference: From references the indexed node via RefType.
+type ReverseEdge struct {
+	From    string
+	RefType string // "uses" or "related"
+	Context string
+}
+
+// ImpactOptions controls a transitive reverse-dependency walk.
+type ImpactOptions struct {
+	// MaxDepth limits how many hops to follow (0 means unlimited).
+	MaxDepth int
+
+	// RefTypes restricts which reference types are followed (empty means all).
+	RefTypes []string
+}
+
+// ImpactEntry is a node reached while walking reverse references.
+type ImpactEntry struct {
+	ID      string
+	Depth   int    // hops from the nearest root
+	Via     string // the node this one references on its shortest path to a root
+	RefType string // reference type of the edge to Via
+	Context string // RefLink.Context of the edge to Via
+}
+
+// BuildTypedReverseIndex creates a reverse reference index that keeps the
+// reference type and context of every incoming edge. Edges to nodes that are
+// not in the graph are ignored, and each (from, type) pair is recorded once.
+func (b *Builder) BuildTypedReverseIndex(g *domain.Graph) map[string][]ReverseEdge {
+	index := make(map[string][]ReverseEdge)
+
+	for _, node := range g.All() {
+		index[node.ID] = nil
+	}
+
+	for _, node := range g.All() {
+		add := func(refType string, links []domain.RefLink) {
+			seen := make(map[string]bool)
+			for _, link := range links {
+				if _, exists := g.Get(link.Target); !exists || seen[link.Target] {
+					continue
+				}
+				seen[link.Target] = true
+				index[link.Target] = append(index[link.Target], ReverseEdge{
+					From:    node.ID,
+					RefType: refType,
+					Context: link.Context,
+				})
+			}
+		}
+		add("uses", node.Refs.Uses)
+		add("related", node.Refs.Related)
+	}
+
+	for id := range index {
+		edges := index[id]
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i].From != edges[j].From {
+				return edges[i].From < edges[j].From
+			}
+			return edges[i].RefType < edges[j].RefType
+		})
+	}
+
+	return index
+}
+
+// Impact walks reverse references breadth-first from the given roots and
+// returns every node that transitively depends on them, ordered by depth and
+// then ID. Roots themselves are not included. Each node appears once, at the
+// depth of its shortest path to any root.
+func (b *Builder) Impact(g *domain.Graph, roots []string, opts ImpactOptions) []ImpactEntry {
+	index := b.BuildTypedReverseIndex(g)
+
+	allowed := make(map[string]bool)
+	for _, t := range opts.RefTypes {
+		allowed[t] = true
+	}
+
+	visited := make(map[string]bool)
+	queue := make([]string, 0, len(roots))
+	for _, id := range roots {
+		if _, exists := g.Get(id); exists && !visited[id] {
+			visited[id] = true
+			queue = append(queue, id)
+		}
+	}
+	sort.Strings(queue)
+
+	var result []ImpactEntry
+	for depth := 1; len(queue) > 0; depth++ {
+		if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+			break
+		}
+
+		var next []string
+		var level []ImpactEntry
+		for _, id := range queue {
+			for _, e := range index[id] {
+				if len(allowed) > 0 && !allowed[e.RefType] {
+					continue
+				}
+				if visited[e.From] {
+					continue
+				}
+				visited[e.From] = true
+				next = append(next, e.From)
+				level = append(level, ImpactEntry{
+					ID:      e.From,
+					Depth:   depth,
+					Via:     id,
+					RefType: e.RefType,
+					Context: e.Context,
+				})
+			}
+		}
+
+		sort.Slice(level, func(i, j int) bool { return level[i].ID < level[j].ID })
+		result = append(result, level...)
+		sort.Strings(next)
+		queue = next
+	}
+
+	return result
+}
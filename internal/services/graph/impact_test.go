@@ -0,0 +1,111 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/graph"
+)
+
+// impactNodes builds: db <- api (uses) <- ui (uses), docs -related-> api, cycle <-> ui
+func impactNodes() []domain.Node {
+	return []domain.Node{
+		{ID: "db", Kind: "component", Version: 1, Status: "approved", Title: "DB"},
+		{ID: "api", Kind: "system", Version: 1, Status: "draft", Title: "API",
+			Refs: domain.Ref{Uses: []domain.RefLink{{Target: "db", Context: "stores users"}}}},
+		{ID: "ui", Kind: "system", Version: 1, Status: "draft", Title: "UI",
+			Refs: domain.Ref{Uses: []domain.RefLink{{Target: "api"}, {Target: "cycle"}}}},
+		{ID: "docs", Kind: "doc", Version: 1, Status: "draft", Title: "Docs",
+			Refs: domain.Ref{Related: []domain.RefLink{{Target: "api"}}}},
+		{ID: "cycle", Kind: "system", Version: 1, Status: "draft", Title: "Cycle",
+			Refs: domain.Ref{Uses: []domain.RefLink{{Target: "ui"}, {Target: "missing"}}}},
+	}
+}
+
+func TestImpact_Transitive(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(impactNodes())
+
+	entries := builder.Impact(g, []string{"db"}, graph.ImpactOptions{})
+
+	want := []struct {
+		id, via string
+		depth   int
+	}{
+		{"api", "db", 1},
+		{"docs", "api", 2},
+		{"ui", "api", 2},
+		{"cycle", "ui", 3},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i, w := range want {
+		if entries[i].ID != w.id || entries[i].Via != w.via || entries[i].Depth != w.depth {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], w)
+		}
+	}
+	if entries[0].Context != "stores users" || entries[0].RefType != "uses" {
+		t.Errorf("expected edge context and type on first entry, got %+v", entries[0])
+	}
+}
+
+func TestImpact_DepthLimit(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(impactNodes())
+
+	entries := builder.Impact(g, []string{"db"}, graph.ImpactOptions{MaxDepth: 1})
+	if len(entries) != 1 || entries[0].ID != "api" {
+		t.Errorf("expected only api at depth 1, got %+v", entries)
+	}
+}
+
+func TestImpact_RefTypeFilter(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(impactNodes())
+
+	entries := builder.Impact(g, []string{"api"}, graph.ImpactOptions{RefTypes: []string{"related"}})
+	if len(entries) != 1 || entries[0].ID != "docs" || entries[0].RefType != "related" {
+		t.Errorf("expected only docs via related, got %+v", entries)
+	}
+}
+
+func TestImpact_UnknownRoot(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(impactNodes())
+
+	if entries := builder.Impact(g, []string{"nope"}, graph.ImpactOptions{}); len(entries) != 0 {
+		t.Errorf("expected no entries for unknown root, got %+v", entries)
+	}
+}
+
+func TestBuildTypedReverseIndex(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(impactNodes())
+
+	index := builder.BuildTypedReverseIndex(g)
+	if len(index["api"]) != 2 {
+		t.Fatalf("expected 2 incoming edges for api, got %+v", index["api"])
+	}
+	if index["api"][0].From != "docs" || index["api"][0].RefType != "related" {
+		t.Errorf("unexpected first edge: %+v", index["api"][0])
+	}
+	if _, ok := index["missing"]; ok {
+		t.Error("expected dangling targets to be left out of the index")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph
+
+import "sort"
+
+// ShortestPath returns the shortest chain of edges leading from one node to
+// another, found breadth-first. Ties are broken by edge order (see BuildEdges).
+// Returns nil if to is unreachable or from == to.
+func (b *Builder) ShortestPath(edges []Edge, from, to string) []Edge {
+	if from == to {
+		return nil
+	}
+	adj := adjacency(edges)
+
+	prev := map[string]Edge{}
+	visited := map[string]bool{from: true}
+	queue := []string{from}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, e := range adj[id] {
+			if visited[e.To] {
+				continue
+			}
+			visited[e.To] = true
+			prev[e.To] = e
+			if e.To == to {
+				var path []Edge
+				for cur := to; cur != from; cur = prev[cur].From {
+					path = append([]Edge{prev[cur]}, path...)
+				}
+				return path
+			}
+			queue = append(queue, e.To)
+		}
+	}
+
+	return nil
+}
+
+// AllPaths returns every simple path (no repeated nodes) from one node to
+// another using at most maxHops edges, shortest first. Parallel edges of
+// different types yield distinct paths.
+func (b *Builder) AllPaths(edges []Edge, from, to string, maxHops int) [][]Edge {
+	if from == to || maxHops <= 0 {
+		return nil
+	}
+	adj := adjacency(edges)
+
+	var paths [][]Edge
+	onPath := map[string]bool{from: true}
+	var current []Edge
+
+	var walk func(id string)
+	walk = func(id string) {
+		if len(current) == maxHops {
+			return
+		}
+		for _, e := range adj[id] {
+			if onPath[e.To] {
+				continue
+			}
+			current = append(current, e)
+			if e.To == to {
+				paths = append(paths, append([]Edge(nil), current...))
+			} else {
+				onPath[e.To] = true
+				walk(e.To)
+				delete(onPath, e.To)
+			}
+			current = current[:len(current)-1]
+		}
+	}
+	walk(from)
+
+	sort.SliceStable(paths, func(i, j int) bool { return len(paths[i]) < len(paths[j]) })
+	return paths
+}
+
+func adjacency(edges []Edge) map[string][]Edge {
+	adj := make(map[string][]Edge)
+	for _, e := range edges {
+		adj[e.From] = append(adj[e.From], e)
+	}
+	return adj
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/graph"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+func pathNodes() []domain.Node {
+	return []domain.Node{
+		{ID: "ui", Kind: "system", Version: 1, Status: "draft", Title: "UI",
+			Refs: domain.Ref{Uses: []domain.RefLink{{Target: "api", Context: "fetches invoices"}}},
+			Contracts: []domain.Contract{{
+				Name: "Checkout", Scenario: "Pay",
+				When: []string{"user pays via @billing"},
+			}}},
+		{ID: "api", Kind: "system", Version: 1, Status: "draft", Title: "API",
+			Refs: domain.Ref{Related: []domain.RefLink{{Target: "billing"}}}},
+		{ID: "billing", Kind: "system", Version: 1, Status: "draft", Title: "Billing",
+			Content: &domain.Content{Sections: []domain.Section{{Name: "s", Blocks: []domain.Block{
+				{Type: "store", Data: map[string]interface{}{"table": "invoices"}},
+			}}}}},
+		{ID: "db", Kind: "component", Version: 1, Status: "draft", Title: "DB",
+			Content: &domain.Content{Sections: []domain.Section{{Name: "s", Blocks: []domain.Block{
+				{Type: "table", Data: map[string]interface{}{"name": "invoices"}},
+			}}}}},
+	}
+}
+
+func pathBlockTypes() map[string]config.BlockTypeConfig {
+	return map[string]config.BlockTypeConfig{
+		"store": {Fields: map[string]config.FieldDef{
+			"table": {Type: "string", Refs: []config.RefConstraint{{BlockType: "table", Field: "name"}}},
+		}},
+	}
+}
+
+func TestBuildEdges_AllKinds(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(pathNodes())
+
+	edges := builder.BuildEdges(g, pathBlockTypes())
+
+	want := map[string]string{
+		"api->billing:related": "",
+		"billing->db:crossref": "store.table -> table.name = invoices",
+		"ui->api:uses":         "fetches invoices",
+		"ui->billing:contract": `contract "Checkout": user pays via @billing`,
+	}
+	if len(edges) != len(want) {
+		t.Fatalf("expected %d edges, got %d: %+v", len(want), len(edges), edges)
+	}
+	for _, e := range edges {
+		key := e.From + "->" + e.To + ":" + e.RefType
+		ctx, ok := want[key]
+		if !ok {
+			t.Errorf("unexpected edge %s", key)
+			continue
+		}
+		if e.Context != ctx {
+			t.Errorf("edge %s context = %q, want %q", key, e.Context, ctx)
+		}
+	}
+}
+
+func TestBuildEdges_NoBlockTypes(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(pathNodes())
+
+	for _, e := range builder.BuildEdges(g, nil) {
+		if e.RefType == graph.EdgeCrossRef {
+			t.Errorf("expected no cross-ref edges without block types, got %+v", e)
+		}
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(pathNodes())
+	edges := builder.BuildEdges(g, pathBlockTypes())
+
+	path := builder.ShortestPath(edges, "ui", "db")
+	if len(path) != 2 {
+		t.Fatalf("expected 2 hops, got %+v", path)
+	}
+	if path[0].To != "billing" || path[0].RefType != graph.EdgeContract || path[1].To != "db" {
+		t.Errorf("unexpected path: %+v", path)
+	}
+
+	if p := builder.ShortestPath(edges, "db", "ui"); p != nil {
+		t.Errorf("expected no reverse path, got %+v", p)
+	}
+}
+
+func TestAllPaths(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(pathNodes())
+	edges := builder.BuildEdges(g, pathBlockTypes())
+
+	paths := builder.AllPaths(edges, "ui", "db", 5)
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths, got %d: %+v", len(paths), paths)
+	}
+	if len(paths[0]) != 2 || len(paths[1]) != 3 {
+		t.Errorf("expected paths ordered by length, got %d then %d hops", len(paths[0]), len(paths[1]))
+	}
+
+	if paths := builder.AllPaths(edges, "ui", "db", 2); len(paths) != 1 {
+		t.Errorf("expected hop limit to drop the longer path, got %d", len(paths))
+	}
+}
+
+func TestNeighborhood(t *testing.T) {
+	builder := graph.NewBuilder()
+	g, _ := builder.Build(pathNodes())
+	edges := builder.BuildEdges(g, pathBlockTypes())
+
+	tests := []struct {
+		name      string
+		root      string
+		depth     int
+		direction string
+		want      []string
+	}{
+		{"out one hop", "ui", 1, graph.DirectionOut, []string{"ui", "api", "billing"}},
+		{"out unlimited", "api", 0, graph.DirectionOut, []string{"api", "billing", "db"}},
+		{"in one hop", "billing", 1, graph.DirectionIn, []string{"billing", "ui", "api"}},
+		{"both one hop", "billing", 1, graph.DirectionBoth, []string{"billing", "ui", "api", "db"}},
+		{"leaf out", "db", 0, graph.DirectionOut, []string{"db"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := builder.Neighborhood(edges, []string{tt.root}, tt.depth, tt.direction)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for _, id := range tt.want {
+				if !got[id] {
+					t.Errorf("missing %s in %v", id, got)
+				}
+			}
+		})
+	}
+}
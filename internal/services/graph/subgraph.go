@@ -0,0 +1,61 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package graph
+
+// Traversal directions for Neighborhood.
+const (
+	DirectionOut  = "out"  // follow references (what the roots depend on)
+	DirectionIn   = "in"   // follow reverse references (what depends on the roots)
+	DirectionBoth = "both" // ignore edge direction
+)
+
+// Neighborhood returns the set of node IDs reachable from roots within depth
+// hops (0 means unlimited) in the given direction. Roots are always included.
+func (b *Builder) Neighborhood(edges []Edge, roots []string, depth int, direction string) map[string]bool {
+	next := make(map[string][]string)
+	for _, e := range edges {
+		if direction != DirectionIn {
+			next[e.From] = append(next[e.From], e.To)
+		}
+		if direction != DirectionOut {
+			next[e.To] = append(next[e.To], e.From)
+		}
+	}
+
+	visited := make(map[string]bool)
+	queue := make([]string, 0, len(roots))
+	for _, id := range roots {
+		if !visited[id] {
+			visited[id] = true
+			queue = append(queue, id)
+		}
+	}
+
+	for hop := 1; len(queue) > 0 && (depth <= 0 || hop <= depth); hop++ {
+		var level []string
+		for _, id := range queue {
+			for _, n := range next[id] {
+				if !visited[n] {
+					visited[n] = true
+					level = append(level, n)
+				}
+			}
+		}
+		queue = level
+	}
+
+	return visited
+}
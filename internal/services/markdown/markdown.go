@@ -0,0 +1,230 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package markdown provides the small amount of Markdown understanding deco
+// needs for doc references: heading anchors, anchored sections and links.
+package markdown
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Heading is an ATX heading ("## Title") found in a Markdown document.
+type Heading struct {
+	Level  int    // 1-6
+	Text   string // heading text without the leading #'s
+	Anchor string // GitHub-style slug, de-duplicated within the document
+	Line   int    // 0-based line index
+}
+
+// Link is an inline link, image or reference definition found in a document.
+type Link struct {
+	Target string // raw target as written, without title
+	Image  bool   // true for ![alt](target)
+	Line   int    // 1-based line number within the scanned text
+	Column int    // 1-based column of the link on its line
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?[ \t]*#*[ \t]*$`)
+	inlineLink     = regexp.MustCompile(`(!?)\[[^\]]*\]\(\s*(<[^>]*>|[^)\s]*)(?:\s+["'(][^)]*)?\s*\)`)
+	refDefinition  = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s*(<[^>]*>|\S+)`)
+	inlineCode     = regexp.MustCompile("`[^`]*`")
+	schemePattern  = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// SplitAnchor splits a doc path like "docs/auth.md#token-rotation" into the
+// file path and the anchor. The anchor is empty when no '#' is present.
+func SplitAnchor(path string) (file, anchor string) {
+	if i := strings.Index(path, "#"); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return path, ""
+}
+
+// IsMarkdown reports whether the path names a Markdown file.
+func IsMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// Slugify converts heading text to a GitHub-style anchor: lowercase, spaces
+// become hyphens, and punctuation other than '-' and '_' is dropped.
+func Slugify(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_':
+			b.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Headings returns all ATX headings outside fenced code blocks.
+// Duplicate anchors receive "-1", "-2", ... suffixes as on GitHub.
+func Headings(content string) []Heading {
+	var headings []Heading
+	seen := make(map[string]int)
+
+	for i, line := range codeMaskedLines(content) {
+		m := headingPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		text := strings.TrimSpace(stripInlineMarkup(m[2]))
+		anchor := Slugify(text)
+		if n, ok := seen[anchor]; ok {
+			seen[anchor] = n + 1
+			anchor = fmt.Sprintf("%s-%d", anchor, n+1)
+		} else {
+			seen[anchor] = 0
+		}
+		headings = append(headings, Heading{
+			Level:  len(m[1]),
+			Text:   text,
+			Anchor: anchor,
+			Line:   i,
+		})
+	}
+	return headings
+}
+
+// Section returns the text of the section introduced by the heading with the
+// given anchor, from the heading line up to (not including) the next heading
+// of the same or higher level. Anchor matching is case-insensitive.
+// The second return value is false when no heading has that anchor.
+func Section(content, anchor string) (string, bool) {
+	start, end, ok := sectionBounds(content, anchor)
+	if !ok {
+		return "", false
+	}
+	lines := strings.Split(content, "\n")
+	return strings.Join(lines[start:end], "\n"), true
+}
+
+// SectionStartLine returns the 0-based line index of the heading for anchor.
+func SectionStartLine(content, anchor string) (int, bool) {
+	start, _, ok := sectionBounds(content, anchor)
+	return start, ok
+}
+
+func sectionBounds(content, anchor string) (int, int, bool) {
+	anchor = strings.ToLower(anchor)
+	headings := Headings(content)
+	lineCount := strings.Count(content, "\n") + 1
+
+	for i, h := range headings {
+		if h.Anchor != anchor {
+			continue
+		}
+		end := lineCount
+		for _, next := range headings[i+1:] {
+			if next.Level <= h.Level {
+				end = next.Line
+				break
+			}
+		}
+		return h.Line, end, true
+	}
+	return 0, 0, false
+}
+
+// HasAnchor reports whether the document contains a heading with the anchor.
+func HasAnchor(content, anchor string) bool {
+	_, ok := Section(content, anchor)
+	return ok
+}
+
+// Links returns inline links, images and reference definitions found outside
+// code. Line numbers are relative to the start of content.
+func Links(content string) []Link {
+	var links []Link
+	for i, line := range codeMaskedLines(content) {
+		// Blank out code spans without shifting columns
+		line = inlineCode.ReplaceAllStringFunc(line, func(s string) string {
+			return strings.Repeat(" ", len(s))
+		})
+		for _, m := range inlineLink.FindAllStringSubmatchIndex(line, -1) {
+			links = append(links, Link{
+				Target: strings.Trim(line[m[4]:m[5]], "<>"),
+				Image:  m[3] > m[2],
+				Line:   i + 1,
+				Column: m[0] + 1,
+			})
+		}
+		if m := refDefinition.FindStringSubmatchIndex(line); m != nil {
+			links = append(links, Link{
+				Target: strings.Trim(line[m[2]:m[3]], "<>"),
+				Line:   i + 1,
+				Column: m[0] + 1,
+			})
+		}
+	}
+	return links
+}
+
+// IsExternal reports whether a link target points outside the project
+// (URLs with a scheme, protocol-relative URLs, or mail links).
+func IsExternal(target string) bool {
+	return schemePattern.MatchString(target) || strings.HasPrefix(target, "//")
+}
+
+// codeMaskedLines splits content into lines, blanking lines that are inside
+// fenced code blocks so they are never mistaken for headings or links.
+func codeMaskedLines(content string) []string {
+	lines := strings.Split(content, "\n")
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			lines[i] = ""
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			lines[i] = ""
+		}
+	}
+	return lines
+}
+
+// stripInlineMarkup removes emphasis, code ticks and link syntax from heading
+// text so "## The `Token` [API](x)" slugs to "the-token-api".
+func stripInlineMarkup(text string) string {
+	text = inlineLink.ReplaceAllStringFunc(text, func(s string) string {
+		open := strings.Index(s, "[")
+		closeIdx := strings.Index(s, "]")
+		if open < 0 || closeIdx < open {
+			return s
+		}
+		return s[open+1 : closeIdx]
+	})
+	return strings.NewReplacer("`", "", "*", "", "~~", "").Replace(text)
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/Toernblom/deco/internal/services/markdown"
+)
+
+func TestSplitAnchor(t *testing.T) {
+	tests := []struct {
+		path, file, anchor string
+	}{
+		{"docs/auth.md", "docs/auth.md", ""},
+		{"docs/auth.md#token-rotation", "docs/auth.md", "token-rotation"},
+		{"#local", "", "local"},
+	}
+	for _, tt := range tests {
+		file, anchor := markdown.SplitAnchor(tt.path)
+		if file != tt.file || anchor != tt.anchor {
+			t.Errorf("SplitAnchor(%q) = %q, %q; want %q, %q", tt.path, file, anchor, tt.file, tt.anchor)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Token Rotation":        "token-rotation",
+		"API: v2 (beta)":        "api-v2-beta",
+		"snake_case & dashes-x": "snake_case--dashes-x",
+	}
+	for in, want := range tests {
+		if got := markdown.Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHeadings(t *testing.T) {
+	content := "# Title\n\n## Setup\n```\n# not a heading\n```\n## Setup\n### The `Token` [API](x.md) ##\n"
+	headings := markdown.Headings(content)
+
+	want := []struct {
+		level  int
+		anchor string
+	}{
+		{1, "title"},
+		{2, "setup"},
+		{2, "setup-1"},
+		{3, "the-token-api"},
+	}
+	if len(headings) != len(want) {
+		t.Fatalf("expected %d headings, got %d: %+v", len(want), len(headings), headings)
+	}
+	for i, w := range want {
+		if headings[i].Level != w.level || headings[i].Anchor != w.anchor {
+			t.Errorf("heading %d = %+v, want level %d anchor %q", i, headings[i], w.level, w.anchor)
+		}
+	}
+}
+
+func TestSection(t *testing.T) {
+	content := "# Auth\nintro\n## Tokens\ntok\n### Rotation\nrot\n## Sessions\nsess\n"
+
+	got, ok := markdown.Section(content, "tokens")
+	if !ok {
+		t.Fatal("expected tokens section")
+	}
+	if want := "## Tokens\ntok\n### Rotation\nrot"; got != want {
+		t.Errorf("Section(tokens) = %q, want %q", got, want)
+	}
+
+	got, ok = markdown.Section(content, "Sessions")
+	if !ok || got != "## Sessions\nsess\n" {
+		t.Errorf("Section(Sessions) = %q, %v", got, ok)
+	}
+
+	if _, ok := markdown.Section(content, "missing"); ok {
+		t.Error("expected missing anchor to report false")
+	}
+}
+
+func TestLinks(t *testing.T) {
+	content := "See [a](a.md) and ![img](p.png \"title\").\n`[code](x.md)`\n\n[ref]: <refs/b.md>\n"
+	links := markdown.Links(content)
+
+	if len(links) != 3 {
+		t.Fatalf("expected 3 links, got %d: %+v", len(links), links)
+	}
+	if links[0].Target != "a.md" || links[0].Image || links[0].Line != 1 {
+		t.Errorf("unexpected first link: %+v", links[0])
+	}
+	if links[1].Target != "p.png" || !links[1].Image {
+		t.Errorf("unexpected image link: %+v", links[1])
+	}
+	if links[2].Target != "refs/b.md" || links[2].Line != 4 {
+		t.Errorf("unexpected reference definition: %+v", links[2])
+	}
+}
+
+func TestIsExternal(t *testing.T) {
+	for _, target := range []string{"https://x.io", "mailto:a@b.c", "//cdn.x/y.png"} {
+		if !markdown.IsExternal(target) {
+			t.Errorf("expected %q to be external", target)
+		}
+	}
+	for _, target := range []string{"a.md", "../b.md#x", "/docs/c.md"} {
+		if markdown.IsExternal(target) {
+			t.Errorf("expected %q to be relative", target)
+		}
+	}
+}
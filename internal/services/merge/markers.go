@@ -0,0 +1,125 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Conflict marker lines, as git writes them.
+const (
+	markerOurs   = "<<<<<<< ours"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>> theirs"
+)
+
+// maxLineCells bounds the line alignment table. Larger files with
+// conflicts are marked as a whole.
+const maxLineCells = 16_000_000
+
+// YAML returns the merged node as YAML. Lines that differ between our and
+// their side of the conflicts are wrapped in conflict markers; everything
+// else is the merged result.
+func (r Result) YAML() ([]byte, error) {
+	ours, err := yaml.Marshal(&r.Node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged node: %w", err)
+	}
+	if len(r.Conflicts) == 0 {
+		return ours, nil
+	}
+	theirs, err := yaml.Marshal(&r.theirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged node: %w", err)
+	}
+	return markConflicts(splitLines(ours), splitLines(theirs)), nil
+}
+
+func splitLines(data []byte) []string {
+	return strings.SplitAfter(string(data), "\n")
+}
+
+// markConflicts aligns two texts on their common lines and wraps each
+// stretch where they differ in conflict markers.
+func markConflicts(a, b []string) []byte {
+	var out bytes.Buffer
+	var oursLines, theirsLines []string
+	flush := func() {
+		if len(oursLines) == 0 && len(theirsLines) == 0 {
+			return
+		}
+		out.WriteString(markerOurs + "\n")
+		for _, l := range oursLines {
+			out.WriteString(withNewline(l))
+		}
+		out.WriteString(markerSep + "\n")
+		for _, l := range theirsLines {
+			out.WriteString(withNewline(l))
+		}
+		out.WriteString(markerTheirs + "\n")
+		oursLines, theirsLines = nil, nil
+	}
+
+	if len(a)*len(b) > maxLineCells {
+		oursLines, theirsLines = a, b
+		flush()
+		return out.Bytes()
+	}
+
+	n, m := len(a), len(b)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			flush()
+			out.WriteString(a[i])
+			i++
+			j++
+		case j >= m || (i < n && table[i+1][j] >= table[i][j+1]):
+			oursLines = append(oursLines, a[i])
+			i++
+		default:
+			theirsLines = append(theirsLines, b[j])
+			j++
+		}
+	}
+	flush()
+	return out.Bytes()
+}
+
+func withNewline(line string) string {
+	if strings.HasSuffix(line, "\n") {
+		return line
+	}
+	return line + "\n"
+}
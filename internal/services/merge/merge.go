@@ -0,0 +1,319 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package merge performs three-way merges of nodes for the git merge
+// driver. A value changed on one side only takes that side's value. Lists
+// are merged item by item: lists of objects by their identifying field
+// (sections by name, blocks and issues by id, refs by target), other lists
+// such as tags as sets. Only values changed differently on both sides are
+// conflicts.
+package merge
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// listKeys are the fields that identify items of a list of objects, tried
+// in order. Items without the field, such as blocks without an id, are
+// matched by their position among the other items without it.
+var listKeys = []string{"id", "name", "target", "path"}
+
+// Conflict is a value both sides changed in different ways. A side that
+// removed the value has nil there.
+type Conflict struct {
+	Path   string
+	Base   interface{}
+	Ours   interface{}
+	Theirs interface{}
+}
+
+// Result is the outcome of a three-way node merge. Node takes our side of
+// each conflict.
+type Result struct {
+	Node      domain.Node
+	Conflicts []Conflict
+	theirs    domain.Node
+}
+
+// absent stands for a value that one side does not have.
+var absent = &struct{}{}
+
+// conflict holds both sides of a conflicting value in the merged tree.
+type conflict struct {
+	ours, theirs interface{}
+}
+
+// Nodes merges the changes ours and theirs made to base.
+func Nodes(base, ours, theirs domain.Node) (Result, error) {
+	var trees [3]map[string]interface{}
+	for i, n := range []domain.Node{base, ours, theirs} {
+		tree, err := toTree(n)
+		if err != nil {
+			return Result{}, err
+		}
+		trees[i] = tree
+	}
+
+	m := &merger{}
+	merged := m.value("", trees[0], trees[1], trees[2])
+
+	var result Result
+	var err error
+	if result.Node, err = fromTree(resolve(merged, true)); err != nil {
+		return Result{}, err
+	}
+	if result.theirs, err = fromTree(resolve(merged, false)); err != nil {
+		return Result{}, err
+	}
+	result.Conflicts = m.conflicts
+	return result, nil
+}
+
+// toTree returns a node as the generic values of its YAML form, so blocks
+// are flat maps with their type and fields side by side.
+func toTree(n domain.Node) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(&n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal node %s: %w", n.ID, err)
+	}
+	tree := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to read node %s: %w", n.ID, err)
+	}
+	return tree, nil
+}
+
+func fromTree(tree interface{}) (domain.Node, error) {
+	var n domain.Node
+	data, err := yaml.Marshal(tree)
+	if err != nil {
+		return n, fmt.Errorf("failed to marshal merged node: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &n); err != nil {
+		return n, fmt.Errorf("failed to read merged node: %w", err)
+	}
+	return n, nil
+}
+
+type merger struct {
+	conflicts []Conflict
+}
+
+// value merges one value. Any of base, ours and theirs may be absent.
+func (m *merger) value(path string, b, o, t interface{}) interface{} {
+	switch {
+	case reflect.DeepEqual(o, t):
+		return o
+	case reflect.DeepEqual(b, o):
+		return t
+	case reflect.DeepEqual(b, t):
+		return o
+	}
+
+	// Both sides bumped the version; sync bumps it again for the merge
+	if path == "version" {
+		if ov, ok := o.(int); ok {
+			if tv, ok := t.(int); ok {
+				return max(ov, tv)
+			}
+		}
+	}
+
+	om, oMap := o.(map[string]interface{})
+	tm, tMap := t.(map[string]interface{})
+	bm, bMap := b.(map[string]interface{})
+	if oMap && tMap && (bMap || b == absent) {
+		return m.maps(path, bm, om, tm)
+	}
+	ol, oList := o.([]interface{})
+	tl, tList := t.([]interface{})
+	bl, bList := b.([]interface{})
+	if oList && tList && (bList || b == absent) {
+		return m.lists(path, bl, ol, tl)
+	}
+
+	m.conflicts = append(m.conflicts, Conflict{Path: path, Base: present(b), Ours: present(o), Theirs: present(t)})
+	return &conflict{ours: o, theirs: t}
+}
+
+func (m *merger) maps(path string, b, o, t map[string]interface{}) map[string]interface{} {
+	keys := make(map[string]bool)
+	for _, side := range []map[string]interface{}{b, o, t} {
+		for k := range side {
+			keys[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	out := make(map[string]interface{}, len(keys))
+	for _, k := range sorted {
+		if v := m.value(member(path, k), lookup(b, k), lookup(o, k), lookup(t, k)); v != absent {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// lists merges items matched by key. The merged list keeps our order, with
+// items only theirs has placed after the item they follow on their side.
+func (m *merger) lists(path string, b, o, t []interface{}) []interface{} {
+	field := keyField(b, o, t)
+	bKeys, oKeys, tKeys := itemKeys(b, field), itemKeys(o, field), itemKeys(t, field)
+	bItems, oItems, tItems := index(b, bKeys), index(o, oKeys), index(t, tKeys)
+
+	order := slices.Clone(oKeys)
+	for j, k := range tKeys {
+		if _, ok := oItems[k]; ok {
+			continue
+		}
+		at := 0
+		for p := j - 1; p >= 0; p-- {
+			if i := slices.Index(order, tKeys[p]); i >= 0 {
+				at = i + 1
+				break
+			}
+		}
+		order = slices.Insert(order, at, k)
+	}
+	out := []interface{}{}
+	for _, k := range order {
+		if v := m.value(path+"["+k+"]", lookup(bItems, k), lookup(oItems, k), lookup(tItems, k)); v != absent {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// keyField returns the first of listKeys that some item in the lists has,
+// or "" when items are matched by value.
+func keyField(lists ...[]interface{}) string {
+	for _, key := range listKeys {
+		for _, list := range lists {
+			for _, v := range list {
+				if item, ok := v.(map[string]interface{}); ok {
+					if _, ok := item[key]; ok {
+						return key
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// itemKeys returns a key per item: its key field, its position among items
+// without one, or its value. Repeated keys get an occurrence suffix.
+func itemKeys(list []interface{}, field string) []string {
+	keys := make([]string, len(list))
+	seen := make(map[string]int)
+	keyless := 0
+	for i, v := range list {
+		var k string
+		item, isMap := v.(map[string]interface{})
+		switch {
+		case field != "" && isMap && item[field] != nil:
+			k = fmt.Sprint(item[field])
+		case field != "":
+			k = fmt.Sprintf("#%d", keyless)
+			keyless++
+		case isMap || isList(v):
+			data, _ := json.Marshal(v)
+			k = string(data)
+		default:
+			k = fmt.Sprint(v)
+		}
+		if n := seen[k]; n > 0 {
+			seen[k]++
+			k = fmt.Sprintf("%s#%d", k, n+1)
+		} else {
+			seen[k] = 1
+		}
+		keys[i] = k
+	}
+	return keys
+}
+
+func index(list []interface{}, keys []string) map[string]interface{} {
+	items := make(map[string]interface{}, len(list))
+	for i, k := range keys {
+		items[k] = list[i]
+	}
+	return items
+}
+
+// resolve replaces conflicts in a merged tree with one side's value.
+func resolve(v interface{}, ours bool) interface{} {
+	switch t := v.(type) {
+	case *conflict:
+		if ours {
+			return resolve(t.ours, ours)
+		}
+		return resolve(t.theirs, ours)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			if r := resolve(item, ours); r != absent {
+				out[k] = r
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0, len(t))
+		for _, item := range t {
+			if r := resolve(item, ours); r != absent {
+				out = append(out, r)
+			}
+		}
+		return out
+	}
+	return v
+}
+
+func lookup(m map[string]interface{}, k string) interface{} {
+	if v, ok := m[k]; ok {
+		return v
+	}
+	return absent
+}
+
+func present(v interface{}) interface{} {
+	if v == absent {
+		return nil
+	}
+	return v
+}
+
+func isList(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+func member(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,214 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package merge
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+func block(typ string, data map[string]interface{}) domain.Block {
+	return domain.Block{Type: typ, Data: data}
+}
+
+func baseNode() domain.Node {
+	return domain.Node{
+		ID:      "systems/combat",
+		Kind:    "system",
+		Version: 3,
+		Status:  "draft",
+		Title:   "Combat",
+		Tags:    []string{"core", "pvp"},
+		Summary: "Players fight with swords",
+		Refs:    domain.Ref{Uses: []domain.RefLink{{Target: "systems/health"}}},
+		Content: &domain.Content{Sections: []domain.Section{
+			{Name: "Weapons", Blocks: []domain.Block{
+				block("rule", map[string]interface{}{"id": "sword", "text": "Swords deal 10 damage", "damage": 10}),
+				block("rule", map[string]interface{}{"id": "bow", "text": "Bows deal 6 damage"}),
+			}},
+		}},
+	}
+}
+
+func conflictPaths(r Result) []string {
+	var paths []string
+	for _, c := range r.Conflicts {
+		paths = append(paths, c.Path)
+	}
+	return paths
+}
+
+func TestNodes_Clean(t *testing.T) {
+	base, ours, theirs := baseNode(), baseNode(), baseNode()
+
+	ours.Version = 4
+	ours.Title = "Melee combat"
+	ours.Tags = []string{"core", "pvp", "lore"}
+	ours.Refs.Uses = append(ours.Refs.Uses, domain.RefLink{Target: "systems/stamina"})
+	ours.Content = &domain.Content{Sections: []domain.Section{{Name: "Weapons", Blocks: []domain.Block{
+		block("rule", map[string]interface{}{"id": "sword", "text": "Swords deal 10 damage", "damage": 12}),
+		block("rule", map[string]interface{}{"id": "bow", "text": "Bows deal 6 damage"}),
+	}}}}
+
+	theirs.Version = 5
+	theirs.Tags = []string{"pvp", "balance"}
+	theirs.Refs.Uses = append(theirs.Refs.Uses, domain.RefLink{Target: "systems/armor"})
+	theirs.Content = &domain.Content{Sections: []domain.Section{
+		{Name: "Weapons", Blocks: []domain.Block{
+			block("rule", map[string]interface{}{"id": "sword", "text": "Swords deal heavy damage", "damage": 10}),
+			block("rule", map[string]interface{}{"id": "axe", "text": "Axes cleave"}),
+			block("rule", map[string]interface{}{"id": "bow", "text": "Bows deal 6 damage"}),
+		}},
+		{Name: "Armor", Blocks: []domain.Block{block("rule", map[string]interface{}{"id": "shield"})}},
+	}}
+
+	r, err := Nodes(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", conflictPaths(r))
+	}
+
+	n := r.Node
+	if n.Version != 5 || n.Title != "Melee combat" {
+		t.Errorf("version %d title %q", n.Version, n.Title)
+	}
+	if want := []string{"pvp", "balance", "lore"}; !reflect.DeepEqual(n.Tags, want) {
+		t.Errorf("tags = %v, want %v", n.Tags, want)
+	}
+	var targets []string
+	for _, ref := range n.Refs.Uses {
+		targets = append(targets, ref.Target)
+	}
+	if want := []string{"systems/health", "systems/armor", "systems/stamina"}; !reflect.DeepEqual(targets, want) {
+		t.Errorf("refs = %v, want %v", targets, want)
+	}
+
+	if len(n.Content.Sections) != 2 || n.Content.Sections[1].Name != "Armor" {
+		t.Fatalf("sections = %+v", n.Content.Sections)
+	}
+	var ids []string
+	for _, b := range n.Content.Sections[0].Blocks {
+		ids = append(ids, b.Data["id"].(string))
+	}
+	if want := []string{"sword", "axe", "bow"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("blocks = %v, want %v", ids, want)
+	}
+	sword := n.Content.Sections[0].Blocks[0].Data
+	if sword["damage"] != 12 || sword["text"] != "Swords deal heavy damage" {
+		t.Errorf("sword = %v, want both sides' edits", sword)
+	}
+
+	data, err := r.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), markerOurs) {
+		t.Errorf("clean merge has conflict markers:\n%s", data)
+	}
+}
+
+func TestNodes_Conflicts(t *testing.T) {
+	base, ours, theirs := baseNode(), baseNode(), baseNode()
+	ours.Summary = "Players duel with swords"
+	theirs.Summary = "Players brawl with swords"
+	ours.Content.Sections[0].Blocks[0].Data["damage"] = 12
+	theirs.Content.Sections[0].Blocks[0].Data["damage"] = 14
+	// Removed on our side, edited on theirs
+	ours.Content.Sections[0].Blocks = ours.Content.Sections[0].Blocks[:1]
+	theirs.Content.Sections[0].Blocks[1].Data["text"] = "Bows deal 8 damage"
+	// Changes to different fields still merge
+	ours.Title = "Melee combat"
+
+	r, err := Nodes(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"content.sections[Weapons].blocks[sword].damage",
+		"content.sections[Weapons].blocks[bow]",
+		"summary",
+	}
+	if got := conflictPaths(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("conflicts = %v, want %v", got, want)
+	}
+	if r.Node.Title != "Melee combat" {
+		t.Errorf("title = %q", r.Node.Title)
+	}
+
+	data, err := r.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, want := range []string{
+		markerOurs + "\n              damage: 12\n" + markerSep + "\n              damage: 14\n" + markerTheirs + "\n",
+		// The removed bow block and the summary are adjacent, so they share a hunk
+		"summary: Players duel with swords\n" + markerSep + "\n",
+		"summary: Players brawl with swords\n" + markerTheirs + "\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if strings.Count(text, markerOurs) != 2 {
+		t.Errorf("expected 2 conflict hunks:\n%s", text)
+	}
+
+	// Taking one side of every hunk gives a valid node
+	var resolved []string
+	skip := false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		switch strings.TrimSuffix(line, "\n") {
+		case markerOurs, markerTheirs:
+			skip = false
+		case markerSep:
+			skip = true
+		default:
+			if !skip {
+				resolved = append(resolved, line)
+			}
+		}
+	}
+	var n domain.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(resolved, "")), &n); err != nil {
+		t.Fatalf("our side does not parse: %v", err)
+	}
+	if n.Summary != "Players duel with swords" || len(n.Content.Sections[0].Blocks) != 1 {
+		t.Errorf("unexpected resolution: %+v", n)
+	}
+}
+
+func TestNodes_AddedOnBothSides(t *testing.T) {
+	ours, theirs := baseNode(), baseNode()
+	theirs.Tags = []string{"pvp", "core"}
+
+	r, err := Nodes(domain.Node{}, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Conflicts) != 0 {
+		t.Errorf("unexpected conflicts: %v", conflictPaths(r))
+	}
+	if want := []string{"core", "pvp"}; !reflect.DeepEqual(r.Node.Tags, want) {
+		t.Errorf("tags = %v, want %v", r.Node.Tags, want)
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package query
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+// AggregateFuncs are the aggregation functions accepted by ParseAggregate.
+var AggregateFuncs = []string{"count", "sum", "avg", "min", "max"}
+
+// Aggregate is one aggregation over a group of blocks.
+type Aggregate struct {
+	Func  string // count, sum, avg, min or max
+	Field string // block field path (dotted for nested values); empty for a plain count
+}
+
+// ParseAggregate parses "count", "count:field" or "func:field".
+func ParseAggregate(spec string) (Aggregate, error) {
+	fn, field, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	a := Aggregate{Func: fn, Field: field}
+	known := false
+	for _, f := range AggregateFuncs {
+		if fn == f {
+			known = true
+		}
+	}
+	if !known {
+		return a, fmt.Errorf("unknown aggregate %q (supported: %s)", spec, strings.Join(AggregateFuncs, ", "))
+	}
+	if fn != "count" && field == "" {
+		return a, fmt.Errorf("aggregate %q needs a field, e.g. %s:cost", spec, fn)
+	}
+	return a, nil
+}
+
+// Name is the column name of the aggregate, e.g. "count" or "sum(cost)".
+func (a Aggregate) Name() string {
+	if a.Field == "" {
+		return a.Func
+	}
+	return a.Func + "(" + a.Field + ")"
+}
+
+// Group is one row of an aggregation.
+type Group struct {
+	Keys   []interface{} // one value per group-by field; nil when the field is missing
+	Values []interface{} // one value per aggregate; nil when there was nothing to aggregate
+}
+
+// AggregateBlocks groups block matches by the values of the groupBy fields
+// and computes the aggregates for each group. Group fields are block field
+// paths or node, section and type. A block whose group field holds a list
+// is counted once in the group of each element. Without group fields the
+// result is a single group, even when there are no matches.
+//
+// Numeric coercion follows the block type's field definitions: a field
+// declared as number also accepts numeric strings, and a field declared
+// with another type cannot be summed or averaged. min and max over values
+// that are not numbers compare them as text.
+func (qe *QueryEngine) AggregateBlocks(matches []BlockMatch, groupBy []string, aggs []Aggregate, blockTypes map[string]config.BlockTypeConfig) ([]Group, error) {
+	if err := checkAggregates(matches, aggs, blockTypes); err != nil {
+		return nil, err
+	}
+
+	type bucket struct {
+		keys    []interface{}
+		matches []BlockMatch
+	}
+	buckets := make(map[string]*bucket)
+	var order []*bucket
+	if len(groupBy) == 0 {
+		order = append(order, &bucket{})
+	}
+	for _, m := range matches {
+		if len(groupBy) == 0 {
+			order[0].matches = append(order[0].matches, m)
+			continue
+		}
+		for _, keys := range groupKeys(m, groupBy) {
+			id := keyID(keys)
+			b, ok := buckets[id]
+			if !ok {
+				b = &bucket{keys: keys}
+				buckets[id] = b
+				order = append(order, b)
+			}
+			b.matches = append(b.matches, m)
+		}
+	}
+
+	groups := make([]Group, len(order))
+	for i, b := range order {
+		groups[i] = Group{Keys: b.keys, Values: computeAggregates(b.matches, aggs, blockTypes)}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		for k := range groups[i].Keys {
+			if c := compareKeys(groups[i].Keys[k], groups[j].Keys[k]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return groups, nil
+}
+
+// AggregateFollow computes the aggregates over the blocks each followed
+// value resolves to. Groups are returned in the order of results, keyed by
+// the followed value.
+func (qe *QueryEngine) AggregateFollow(results []FollowResult, aggs []Aggregate, blockTypes map[string]config.BlockTypeConfig) ([]Group, error) {
+	groups := make([]Group, len(results))
+	for i, r := range results {
+		if err := checkAggregates(r.Matches, aggs, blockTypes); err != nil {
+			return nil, err
+		}
+		groups[i] = Group{Keys: []interface{}{r.Value}, Values: computeAggregates(r.Matches, aggs, blockTypes)}
+	}
+	return groups, nil
+}
+
+// checkAggregates rejects sums and averages over fields whose declared type
+// is not a number.
+func checkAggregates(matches []BlockMatch, aggs []Aggregate, blockTypes map[string]config.BlockTypeConfig) error {
+	for _, a := range aggs {
+		if a.Func != "sum" && a.Func != "avg" {
+			continue
+		}
+		for _, m := range matches {
+			def := fieldDef(blockTypes, m.Block.Type, a.Field)
+			if def != nil && def.Type != "" && def.Type != "number" {
+				return fmt.Errorf("cannot %s %q: the field is declared as %s in block type %q", a.Func, a.Field, def.Type, m.Block.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// fieldDef returns the definition of a top-level block field, if declared.
+func fieldDef(blockTypes map[string]config.BlockTypeConfig, blockType, field string) *config.FieldDef {
+	bt, ok := blockTypes[blockType]
+	if !ok {
+		return nil
+	}
+	def, ok := bt.Fields[field]
+	if !ok {
+		return nil
+	}
+	return &def
+}
+
+// blockValue returns a block's value for a group or aggregate field.
+func blockValue(m BlockMatch, path string) interface{} {
+	switch path {
+	case "node":
+		return m.NodeID
+	case "section":
+		return m.SectionName
+	case "type":
+		return m.Block.Type
+	}
+	var cur interface{} = m.Block.Data
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if cur, ok = obj[key]; !ok {
+			return nil
+		}
+	}
+	return cur
+}
+
+// groupKeys returns every key combination a block belongs to, expanding
+// list values into one key per element. An empty list counts as missing.
+func groupKeys(m BlockMatch, groupBy []string) [][]interface{} {
+	combos := [][]interface{}{{}}
+	for _, field := range groupBy {
+		values := []interface{}{blockValue(m, field)}
+		if list, ok := values[0].([]interface{}); ok {
+			values = list
+			if len(list) == 0 {
+				values = []interface{}{nil}
+			}
+		}
+		var next [][]interface{}
+		for _, c := range combos {
+			for _, v := range values {
+				next = append(next, append(append([]interface{}{}, c...), v))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+func keyID(keys []interface{}) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%T:%v", k, k)
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// compareKeys orders numbers numerically and other values as text, with
+// missing values last.
+func compareKeys(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	x, xok := numeric(a, nil)
+	y, yok := numeric(b, nil)
+	if xok && yok {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// numeric converts a value to a number. Strings convert only when the
+// field is declared as a number.
+func numeric(v interface{}, def *config.FieldDef) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		if def != nil && def.Type == "number" {
+			f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			return f, err == nil
+		}
+	}
+	return 0, false
+}
+
+// number returns f as an int when it is whole, so counts and sums of
+// integers print without a fraction.
+func number(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int(f)
+	}
+	return f
+}
+
+func computeAggregates(matches []BlockMatch, aggs []Aggregate, blockTypes map[string]config.BlockTypeConfig) []interface{} {
+	values := make([]interface{}, len(aggs))
+	for i, a := range aggs {
+		values[i] = computeAggregate(matches, a, blockTypes)
+	}
+	return values
+}
+
+func computeAggregate(matches []BlockMatch, a Aggregate, blockTypes map[string]config.BlockTypeConfig) interface{} {
+	if a.Func == "count" && a.Field == "" {
+		return len(matches)
+	}
+
+	var nums []float64
+	var texts []string
+	count := 0
+	for _, m := range matches {
+		v := blockValue(m, a.Field)
+		if v == nil {
+			continue
+		}
+		count++
+		if f, ok := numeric(v, fieldDef(blockTypes, m.Block.Type, a.Field)); ok {
+			nums = append(nums, f)
+		} else if _, isList := v.([]interface{}); !isList {
+			texts = append(texts, fmt.Sprint(v))
+		}
+	}
+
+	switch a.Func {
+	case "count":
+		return count
+	case "sum", "avg":
+		if len(nums) == 0 {
+			return nil
+		}
+		sum := 0.0
+		for _, f := range nums {
+			sum += f
+		}
+		if a.Func == "avg" {
+			return number(sum / float64(len(nums)))
+		}
+		return number(sum)
+	}
+
+	// min and max: numbers when every value is one, text otherwise
+	if len(nums) > 0 && len(texts) == 0 {
+		best := nums[0]
+		for _, f := range nums[1:] {
+			if (a.Func == "min" && f < best) || (a.Func == "max" && f > best) {
+				best = f
+			}
+		}
+		return number(best)
+	}
+	for _, f := range nums {
+		texts = append(texts, fmt.Sprint(number(f)))
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+	sort.Strings(texts)
+	if a.Func == "min" {
+		return texts[0]
+	}
+	return texts[len(texts)-1]
+}
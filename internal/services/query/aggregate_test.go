@@ -0,0 +1,152 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package query_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/query"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+func buildingMatches() []query.BlockMatch {
+	block := func(node string, data map[string]interface{}) query.BlockMatch {
+		return query.BlockMatch{NodeID: node, SectionName: "Buildings", Block: domain.Block{Type: "building", Data: data}}
+	}
+	return []query.BlockMatch{
+		block("eras/bronze", map[string]interface{}{"name": "Forge", "age": "bronze", "cost": 30, "materials": []interface{}{"Copper", "Tin"}}),
+		block("eras/bronze", map[string]interface{}{"name": "Hut", "age": "bronze", "cost": "12", "materials": []interface{}{"Wood"}}),
+		block("eras/iron", map[string]interface{}{"name": "Smelter", "age": "iron", "cost": 45.5, "materials": []interface{}{"Copper"}}),
+		block("eras/iron", map[string]interface{}{"name": "Well", "materials": []interface{}{}}),
+	}
+}
+
+var buildingTypes = map[string]config.BlockTypeConfig{
+	"building": {Fields: map[string]config.FieldDef{
+		"name": {Type: "string"},
+		"age":  {Type: "string"},
+		"cost": {Type: "number"},
+	}},
+}
+
+func mustAggs(t *testing.T, specs ...string) []query.Aggregate {
+	t.Helper()
+	var aggs []query.Aggregate
+	for _, s := range specs {
+		a, err := query.ParseAggregate(s)
+		if err != nil {
+			t.Fatalf("ParseAggregate(%q): %v", s, err)
+		}
+		aggs = append(aggs, a)
+	}
+	return aggs
+}
+
+func TestAggregateBlocks_GroupBy(t *testing.T) {
+	qe := query.New()
+	aggs := mustAggs(t, "count", "sum:cost", "min:cost", "max:name", "avg:cost")
+
+	groups, err := qe.AggregateBlocks(buildingMatches(), []string{"age"}, aggs, buildingTypes)
+	if err != nil {
+		t.Fatalf("AggregateBlocks failed: %v", err)
+	}
+	want := []query.Group{
+		// "12" counts as a number because cost is declared as one
+		{Keys: []interface{}{"bronze"}, Values: []interface{}{2, 42, 12, "Hut", 21}},
+		{Keys: []interface{}{"iron"}, Values: []interface{}{1, 45.5, 45.5, "Smelter", 45.5}},
+		// Missing group values sort last
+		{Keys: []interface{}{nil}, Values: []interface{}{1, nil, nil, "Well", nil}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("got %+v\nwant %+v", groups, want)
+	}
+}
+
+func TestAggregateBlocks_ListValuesAndNoGroups(t *testing.T) {
+	qe := query.New()
+
+	groups, err := qe.AggregateBlocks(buildingMatches(), []string{"materials"}, mustAggs(t, "count"), buildingTypes)
+	if err != nil {
+		t.Fatalf("AggregateBlocks failed: %v", err)
+	}
+	var got []string
+	for _, g := range groups {
+		got = append(got, fmt.Sprintf("%v=%v", g.Keys[0], g.Values[0]))
+	}
+	if strings.Join(got, ",") != "Copper=2,Tin=1,Wood=1,<nil>=1" {
+		t.Errorf("unexpected groups: %v", got)
+	}
+
+	groups, err = qe.AggregateBlocks(nil, nil, mustAggs(t, "count", "sum:cost"), buildingTypes)
+	if err != nil {
+		t.Fatalf("AggregateBlocks failed: %v", err)
+	}
+	if !reflect.DeepEqual(groups, []query.Group{{Values: []interface{}{0, nil}}}) {
+		t.Errorf("expected a single empty group, got %+v", groups)
+	}
+
+	// Undeclared string values are not coerced
+	groups, err = qe.AggregateBlocks(buildingMatches(), []string{"node"}, mustAggs(t, "sum:cost", "count:cost"), nil)
+	if err != nil {
+		t.Fatalf("AggregateBlocks failed: %v", err)
+	}
+	if groups[0].Keys[0] != "eras/bronze" || groups[0].Values[0] != 30 || groups[0].Values[1] != 2 {
+		t.Errorf("unexpected bronze group: %+v", groups[0])
+	}
+}
+
+func TestAggregateBlocks_Errors(t *testing.T) {
+	qe := query.New()
+	if _, err := qe.AggregateBlocks(buildingMatches(), nil, mustAggs(t, "sum:name"), buildingTypes); err == nil ||
+		!strings.Contains(err.Error(), `declared as string`) {
+		t.Errorf("expected a type error, got %v", err)
+	}
+
+	for _, bad := range []string{"total:cost", "sum", "avg:"} {
+		if _, err := query.ParseAggregate(bad); err == nil {
+			t.Errorf("ParseAggregate(%q) should fail", bad)
+		}
+	}
+	if a := mustAggs(t, "max:stats.hp")[0]; a.Name() != "max(stats.hp)" {
+		t.Errorf("unexpected name %q", a.Name())
+	}
+}
+
+func TestAggregateFollow(t *testing.T) {
+	qe := query.New()
+	results := []query.FollowResult{
+		{Value: "Copper", RefCount: 2, Matches: []query.BlockMatch{
+			{Block: domain.Block{Type: "resource", Data: map[string]interface{}{"tier": 1}}},
+			{Block: domain.Block{Type: "resource", Data: map[string]interface{}{"tier": 2}}},
+		}},
+		{Value: "Tin", RefCount: 1},
+	}
+	groups, err := qe.AggregateFollow(results, mustAggs(t, "count", "max:tier"), nil)
+	if err != nil {
+		t.Fatalf("AggregateFollow failed: %v", err)
+	}
+	want := []query.Group{
+		{Keys: []interface{}{"Copper"}, Values: []interface{}{2, 2}},
+		{Keys: []interface{}{"Tin"}, Values: []interface{}{0, nil}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("got %+v\nwant %+v", groups, want)
+	}
+}
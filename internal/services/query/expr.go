@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/validator"
+	"github.com/google/cel-go/cel"
+)
+
+// Expr is a compiled boolean CEL expression for --where or --block-where.
+type Expr struct {
+	source string
+	prg    cel.Program
+	params map[string]string
+}
+
+// String returns the expression source.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// nodeExprVariables mirror the constraint environment so expressions are
+// portable between constraints and queries, plus summary for convenience.
+var nodeExprVariables = []cel.EnvOption{
+	cel.Variable("id", cel.StringType),
+	cel.Variable("kind", cel.StringType),
+	cel.Variable("version", cel.IntType),
+	cel.Variable("status", cel.StringType),
+	cel.Variable("title", cel.StringType),
+	cel.Variable("summary", cel.StringType),
+	cel.Variable("tags", cel.ListType(cel.StringType)),
+	cel.Variable("self", cel.MapType(cel.StringType, cel.DynType)),
+	cel.Variable("refs", cel.MapType(cel.StringType, cel.DynType)),
+	cel.Variable("allNodes", cel.ListType(cel.MapType(cel.StringType, cel.DynType))),
+	cel.Variable("custom", cel.MapType(cel.StringType, cel.DynType)),
+	cel.Variable("params", cel.MapType(cel.StringType, cel.StringType)),
+}
+
+// blockExprVariables expose one block, its section name and its owning node.
+var blockExprVariables = []cel.EnvOption{
+	cel.Variable("block", cel.MapType(cel.StringType, cel.DynType)),
+	cel.Variable("section", cel.StringType),
+	cel.Variable("self", cel.MapType(cel.StringType, cel.DynType)),
+	cel.Variable("params", cel.MapType(cel.StringType, cel.StringType)),
+}
+
+// CompileWhere compiles a node-level expression. Available variables are the
+// same as for constraints: id, kind, version, status, title, tags, custom,
+// self (the full node map), refs (all nodes by ID) and allNodes, plus summary
+// and params (saved query parameters, see WithParams).
+func (qe *QueryEngine) CompileWhere(expr string) (*Expr, error) {
+	return compileExpr(expr, nodeExprVariables)
+}
+
+// CompileBlockWhere compiles a block-level expression with the variables
+// block (type plus block fields), section (section name), self (the
+// owning node map) and params.
+func (qe *QueryEngine) CompileBlockWhere(expr string) (*Expr, error) {
+	return compileExpr(expr, blockExprVariables)
+}
+
+func compileExpr(expr string, vars []cel.EnvOption) (*Expr, error) {
+	opts := append([]cel.EnvOption{cel.CrossTypeNumericComparisons(true)}, vars...)
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression: %w", issues.Err())
+	}
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return nil, fmt.Errorf("invalid expression: must evaluate to a boolean, got %s", t)
+	}
+
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL program: %w", err)
+	}
+	return &Expr{source: expr, prg: prg}, nil
+}
+
+// WithParams returns a copy of the expression that sees values as the params
+// map. Saved query parameters are passed this way rather than pasted into
+// the source, so a value is always a string and never part of the logic.
+func (e *Expr) WithParams(values map[string]string) *Expr {
+	c := *e
+	c.params = values
+	return &c
+}
+
+// eval runs the expression. A missing map key counts as no match, so
+// "self.custom.cost > 10" skips nodes without a cost; any other evaluation
+// error, such as comparing a number with a string, is returned.
+func (e *Expr) eval(input map[string]interface{}) (bool, error) {
+	if e.params != nil {
+		input["params"] = e.params
+	} else {
+		input["params"] = map[string]string{}
+	}
+	out, _, err := e.prg.Eval(input)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "no such key") {
+			return false, nil
+		}
+		return false, fmt.Errorf("expression %q failed: %w", e.source, err)
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q did not evaluate to a boolean", e.source)
+	}
+	return b, nil
+}
+
+// Where returns the nodes for which the expression is true. allNodes backs
+// the refs and allNodes variables.
+func (qe *QueryEngine) Where(nodes []domain.Node, allNodes []domain.Node, e *Expr) ([]domain.Node, error) {
+	refs := make(map[string]interface{}, len(allNodes))
+	all := make([]interface{}, len(allNodes))
+	for i := range allNodes {
+		m := validator.NodeToMap(&allNodes[i])
+		refs[allNodes[i].ID] = m
+		all[i] = m
+	}
+
+	var results []domain.Node
+	for i := range nodes {
+		n := &nodes[i]
+		custom := n.Custom
+		if custom == nil {
+			custom = map[string]interface{}{}
+		}
+		tags := n.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		ok, err := e.eval(map[string]interface{}{
+			"id":       n.ID,
+			"kind":     n.Kind,
+			"version":  int64(n.Version),
+			"status":   n.Status,
+			"title":    n.Title,
+			"summary":  n.Summary,
+			"tags":     tags,
+			"self":     validator.NodeToMap(n),
+			"refs":     refs,
+			"allNodes": all,
+			"custom":   custom,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			results = append(results, *n)
+		}
+	}
+	return results, nil
+}
+
+// WhereBlocks returns the block matches for which the expression is true.
+// allNodes supplies the owning node for the self variable.
+func (qe *QueryEngine) WhereBlocks(matches []BlockMatch, allNodes []domain.Node, e *Expr) ([]BlockMatch, error) {
+	nodeMaps := make(map[string]map[string]interface{}, len(allNodes))
+	for i := range allNodes {
+		nodeMaps[allNodes[i].ID] = validator.NodeToMap(&allNodes[i])
+	}
+
+	var results []BlockMatch
+	for _, m := range matches {
+		block := map[string]interface{}{"type": m.Block.Type}
+		for k, v := range m.Block.Data {
+			block[k] = v
+		}
+		ok, err := e.eval(map[string]interface{}{
+			"block":   block,
+			"section": m.SectionName,
+			"self":    nodeMaps[m.NodeID],
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			results = append(results, m)
+		}
+	}
+	return results, nil
+}
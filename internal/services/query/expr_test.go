@@ -0,0 +1,201 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package query_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/query"
+)
+
+func exprTestNodes() []domain.Node {
+	return []domain.Node{
+		{
+			ID: "items/sword", Kind: "item", Version: 2, Status: "approved", Title: "Sword",
+			Tags:   []string{"weapon", "combat"},
+			Custom: map[string]interface{}{"cost": 50},
+			Refs:   domain.Ref{Uses: []domain.RefLink{{Target: "items/iron"}}},
+			Content: &domain.Content{Sections: []domain.Section{{
+				Name: "Recipes",
+				Blocks: []domain.Block{
+					{Type: "recipe", Data: map[string]interface{}{"output": "Sword", "materials": []interface{}{"Iron", "Wood"}}},
+					{Type: "rule", Data: map[string]interface{}{"text": "Sharp"}},
+				},
+			}}},
+		},
+		{
+			ID: "items/iron", Kind: "item", Version: 1, Status: "draft", Title: "Iron",
+			Tags:   []string{"material"},
+			Custom: map[string]interface{}{"cost": 5.5},
+		},
+		{
+			ID: "systems/combat", Kind: "system", Version: 1, Status: "review", Title: "Combat",
+			Tags: []string{"combat"},
+		},
+	}
+}
+
+func whereIDs(t *testing.T, expr string) []string {
+	t.Helper()
+	qe := query.New()
+	e, err := qe.CompileWhere(expr)
+	if err != nil {
+		t.Fatalf("CompileWhere(%q) failed: %v", expr, err)
+	}
+	nodes := exprTestNodes()
+	results, err := qe.Where(nodes, nodes, e)
+	if err != nil {
+		t.Fatalf("Where(%q) failed: %v", expr, err)
+	}
+	var ids []string
+	for _, n := range results {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestQueryEngine_Where(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{"or", `kind == "system" || status == "draft"`, []string{"items/iron", "systems/combat"}},
+		{"negated tag", `!("combat" in tags)`, []string{"items/iron"}},
+		{"set membership", `status in ["approved", "review"]`, []string{"items/sword", "systems/combat"}},
+		{"numeric custom field", `custom.cost > 10`, []string{"items/sword"}},
+		{"int and double compare", `self.custom.cost < 10`, []string{"items/iron"}},
+		{"version", `version >= 2`, []string{"items/sword"}},
+		{"nested content", `self.content.sections.exists(s, s.blocks.exists(b, b.type == "recipe"))`, []string{"items/sword"}},
+		{"refs lookup", `self.refs.uses.exists(u, refs[u.target].status == "draft")`, []string{"items/sword"}},
+		{"all nodes", `allNodes.filter(n, n.kind == kind).size() > 1`, []string{"items/sword", "items/iron"}},
+		{"summary", `summary == ""`, []string{"items/sword", "items/iron", "systems/combat"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := whereIDs(t, tt.expr)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Where(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryEngine_Where_MissingFieldIsNoMatch(t *testing.T) {
+	// systems/combat has no cost; it must be skipped rather than failing
+	got := whereIDs(t, `custom.cost >= 0`)
+	if strings.Join(got, ",") != "items/sword,items/iron" {
+		t.Errorf("expected nodes with a cost only, got %v", got)
+	}
+}
+
+func TestQueryEngine_Where_EvaluationErrors(t *testing.T) {
+	qe := query.New()
+	nodes := exprTestNodes()
+	for _, expr := range []string{`custom.cost > "5"`, `custom.cost / 0 > 1`, `tags[5] == "x"`} {
+		e, err := qe.CompileWhere(expr)
+		if err != nil {
+			t.Fatalf("CompileWhere(%q) failed: %v", expr, err)
+		}
+		if _, err := qe.Where(nodes, nodes, e); err == nil {
+			t.Errorf("Where(%q) should fail instead of matching nothing", expr)
+		}
+	}
+}
+
+func TestQueryEngine_CompileWhere_Errors(t *testing.T) {
+	qe := query.New()
+	for _, expr := range []string{`kind ==`, `kind + 1`, `title`, `unknown == "x"`} {
+		if _, err := qe.CompileWhere(expr); err == nil {
+			t.Errorf("CompileWhere(%q) should fail", expr)
+		}
+	}
+	if _, err := qe.CompileWhere(`custom.cost`); err != nil {
+		t.Errorf("dynamic result should compile, got %v", err)
+	}
+}
+
+func TestQueryEngine_Where_NonBoolResult(t *testing.T) {
+	qe := query.New()
+	e, err := qe.CompileWhere(`custom.cost`)
+	if err != nil {
+		t.Fatalf("CompileWhere failed: %v", err)
+	}
+	nodes := exprTestNodes()
+	if _, err := qe.Where(nodes, nodes, e); err == nil {
+		t.Error("expected error for non-boolean result")
+	}
+}
+
+func TestQueryEngine_Where_Params(t *testing.T) {
+	qe := query.New()
+	e, err := qe.CompileWhere(`kind == params.kind`)
+	if err != nil {
+		t.Fatalf("CompileWhere failed: %v", err)
+	}
+	nodes := exprTestNodes()
+	for value, want := range map[string]int{"item": 2, `item" || true || "`: 0} {
+		got, err := qe.Where(nodes, nodes, e.WithParams(map[string]string{"kind": value}))
+		if err != nil {
+			t.Fatalf("Where failed: %v", err)
+		}
+		if len(got) != want {
+			t.Errorf("params.kind = %q matched %d nodes, want %d", value, len(got), want)
+		}
+	}
+	// Without parameters, params is empty and lookups do not match
+	if got, _ := qe.Where(nodes, nodes, e); len(got) != 0 {
+		t.Errorf("expected no matches without params, got %d", len(got))
+	}
+}
+
+func TestQueryEngine_WhereBlocks(t *testing.T) {
+	qe := query.New()
+	nodes := exprTestNodes()
+	blocks := qe.FilterBlocks(nodes, query.FilterCriteria{})
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(blocks))
+	}
+
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{`block.type == "recipe" && "Wood" in block.materials`, 1},
+		{`section == "Recipes"`, 2},
+		{`self.status == "approved" && block.type == "rule"`, 1},
+		{`block.materials.size() > 5`, 0}, // rule has no materials: no match, not an error
+	}
+	for _, tt := range tests {
+		e, err := qe.CompileBlockWhere(tt.expr)
+		if err != nil {
+			t.Fatalf("CompileBlockWhere(%q) failed: %v", tt.expr, err)
+		}
+		got, err := qe.WhereBlocks(blocks, nodes, e)
+		if err != nil {
+			t.Fatalf("WhereBlocks(%q) failed: %v", tt.expr, err)
+		}
+		if len(got) != tt.want {
+			t.Errorf("WhereBlocks(%q) = %d blocks, want %d", tt.expr, len(got), tt.want)
+		}
+	}
+
+	if _, err := qe.CompileBlockWhere(`kind == "item"`); err == nil {
+		t.Error("node variables should not be available to block expressions")
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package query
+
+import (
+	"fmt"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+// DefaultMaxJoinDepth is the default limit on hops along one join path.
+const DefaultMaxJoinDepth = 10
+
+// MaxJoinPaths caps the number of paths a chain may produce.
+const MaxJoinPaths = 10000
+
+// Reasons a join path stopped before following every hop.
+const (
+	StopUnresolved = "unresolved" // the value matched no block
+	StopNoValue    = "no-value"   // the block has no value for the next hop
+	StopCycle      = "cycle"      // the block is already on the path
+	StopDepth      = "depth"      // the depth limit was reached
+)
+
+// FollowHop is one step of a follow chain.
+type FollowHop struct {
+	Field   string         // field holding the referenced values
+	Targets []FollowTarget // explicit targets; inferred from ref constraints when empty
+	Repeat  bool           // follow the same field again until it runs out (last hop only)
+}
+
+// JoinStep is one hop taken along a join path.
+type JoinStep struct {
+	Field string      // field followed from the previous block
+	Value string      // the referenced value
+	Match *BlockMatch // the block providing the value; nil when unresolved
+}
+
+// JoinPath is a chain of blocks from a source block through each hop.
+type JoinPath struct {
+	Source BlockMatch
+	Steps  []JoinStep
+	Stop   string // empty when every hop was followed, otherwise one of the Stop constants
+}
+
+// Last returns the block the path ends at.
+func (p JoinPath) Last() BlockMatch {
+	for i := len(p.Steps) - 1; i >= 0; i-- {
+		if p.Steps[i].Match != nil {
+			return *p.Steps[i].Match
+		}
+	}
+	return p.Source
+}
+
+// FollowChain follows a chain of hops from each source block and returns
+// every resulting path. A referenced value that several blocks provide
+// forks the path. Hops without explicit targets use the ref constraints of
+// the current block's type, so a chain may pass through different block
+// types. A path stops early when a value is unresolved, a block has no
+// value for the next hop, a block would be visited twice, or maxDepth hops
+// were taken (0 means DefaultMaxJoinDepth).
+func (qe *QueryEngine) FollowChain(sources []BlockMatch, hops []FollowHop, allNodes []domain.Node, blockTypes map[string]config.BlockTypeConfig, maxDepth int) ([]JoinPath, error) {
+	if len(hops) == 0 {
+		return nil, fmt.Errorf("follow chain has no hops")
+	}
+	for i, hop := range hops {
+		if hop.Repeat && i != len(hops)-1 {
+			return nil, fmt.Errorf("only the last hop of a follow chain can repeat")
+		}
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxJoinDepth
+	}
+
+	j := &joiner{
+		qe:         qe,
+		hops:       hops,
+		nodes:      allNodes,
+		blockTypes: blockTypes,
+		maxDepth:   maxDepth,
+		indexes:    make(map[FollowTarget]map[string][]BlockMatch),
+	}
+	for _, src := range sources {
+		path := JoinPath{Source: src}
+		visited := map[string]bool{blockKey(src): true}
+		if err := j.walk(path, src, 0, visited); err != nil {
+			return nil, err
+		}
+	}
+	return j.paths, nil
+}
+
+type joiner struct {
+	qe         *QueryEngine
+	hops       []FollowHop
+	nodes      []domain.Node
+	blockTypes map[string]config.BlockTypeConfig
+	maxDepth   int
+	indexes    map[FollowTarget]map[string][]BlockMatch
+	paths      []JoinPath
+}
+
+func (j *joiner) emit(path JoinPath, stop string) error {
+	if len(j.paths) >= MaxJoinPaths {
+		return fmt.Errorf("follow chain produced more than %d paths; narrow the query or lower --max-depth", MaxJoinPaths)
+	}
+	path.Stop = stop
+	path.Steps = append([]JoinStep(nil), path.Steps...)
+	j.paths = append(j.paths, path)
+	return nil
+}
+
+// walk follows hop i from the current block.
+func (j *joiner) walk(path JoinPath, current BlockMatch, i int, visited map[string]bool) error {
+	if i == len(j.hops) {
+		return j.emit(path, "")
+	}
+	hop := j.hops[i]
+	values := extractStringValues(current.Block.Data[hop.Field])
+	if len(values) == 0 {
+		// Running out is how a repeated hop ends
+		if hop.Repeat && len(path.Steps) > 0 {
+			return j.walk(path, current, i+1, visited)
+		}
+		return j.emit(path, StopNoValue)
+	}
+	if len(path.Steps) >= j.maxDepth {
+		return j.emit(path, StopDepth)
+	}
+
+	targets, err := j.targets(hop, current.Block.Type)
+	if err != nil {
+		return err
+	}
+	next := i + 1
+	if hop.Repeat {
+		next = i
+	}
+
+	for _, value := range values {
+		matches := j.lookup(targets, value)
+		if len(matches) == 0 {
+			step := JoinStep{Field: hop.Field, Value: value}
+			if err := j.emit(JoinPath{Source: path.Source, Steps: append(path.Steps, step)}, StopUnresolved); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, m := range matches {
+			m := m
+			step := JoinStep{Field: hop.Field, Value: value, Match: &m}
+			extended := JoinPath{Source: path.Source, Steps: append(append([]JoinStep(nil), path.Steps...), step)}
+			key := blockKey(m)
+			if visited[key] {
+				if err := j.emit(extended, StopCycle); err != nil {
+					return err
+				}
+				continue
+			}
+			visited[key] = true
+			err := j.walk(extended, m, next, visited)
+			delete(visited, key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// targets returns the explicit targets of a hop, or those inferred from
+// the ref constraint of the field in the given block type.
+func (j *joiner) targets(hop FollowHop, blockType string) ([]FollowTarget, error) {
+	if len(hop.Targets) > 0 {
+		return hop.Targets, nil
+	}
+	return j.qe.resolveFollowTargets(blockType, hop.Field, j.blockTypes)
+}
+
+// lookup returns the blocks providing a value in any of the targets.
+func (j *joiner) lookup(targets []FollowTarget, value string) []BlockMatch {
+	var matches []BlockMatch
+	for _, t := range targets {
+		index, ok := j.indexes[t]
+		if !ok {
+			index = j.buildIndex(t)
+			j.indexes[t] = index
+		}
+		matches = append(matches, index[value]...)
+	}
+	return matches
+}
+
+func (j *joiner) buildIndex(t FollowTarget) map[string][]BlockMatch {
+	index := make(map[string][]BlockMatch)
+	for _, node := range j.nodes {
+		if node.Content == nil {
+			continue
+		}
+		for _, section := range node.Content.Sections {
+			for blockIdx, block := range section.Blocks {
+				if block.Type != t.BlockType {
+					continue
+				}
+				for _, v := range extractStringValues(block.Data[t.Field]) {
+					index[v] = append(index[v], BlockMatch{
+						NodeID:      node.ID,
+						NodeTitle:   node.Title,
+						SectionName: section.Name,
+						BlockIndex:  blockIdx,
+						Block:       block,
+					})
+				}
+			}
+		}
+	}
+	return index
+}
+
+// blockKey identifies a block by its position.
+func blockKey(m BlockMatch) string {
+	return fmt.Sprintf("%s\x00%s\x00%d", m.NodeID, m.SectionName, m.BlockIndex)
+}
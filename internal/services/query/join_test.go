@@ -0,0 +1,176 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package query_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/query"
+	"github.com/Toernblom/deco/internal/storage/config"
+)
+
+// joinNodes models buildings made of materials, recipes producing
+// materials from inputs, and raw resources.
+func joinNodes() []domain.Node {
+	block := func(typ string, data map[string]interface{}) domain.Block {
+		return domain.Block{Type: typ, Data: data}
+	}
+	return []domain.Node{{
+		ID: "eco", Title: "Economy",
+		Content: &domain.Content{Sections: []domain.Section{
+			{Name: "Buildings", Blocks: []domain.Block{
+				block("building", map[string]interface{}{"name": "Forge", "materials": []interface{}{"Bronze", "Stone"}}),
+			}},
+			{Name: "Recipes", Blocks: []domain.Block{
+				block("recipe", map[string]interface{}{"output": "Bronze", "inputs": []interface{}{"Copper", "Tin"}}),
+				block("recipe", map[string]interface{}{"output": "Copper", "inputs": []interface{}{"Ore"}}),
+				// A loop: Slag is made from Slag
+				block("recipe", map[string]interface{}{"output": "Slag", "inputs": []interface{}{"Slag"}}),
+			}},
+			{Name: "Resources", Blocks: []domain.Block{
+				block("resource", map[string]interface{}{"name": "Stone"}),
+				block("resource", map[string]interface{}{"name": "Tin"}),
+				block("resource", map[string]interface{}{"name": "Ore"}),
+			}},
+		}},
+	}}
+}
+
+var joinTypes = map[string]config.BlockTypeConfig{
+	"building": {Fields: map[string]config.FieldDef{
+		"materials": {Type: "list", Refs: []config.RefConstraint{{BlockType: "resource", Field: "name"}, {BlockType: "recipe", Field: "output"}}},
+	}},
+	"recipe": {Fields: map[string]config.FieldDef{
+		"inputs": {Type: "list", Refs: []config.RefConstraint{{BlockType: "resource", Field: "name"}, {BlockType: "recipe", Field: "output"}}},
+	}},
+}
+
+// describe renders a path as "Forge -materials=Bronze-> recipe -inputs=Tin-> resource [stop]".
+func describe(p query.JoinPath) string {
+	var b strings.Builder
+	b.WriteString(p.Source.Block.Type)
+	for _, s := range p.Steps {
+		b.WriteString(" -" + s.Field + "=" + s.Value + "-> ")
+		if s.Match == nil {
+			b.WriteString("?")
+		} else {
+			b.WriteString(s.Match.Block.Type)
+		}
+	}
+	if p.Stop != "" {
+		b.WriteString(" [" + p.Stop + "]")
+	}
+	return b.String()
+}
+
+func describeAll(paths []query.JoinPath) string {
+	var out []string
+	for _, p := range paths {
+		out = append(out, describe(p))
+	}
+	return strings.Join(out, "\n")
+}
+
+func sourceBlocks(t *testing.T, blockType string) []query.BlockMatch {
+	t.Helper()
+	return query.New().FilterBlocks(joinNodes(), query.FilterCriteria{BlockType: &blockType})
+}
+
+func TestFollowChain_ExplicitAndInferredHops(t *testing.T) {
+	qe := query.New()
+	buildings := sourceBlocks(t, "building")
+
+	// Explicit targets restrict each hop
+	hops := []query.FollowHop{
+		{Field: "materials", Targets: []query.FollowTarget{{BlockType: "recipe", Field: "output"}}},
+		{Field: "inputs", Targets: []query.FollowTarget{{BlockType: "resource", Field: "name"}}},
+	}
+	paths, err := qe.FollowChain(buildings, hops, joinNodes(), joinTypes, 0)
+	if err != nil {
+		t.Fatalf("FollowChain failed: %v", err)
+	}
+	want := `building -materials=Bronze-> recipe -inputs=Copper-> ? [unresolved]
+building -materials=Bronze-> recipe -inputs=Tin-> resource
+building -materials=Stone-> ? [unresolved]`
+	if got := describeAll(paths); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Inferred hops use the ref constraints of each block type
+	paths, err = qe.FollowChain(buildings, []query.FollowHop{{Field: "materials"}, {Field: "inputs"}}, joinNodes(), joinTypes, 0)
+	if err != nil {
+		t.Fatalf("FollowChain failed: %v", err)
+	}
+	want = `building -materials=Bronze-> recipe -inputs=Copper-> recipe
+building -materials=Bronze-> recipe -inputs=Tin-> resource
+building -materials=Stone-> resource [no-value]`
+	if got := describeAll(paths); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if last := paths[1].Last(); last.Block.Data["name"] != "Tin" || last.SectionName != "Resources" {
+		t.Errorf("unexpected last block: %+v", last)
+	}
+}
+
+func TestFollowChain_RepeatCycleAndDepth(t *testing.T) {
+	qe := query.New()
+	buildings := sourceBlocks(t, "building")
+	hops := []query.FollowHop{{Field: "materials"}, {Field: "inputs", Repeat: true}}
+
+	paths, err := qe.FollowChain(buildings, hops, joinNodes(), joinTypes, 0)
+	if err != nil {
+		t.Fatalf("FollowChain failed: %v", err)
+	}
+	want := `building -materials=Bronze-> recipe -inputs=Copper-> recipe -inputs=Ore-> resource
+building -materials=Bronze-> recipe -inputs=Tin-> resource
+building -materials=Stone-> resource`
+	if got := describeAll(paths); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	paths, err = qe.FollowChain(buildings, hops, joinNodes(), joinTypes, 2)
+	if err != nil {
+		t.Fatalf("FollowChain failed: %v", err)
+	}
+	if got := describeAll(paths); !strings.Contains(got, "-inputs=Copper-> recipe [depth]") {
+		t.Errorf("expected the depth limit to stop the Copper path, got:\n%s", got)
+	}
+
+	slag := "recipe"
+	recipes := qe.FilterBlocks(joinNodes(), query.FilterCriteria{BlockType: &slag, FieldFilters: map[string]string{"output": "Slag"}})
+	paths, err = qe.FollowChain(recipes, []query.FollowHop{{Field: "inputs", Repeat: true}}, joinNodes(), joinTypes, 0)
+	if err != nil {
+		t.Fatalf("FollowChain failed: %v", err)
+	}
+	if got := describeAll(paths); got != "recipe -inputs=Slag-> recipe [cycle]" {
+		t.Errorf("expected the loop to be cut, got:\n%s", got)
+	}
+}
+
+func TestFollowChain_Errors(t *testing.T) {
+	qe := query.New()
+	buildings := sourceBlocks(t, "building")
+
+	if _, err := qe.FollowChain(buildings, []query.FollowHop{{Field: "materials", Repeat: true}, {Field: "inputs"}}, joinNodes(), joinTypes, 0); err == nil {
+		t.Error("expected an error for a repeated hop before the last")
+	}
+	if _, err := qe.FollowChain(buildings, []query.FollowHop{{Field: "materials"}}, joinNodes(), nil, 0); err == nil ||
+		!strings.Contains(err.Error(), "no ref constraint") {
+		t.Errorf("expected a missing ref constraint error, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package replay reconstructs the project as it was at an earlier time by
+// undoing the history entries recorded since.
+package replay
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+// Result is the node set reconstructed at a point in time.
+type Result struct {
+	Nodes []domain.Node
+
+	// Incomplete lists, sorted, the nodes whose state at the time history
+	// alone cannot recover: their content changed or they were deleted
+	// since. A node listed here may be missing from Nodes or only partly
+	// reverted; callers fill these in from another source such as git.
+	Incomplete []string
+}
+
+// AsOf reconstructs the nodes at time t from the current nodes by undoing,
+// newest first, every entry recorded after t. Entries must be in
+// chronological order, as history.Repository.Query returns them.
+//
+// Creations, moves, workflow status changes and approvals are undone
+// exactly. Entries that only record a content hash (sync, migrate, rewrite)
+// and deletions mark the node incomplete.
+func AsOf(current []domain.Node, entries []domain.AuditEntry, t time.Time) Result {
+	order := make([]string, 0, len(current))
+	byID := make(map[string]*domain.Node, len(current))
+	for _, n := range current {
+		n := n
+		order = append(order, n.ID)
+		byID[n.ID] = &n
+	}
+	incomplete := make(map[string]bool)
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if !e.Timestamp.After(t) {
+			break
+		}
+		n := byID[e.NodeID]
+
+		switch e.Operation {
+		case "create":
+			delete(byID, e.NodeID)
+			delete(incomplete, e.NodeID)
+		case "delete":
+			incomplete[e.NodeID] = true
+		case "move":
+			from, _ := e.Before["id"].(string)
+			if from == "" {
+				incomplete[e.NodeID] = true
+				continue
+			}
+			if n != nil {
+				delete(byID, e.NodeID)
+				n.ID = from
+				byID[from] = n
+				for j, id := range order {
+					if id == e.NodeID {
+						order[j] = from
+					}
+				}
+			}
+			if incomplete[e.NodeID] {
+				delete(incomplete, e.NodeID)
+				incomplete[from] = true
+			}
+		case "baseline":
+			// Records state without changing it
+		case "sync", "migrate", "rewrite":
+			if n != nil {
+				revertFields(n, e.Before)
+			}
+			incomplete[e.NodeID] = true
+		default:
+			if n == nil {
+				continue
+			}
+			if len(e.Before) == 0 || !revertFields(n, e.Before) {
+				incomplete[e.NodeID] = true
+			}
+		}
+	}
+
+	result := Result{Nodes: make([]domain.Node, 0, len(byID))}
+	for _, id := range order {
+		n, ok := byID[id]
+		if !ok || n.ID != id {
+			continue
+		}
+		n.Reviewers = approvalsUntil(n.Reviewers, t)
+		result.Nodes = append(result.Nodes, *n)
+	}
+	for id := range incomplete {
+		result.Incomplete = append(result.Incomplete, id)
+	}
+	sort.Strings(result.Incomplete)
+	return result
+}
+
+// revertFields restores the node fields recorded in an entry's Before map
+// and reports whether every recorded field could be restored.
+func revertFields(n *domain.Node, before map[string]interface{}) bool {
+	complete := true
+	for key, value := range before {
+		s, isString := value.(string)
+		switch key {
+		case "status":
+			n.Status = s
+		case "title":
+			n.Title = s
+		case "summary":
+			n.Summary = s
+		case "kind":
+			n.Kind = s
+		case "version":
+			switch v := value.(type) {
+			case int:
+				n.Version = v
+			case float64:
+				n.Version = int(v)
+			default:
+				complete = false
+			}
+			continue
+		default:
+			complete = false
+			continue
+		}
+		if !isString {
+			complete = false
+		}
+	}
+	return complete
+}
+
+// approvalsUntil keeps the approvals given at or before t.
+func approvalsUntil(reviewers []domain.Reviewer, t time.Time) []domain.Reviewer {
+	var kept []domain.Reviewer
+	for _, r := range reviewers {
+		if !r.Timestamp.After(t) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package replay_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/replay"
+)
+
+var t0 = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func at(days int) time.Time { return t0.AddDate(0, 0, days) }
+
+func entry(days int, id, op string, before map[string]interface{}) domain.AuditEntry {
+	return domain.AuditEntry{Timestamp: at(days), NodeID: id, Operation: op, User: "alice", Before: before}
+}
+
+func currentNodes() []domain.Node {
+	return []domain.Node{
+		{ID: "systems/combat", Kind: "system", Title: "Combat", Version: 3, Status: "approved",
+			Reviewers: []domain.Reviewer{{Name: "bob", Version: 3, Timestamp: at(4)}}},
+		{ID: "items/sword", Kind: "item", Title: "Sword", Version: 1, Status: "draft"},
+		{ID: "systems/magic", Kind: "system", Title: "Magic", Version: 2, Status: "draft"},
+	}
+}
+
+func history() []domain.AuditEntry {
+	return []domain.AuditEntry{
+		entry(0, "systems/combat", "create", nil),
+		entry(0, "systems/spells", "create", nil),
+		entry(1, "systems/combat", "sync", map[string]interface{}{"version": float64(1), "status": "draft"}),
+		entry(2, "systems/magic", "move", map[string]interface{}{"id": "systems/spells"}),
+		entry(3, "items/sword", "create", nil),
+		entry(3, "systems/combat", "submit", map[string]interface{}{"status": "draft"}),
+		entry(4, "systems/combat", "approve", map[string]interface{}{"status": "review"}),
+		entry(5, "items/shield", "delete", nil),
+	}
+}
+
+func ids(nodes []domain.Node) []string {
+	var out []string
+	for _, n := range nodes {
+		out = append(out, n.ID)
+	}
+	return out
+}
+
+func TestAsOf_UndoesWorkflowAndCreations(t *testing.T) {
+	result := replay.AsOf(currentNodes(), history(), at(3))
+
+	// Entries at exactly t are kept
+	if got := ids(result.Nodes); !reflect.DeepEqual(got, []string{"systems/combat", "items/sword", "systems/magic"}) {
+		t.Fatalf("unexpected nodes %v", got)
+	}
+	combat := result.Nodes[0]
+	if combat.Status != "review" || combat.Version != 3 || len(combat.Reviewers) != 0 {
+		t.Errorf("expected combat in review at v3 without approvals, got %s v%d %v", combat.Status, combat.Version, combat.Reviewers)
+	}
+	// The shield was deleted later, so its content is unknown to history
+	if !reflect.DeepEqual(result.Incomplete, []string{"items/shield"}) {
+		t.Errorf("unexpected incomplete nodes %v", result.Incomplete)
+	}
+}
+
+func TestAsOf_MovesAndContentChanges(t *testing.T) {
+	result := replay.AsOf(currentNodes(), history(), at(1).Add(-time.Hour))
+
+	if got := ids(result.Nodes); !reflect.DeepEqual(got, []string{"systems/combat", "systems/spells"}) {
+		t.Fatalf("unexpected nodes %v", got)
+	}
+	combat := result.Nodes[0]
+	if combat.Version != 1 || combat.Status != "draft" {
+		t.Errorf("expected combat reverted to draft v1, got %s v%d", combat.Status, combat.Version)
+	}
+	// The sync changed combat's content, which history does not record
+	if !reflect.DeepEqual(result.Incomplete, []string{"items/shield", "systems/combat"}) {
+		t.Errorf("unexpected incomplete nodes %v", result.Incomplete)
+	}
+
+	result = replay.AsOf(currentNodes(), history(), at(-1))
+	if len(result.Nodes) != 0 || len(result.Incomplete) != 1 {
+		t.Errorf("expected only the deleted shield before any creation, got %v %v", ids(result.Nodes), result.Incomplete)
+	}
+}
+
+func TestAsOf_Present(t *testing.T) {
+	result := replay.AsOf(currentNodes(), history(), at(10))
+	if !reflect.DeepEqual(result.Nodes, currentNodes()) || len(result.Incomplete) != 0 {
+		t.Errorf("expected the current nodes unchanged, got %+v %v", result.Nodes, result.Incomplete)
+	}
+}
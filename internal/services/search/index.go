@@ -0,0 +1,357 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package search provides a persistent, BM25-ranked full-text index over
+// all node content: titles, summaries, blocks, issues, contracts, glossary
+// entries, custom fields and referenced doc files.
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/markdown"
+	"github.com/Toernblom/deco/internal/storage/fsutil"
+)
+
+// FormatVersion is bumped whenever extraction or tokenization changes, so
+// stale caches are rebuilt instead of misread.
+const FormatVersion = 1
+
+// IndexFile is the name of the index file inside the cache directory.
+const IndexFile = "index.json"
+
+// Document is one searchable piece of a node, such as its title, an issue,
+// a content block or a referenced doc file.
+type Document struct {
+	NodeID   string           `json:"node_id"`
+	Field    string           `json:"field"`    // title, summary, tag, issue, contract, glossary, doc, custom, constraint, llm_context or a block type
+	Location string           `json:"location"` // where in the node, e.g. "Movement > rule tick_rate"
+	Text     string           `json:"text"`
+	Length   int              `json:"length"` // number of tokens
+	Terms    map[string][]int `json:"terms"`  // token -> positions
+}
+
+// entry holds the documents of one node and the content hash they were built from.
+type entry struct {
+	Hash      string     `json:"hash"`
+	Documents []Document `json:"documents"`
+}
+
+// Index is an inverted index over node documents. Postings are kept per
+// document (token -> positions) and merged across documents at query time.
+type Index struct {
+	Version int               `json:"version"`
+	Nodes   map[string]*entry `json:"nodes"`
+
+	changed  bool
+	docs     []*Document            // all documents in node ID order, built on demand
+	postings map[string][]*Document // token -> documents containing it, built on demand
+}
+
+// UpdateStats reports what an Update did.
+type UpdateStats struct {
+	Added     int
+	Updated   int
+	Removed   int
+	Unchanged int
+}
+
+// New returns an empty index.
+func New() *Index {
+	return &Index{Version: FormatVersion, Nodes: make(map[string]*entry)}
+}
+
+// Load reads the index from dir. A missing, unreadable or outdated index
+// yields an empty one, since the cache can always be rebuilt.
+func Load(dir string) *Index {
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		return New()
+	}
+	var ix Index
+	if err := json.Unmarshal(data, &ix); err != nil || ix.Version != FormatVersion || ix.Nodes == nil {
+		return New()
+	}
+	return &ix
+}
+
+// Save writes the index to dir, creating it when needed. The file is
+// written to a temporary name first so readers never see a partial index.
+func (ix *Index) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create search cache: %w", err)
+	}
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+	if err := fsutil.WriteFile(filepath.Join(dir, IndexFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	ix.changed = false
+	return nil
+}
+
+// Changed reports whether the index differs from what was loaded or saved.
+func (ix *Index) Changed() bool {
+	return ix.changed
+}
+
+// Update brings the index in line with nodes. hashes maps node IDs to
+// content hashes; only nodes whose hash differs from the indexed one are
+// re-extracted. root is the project root used to read referenced doc files.
+func (ix *Index) Update(nodes []domain.Node, hashes map[string]string, root string) UpdateStats {
+	var stats UpdateStats
+	present := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		present[n.ID] = true
+		hash := hashes[n.ID]
+		old, ok := ix.Nodes[n.ID]
+		if ok && hash != "" && old.Hash == hash {
+			stats.Unchanged++
+			continue
+		}
+		ix.Nodes[n.ID] = &entry{Hash: hash, Documents: Extract(n, root)}
+		ix.changed = true
+		if ok {
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+	}
+	for id := range ix.Nodes {
+		if !present[id] {
+			delete(ix.Nodes, id)
+			ix.changed = true
+			stats.Removed++
+		}
+	}
+	if ix.changed {
+		ix.docs, ix.postings = nil, nil
+	}
+	return stats
+}
+
+// buildPostings merges the per-document term lists into the global
+// token -> documents map used to find candidates.
+func (ix *Index) buildPostings() {
+	if ix.postings != nil {
+		return
+	}
+	ids := make([]string, 0, len(ix.Nodes))
+	for id := range ix.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	ix.docs = nil
+	ix.postings = make(map[string][]*Document)
+	for _, id := range ids {
+		e := ix.Nodes[id]
+		for i := range e.Documents {
+			d := &e.Documents[i]
+			ix.docs = append(ix.docs, d)
+			for term := range d.Terms {
+				ix.postings[term] = append(ix.postings[term], d)
+			}
+		}
+	}
+}
+
+// Extract splits a node into searchable documents. Doc file references are
+// read relative to root; unreadable files are skipped.
+func Extract(n domain.Node, root string) []Document {
+	var docs []Document
+	add := func(field, location string, parts ...string) {
+		text := strings.TrimSpace(strings.Join(nonEmpty(parts), "\n"))
+		if text == "" {
+			return
+		}
+		docs = append(docs, newDocument(n.ID, field, location, text))
+	}
+
+	add("title", "", n.Title)
+	add("summary", "", n.Summary)
+	add("tag", "", strings.Join(n.Tags, " "))
+	add("llm_context", "", n.LLMContext)
+
+	for _, issue := range n.Issues {
+		add("issue", "issue "+issue.ID, issue.Description)
+	}
+	for _, c := range n.Contracts {
+		parts := []string{c.Name, c.Scenario}
+		parts = append(parts, c.Given...)
+		parts = append(parts, c.When...)
+		parts = append(parts, c.Then...)
+		add("contract", "contract "+c.Name, parts...)
+	}
+	for _, term := range sortedKeys(n.Glossary) {
+		add("glossary", "glossary "+term, term+": "+n.Glossary[term])
+	}
+	for _, c := range n.Constraints {
+		add("constraint", "constraint", c.Message)
+	}
+	for _, key := range sortedKeys(n.Custom) {
+		add("custom", "custom "+key, key, strings.Join(stringsIn(n.Custom[key]), " "))
+	}
+	for _, d := range n.Docs {
+		add("doc", "docs "+d.Path, d.Context, strings.Join(d.Keywords, " "), readDoc(root, d.Path))
+	}
+
+	if n.Content != nil {
+		for _, section := range n.Content.Sections {
+			for i, block := range section.Blocks {
+				name := fmt.Sprintf("#%d", i)
+				if id, ok := block.Data["id"].(string); ok && id != "" {
+					name = id
+				}
+				parts := blockText(block.Data)
+				if block.Type == "doc" {
+					if p, ok := block.Data["path"].(string); ok {
+						parts = append(parts, readDoc(root, p))
+					}
+				}
+				add(block.Type, section.Name+" > "+block.Type+" "+name, parts...)
+			}
+		}
+	}
+
+	return docs
+}
+
+func newDocument(nodeID, field, location, text string) Document {
+	doc := Document{NodeID: nodeID, Field: field, Location: location, Text: text, Terms: make(map[string][]int)}
+	for i, tok := range tokenize(text) {
+		doc.Terms[tok.term] = append(doc.Terms[tok.term], i)
+		doc.Length++
+	}
+	return doc
+}
+
+// leadFields are placed first in block text so snippets start with prose.
+var leadFields = []string{"name", "title", "text", "description"}
+
+// blockText collects a block's values, prose fields first.
+func blockText(data map[string]interface{}) []string {
+	var parts []string
+	lead := make(map[string]bool)
+	for _, f := range leadFields {
+		lead[f] = true
+		parts = append(parts, stringsIn(data[f])...)
+	}
+	for _, k := range sortedKeys(data) {
+		if !lead[k] {
+			parts = append(parts, stringsIn(data[k])...)
+		}
+	}
+	return parts
+}
+
+// readDoc returns a referenced doc file, or only its anchored section.
+func readDoc(root, path string) string {
+	if root == "" || path == "" {
+		return ""
+	}
+	file, anchor := markdown.SplitAnchor(path)
+	content, err := os.ReadFile(filepath.Join(root, file))
+	if err != nil {
+		return ""
+	}
+	if anchor == "" {
+		return string(content)
+	}
+	section, _ := markdown.Section(string(content), anchor)
+	return section
+}
+
+// stringsIn collects the scalar values inside v in a deterministic order.
+func stringsIn(v interface{}) []string {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{t}
+	case map[string]interface{}:
+		var out []string
+		for _, k := range sortedKeys(t) {
+			out = append(out, stringsIn(t[k])...)
+		}
+		return out
+	case []interface{}:
+		var out []string
+		for _, item := range t {
+			out = append(out, stringsIn(item)...)
+		}
+		return out
+	case []string:
+		return t
+	default:
+		return []string{fmt.Sprint(t)}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func nonEmpty(parts []string) []string {
+	var out []string
+	for _, p := range parts {
+		if strings.TrimSpace(p) != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// token is a lowercased word and its byte range in the source text.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into lowercased runs of letters and digits.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
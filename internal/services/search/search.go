@@ -0,0 +1,333 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package search
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// fieldBoost weights matches in a node's headline fields above body text.
+var fieldBoost = map[string]float64{
+	"title":   2.0,
+	"summary": 1.5,
+	"tag":     1.5,
+}
+
+// snippetContext is the number of tokens shown before the first match;
+// twice as many follow it.
+const snippetContext = 8
+
+// Clause is one query term or quoted phrase, optionally restricted to a field.
+type Clause struct {
+	Field string   // empty matches any field
+	Terms []string // more than one term means a phrase
+}
+
+// Query is a parsed search query. Every clause must match; see Search.
+type Query struct {
+	Clauses []Clause
+}
+
+// Hit is a ranked matching document.
+type Hit struct {
+	NodeID     string   `json:"node_id"`
+	Field      string   `json:"field"`
+	Location   string   `json:"location,omitempty"`
+	Score      float64  `json:"score"`
+	Snippet    string   `json:"snippet"`
+	Highlights [][2]int `json:"highlights,omitempty"` // byte ranges of matches within Snippet
+}
+
+var (
+	clausePattern = regexp.MustCompile(`(?:([A-Za-z_]+):)?(?:"([^"]*)"|(\S+))`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// ParseQuery parses a query such as `rule:collision "tick rate" issue:balance`.
+// A field prefix restricts the following word or quoted phrase to documents
+// of that field (title, summary, tag, issue, contract, glossary, doc, custom,
+// constraint, llm_context or a block type such as rule or param).
+func ParseQuery(q string) (Query, error) {
+	var query Query
+	for _, m := range clausePattern.FindAllStringSubmatch(q, -1) {
+		text := m[3]
+		if m[2] != "" || strings.Contains(m[0], `"`) {
+			text = m[2]
+		}
+		var terms []string
+		for _, tok := range tokenize(text) {
+			terms = append(terms, tok.term)
+		}
+		if len(terms) == 0 {
+			continue
+		}
+		query.Clauses = append(query.Clauses, Clause{Field: strings.ToLower(m[1]), Terms: terms})
+	}
+	if len(query.Clauses) == 0 {
+		return query, fmt.Errorf("empty search query")
+	}
+	return query, nil
+}
+
+// Search returns the documents matching every clause of q, ranked by BM25
+// score (highest first, ties broken by node and location). A node none of
+// whose documents matches every clause, but whose documents together do,
+// is returned as one hit spanning the best document for each clause, ranked
+// below a single document scoring the same.
+func (ix *Index) Search(q Query) []Hit {
+	ix.buildPostings()
+	if len(ix.docs) == 0 || len(q.Clauses) == 0 {
+		return nil
+	}
+	total := 0
+	for _, d := range ix.docs {
+		total += d.Length
+	}
+	n := float64(len(ix.docs))
+	avgLen := float64(total) / n
+
+	// Term frequency per clause for every document matching it
+	freqs := make([]map[*Document]int, len(q.Clauses))
+	for i, c := range q.Clauses {
+		freqs[i] = make(map[*Document]int)
+		for _, d := range ix.postings[c.Terms[0]] {
+			if tf := c.frequency(d); tf > 0 {
+				freqs[i][d] = tf
+			}
+		}
+	}
+
+	// score returns the boosted BM25 score of clause i in d, if it matches
+	score := func(i int, d *Document) (float64, bool) {
+		tf, ok := freqs[i][d]
+		if !ok {
+			return 0, false
+		}
+		df := float64(len(freqs[i]))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		norm := bm25K1 * (1 - bm25B + bm25B*float64(d.Length)/avgLen)
+		s := idf * float64(tf) * (bm25K1 + 1) / (float64(tf) + norm)
+		if boost, ok := fieldBoost[d.Field]; ok {
+			s *= boost
+		}
+		return s, true
+	}
+
+	var hits []Hit
+	matchedNodes := make(map[string]bool)
+	for _, d := range ix.docs {
+		if _, ok := freqs[0][d]; !ok {
+			continue
+		}
+		sum := 0.0
+		matched := true
+		for i := range q.Clauses {
+			s, ok := score(i, d)
+			if !ok {
+				matched = false
+				break
+			}
+			sum += s
+		}
+		if !matched {
+			continue
+		}
+		matchedNodes[d.NodeID] = true
+		snippet, highlights := snippetFor(d.Text, q)
+		hits = append(hits, Hit{
+			NodeID:     d.NodeID,
+			Field:      d.Field,
+			Location:   d.Location,
+			Score:      math.Round(sum*1000) / 1000,
+			Snippet:    snippet,
+			Highlights: highlights,
+		})
+	}
+
+	if len(q.Clauses) > 1 {
+		for start := 0; start < len(ix.docs); {
+			end := start
+			for end < len(ix.docs) && ix.docs[end].NodeID == ix.docs[start].NodeID {
+				end++
+			}
+			if !matchedNodes[ix.docs[start].NodeID] {
+				if hit, ok := spreadHit(ix.docs[start:end], q, score); ok {
+					hits = append(hits, hit)
+				}
+			}
+			start = end
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].NodeID != hits[j].NodeID {
+			return hits[i].NodeID < hits[j].NodeID
+		}
+		return hits[i].Location < hits[j].Location
+	})
+	return hits
+}
+
+// spreadPenalty scales the score of a hit whose clauses match different
+// documents of a node, since terms far apart are weaker evidence than
+// terms in the same block.
+const spreadPenalty = 0.5
+
+// spreadHit matches q against all documents of one node together: each
+// clause takes its best-scoring document. The hit lists the fields and
+// locations of those documents and joins their snippets.
+func spreadHit(docs []*Document, q Query, score func(int, *Document) (float64, bool)) (Hit, bool) {
+	var best []*Document
+	total := 0.0
+	for i := range q.Clauses {
+		var top *Document
+		topScore := 0.0
+		for _, d := range docs {
+			if s, ok := score(i, d); ok && (top == nil || s > topScore) {
+				top, topScore = d, s
+			}
+		}
+		if top == nil {
+			return Hit{}, false
+		}
+		total += topScore
+		if !slices.Contains(best, top) {
+			best = append(best, top)
+		}
+	}
+
+	hit := Hit{NodeID: docs[0].NodeID, Score: math.Round(total*spreadPenalty*1000) / 1000}
+	var fields, locations []string
+	var b strings.Builder
+	for _, d := range best {
+		if !slices.Contains(fields, d.Field) {
+			fields = append(fields, d.Field)
+		}
+		location := d.Location
+		if location == "" {
+			location = d.Field
+		}
+		locations = append(locations, location)
+
+		if b.Len() > 0 {
+			b.WriteString(" / ")
+		}
+		snippet, highlights := snippetFor(d.Text, q)
+		for _, h := range highlights {
+			hit.Highlights = append(hit.Highlights, [2]int{h[0] + b.Len(), h[1] + b.Len()})
+		}
+		b.WriteString(snippet)
+	}
+	hit.Field = strings.Join(fields, ",")
+	hit.Location = strings.Join(locations, "; ")
+	hit.Snippet = b.String()
+	return hit, true
+}
+
+// frequency counts occurrences of the clause in d, honouring its field
+// restriction and requiring phrase terms at consecutive positions.
+func (c Clause) frequency(d *Document) int {
+	if c.Field != "" && c.Field != d.Field {
+		return 0
+	}
+	first := d.Terms[c.Terms[0]]
+	if len(c.Terms) == 1 {
+		return len(first)
+	}
+	count := 0
+	for _, p := range first {
+		if c.phraseAt(d, p) {
+			count++
+		}
+	}
+	return count
+}
+
+func (c Clause) phraseAt(d *Document, start int) bool {
+	for i, term := range c.Terms[1:] {
+		if !containsInt(d.Terms[term], start+i+1) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsInt(sorted []int, v int) bool {
+	i := sort.SearchInts(sorted, v)
+	return i < len(sorted) && sorted[i] == v
+}
+
+// snippetFor returns a single-line excerpt around the first match in text,
+// with the byte ranges of all query terms inside it.
+func snippetFor(text string, q Query) (string, [][2]int) {
+	tokens := tokenize(text)
+	terms := make(map[string]bool)
+	for _, c := range q.Clauses {
+		for _, t := range c.Terms {
+			terms[t] = true
+		}
+	}
+
+	first := 0
+	for i, tok := range tokens {
+		if terms[tok.term] {
+			first = i
+			break
+		}
+	}
+	from := max(first-snippetContext, 0)
+	to := min(first+snippetContext*2, len(tokens)-1)
+
+	var b strings.Builder
+	var highlights [][2]int
+	if from > 0 {
+		b.WriteString("…")
+	}
+	for i := from; i <= to; i++ {
+		tok := tokens[i]
+		if i > from {
+			b.WriteString(squash(text[tokens[i-1].end:tok.start]))
+		}
+		start := b.Len()
+		b.WriteString(text[tok.start:tok.end])
+		if terms[tok.term] {
+			highlights = append(highlights, [2]int{start, b.Len()})
+		}
+	}
+	if to < len(tokens)-1 {
+		b.WriteString("…")
+	}
+	return b.String(), highlights
+}
+
+// squash collapses whitespace runs, including newlines, to single spaces.
+func squash(s string) string {
+	return spacePattern.ReplaceAllString(s, " ")
+}
@@ -0,0 +1,300 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package search_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/services/search"
+)
+
+func searchTestNodes() []domain.Node {
+	return []domain.Node{
+		{
+			ID: "systems/movement", Kind: "system", Title: "Movement",
+			Summary: "How the snake moves across the grid",
+			Content: &domain.Content{Sections: []domain.Section{{
+				Name: "Rules",
+				Blocks: []domain.Block{
+					{Type: "rule", Data: map[string]interface{}{"id": "wall", "text": "Hitting a wall ends the game"}},
+					{Type: "param", Data: map[string]interface{}{"name": "Tick Rate", "description": "Time between moves", "min": 50}},
+				},
+			}}},
+			Issues:   []domain.Issue{{ID: "speed", Description: "Balance the tick rate against difficulty"}},
+			Glossary: map[string]string{"Tick": "One step of the game loop"},
+		},
+		{
+			ID: "systems/scoring", Kind: "system", Title: "Scoring",
+			Summary:   "Points for eating food",
+			Contracts: []domain.Contract{{Name: "Eat food", Scenario: "Snake eats food", Then: []string{"score increases by 10"}}},
+			Custom:    map[string]interface{}{"owner": "design team"},
+		},
+	}
+}
+
+func newTestIndex(t *testing.T) *search.Index {
+	t.Helper()
+	ix := search.New()
+	ix.Update(searchTestNodes(), nil, "")
+	return ix
+}
+
+func runSearch(t *testing.T, ix *search.Index, q string) []search.Hit {
+	t.Helper()
+	query, err := search.ParseQuery(q)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q) failed: %v", q, err)
+	}
+	return ix.Search(query)
+}
+
+func TestSearch_FindsContentBeyondTitleAndSummary(t *testing.T) {
+	ix := newTestIndex(t)
+
+	tests := []struct {
+		query, node, field string
+	}{
+		{"wall", "systems/movement", "rule"},
+		{"difficulty", "systems/movement", "issue"},
+		{"loop", "systems/movement", "glossary"},
+		{"increases", "systems/scoring", "contract"},
+		{"design", "systems/scoring", "custom"},
+	}
+	for _, tt := range tests {
+		hits := runSearch(t, ix, tt.query)
+		if len(hits) == 0 {
+			t.Errorf("%q: expected a hit", tt.query)
+			continue
+		}
+		if hits[0].NodeID != tt.node || hits[0].Field != tt.field {
+			t.Errorf("%q: got %s/%s, want %s/%s", tt.query, hits[0].NodeID, hits[0].Field, tt.node, tt.field)
+		}
+	}
+}
+
+func TestSearch_FieldPrefix(t *testing.T) {
+	ix := newTestIndex(t)
+
+	// "tick" appears in a param, an issue and the glossary
+	if got := len(runSearch(t, ix, "tick")); got != 3 {
+		t.Errorf("expected 3 hits for tick, got %d", got)
+	}
+	hits := runSearch(t, ix, "issue:tick")
+	if len(hits) != 1 || hits[0].Field != "issue" || hits[0].Location != "issue speed" {
+		t.Errorf("issue:tick should only match the issue, got %+v", hits)
+	}
+	if hits := runSearch(t, ix, "glossary:tick"); len(hits) != 1 || hits[0].Field != "glossary" {
+		t.Errorf("glossary:tick should only match the glossary, got %+v", hits)
+	}
+	if hits := runSearch(t, ix, "rule:tick"); len(hits) != 0 {
+		t.Errorf("rule:tick should not match, got %+v", hits)
+	}
+}
+
+func TestSearch_Phrase(t *testing.T) {
+	ix := newTestIndex(t)
+
+	if hits := runSearch(t, ix, `"tick rate"`); len(hits) != 2 {
+		t.Errorf(`expected 2 hits for "tick rate", got %d`, len(hits))
+	}
+	if hits := runSearch(t, ix, `"rate tick"`); len(hits) != 0 {
+		t.Errorf(`"rate tick" should not match, got %+v`, hits)
+	}
+	if hits := runSearch(t, ix, `issue:"tick rate" balance`); len(hits) != 1 {
+		t.Errorf("expected phrase and term in the same issue, got %+v", hits)
+	}
+}
+
+func TestSearch_TermsAcrossFields(t *testing.T) {
+	ix := newTestIndex(t)
+
+	// "scoring" is only in the title, "design team" only in a custom field
+	hits := runSearch(t, ix, `scoring "design team"`)
+	if len(hits) != 1 || hits[0].NodeID != "systems/scoring" {
+		t.Fatalf("expected one hit for the node, got %+v", hits)
+	}
+	h := hits[0]
+	if h.Field != "title,custom" || !strings.HasPrefix(h.Location, "title; ") {
+		t.Errorf("expected the hit to name both fields, got field %q location %q", h.Field, h.Location)
+	}
+	var words []string
+	for _, r := range h.Highlights {
+		words = append(words, h.Snippet[r[0]:r[1]])
+	}
+	if strings.Join(words, " ") != "Scoring design team" {
+		t.Errorf("expected highlights in both snippets, got %q in %q", words, h.Snippet)
+	}
+
+	// A node with a document matching every clause gets no spread hit
+	hits = runSearch(t, ix, "tick balance")
+	if len(hits) != 1 || hits[0].Field != "issue" {
+		t.Errorf("expected only the issue mentioning both, got %+v", hits)
+	}
+
+	// Terms in different nodes still do not match
+	if hits := runSearch(t, ix, `wall "design team"`); len(hits) != 0 {
+		t.Errorf("expected no hits for terms in different nodes, got %+v", hits)
+	}
+}
+
+func TestSearch_RankingAndLocation(t *testing.T) {
+	ix := newTestIndex(t)
+
+	// Title matches are boosted above body text
+	hits := runSearch(t, ix, "movement")
+	if len(hits) == 0 || hits[0].Field != "title" {
+		t.Fatalf("expected title hit first, got %+v", hits)
+	}
+
+	hits = runSearch(t, ix, "wall")
+	if hits[0].Location != "Rules > rule wall" {
+		t.Errorf("expected block location, got %q", hits[0].Location)
+	}
+	hits = runSearch(t, ix, "between")
+	if hits[0].Location != "Rules > param #1" {
+		t.Errorf("expected positional location for block without id, got %q", hits[0].Location)
+	}
+}
+
+func TestSearch_Snippet(t *testing.T) {
+	ix := search.New()
+	long := strings.Repeat("filler ", 20) + "the Collision rule\nis strict " + strings.Repeat("tail ", 20)
+	ix.Update([]domain.Node{{ID: "a", Title: "A", Summary: long}}, nil, "")
+
+	hits := runSearch(t, ix, "collision")
+	if len(hits) != 1 {
+		t.Fatalf("expected 1 hit, got %d", len(hits))
+	}
+	h := hits[0]
+	if !strings.HasPrefix(h.Snippet, "…") || !strings.HasSuffix(h.Snippet, "…") {
+		t.Errorf("expected ellipses on a truncated snippet, got %q", h.Snippet)
+	}
+	if strings.Contains(h.Snippet, "\n") {
+		t.Errorf("snippet should be a single line, got %q", h.Snippet)
+	}
+	if len(h.Highlights) != 1 || h.Snippet[h.Highlights[0][0]:h.Highlights[0][1]] != "Collision" {
+		t.Errorf("expected Collision highlighted, got %v in %q", h.Highlights, h.Snippet)
+	}
+}
+
+func TestSearch_DocFiles(t *testing.T) {
+	root := t.TempDir()
+	doc := "# Design\n\nIntro.\n\n## Combat\n\nParry windows last six frames.\n\n## Other\n\nUnrelated crafting notes.\n"
+	if err := os.WriteFile(filepath.Join(root, "design.md"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ix := search.New()
+	ix.Update([]domain.Node{{
+		ID: "a", Title: "A",
+		Docs: []domain.DocRef{{Path: "design.md#combat"}},
+	}}, nil, root)
+
+	if hits := runSearch(t, ix, "doc:parry"); len(hits) != 1 {
+		t.Errorf("expected anchored doc section to be indexed, got %+v", hits)
+	}
+	if hits := runSearch(t, ix, "crafting"); len(hits) != 0 {
+		t.Errorf("text outside the anchor should not be indexed, got %+v", hits)
+	}
+}
+
+func TestIndex_IncrementalUpdate(t *testing.T) {
+	nodes := searchTestNodes()
+	hashes := map[string]string{"systems/movement": "h1", "systems/scoring": "h2"}
+
+	ix := search.New()
+	stats := ix.Update(nodes, hashes, "")
+	if stats.Added != 2 || !ix.Changed() {
+		t.Fatalf("expected 2 added, got %+v", stats)
+	}
+
+	dir := filepath.Join(t.TempDir(), "cache", "search")
+	if err := ix.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := search.Load(dir)
+	if loaded.Changed() {
+		t.Error("freshly loaded index should be unchanged")
+	}
+
+	// Same hash: the stale title is kept, proving nothing was re-extracted
+	nodes[0].Title = "Renamed"
+	stats = loaded.Update(nodes, hashes, "")
+	if stats.Unchanged != 2 || loaded.Changed() {
+		t.Errorf("expected nothing re-indexed, got %+v", stats)
+	}
+
+	hashes["systems/movement"] = "h3"
+	stats = loaded.Update(nodes[:1], hashes, "")
+	if stats.Updated != 1 || stats.Removed != 1 {
+		t.Errorf("expected 1 updated and 1 removed, got %+v", stats)
+	}
+	if hits := runSearch(t, loaded, "title:renamed"); len(hits) != 1 {
+		t.Errorf("expected updated title to be searchable, got %+v", hits)
+	}
+	if hits := runSearch(t, loaded, "scoring"); len(hits) != 0 {
+		t.Errorf("removed node should not be found, got %+v", hits)
+	}
+}
+
+func TestLoad_InvalidCacheIsEmpty(t *testing.T) {
+	dir := t.TempDir()
+	if ix := search.Load(dir); len(ix.Nodes) != 0 {
+		t.Error("missing index should load empty")
+	}
+	if err := os.WriteFile(filepath.Join(dir, search.IndexFile), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ix := search.Load(dir); len(ix.Nodes) != 0 {
+		t.Error("corrupt index should load empty")
+	}
+	if err := os.WriteFile(filepath.Join(dir, search.IndexFile), []byte(`{"version": 0, "nodes": {"x": {}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ix := search.Load(dir); len(ix.Nodes) != 0 {
+		t.Error("outdated index should load empty")
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := search.ParseQuery(`Rule:"Wall  Collision" tick issue:balance`)
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	if len(q.Clauses) != 3 {
+		t.Fatalf("expected 3 clauses, got %+v", q.Clauses)
+	}
+	if c := q.Clauses[0]; c.Field != "rule" || strings.Join(c.Terms, " ") != "wall collision" {
+		t.Errorf("unexpected phrase clause %+v", c)
+	}
+	if c := q.Clauses[1]; c.Field != "" || c.Terms[0] != "tick" {
+		t.Errorf("unexpected term clause %+v", c)
+	}
+	if c := q.Clauses[2]; c.Field != "issue" || c.Terms[0] != "balance" {
+		t.Errorf("unexpected prefixed clause %+v", c)
+	}
+
+	for _, empty := range []string{"", "   ", `""`, "!!"} {
+		if _, err := search.ParseQuery(empty); err == nil {
+			t.Errorf("ParseQuery(%q) should fail", empty)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package signing signs and verifies review approvals with ed25519 keys.
+// Keys are read from OpenSSH files (ssh-keygen -t ed25519) or PEM files
+// (PKCS#8 private keys, PKIX public keys). An approval signature covers
+// the node ID, version, content hash and reviewer identity, so it cannot
+// be moved to another node, version or reviewer. The content hash is the
+// full SHA-256 of the node's content fields, so a second content cannot be
+// crafted to match a signed one. Files referenced by doc blocks are not
+// covered: editing them does not invalidate an approval.
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// keyType is the OpenSSH name of ed25519 keys.
+const keyType = "ssh-ed25519"
+
+// Payload returns the bytes an approval signature covers. contentHash is
+// the full SHA-256 of the node content in hex. The format is versioned;
+// v1 signed the 64-bit content hash and is no longer accepted.
+func Payload(nodeID string, version int, contentHash, reviewer string) []byte {
+	return []byte(fmt.Sprintf("deco approval v2\nnode: %s\nversion: %d\ncontent: %s\nreviewer: %s\n",
+		nodeID, version, contentHash, reviewer))
+}
+
+// Sign signs a payload and returns the base64-encoded signature.
+func Sign(key ed25519.PrivateKey, payload []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+}
+
+// Verify reports whether signature is a valid signature of payload by key.
+func Verify(key ed25519.PublicKey, payload []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(key, payload, sig)
+}
+
+// Fingerprint returns the SHA256 fingerprint of a public key, in the same
+// form as ssh-keygen -l.
+func Fingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(wireKey(key))
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// FormatPublicKey returns a public key as an authorized_keys line without
+// a comment.
+func FormatPublicKey(key ed25519.PublicKey) string {
+	return keyType + " " + base64.StdEncoding.EncodeToString(wireKey(key))
+}
+
+// ParsePublicKey parses an ed25519 public key from an OpenSSH public key
+// line ("ssh-ed25519 AAAA... comment") or a PEM "PUBLIC KEY" block.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PEM public key: %w", err)
+		}
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported public key type %T: only ed25519 keys are supported", key)
+		}
+		return pub, nil
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return nil, errors.New("expected an OpenSSH public key (ssh-ed25519 AAAA...) or a PEM public key")
+	}
+	if fields[0] != keyType {
+		return nil, fmt.Errorf("unsupported key type %q: only ssh-ed25519 keys are supported", fields[0])
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	r := reader{data: blob}
+	if name := r.string(); string(name) != keyType {
+		return nil, fmt.Errorf("public key is of type %q, not %s", name, keyType)
+	}
+	pub := r.string()
+	if r.err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("malformed ssh-ed25519 public key")
+	}
+	return ed25519.PublicKey(pub), nil
+}
+
+// ParsePrivateKey parses an unencrypted ed25519 private key from an
+// OpenSSH private key file or a PEM "PRIVATE KEY" (PKCS#8) block.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("expected a PEM-encoded private key")
+	}
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		return parseOpenSSHPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PKCS#8 private key: %w", err)
+		}
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T: only ed25519 keys are supported", key)
+		}
+		return priv, nil
+	}
+	return nil, fmt.Errorf("unsupported private key block %q: only ed25519 keys are supported", block.Type)
+}
+
+// opensshMagic starts the body of an OpenSSH private key file.
+const opensshMagic = "openssh-key-v1\x00"
+
+// parseOpenSSHPrivateKey decodes the openssh-key-v1 format described in
+// OpenSSH's PROTOCOL.key.
+func parseOpenSSHPrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	if !bytes.HasPrefix(data, []byte(opensshMagic)) {
+		return nil, errors.New("invalid OpenSSH private key")
+	}
+	r := reader{data: data[len(opensshMagic):]}
+	cipher, kdf := r.string(), r.string()
+	r.string() // kdf options
+	count := r.uint32()
+	r.string() // public key
+	private := r.string()
+	if r.err != nil {
+		return nil, errors.New("malformed OpenSSH private key")
+	}
+	if string(cipher) != "none" || string(kdf) != "none" {
+		return nil, errors.New("encrypted private keys are not supported; remove the passphrase or use a separate signing key")
+	}
+	if count != 1 {
+		return nil, fmt.Errorf("expected one key in the file, found %d", count)
+	}
+
+	r = reader{data: private}
+	if r.uint32() != r.uint32() {
+		return nil, errors.New("malformed OpenSSH private key")
+	}
+	if name := r.string(); r.err == nil && string(name) != keyType {
+		return nil, fmt.Errorf("unsupported key type %q: only ssh-ed25519 keys are supported", name)
+	}
+	r.string() // public key
+	priv := r.string()
+	if r.err != nil || len(priv) != ed25519.PrivateKeySize {
+		return nil, errors.New("malformed ssh-ed25519 private key")
+	}
+	return ed25519.PrivateKey(priv), nil
+}
+
+// wireKey encodes a public key in the SSH wire format.
+func wireKey(key ed25519.PublicKey) []byte {
+	var b bytes.Buffer
+	for _, field := range [][]byte{[]byte(keyType), key} {
+		binary.Write(&b, binary.BigEndian, uint32(len(field)))
+		b.Write(field)
+	}
+	return b.Bytes()
+}
+
+// reader reads SSH wire-format values, remembering the first error.
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) uint32() uint32 {
+	if r.err != nil || len(r.data) < 4 {
+		r.err = errors.New("truncated data")
+		return 0
+	}
+	v := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return v
+}
+
+func (r *reader) string() []byte {
+	n := r.uint32()
+	if r.err != nil || uint32(len(r.data)) < n {
+		r.err = errors.New("truncated data")
+		return nil
+	}
+	s := r.data[:n]
+	r.data = r.data[n:]
+	return s
+}
@@ -17,20 +17,26 @@ package validator
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/Toernblom/deco/internal/domain"
 	"github.com/Toernblom/deco/internal/errors"
+	"github.com/Toernblom/deco/internal/services/markdown"
 )
 
 // DocValidator validates external doc references in nodes and doc blocks.
-type DocValidator struct{}
+type DocValidator struct {
+	suggester *errors.Suggester
+}
 
 // NewDocValidator creates a new doc validator.
 func NewDocValidator() *DocValidator {
-	return &DocValidator{}
+	return &DocValidator{
+		suggester: errors.NewSuggester(),
+	}
 }
 
 // ValidateNodeDocs validates all node-level doc references.
@@ -79,31 +85,53 @@ func (dv *DocValidator) ValidateDocBlock(block *domain.Block, nodeID, sectionNam
 	dv.validateDocRef(path, keywords, nodeID, sectionName, "doc", blockIdx, projectRoot, nil, collector)
 }
 
-// validateDocRef checks that a doc file exists and contains required keywords.
+// validateDocRef checks that a doc file exists, that its anchor (if any)
+// resolves to a heading, that required keywords appear in the referenced
+// text, and that relative links inside Markdown docs resolve.
 func (dv *DocValidator) validateDocRef(path string, keywords []string, nodeID, sectionName, blockType string, blockIdx int, projectRoot string, location *domain.Location, collector *errors.Collector) {
-	fullPath := filepath.Join(projectRoot, path)
+	file, anchor := markdown.SplitAnchor(path)
+	fullPath := filepath.Join(projectRoot, file)
 
 	content, err := os.ReadFile(fullPath)
 	if err != nil {
-		detail := fmt.Sprintf("node %q references doc file %q which does not exist", nodeID, path)
+		detail := fmt.Sprintf("node %q references doc file %q which does not exist", nodeID, file)
 		if sectionName != "" {
-			detail = fmt.Sprintf("in node %q, section %q, block %d: doc file %q not found", nodeID, sectionName, blockIdx, path)
+			detail = fmt.Sprintf("in node %q, section %q, block %d: doc file %q not found", nodeID, sectionName, blockIdx, file)
 		}
 		collector.Add(domain.DecoError{
 			Code:     "E055",
-			Summary:  fmt.Sprintf("Doc file not found: %s", path),
+			Summary:  fmt.Sprintf("Doc file not found: %s", file),
 			Detail:   detail,
 			Location: location,
 		})
 		return // Skip keyword check if file doesn't exist
 	}
 
-	// Check keywords (case-insensitive substring matching)
-	if len(keywords) == 0 {
-		return
+	// Narrow the checked text to the anchored section
+	text := string(content)
+	lineOffset := 0
+	if anchor != "" {
+		section, ok := markdown.Section(text, anchor)
+		if !ok {
+			detail := fmt.Sprintf("node %q: no heading with anchor #%s in %s", nodeID, anchor, file)
+			if sectionName != "" {
+				detail = fmt.Sprintf("in node %q, section %q, block %d: no heading with anchor #%s in %s", nodeID, sectionName, blockIdx, anchor, file)
+			}
+			collector.Add(domain.DecoError{
+				Code:       "E057",
+				Summary:    fmt.Sprintf("Doc anchor not found: %s", path),
+				Detail:     detail,
+				Location:   location,
+				Suggestion: dv.anchorSuggestion(text, anchor),
+			})
+			return // Skip keyword check if the section doesn't exist
+		}
+		text = section
+		lineOffset, _ = markdown.SectionStartLine(string(content), anchor)
 	}
 
-	lowerContent := strings.ToLower(string(content))
+	// Check keywords (case-insensitive substring matching)
+	lowerContent := strings.ToLower(text)
 	for _, keyword := range keywords {
 		if !strings.Contains(lowerContent, strings.ToLower(keyword)) {
 			detail := fmt.Sprintf("node %q: keyword %q not found in %s", nodeID, keyword, path)
@@ -118,4 +146,77 @@ func (dv *DocValidator) validateDocRef(path string, keywords []string, nodeID, s
 			})
 		}
 	}
+
+	if markdown.IsMarkdown(file) {
+		dv.validateLinks(text, lineOffset, string(content), file, nodeID, projectRoot, collector)
+	}
+}
+
+// validateLinks checks relative links and images in Markdown text taken from
+// docFile. Same-document anchors are resolved against fullContent; links to
+// other Markdown files with an anchor are resolved against that file's headings.
+func (dv *DocValidator) validateLinks(text string, lineOffset int, fullContent, docFile, nodeID, projectRoot string, collector *errors.Collector) {
+	for _, link := range markdown.Links(text) {
+		if link.Target == "" || markdown.IsExternal(link.Target) {
+			continue
+		}
+
+		target, anchor := markdown.SplitAnchor(link.Target)
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+
+		var problem string
+		if target == "" {
+			if anchor != "" && !markdown.HasAnchor(fullContent, anchor) {
+				problem = fmt.Sprintf("no heading with anchor #%s", anchor)
+			}
+		} else {
+			linkedPath := filepath.Join(filepath.Dir(docFile), target)
+			if strings.HasPrefix(target, "/") {
+				linkedPath = target
+			}
+			linked, err := os.ReadFile(filepath.Join(projectRoot, linkedPath))
+			switch {
+			case err != nil && !isDir(filepath.Join(projectRoot, linkedPath)):
+				problem = fmt.Sprintf("%s does not exist", filepath.ToSlash(linkedPath))
+			case err == nil && anchor != "" && markdown.IsMarkdown(target) && !markdown.HasAnchor(string(linked), anchor):
+				problem = fmt.Sprintf("no heading with anchor #%s in %s", anchor, filepath.ToSlash(linkedPath))
+			}
+		}
+		if problem == "" {
+			continue
+		}
+
+		kind := "link"
+		if link.Image {
+			kind = "image"
+		}
+		collector.Add(domain.DecoError{
+			Code:     "E058",
+			Summary:  fmt.Sprintf("Broken %s in %s: %s", kind, docFile, link.Target),
+			Detail:   fmt.Sprintf("node %q references %s, which links to %q: %s", nodeID, docFile, link.Target, problem),
+			Location: &domain.Location{File: docFile, Line: lineOffset + link.Line, Column: link.Column},
+		})
+	}
+}
+
+// anchorSuggestion proposes the closest heading anchor in the document.
+func (dv *DocValidator) anchorSuggestion(content, anchor string) string {
+	var anchors []string
+	for _, h := range markdown.Headings(content) {
+		anchors = append(anchors, h.Anchor)
+	}
+	if len(anchors) == 0 {
+		return "The document has no headings"
+	}
+	if suggs := dv.suggester.Suggest(anchor, anchors); len(suggs) > 0 {
+		return fmt.Sprintf("Did you mean #%s?", suggs[0])
+	}
+	return fmt.Sprintf("Available anchors: #%s", strings.Join(anchors, ", #"))
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
@@ -339,3 +339,127 @@ func TestDocValidator_MultipleDocs(t *testing.T) {
 		t.Error("expected E055 for missing outro.md")
 	}
 }
+
+func TestDocValidator_Anchor_Resolves(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "auth.md"), []byte("# Auth\n\n## Token Rotation\nTokens rotate every 24h.\n\n## Sessions\nSessions expire.\n"), 0644)
+
+	node := &domain.Node{
+		ID: "systems/auth",
+		Docs: []domain.DocRef{
+			{Path: "auth.md#token-rotation", Keywords: []string{"rotate"}},
+		},
+	}
+
+	collector := errors.NewCollector()
+	validator.NewDocValidator().ValidateNodeDocs(node, dir, collector)
+
+	if collector.HasErrors() {
+		t.Errorf("expected no errors, got: %v", collector.Errors())
+	}
+}
+
+func TestDocValidator_Anchor_Missing(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "auth.md"), []byte("# Auth\n\n## Token Rotation\nTokens rotate.\n"), 0644)
+
+	node := &domain.Node{
+		ID: "systems/auth",
+		Docs: []domain.DocRef{
+			{Path: "auth.md#token-rotaton", Keywords: []string{"rotate"}},
+		},
+	}
+
+	collector := errors.NewCollector()
+	validator.NewDocValidator().ValidateNodeDocs(node, dir, collector)
+
+	errs := collector.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error (keywords skipped), got %d: %v", len(errs), errs)
+	}
+	if errs[0].Code != "E057" {
+		t.Errorf("expected E057, got %s", errs[0].Code)
+	}
+	if errs[0].Suggestion != "Did you mean #token-rotation?" {
+		t.Errorf("unexpected suggestion: %q", errs[0].Suggestion)
+	}
+}
+
+func TestDocValidator_Anchor_KeywordsScopedToSection(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "auth.md"), []byte("# Auth\n\n## Token Rotation\nTokens rotate.\n\n## Sessions\nSessions use cookies.\n"), 0644)
+
+	block := domain.Block{
+		Type: "doc",
+		Data: map[string]interface{}{
+			"path":     "auth.md#token-rotation",
+			"keywords": []interface{}{"cookies"},
+		},
+	}
+
+	collector := errors.NewCollector()
+	validator.NewDocValidator().ValidateDocBlock(&block, "systems/auth", "Docs", 0, dir, collector)
+
+	errs := collector.Errors()
+	if len(errs) != 1 || errs[0].Code != "E056" {
+		t.Fatalf("expected a single E056 for keyword outside the section, got: %v", errs)
+	}
+}
+
+func TestDocValidator_Links(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "docs", "img"), 0755)
+	os.WriteFile(filepath.Join(dir, "docs", "img", "flow.png"), []byte("png"), 0644)
+	os.WriteFile(filepath.Join(dir, "docs", "api.md"), []byte("# API\n\n## Endpoints\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "docs", "auth.md"), []byte(`# Auth
+
+See [API](api.md#endpoints), [home](https://example.com) and [below](#sessions).
+![flow](img/flow.png)
+![missing](img/missing.png)
+[gone](old.md) and [bad anchor](api.md#nope) and [self](#nowhere)
+
+`+"```"+`
+[ignored](in-code.md)
+`+"```"+`
+
+## Sessions
+`), 0644)
+
+	node := &domain.Node{
+		ID:   "systems/auth",
+		Docs: []domain.DocRef{{Path: "docs/auth.md"}},
+	}
+
+	collector := errors.NewCollector()
+	validator.NewDocValidator().ValidateNodeDocs(node, dir, collector)
+
+	errs := collector.Errors()
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 broken links, got %d: %v", len(errs), errs)
+	}
+	for _, err := range errs {
+		if err.Code != "E058" {
+			t.Errorf("expected E058, got %s", err.Code)
+		}
+		if err.Location == nil || err.Location.File != "docs/auth.md" || err.Location.Line == 0 {
+			t.Errorf("expected location in docs/auth.md, got %+v", err.Location)
+		}
+	}
+}
+
+func TestDocValidator_Links_ScopedToAnchor(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "auth.md"), []byte("# Auth\n[broken](missing.md)\n\n## Tokens\n[ok](#auth)\n"), 0644)
+
+	node := &domain.Node{
+		ID:   "systems/auth",
+		Docs: []domain.DocRef{{Path: "auth.md#tokens"}},
+	}
+
+	collector := errors.NewCollector()
+	validator.NewDocValidator().ValidateNodeDocs(node, dir, collector)
+
+	if collector.HasErrors() {
+		t.Errorf("expected links outside the anchored section to be ignored, got: %v", collector.Errors())
+	}
+}
@@ -16,6 +16,7 @@
 package validator
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -24,6 +25,7 @@ import (
 	"github.com/Toernblom/deco/internal/domain"
 	"github.com/Toernblom/deco/internal/errors"
 	yamlloc "github.com/Toernblom/deco/internal/errors/yaml"
+	"github.com/Toernblom/deco/internal/services/signing"
 	"github.com/Toernblom/deco/internal/storage/config"
 	"github.com/google/cel-go/cel"
 	"gopkg.in/yaml.v3"
@@ -342,8 +344,9 @@ func (rv *ReferenceValidator) Validate(nodes []domain.Node, collector *errors.Co
 
 // ConstraintValidator validates CEL expression constraints on nodes.
 type ConstraintValidator struct {
-	env      *cel.Env
-	programs map[string]cel.Program // cache compiled programs by expression
+	env         *cel.Env
+	programs    map[string]cel.Program     // cache compiled programs by expression
+	queryScopes map[string]map[string]bool // saved query name -> selected node IDs
 }
 
 // NewConstraintValidator creates a new constraint validator.
@@ -393,6 +396,20 @@ func (cv *ConstraintValidator) Validate(node *domain.Node, allNodes []domain.Nod
 
 	// Evaluate each constraint
 	for _, constraint := range node.Constraints {
+		// Saved query scopes must name a query that could be run
+		if name, ok := strings.CutPrefix(constraint.Scope, "@"); ok {
+			if _, known := cv.queryScopes[name]; !known {
+				collector.Add(domain.DecoError{
+					Code:       "E059",
+					Summary:    fmt.Sprintf("Constraint scope %q does not name a usable saved query", constraint.Scope),
+					Detail:     "Scopes starting with @ refer to saved queries that run without parameters.",
+					Suggestion: "Run 'deco query --list-saved' to see the saved queries defined in .deco/config.yaml.",
+					Location:   location,
+				})
+				continue
+			}
+		}
+
 		// Skip constraints that don't match the node's scope
 		if !cv.matchesScope(constraint.Scope, node) {
 			continue
@@ -416,11 +433,16 @@ func (cv *ConstraintValidator) Validate(node *domain.Node, allNodes []domain.Nod
 //   - "all" matches any node
 //   - exact kind match (e.g., "mechanic") matches nodes with that Kind
 //   - path pattern with glob (e.g., "systems/*") matches node IDs using filepath.Match
+//   - "@name" matches the nodes selected by the saved query "name"
 func (cv *ConstraintValidator) matchesScope(scope string, node *domain.Node) bool {
 	if scope == "" || scope == "all" {
 		return true
 	}
 
+	if name, ok := strings.CutPrefix(scope, "@"); ok {
+		return cv.queryScopes[name][node.ID]
+	}
+
 	// Try exact kind match first
 	if scope == node.Kind {
 		return true
@@ -438,9 +460,17 @@ func (cv *ConstraintValidator) matchesScope(scope string, node *domain.Node) boo
 	return false
 }
 
-// nodeToMap converts a domain.Node to a map suitable for CEL evaluation.
-// This allows CEL expressions to access all node fields including custom data.
-func nodeToMap(node *domain.Node) map[string]interface{} {
+// SetQueryScopes sets the node IDs selected by each saved query, making
+// "@name" usable as a constraint scope. The validator cannot run queries
+// itself, so callers evaluate them up front.
+func (cv *ConstraintValidator) SetQueryScopes(scopes map[string]map[string]bool) {
+	cv.queryScopes = scopes
+}
+
+// NodeToMap converts a domain.Node to a map suitable for CEL evaluation.
+// This allows CEL expressions (constraints and query --where) to access all
+// node fields including custom data.
+func NodeToMap(node *domain.Node) map[string]interface{} {
 	if node == nil {
 		return nil
 	}
@@ -538,7 +568,7 @@ func contentToMap(content *domain.Content) map[string]interface{} {
 func buildRefsLookup(allNodes []domain.Node) map[string]interface{} {
 	lookup := make(map[string]interface{})
 	for i := range allNodes {
-		lookup[allNodes[i].ID] = nodeToMap(&allNodes[i])
+		lookup[allNodes[i].ID] = NodeToMap(&allNodes[i])
 	}
 	return lookup
 }
@@ -547,7 +577,7 @@ func buildRefsLookup(allNodes []domain.Node) map[string]interface{} {
 func buildAllNodesList(allNodes []domain.Node) []interface{} {
 	result := make([]interface{}, len(allNodes))
 	for i := range allNodes {
-		result[i] = nodeToMap(&allNodes[i])
+		result[i] = NodeToMap(&allNodes[i])
 	}
 	return result
 }
@@ -561,7 +591,7 @@ func (cv *ConstraintValidator) evaluateConstraint(node *domain.Node, allNodes []
 	}
 
 	// Convert current node to map
-	selfMap := nodeToMap(node)
+	selfMap := NodeToMap(node)
 
 	// Prepare custom map (empty if nil)
 	customMap := map[string]interface{}{}
@@ -676,6 +706,8 @@ var knownReviewerKeys = map[string]bool{
 	"timestamp": true,
 	"version":   true,
 	"note":      true,
+	"key":       true,
+	"signature": true,
 }
 
 // knownConstraintKeys defines the valid keys in a constraint entry.
@@ -989,6 +1021,12 @@ func (uf *UnknownFieldValidator) ValidateDirectory(rootDir string, collector *er
 // ApprovalValidator validates that approved nodes have sufficient approvals.
 type ApprovalValidator struct {
 	requiredApprovals int
+
+	// When requireSigned is set, only approvals signed by a trusted key
+	// count, and unsigned or invalid ones are reported.
+	requireSigned bool
+	trustedKeys   map[string]ed25519.PublicKey
+	contentHash   func(domain.Node) string
 }
 
 // NewApprovalValidator creates a new approval validator.
@@ -996,6 +1034,22 @@ func NewApprovalValidator(requiredApprovals int) *ApprovalValidator {
 	return &ApprovalValidator{requiredApprovals: requiredApprovals}
 }
 
+// RequireSignatures makes the validator count only approvals signed by
+// the reviewer's trusted key. trustedKeys maps reviewer names to OpenSSH
+// public keys; contentHash computes the hash an approval signs.
+func (av *ApprovalValidator) RequireSignatures(trustedKeys map[string]string, contentHash func(domain.Node) string) {
+	av.requireSigned = true
+	av.contentHash = contentHash
+	av.trustedKeys = make(map[string]ed25519.PublicKey)
+	for name, key := range trustedKeys {
+		// Keys that do not parse are left out, so approvals signed with
+		// them are reported as untrusted
+		if pub, err := signing.ParsePublicKey([]byte(key)); err == nil {
+			av.trustedKeys[name] = pub
+		}
+	}
+}
+
 // Validate checks that approved nodes have enough current-version approvals.
 func (av *ApprovalValidator) Validate(node *domain.Node, collector *errors.Collector) {
 	if node == nil {
@@ -1013,25 +1067,87 @@ func (av *ApprovalValidator) Validate(node *domain.Node, collector *errors.Colle
 		location = &domain.Location{File: node.SourceFile}
 	}
 
-	// Count approvals for current version
+	// Count distinct approvers for the current version: a reviewer name or
+	// signing key that appears on several entries counts once
 	validApprovals := 0
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
 	for _, r := range node.Reviewers {
-		if r.Version == node.Version {
-			validApprovals++
+		if r.Version != node.Version {
+			continue
+		}
+		if av.requireSigned && !av.validSignature(node, r, location, collector) {
+			continue
 		}
+		if names[r.Name] || (r.Key != "" && keys[r.Key]) {
+			continue
+		}
+		names[r.Name] = true
+		if r.Key != "" {
+			keys[r.Key] = true
+		}
+		validApprovals++
 	}
 
 	if validApprovals < av.requiredApprovals {
+		kind := ""
+		if av.requireSigned {
+			kind = "signed "
+		}
 		collector.Add(domain.DecoError{
 			Code:       "E050",
-			Summary:    fmt.Sprintf("Node %q requires %d approval(s), has %d", node.ID, av.requiredApprovals, validApprovals),
-			Detail:     fmt.Sprintf("Approved nodes must have at least %d approval(s) for version %d. Current approvals: %d.", av.requiredApprovals, node.Version, validApprovals),
+			Summary:    fmt.Sprintf("Node %q requires %d %sapproval(s), has %d", node.ID, av.requiredApprovals, kind, validApprovals),
+			Detail:     fmt.Sprintf("Approved nodes must have at least %d %sapproval(s) for version %d from different reviewers. Current approvals: %d.", av.requiredApprovals, kind, node.Version, validApprovals),
 			Suggestion: "Use 'deco review approve' to add approvals, or change status back to 'draft' or 'review'.",
 			Location:   location,
 		})
 	}
 }
 
+// validSignature checks one approval's signature, reporting why it does
+// not count if it is unsigned, signed by an untrusted key or invalid.
+func (av *ApprovalValidator) validSignature(node *domain.Node, r domain.Reviewer, location *domain.Location, collector *errors.Collector) bool {
+	if r.Signature == "" {
+		collector.Add(domain.DecoError{
+			Code:       "E120",
+			Summary:    fmt.Sprintf("Approval of %q by %s is not signed", node.ID, r.Name),
+			Detail:     "The project requires signed approvals (require_signed_approvals), so unsigned approvals do not count.",
+			Suggestion: "Approve again with 'deco review approve --key <private-key-file>'.",
+			Location:   location,
+		})
+		return false
+	}
+
+	key, ok := av.trustedKeys[r.Name]
+	if !ok || signing.Fingerprint(key) != r.Key {
+		detail := fmt.Sprintf("No trusted key is registered for %s.", r.Name)
+		if ok {
+			detail = fmt.Sprintf("The approval was signed with %s, but the trusted key for %s is %s.", r.Key, r.Name, signing.Fingerprint(key))
+		}
+		collector.Add(domain.DecoError{
+			Code:       "E121",
+			Summary:    fmt.Sprintf("Approval of %q by %s is signed with an untrusted key", node.ID, r.Name),
+			Detail:     detail,
+			Suggestion: fmt.Sprintf("Register the reviewer's public key with 'deco keys add %q <public-key-file>'.", r.Name),
+			Location:   location,
+		})
+		return false
+	}
+
+	payload := signing.Payload(node.ID, r.Version, av.contentHash(*node), r.Name)
+	if !signing.Verify(key, payload, r.Signature) {
+		collector.Add(domain.DecoError{
+			Code:       "E122",
+			Summary:    fmt.Sprintf("Approval of %q by %s has an invalid signature", node.ID, r.Name),
+			Detail:     fmt.Sprintf("The signature does not match node %s version %d with its current content, so the approval or the node was changed after signing.", node.ID, r.Version),
+			Suggestion: "Ask the reviewer to approve the current content again.",
+			Location:   location,
+		})
+		return false
+	}
+	return true
+}
+
 // Orchestrator coordinates all validators and aggregates errors.
 type Orchestrator struct {
 	schemaValidator       *SchemaValidator
@@ -1088,6 +1204,18 @@ func NewOrchestrator() *Orchestrator {
 	return NewOrchestratorWithConfig(1)
 }
 
+// SetQueryScopes sets the node IDs selected by each saved query for
+// constraints scoped with "@name".
+func (o *Orchestrator) SetQueryScopes(scopes map[string]map[string]bool) {
+	o.constraintValidator.SetQueryScopes(scopes)
+}
+
+// RequireSignedApprovals makes approval validation count only approvals
+// signed by a trusted reviewer key.
+func (o *Orchestrator) RequireSignedApprovals(trustedKeys map[string]string, contentHash func(domain.Node) string) {
+	o.approvalValidator.RequireSignatures(trustedKeys, contentHash)
+}
+
 // ValidateAll runs all validators on the provided nodes and returns aggregated errors.
 func (o *Orchestrator) ValidateAll(nodes []domain.Node) *errors.Collector {
 	collector := errors.NewCollectorWithLimit(1000)
@@ -16,12 +16,14 @@
 package validator_test
 
 import (
+	"crypto/ed25519"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/Toernblom/deco/internal/domain"
 	"github.com/Toernblom/deco/internal/errors"
+	"github.com/Toernblom/deco/internal/services/signing"
 	"github.com/Toernblom/deco/internal/services/validator"
 	"github.com/Toernblom/deco/internal/storage/config"
 )
@@ -923,6 +925,31 @@ func TestConstraintValidator_ScopeMatching(t *testing.T) {
 	}
 }
 
+func TestConstraintValidator_SavedQueryScope(t *testing.T) {
+	cv := validator.NewConstraintValidator()
+	cv.SetQueryScopes(map[string]map[string]bool{"heavy": {"items/anvil": true}})
+
+	validate := func(id, scope string) []domain.DecoError {
+		node := domain.Node{
+			ID: id, Kind: "item", Version: 1, Status: "draft", Title: "Test Node",
+			Constraints: []domain.Constraint{{Expr: "version < 0", Message: "Always fails", Scope: scope}},
+		}
+		collector := errors.NewCollectorWithLimit(100)
+		cv.Validate(&node, []domain.Node{node}, collector)
+		return collector.Errors()
+	}
+
+	if errs := validate("items/anvil", "@heavy"); len(errs) != 1 || errs[0].Code != "E041" {
+		t.Errorf("expected the constraint to apply to a selected node, got %v", errs)
+	}
+	if errs := validate("items/feather", "@heavy"); len(errs) != 0 {
+		t.Errorf("expected the constraint to skip an unselected node, got %v", errs)
+	}
+	if errs := validate("items/anvil", "@light"); len(errs) != 1 || errs[0].Code != "E059" {
+		t.Errorf("expected E059 for an unknown saved query, got %v", errs)
+	}
+}
+
 func TestConstraintValidator_ScopeWithMultipleConstraints(t *testing.T) {
 	cv := validator.NewConstraintValidator()
 
@@ -2842,6 +2869,27 @@ func TestApprovalValidator(t *testing.T) {
 		}
 	})
 
+	t.Run("the same reviewer counts once", func(t *testing.T) {
+		av := validator.NewApprovalValidator(2)
+		collector := errors.NewCollector()
+
+		node := &domain.Node{
+			ID:      "test/node",
+			Version: 1,
+			Status:  "approved",
+			Reviewers: []domain.Reviewer{
+				{Name: "alice", Timestamp: time.Now(), Version: 1},
+				{Name: "alice", Timestamp: time.Now(), Version: 1},
+			},
+		}
+
+		av.Validate(node, collector)
+
+		if !collector.HasErrors() {
+			t.Error("Expected a repeated reviewer not to satisfy 2 approvals")
+		}
+	})
+
 	t.Run("approvals must match current version", func(t *testing.T) {
 		av := validator.NewApprovalValidator(1)
 		collector := errors.NewCollector()
@@ -2866,6 +2914,89 @@ func TestApprovalValidator(t *testing.T) {
 	})
 }
 
+func TestApprovalValidator_Signed(t *testing.T) {
+	alicePub, alicePriv, _ := ed25519.GenerateKey(nil)
+	_, malloryPriv, _ := ed25519.GenerateKey(nil)
+	trusted := map[string]string{"alice": signing.FormatPublicKey(alicePub), "bob": "not a key"}
+	contentHash := func(n domain.Node) string { return n.Title }
+
+	approval := func(name string, key ed25519.PrivateKey, title string) domain.Reviewer {
+		pub := key.Public().(ed25519.PublicKey)
+		payload := signing.Payload("test/node", 1, title, name)
+		return domain.Reviewer{Name: name, Version: 1, Key: signing.Fingerprint(pub), Signature: signing.Sign(key, payload)}
+	}
+
+	tests := []struct {
+		name     string
+		reviewer domain.Reviewer
+		codes    []string
+	}{
+		{"signed by trusted key", approval("alice", alicePriv, "Test"), nil},
+		{"unsigned", domain.Reviewer{Name: "alice", Version: 1}, []string{"E120", "E050"}},
+		{"signed by another key", approval("alice", malloryPriv, "Test"), []string{"E121", "E050"}},
+		{"reviewer without a valid trusted key", approval("bob", alicePriv, "Test"), []string{"E121", "E050"}},
+		{"content changed after signing", approval("alice", alicePriv, "Old title"), []string{"E122", "E050"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			av := validator.NewApprovalValidator(1)
+			av.RequireSignatures(trusted, contentHash)
+			collector := errors.NewCollector()
+
+			node := &domain.Node{
+				ID:        "test/node",
+				Kind:      "mechanic",
+				Version:   1,
+				Status:    "approved",
+				Title:     "Test",
+				Reviewers: []domain.Reviewer{tt.reviewer},
+			}
+			av.Validate(node, collector)
+
+			var codes []string
+			for _, e := range collector.Errors() {
+				codes = append(codes, e.Code)
+			}
+			if strings.Join(codes, ",") != strings.Join(tt.codes, ",") {
+				t.Errorf("codes = %v, want %v", codes, tt.codes)
+			}
+		})
+	}
+
+	// A copied entry, or one key under two names, counts once
+	bobPub, bobPriv, _ := ed25519.GenerateKey(nil)
+	quorum := map[string]string{
+		"alice": signing.FormatPublicKey(alicePub),
+		"bob":   signing.FormatPublicKey(bobPub),
+		"eve":   signing.FormatPublicKey(alicePub),
+	}
+	for _, tt := range []struct {
+		name      string
+		reviewers []domain.Reviewer
+		errs      int
+	}{
+		{"duplicated signed entry", []domain.Reviewer{approval("alice", alicePriv, "Test"), approval("alice", alicePriv, "Test")}, 1},
+		{"one key under two names", []domain.Reviewer{approval("alice", alicePriv, "Test"), approval("eve", alicePriv, "Test")}, 1},
+		{"two reviewers", []domain.Reviewer{approval("alice", alicePriv, "Test"), approval("bob", bobPriv, "Test")}, 0},
+	} {
+		av := validator.NewApprovalValidator(2)
+		av.RequireSignatures(quorum, contentHash)
+		collector := errors.NewCollector()
+		av.Validate(&domain.Node{ID: "test/node", Version: 1, Status: "approved", Title: "Test", Reviewers: tt.reviewers}, collector)
+		if got := len(collector.Errors()); got != tt.errs || (got == 1 && collector.Errors()[0].Code != "E050") {
+			t.Errorf("%s: errors = %v, want %d E050", tt.name, collector.Errors(), tt.errs)
+		}
+	}
+
+	// Without the requirement, unsigned approvals still count
+	av := validator.NewApprovalValidator(1)
+	collector := errors.NewCollector()
+	av.Validate(&domain.Node{ID: "test/node", Version: 1, Status: "approved", Reviewers: []domain.Reviewer{{Name: "alice", Version: 1}}}, collector)
+	if collector.HasErrors() {
+		t.Errorf("expected unsigned approvals to count by default, got %v", collector.Errors())
+	}
+}
+
 func TestOrchestrator_ApprovalValidation(t *testing.T) {
 	t.Run("orchestrator validates approvals", func(t *testing.T) {
 		orch := validator.NewOrchestratorWithConfig(2) // 2 required approvals
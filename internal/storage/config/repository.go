@@ -118,6 +118,42 @@ type SchemaRuleConfig struct {
 	RequiredFields []string `yaml:"required_fields" json:"required_fields"`
 }
 
+// SavedQuery is a named query stored in config and run as 'deco query @name'.
+// String values may contain ${param} placeholders that are filled in from
+// Params defaults or 'deco query --param name=value'.
+type SavedQuery struct {
+	// Description explains what the query is for.
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Params maps parameter names to default values. An empty default makes
+	// the parameter required.
+	Params map[string]string `yaml:"params,omitempty" json:"params,omitempty"`
+
+	Search     string            `yaml:"search,omitempty" json:"search,omitempty"`
+	Kind       string            `yaml:"kind,omitempty" json:"kind,omitempty"`
+	Status     string            `yaml:"status,omitempty" json:"status,omitempty"`
+	Tag        string            `yaml:"tag,omitempty" json:"tag,omitempty"`
+	BlockType  string            `yaml:"block_type,omitempty" json:"block_type,omitempty"`
+	Fields     map[string]string `yaml:"fields,omitempty" json:"fields,omitempty"`
+	Follow     string            `yaml:"follow,omitempty" json:"follow,omitempty"`
+	Where      string            `yaml:"where,omitempty" json:"where,omitempty"`
+	BlockWhere string            `yaml:"block_where,omitempty" json:"block_where,omitempty"`
+	GroupBy    []string          `yaml:"group_by,omitempty" json:"group_by,omitempty"`
+	Agg        []string          `yaml:"agg,omitempty" json:"agg,omitempty"`
+
+	// Output shaping, as with --format, --columns, --sort and --limit.
+	Format  string   `yaml:"format,omitempty" json:"format,omitempty"`
+	Columns []string `yaml:"columns,omitempty" json:"columns,omitempty"`
+	Sort    string   `yaml:"sort,omitempty" json:"sort,omitempty"`
+	Limit   int      `yaml:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ChangelogConfig holds defaults for 'deco changelog'.
+type ChangelogConfig struct {
+	// Exclude lists history operations left out of changelogs, such as
+	// sync or baseline. --exclude overrides it.
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+}
+
 // Config represents the project configuration.
 // It defines where nodes are stored, project metadata, and other settings.
 type Config struct {
@@ -151,6 +187,21 @@ type Config struct {
 	// Used to detect when schema changes require migration.
 	SchemaVersion string `yaml:"schema_version,omitempty" json:"schema_version,omitempty"`
 
+	// Queries defines saved named queries, keyed by name.
+	// They run as 'deco query @name' and can scope constraints and exports.
+	Queries map[string]SavedQuery `yaml:"queries,omitempty" json:"queries,omitempty"`
+
+	// RequireSignedApprovals makes 'deco validate' count only approvals
+	// signed by a key in TrustedKeys.
+	RequireSignedApprovals bool `yaml:"require_signed_approvals,omitempty" json:"require_signed_approvals,omitempty"`
+
+	// TrustedKeys maps reviewer names to their public keys, in OpenSSH
+	// authorized_keys form. Managed with 'deco keys'.
+	TrustedKeys map[string]string `yaml:"trusted_keys,omitempty" json:"trusted_keys,omitempty"`
+
+	// Changelog holds defaults for 'deco changelog'.
+	Changelog ChangelogConfig `yaml:"changelog,omitempty" json:"changelog,omitempty"`
+
 	// Custom allows projects to add arbitrary configuration fields.
 	Custom map[string]interface{} `yaml:"custom,omitempty" json:"custom,omitempty"`
 }
@@ -196,3 +247,32 @@ func ResolveHistoryPath(cfg Config, rootDir string) string {
 	}
 	return filepath.Join(rootDir, path)
 }
+
+// ResolveObjectsPath returns the absolute path of the snapshot object store:
+// an "objects" directory next to the history file.
+func ResolveObjectsPath(cfg Config, rootDir string) string {
+	return ObjectsPathFor(ResolveHistoryPath(cfg, rootDir))
+}
+
+// ObjectsPathFor returns the snapshot object store that belongs to a
+// history file.
+func ObjectsPathFor(historyPath string) string {
+	return filepath.Join(filepath.Dir(historyPath), "objects")
+}
+
+// CachePath is the directory for derived data such as the search index.
+// Everything in it can be rebuilt from nodes and is ignored by git.
+const CachePath = ".deco/cache"
+
+// ResolveCachePath returns the directory for the named cache under CachePath.
+func ResolveCachePath(rootDir, name string) string {
+	return filepath.Join(rootDir, CachePath, name)
+}
+
+// ReleasesPath is the directory of release files written by deco release.
+const ReleasesPath = ".deco/releases"
+
+// ResolveReleasesPath returns the absolute path of the releases directory.
+func ResolveReleasesPath(rootDir string) string {
+	return filepath.Join(rootDir, ReleasesPath)
+}
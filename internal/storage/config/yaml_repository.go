@@ -20,6 +20,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/Toernblom/deco/internal/storage/fsutil"
 	"gopkg.in/yaml.v3"
 )
 
@@ -83,8 +84,8 @@ func (r *YAMLRepository) Save(cfg Config) error {
 		return fmt.Errorf("failed to marshal config to YAML: %w", err)
 	}
 
-	// Write to file
-	err = os.WriteFile(path, data, 0644)
+	// Write to a temporary file and rename it into place
+	err = fsutil.WriteFile(path, data, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
@@ -0,0 +1,109 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package fsutil writes files so that a crash never leaves them partly
+// written.
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data to path atomically: the data goes to a temporary
+// file in the same directory, is flushed to disk and then renamed over
+// path. Readers see either the old contents or the new, never a mix.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := CreateTemp(dir, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if err := writeAndClose(tmp, data, perm); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
+	}
+	SyncDir(dir)
+	return nil
+}
+
+// WriteFileExclusive writes data to a new file at path atomically. It fails
+// with an error satisfying os.IsExist when path already exists, even when
+// another process creates it at the same time.
+func WriteFileExclusive(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := CreateTemp(dir, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := writeAndClose(tmp, data, perm); err != nil {
+		return err
+	}
+	// A hard link, unlike a rename, never replaces an existing file
+	if err := os.Link(tmp.Name(), path); err != nil {
+		return err
+	}
+	SyncDir(dir)
+	return nil
+}
+
+// CreateTemp creates a hidden temporary file next to the file it will
+// replace. Its name does not end in the original extension, so directory
+// scans for .yaml or .jsonl files skip it.
+func CreateTemp(dir, base string) (*os.File, error) {
+	tmp, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	return tmp, nil
+}
+
+// writeAndClose writes data, sets perm and flushes the file to disk.
+func writeAndClose(f *os.File, data []byte, perm os.FileMode) error {
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", f.Name(), err)
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to set mode of %s: %w", f.Name(), err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync %s: %w", f.Name(), err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.Name(), err)
+	}
+	return nil
+}
+
+// SyncDir flushes a directory so that files created or renamed in it
+// survive a crash. Not every platform can sync a directory; failures are
+// ignored.
+func SyncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
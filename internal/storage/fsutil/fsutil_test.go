@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package fsutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/storage/fsutil"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "node.yaml")
+
+	for _, content := range []string{"first\n", "second\n"} {
+		if err := fsutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("content = %q, want %q", data, content)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want 0644", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files left behind, got %v", entries)
+	}
+}
+
+func TestWriteFile_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "node.yaml")
+	if err := fsutil.WriteFile(path, []byte("x"), 0644); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestWriteFileExclusive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "v1.yaml")
+
+	if err := fsutil.WriteFileExclusive(path, []byte("frozen\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := fsutil.WriteFileExclusive(path, []byte("replaced\n"), 0644)
+	if !os.IsExist(err) {
+		t.Errorf("expected an existing file to be kept, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "frozen\n" {
+		t.Errorf("content = %q, want the first write", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files left behind, got %v", entries)
+	}
+}
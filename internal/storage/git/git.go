@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package git shells out to the local git binary for the few things deco
+// reads from the repository a project lives in.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Run runs a git command in dir and returns its standard output.
+func Run(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return out, nil
+}
+
+// Identity returns the committer identity configured for the repository
+// containing dir as "Name <email>", or only the part that is set. It
+// returns "" when git is unavailable or neither is configured.
+func Identity(dir string) string {
+	// One git call for both keys; deco records an identity per command
+	out, _ := Run(dir, "config", "--get-regexp", `^user\.(name|email)$`)
+	var name, email string
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "user.name":
+			name = strings.TrimSpace(value)
+		case "user.email":
+			email = strings.TrimSpace(value)
+		}
+	}
+	switch {
+	case name != "" && email != "":
+		return name + " <" + email + ">"
+	case name != "":
+		return name
+	}
+	return email
+}
+
+// Head returns the commit SHA checked out in the repository containing
+// dir, or "" outside a repository or before the first commit.
+func Head(dir string) string {
+	out, err := Run(dir, "rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// ResolveRevision returns the full commit SHA a revision names.
+func ResolveRevision(dir, rev string) (string, error) {
+	out, err := Run(dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown git revision %q", rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CommitTime returns the committer time of the commit a revision names. The
+// revision is never read as an option, even when it starts with "-".
+func CommitTime(dir, rev string) (time.Time, error) {
+	out, err := Run(dir, "log", "-1", "--format=%cI", "--end-of-options", rev, "--")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown git revision %q", rev)
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(out)))
+}
+
+// Ancestors returns the commits reachable from a revision, the revision's
+// own commit excluded.
+func Ancestors(dir, rev string) (map[string]bool, error) {
+	commit, err := ResolveRevision(dir, rev)
+	if err != nil {
+		return nil, err
+	}
+	out, err := Run(dir, "rev-list", commit)
+	if err != nil {
+		return nil, err
+	}
+	ancestors := make(map[string]bool)
+	for _, line := range strings.Fields(string(out)) {
+		if line != commit {
+			ancestors[line] = true
+		}
+	}
+	return ancestors, nil
+}
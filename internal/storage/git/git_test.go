@@ -0,0 +1,103 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package git_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/storage/git"
+)
+
+func TestIdentityAndHead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if git.Head(dir) != "" {
+		t.Error("expected no HEAD outside a repository")
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Alice Smith"},
+		{"config", "user.email", "alice@example.com"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if _, err := git.Run(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := git.Identity(dir); got != "Alice Smith <alice@example.com>" {
+		t.Errorf("Identity() = %q", got)
+	}
+	head := git.Head(dir)
+	if len(head) != 40 {
+		t.Fatalf("Head() = %q, want a full SHA", head)
+	}
+	if rev, err := git.ResolveRevision(dir, "HEAD"); err != nil || rev != head {
+		t.Errorf("ResolveRevision(HEAD) = %q, %v; want %s", rev, err, head)
+	}
+	if _, err := git.ResolveRevision(dir, "no-such-branch"); err == nil || !strings.Contains(err.Error(), "no-such-branch") {
+		t.Errorf("expected an unknown revision error, got %v", err)
+	}
+	if ct, err := git.CommitTime(dir, "HEAD"); err != nil || time.Since(ct) > time.Hour {
+		t.Errorf("CommitTime(HEAD) = %v, %v; want the commit just made", ct, err)
+	}
+
+	// A revision that looks like an option is not passed to git as one
+	out := filepath.Join(dir, "out.txt")
+	if _, err := git.CommitTime(dir, "--output="+out); err == nil {
+		t.Error("expected an option-like revision to be rejected")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("option-like revision was read as an option: %v", err)
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=alice", "-c", "user.email=alice@example.com", "commit", "-q", "--allow-empty", "-m", "first"},
+		{"-c", "user.name=alice", "-c", "user.email=alice@example.com", "commit", "-q", "--allow-empty", "-m", "second"},
+	} {
+		if _, err := git.Run(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, _ := git.ResolveRevision(dir, "HEAD~1")
+	head := git.Head(dir)
+
+	ancestors, err := git.Ancestors(dir, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ancestors) != 1 || !ancestors[first] || ancestors[head] {
+		t.Errorf("Ancestors(HEAD) = %v, want only %s", ancestors, first)
+	}
+	if ancestors, err := git.Ancestors(dir, "HEAD~1"); err != nil || len(ancestors) != 0 {
+		t.Errorf("expected the root commit to have no ancestors, got %v, %v", ancestors, err)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+// ErrChainBroken is returned by operations that re-seal the chain when the
+// existing chain does not verify, since sealing it again would hide the
+// change that broke it.
+var ErrChainBroken = errors.New("history hash chain is broken")
+
+// ChainBreak is the first link of the hash chain that does not hold.
+type ChainBreak struct {
+	File   string             // history file or closed segment holding the line
+	Line   int                // 1-based line in that file
+	Entry  *domain.AuditEntry // nil when the line is not a valid entry
+	Reason string
+}
+
+// ChainReport is the result of verifying the hash chain.
+type ChainReport struct {
+	Verified int         // chained entries checked before any break
+	Legacy   int         // entries written before the chain started, not covered
+	Break    *ChainBreak // nil when the chain is intact
+}
+
+// seal links an entry to the previous one and computes its EntryHash.
+// The entry is normalized through a JSON round trip first, so the hash
+// can be recomputed exactly from the line that is written.
+func seal(entry domain.AuditEntry, prevHash string) (domain.AuditEntry, error) {
+	entry.PrevHash = prevHash
+	entry.EntryHash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return entry, fmt.Errorf("failed to marshal entry: %w", err)
+	}
+	var normalized domain.AuditEntry
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return entry, fmt.Errorf("failed to normalize entry: %w", err)
+	}
+	hash, err := EntryHash(normalized)
+	if err != nil {
+		return entry, err
+	}
+	normalized.EntryHash = hash
+	return normalized, nil
+}
+
+// EntryHash returns the SHA-256 of an entry's JSON encoding without its
+// EntryHash. PrevHash is included, so each hash covers the chain before it.
+func EntryHash(entry domain.AuditEntry) (string, error) {
+	entry.EntryHash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// lastEntryHash returns the EntryHash of the last entry in the file. found
+// is false when the file is empty or missing; the hash is "" when the last
+// entry predates the chain.
+func lastEntryHash(path string) (found bool, hash string, err error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to stat history file: %w", err)
+	}
+	line, err := lastLine(file, info.Size())
+	if err != nil {
+		return false, "", fmt.Errorf("failed to read history file: %w", err)
+	}
+	if len(line) == 0 {
+		return false, "", nil
+	}
+	var entry domain.AuditEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return false, "", fmt.Errorf("history file ends with an invalid entry: %w", err)
+	}
+	return true, entry.EntryHash, nil
+}
+
+// lastLine reads the last non-empty line of a file from the end.
+func lastLine(file *os.File, size int64) ([]byte, error) {
+	const chunk = 4096
+	var buf []byte
+	for off := size; off > 0; {
+		n := int64(chunk)
+		if off < n {
+			n = off
+		}
+		off -= n
+		part := make([]byte, n)
+		if _, err := file.ReadAt(part, off); err != nil {
+			return nil, err
+		}
+		buf = append(part, buf...)
+		trimmed := bytes.TrimRight(buf, "\r\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+		if off == 0 {
+			return trimmed, nil
+		}
+	}
+	return nil, nil
+}
+
+// Verify checks the hash chain in file order, across closed segments and
+// the active file, and reports the first entry that was modified, deleted,
+// inserted or moved. Entries written before the chain was introduced are
+// counted as legacy; once the chain starts, every entry must carry hashes.
+func (r *JSONLRepository) Verify() (ChainReport, error) {
+	var report ChainReport
+	paths, err := r.historyFiles()
+	if err != nil {
+		return report, err
+	}
+
+	prev := ""
+	started := false
+	for _, path := range paths {
+		err := eachLine(path, func(lineNum int, line []byte) error {
+			fail := func(entry *domain.AuditEntry, reason string) error {
+				report.Break = &ChainBreak{File: path, Line: lineNum, Entry: entry, Reason: reason}
+				return errChainBroken
+			}
+
+			var entry domain.AuditEntry
+			if err := json.Unmarshal(line, &entry); err != nil {
+				return fail(nil, "line is not a valid history entry")
+			}
+
+			if entry.EntryHash == "" {
+				if started {
+					return fail(&entry, "entry has no hash: it was added or rewritten outside deco")
+				}
+				report.Legacy++
+				return nil
+			}
+
+			hash, err := EntryHash(entry)
+			if err != nil {
+				return err
+			}
+			if hash != entry.EntryHash {
+				return fail(&entry, "entry was modified: its content does not match entry_hash")
+			}
+			if entry.PrevHash != prev {
+				if !started {
+					return fail(&entry, "first chained entry links to an entry that is missing: earlier entries were deleted")
+				}
+				return fail(&entry, "prev_hash does not match the entry before it: entries were deleted, inserted or reordered")
+			}
+
+			started = true
+			prev = entry.EntryHash
+			report.Verified++
+			return nil
+		})
+		if err == errChainBroken {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// errChainBroken stops the line walk once a break is recorded.
+var errChainBroken = errors.New("history chain broken")
@@ -0,0 +1,146 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/history"
+)
+
+// writeChain appends four entries and returns the history path and lines.
+func writeChain(t *testing.T, legacy string) (string, []string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if legacy != "" {
+		if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := history.NewYAMLRepository(path)
+	base := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	for i, op := range []string{"create", "sync", "submit", "approve"} {
+		entry := domain.AuditEntry{
+			Timestamp: base.Add(time.Duration(i) * time.Hour),
+			NodeID:    "systems/combat",
+			Operation: op,
+			User:      "alice",
+			Before:    map[string]interface{}{"version": i, "ratio": 0.5},
+		}
+		if err := repo.Append(entry); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func rewrite(t *testing.T, path string, lines []string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerify_IntactChain(t *testing.T) {
+	legacy := `{"timestamp":"2026-04-01T00:00:00Z","node_id":"old","operation":"baseline","user":"bob"}` + "\n"
+	path, lines := writeChain(t, legacy)
+
+	entries, err := history.NewYAMLRepository(path).Query(history.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[1].PrevHash != "" || entries[1].EntryHash == "" || entries[2].PrevHash != entries[1].EntryHash {
+		t.Errorf("expected the chain to start after the legacy entry, got %+v", entries[1:3])
+	}
+
+	report, err := history.NewYAMLRepository(path).Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if report.Break != nil || report.Verified != 4 || report.Legacy != 1 {
+		t.Errorf("unexpected report %+v (break %+v)", report, report.Break)
+	}
+	if len(lines) != 5 {
+		t.Errorf("expected 5 lines, got %d", len(lines))
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func([]string) []string
+		line   int
+		reason string
+	}{
+		{"edited", func(l []string) []string {
+			l[2] = strings.Replace(l[2], `"user":"alice"`, `"user":"mallory"`, 1)
+			return l
+		}, 3, "modified"},
+		{"deleted", func(l []string) []string { return append(l[:1], l[2:]...) }, 2, "deleted, inserted or reordered"},
+		{"reordered", func(l []string) []string {
+			l[1], l[2] = l[2], l[1]
+			return l
+		}, 2, "deleted, inserted or reordered"},
+		{"first deleted", func(l []string) []string { return l[1:] }, 1, "earlier entries were deleted"},
+		{"unhashed insert", func(l []string) []string {
+			return append(l[:2], append([]string{`{"timestamp":"2026-05-01T10:30:00Z","node_id":"x","operation":"approve","user":"mallory"}`}, l[2:]...)...)
+		}, 3, "no hash"},
+		{"garbage", func(l []string) []string {
+			l[3] = "not json"
+			return l
+		}, 4, "not a valid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, lines := writeChain(t, "")
+			rewrite(t, path, tt.tamper(lines))
+
+			report, err := history.NewYAMLRepository(path).Verify()
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if report.Break == nil {
+				t.Fatal("expected a broken chain")
+			}
+			if report.Break.Line != tt.line || !strings.Contains(report.Break.Reason, tt.reason) {
+				t.Errorf("got line %d %q, want line %d containing %q", report.Break.Line, report.Break.Reason, tt.line, tt.reason)
+			}
+		})
+	}
+}
+
+func TestAppend_ContinuesChainAcrossInstances(t *testing.T) {
+	path, _ := writeChain(t, "")
+	// A new repository instance picks up the last hash from the file
+	if err := history.NewYAMLRepository(path).Append(domain.AuditEntry{
+		Timestamp: time.Now(), NodeID: "systems/magic", Operation: "create", User: "bob",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := history.NewYAMLRepository(path).Verify()
+	if err != nil || report.Break != nil || report.Verified != 5 {
+		t.Errorf("expected 5 verified entries, got %+v %v", report, err)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/fsutil"
+)
+
+// CompactOptions controls Compact.
+type CompactOptions struct {
+	// Before moves entries older than this into closed segments; the rest
+	// stay in the active file. Zero compacts every entry.
+	Before time.Time
+
+	// DryRun reports what would change without writing anything.
+	DryRun bool
+
+	// Force compacts a log whose hash chain is broken, sealing the entries
+	// as they stand. Without it Compact returns ErrChainBroken.
+	Force bool
+}
+
+// CompactResult summarizes a compaction.
+type CompactResult struct {
+	Entries  int    // entries kept
+	Dropped  int    // redundant entries removed
+	Segments int    // closed segments written
+	Active   int    // entries left in the active file
+	OldHead  string // entry_hash of the last entry before compaction
+	NewHead  string // entry_hash of the last entry after compaction
+}
+
+// Compact rewrites the whole log: entries older than opts.Before are packed
+// into compressed segments of the configured size, and entries that repeat
+// the previous entry of their node without recording a change are dropped.
+// Content hashes and snapshot references are kept on every remaining
+// entry, so the latest hash per node is unchanged. The hash chain is sealed
+// again from the first entry, which also brings legacy entries into it;
+// the new head hash is returned so trusted copies can be updated. A chain
+// that does not verify is refused unless opts.Force is set, so compaction
+// cannot launder an edited entry.
+func (r *JSONLRepository) Compact(opts CompactOptions) (CompactResult, error) {
+	var result CompactResult
+	if !opts.DryRun {
+		unlock, err := r.lockWriters()
+		if err != nil {
+			return result, err
+		}
+		defer unlock()
+	}
+
+	if !opts.Force {
+		report, err := r.Verify()
+		if err != nil {
+			return result, err
+		}
+		if report.Break != nil {
+			b := report.Break
+			return result, fmt.Errorf("%w at %s line %d: %s", ErrChainBroken, b.File, b.Line, b.Reason)
+		}
+	}
+
+	paths, err := r.historyFiles()
+	if err != nil {
+		return result, err
+	}
+	var entries []domain.AuditEntry
+	for _, path := range paths {
+		fileEntries, err := readEntries(path)
+		if err != nil {
+			return result, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	if len(entries) > 0 {
+		result.OldHead = entries[len(entries)-1].EntryHash
+	}
+
+	// Drop redundant entries and re-seal the chain
+	var kept []domain.AuditEntry
+	lastByNode := make(map[string]domain.AuditEntry)
+	prevHash := ""
+	for _, e := range entries {
+		older := opts.Before.IsZero() || e.Timestamp.Before(opts.Before)
+		if last, ok := lastByNode[e.NodeID]; ok && older && redundant(last, e) {
+			result.Dropped++
+			continue
+		}
+		sealed, err := seal(e, prevHash)
+		if err != nil {
+			return result, err
+		}
+		prevHash = sealed.EntryHash
+		lastByNode[e.NodeID] = sealed
+		kept = append(kept, sealed)
+	}
+	result.Entries = len(kept)
+	result.NewHead = prevHash
+
+	// Split into closed segments and the active tail
+	cut := len(kept)
+	if !opts.Before.IsZero() {
+		for i, e := range kept {
+			if !e.Timestamp.Before(opts.Before) {
+				cut = i
+				break
+			}
+		}
+	}
+	active := kept[cut:]
+	var segments [][]domain.AuditEntry
+	var current []domain.AuditEntry
+	var size int64
+	for _, e := range kept[:cut] {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return result, fmt.Errorf("failed to marshal entry: %w", err)
+		}
+		current = append(current, e)
+		size += int64(len(data)) + 1
+		if r.segmentSize > 0 && size >= r.segmentSize {
+			segments = append(segments, current)
+			current, size = nil, 0
+		}
+	}
+	if len(current) > 0 {
+		segments = append(segments, current)
+	}
+	result.Segments = len(segments)
+	result.Active = len(active)
+
+	if opts.DryRun {
+		return result, nil
+	}
+	return result, r.replaceLog(segments, active)
+}
+
+// redundant reports whether e records nothing beyond the node's previous
+// entry: same content hash and snapshot, and no before/after values.
+func redundant(last, e domain.AuditEntry) bool {
+	return e.ContentHash != "" &&
+		e.ContentHash == last.ContentHash &&
+		(e.Snapshot == "" || e.Snapshot == last.Snapshot) &&
+		len(e.Before) == 0 && len(e.After) == 0
+}
+
+// replaceLog writes a new segment directory and active file next to the
+// current ones and swaps them in.
+func (r *JSONLRepository) replaceLog(segments [][]domain.AuditEntry, active []domain.AuditEntry) error {
+	dir := segmentDir(r.historyFile())
+	tmpDir := dir + ".compact"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return fmt.Errorf("failed to clear compaction directory: %w", err)
+	}
+	if len(segments) > 0 {
+		if err := os.MkdirAll(tmpDir, 0755); err != nil {
+			return fmt.Errorf("failed to create compaction directory: %w", err)
+		}
+		idx := &segmentIndex{Version: indexVersion, Latest: map[string]latestHash{}}
+		for i, seg := range segments {
+			name := segmentName(i + 1)
+			if err := writeSegment(filepath.Join(tmpDir, name), seg); err != nil {
+				os.RemoveAll(tmpDir)
+				return err
+			}
+			idx.add(name, seg)
+		}
+		if err := saveIndex(tmpDir, idx); err != nil {
+			os.RemoveAll(tmpDir)
+			return err
+		}
+	}
+
+	tmpFile := r.historyFile() + ".compact"
+	var data []byte
+	for _, e := range active {
+		line, err := json.Marshal(e)
+		if err != nil {
+			os.RemoveAll(tmpDir)
+			return fmt.Errorf("failed to marshal entry: %w", err)
+		}
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+	if err := fsutil.WriteFile(tmpFile, data, 0644); err != nil {
+		os.RemoveAll(tmpDir)
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	oldDir := dir + ".old"
+	os.RemoveAll(oldDir)
+	if _, err := os.Stat(dir); err == nil {
+		if err := os.Rename(dir, oldDir); err != nil {
+			return fmt.Errorf("failed to replace history segments: %w", err)
+		}
+	}
+	if len(segments) > 0 {
+		if err := os.Rename(tmpDir, dir); err != nil {
+			return fmt.Errorf("failed to replace history segments: %w", err)
+		}
+	}
+	if err := os.Rename(tmpFile, r.historyFile()); err != nil {
+		return fmt.Errorf("failed to replace history file: %w", err)
+	}
+	fsutil.SyncDir(filepath.Dir(r.historyFile()))
+	return os.RemoveAll(oldDir)
+}
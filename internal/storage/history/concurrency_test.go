@@ -0,0 +1,142 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/history"
+	"github.com/Toernblom/deco/internal/storage/lock"
+)
+
+// appendHelperEnv makes the test binary append entries as a separate deco
+// process instead of running the tests.
+const appendHelperEnv = "DECO_HISTORY_APPEND_HELPER"
+
+func TestMain(m *testing.M) {
+	if path := os.Getenv(appendHelperEnv); path != "" {
+		if err := appendEntries(path, 25); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// appendEntries appends n entries tagged with this process's ID, with a
+// segment size small enough to rotate while other processes append.
+func appendEntries(path string, n int) error {
+	repo := history.NewYAMLRepository(path)
+	repo.SetSegmentSize(4000)
+	for i := 0; i < n; i++ {
+		if err := repo.Append(domain.AuditEntry{
+			Timestamp: time.Now(),
+			NodeID:    "systems/combat",
+			Operation: "sync",
+			User:      strconv.Itoa(os.Getpid()),
+			After:     map[string]interface{}{"version": i + 1},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestAppend_ConcurrentProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".deco", "history.jsonl")
+	const processes = 4
+
+	var wg sync.WaitGroup
+	errs := make(chan error, processes)
+	for i := 0; i < processes; i++ {
+		cmd := exec.Command(os.Args[0])
+		cmd.Env = append(os.Environ(), appendHelperEnv+"="+path)
+		cmd.Stderr = os.Stderr
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- cmd.Run()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("append process failed: %v", err)
+		}
+	}
+
+	repo := history.NewYAMLRepository(path)
+	entries, err := repo.Query(history.Filter{})
+	if err != nil {
+		t.Fatalf("history unreadable after concurrent appends: %v", err)
+	}
+	if len(entries) != processes*25 {
+		t.Errorf("expected %d entries, got %d", processes*25, len(entries))
+	}
+	perProcess := make(map[string]int)
+	for _, e := range entries {
+		perProcess[e.User]++
+	}
+	for user, count := range perProcess {
+		if count != 25 {
+			t.Errorf("process %s recorded %d entries, want 25", user, count)
+		}
+	}
+
+	report, err := repo.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Break != nil {
+		t.Errorf("hash chain forked by concurrent appends: %+v", report.Break)
+	}
+}
+
+func TestAppend_LockTimeout(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".deco")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// The parent of the test process is alive and does not hold the lock
+	host, _ := os.Hostname()
+	data, _ := json.Marshal(lock.Owner{PID: os.Getppid(), Host: host, Command: "deco sync", Acquired: time.Now()})
+	if err := os.WriteFile(filepath.Join(dir, lock.FileName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := history.NewYAMLRepository(filepath.Join(dir, "history.jsonl"))
+	repo.SetLockTimeout(150 * time.Millisecond)
+	start := time.Now()
+	err := repo.Append(domain.AuditEntry{Timestamp: time.Now(), NodeID: "systems/combat", Operation: "sync"})
+	if !errors.Is(err, lock.ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("append waited %v, ignoring the 150ms lock timeout", elapsed)
+	}
+}
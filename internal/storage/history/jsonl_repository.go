@@ -16,22 +16,60 @@
 package history
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/fsutil"
+	"github.com/Toernblom/deco/internal/storage/git"
+	"github.com/Toernblom/deco/internal/storage/lock"
 )
 
 // JSONLRepository implements Repository using JSONL (JSON Lines) format.
 // This is an append-only log stored at the configured history path.
+// When the file grows past the segment size it is closed into a gzip
+// segment under the segment directory (.deco/history/), with a sidecar
+// index that lets queries skip segments without matching entries.
+//
+// Writes hold the lock on the directory of the history file (.deco/ by
+// default), so concurrent deco processes never interleave or fork the
+// hash chain, and appends are flushed to disk before they return.
 type JSONLRepository struct {
 	historyPath string
-	mu          sync.Mutex // Protects concurrent writes
+	segmentSize int64
+	lockTimeout time.Duration
+
+	// head is the commit recorded on appended entries, resolved from git
+	// on the first append so a command writing many entries asks once.
+	head      string
+	headKnown bool
+}
+
+// writeMu serializes writers within this process; the directory lock is
+// shared by every lock holder in the process.
+var writeMu sync.Mutex
+
+// lockWriters takes the history write lock and returns its release.
+func (r *JSONLRepository) lockWriters() (func(), error) {
+	writeMu.Lock()
+	l, err := lock.Acquire(filepath.Dir(r.historyFile()), lock.Options{
+		Timeout: r.lockTimeout,
+		Command: "deco history write",
+	})
+	if err != nil {
+		writeMu.Unlock()
+		return nil, err
+	}
+	return func() {
+		l.Release()
+		writeMu.Unlock()
+	}, nil
 }
 
 // NewYAMLRepository creates a new JSONL-based history repository.
@@ -42,9 +80,22 @@ type JSONLRepository struct {
 func NewYAMLRepository(historyPath string) *JSONLRepository {
 	return &JSONLRepository{
 		historyPath: historyPath,
+		segmentSize: DefaultSegmentSize,
 	}
 }
 
+// SetSegmentSize sets the size in bytes at which the active history file is
+// closed into a compressed segment. Zero or less disables rotation.
+func (r *JSONLRepository) SetSegmentSize(size int64) {
+	r.segmentSize = size
+}
+
+// SetLockTimeout sets how long a write waits for another process holding
+// the lock. Zero means lock.DefaultTimeout.
+func (r *JSONLRepository) SetLockTimeout(timeout time.Duration) {
+	r.lockTimeout = timeout
+}
+
 // historyFile returns the path to the history log file
 func (r *JSONLRepository) historyFile() string {
 	return r.historyPath
@@ -53,9 +104,6 @@ func (r *JSONLRepository) historyFile() string {
 // Append adds a new entry to the audit log.
 // Entries are immutable once appended.
 func (r *JSONLRepository) Append(entry domain.AuditEntry) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	// Ensure parent directory exists
 	parentDir := filepath.Dir(r.historyFile())
 	err := os.MkdirAll(parentDir, 0755)
@@ -63,6 +111,34 @@ func (r *JSONLRepository) Append(entry domain.AuditEntry) error {
 		return fmt.Errorf("failed to create history directory: %w", err)
 	}
 
+	unlock, err := r.lockWriters()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Attribute the entry to the commit the project is at
+	if entry.Commit == "" {
+		if !r.headKnown {
+			r.head, r.headKnown = git.Head(parentDir), true
+		}
+		entry.Commit = r.head
+	}
+
+	if err := r.finishRotation(); err != nil {
+		return err
+	}
+
+	// Link the entry to the last one in the hash chain
+	prevHash, err := r.chainHead()
+	if err != nil {
+		return err
+	}
+	entry, err = seal(entry, prevHash)
+	if err != nil {
+		return err
+	}
+
 	// Open file in append mode (create if doesn't exist)
 	file, err := os.OpenFile(r.historyFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -76,63 +152,101 @@ func (r *JSONLRepository) Append(entry domain.AuditEntry) error {
 		return fmt.Errorf("failed to marshal entry: %w", err)
 	}
 
-	// Append JSON line (with newline)
+	// Append JSON line (with newline) in a single write, and flush it so
+	// a crash cannot lose an entry the caller was told is recorded
 	_, err = file.Write(append(data, '\n'))
 	if err != nil {
 		return fmt.Errorf("failed to write entry: %w", err)
 	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync history file: %w", err)
+	}
+
+	// Close the active file into a segment once it is large enough
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat history file: %w", err)
+	}
+	if info.Size() == int64(len(data)+1) {
+		// The file was just created
+		fsutil.SyncDir(parentDir)
+	}
+	if r.segmentSize > 0 && info.Size() >= r.segmentSize {
+		file.Close()
+		return r.rotate()
+	}
 
 	return nil
 }
 
+// chainHead returns the EntryHash the next entry links to: the last entry of
+// the active file, or of the last closed segment when the file is empty.
+func (r *JSONLRepository) chainHead() (string, error) {
+	found, hash, err := lastEntryHash(r.historyFile())
+	if err != nil || found {
+		return hash, err
+	}
+	idx, err := r.loadIndex()
+	if err != nil {
+		return "", err
+	}
+	return idx.head(), nil
+}
+
 // Query retrieves audit entries matching the filter criteria.
 // Returns entries in chronological order (oldest first).
+// Closed segments whose index shows no entries for the filtered node or
+// time range are not read.
 func (r *JSONLRepository) Query(filter Filter) ([]domain.AuditEntry, error) {
-	historyPath := r.historyFile()
-
-	// Check if file exists
-	if _, err := os.Stat(historyPath); os.IsNotExist(err) {
-		// No history file = empty results
-		return []domain.AuditEntry{}, nil
+	idx, err := r.loadIndex()
+	if err != nil {
+		return nil, err
 	}
 
-	// Open file for reading
-	file, err := os.Open(historyPath)
+	var paths []string
+	dir := segmentDir(r.historyFile())
+	for _, seg := range idx.Segments {
+		if filter.NodeID != "" && !seg.hasNode(filter.NodeID) {
+			continue
+		}
+		if !seg.overlaps(filter) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, seg.File))
+	}
+	done, err := r.rotated(idx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open history file: %w", err)
+		return nil, err
+	}
+	if !done {
+		paths = append(paths, r.historyFile())
 	}
-	defer file.Close()
 
-	var entries []domain.AuditEntry
-	scanner := bufio.NewScanner(file)
+	entries := []domain.AuditEntry{}
+	for _, path := range paths {
+		err := eachLine(path, func(_ int, line []byte) error {
+			var entry domain.AuditEntry
+			if err := json.Unmarshal(line, &entry); err != nil {
+				return fmt.Errorf("failed to unmarshal entry: %w", err)
+			}
 
-	// Read each line
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue // Skip empty lines
+			// Apply filters
+			if matchesFilter(entry, filter) {
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+		if os.IsNotExist(err) {
+			// No active file = nothing appended since the last rotation
+			continue
 		}
-
-		var entry domain.AuditEntry
-		err := json.Unmarshal(line, &entry)
 		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
-		}
-
-		// Apply filters
-		if !matchesFilter(entry, filter) {
-			continue
+			return nil, err
 		}
-
-		entries = append(entries, entry)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading history file: %w", err)
 	}
 
 	// Sort by timestamp (oldest first)
-	sort.Slice(entries, func(i, j int) bool {
+	sort.SliceStable(entries, func(i, j int) bool {
 		return entries[i].Timestamp.Before(entries[j].Timestamp)
 	})
 
@@ -145,73 +259,49 @@ func (r *JSONLRepository) Query(filter Filter) ([]domain.AuditEntry, error) {
 }
 
 // QueryLatestHashes returns a map of nodeID -> latest content hash for all nodes.
-// This reads the history file once, providing O(history) complexity instead of
-// O(nodes × history) when querying each node individually.
+// Closed segments are covered by the sidecar index, so only the active file
+// is read, instead of querying each node individually.
 func (r *JSONLRepository) QueryLatestHashes() (map[string]string, error) {
-	historyPath := r.historyFile()
-
-	// Check if file exists
-	if _, err := os.Stat(historyPath); os.IsNotExist(err) {
-		return map[string]string{}, nil
-	}
-
-	file, err := os.Open(historyPath)
+	idx, err := r.loadIndex()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open history file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
 
-	// Track latest hash and timestamp per node
-	type nodeState struct {
-		hash      string
-		timestamp int64
+	latest := make(map[string]latestHash, len(idx.Latest))
+	for id, h := range idx.Latest {
+		latest[id] = h
 	}
-	latestByNode := make(map[string]*nodeState)
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
-
+	err = eachLine(r.historyFile(), func(_ int, line []byte) error {
 		var entry domain.AuditEntry
 		if err := json.Unmarshal(line, &entry); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+			return fmt.Errorf("failed to unmarshal entry: %w", err)
 		}
-
-		// Skip entries without content hash
-		if entry.ContentHash == "" {
-			continue
-		}
-
-		ts := entry.Timestamp.Unix()
-		if existing, ok := latestByNode[entry.NodeID]; ok {
-			if ts > existing.timestamp {
-				existing.hash = entry.ContentHash
-				existing.timestamp = ts
-			}
-		} else {
-			latestByNode[entry.NodeID] = &nodeState{
-				hash:      entry.ContentHash,
-				timestamp: ts,
-			}
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading history file: %w", err)
+		latest = trackLatest(latest, entry)
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
 	}
 
 	// Convert to simple map
-	result := make(map[string]string, len(latestByNode))
-	for nodeID, state := range latestByNode {
-		result[nodeID] = state.hash
+	result := make(map[string]string, len(latest))
+	for nodeID, state := range latest {
+		result[nodeID] = state.Hash
 	}
 
 	return result, nil
 }
 
+// matchesUser reports whether an entry's user is the one asked for. Users
+// recorded from git as "Name <email>" also match by name or email alone.
+func matchesUser(recorded, user string) bool {
+	if recorded == user {
+		return true
+	}
+	name, email, ok := strings.Cut(recorded, " <")
+	return ok && (name == user || strings.TrimSuffix(email, ">") == user)
+}
+
 // matchesFilter checks if an entry matches the filter criteria
 func matchesFilter(entry domain.AuditEntry, filter Filter) bool {
 	// Filter by NodeID
@@ -225,7 +315,7 @@ func matchesFilter(entry domain.AuditEntry, filter Filter) bool {
 	}
 
 	// Filter by User
-	if filter.User != "" && entry.User != filter.User {
+	if filter.User != "" && !matchesUser(entry.User, filter.User) {
 		return false
 	}
 
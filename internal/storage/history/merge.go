@@ -0,0 +1,181 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Toernblom/deco/internal/domain"
+)
+
+// MergeResult summarizes a merge of two history files.
+type MergeResult struct {
+	Entries int // entries in the merged file
+	Ours    int // entries only our side added
+	Theirs  int // entries only their side added
+	Dropped int // ancestor entries one side no longer has
+}
+
+// Merge combines two versions of the active history file that diverged
+// from ancestor, for the git merge driver. Entries are matched by their
+// content without the chain hashes, so the same entry sealed on both
+// branches counts once. The result holds every entry either side has,
+// except ancestor entries one side removed by compaction or rotation into
+// a segment, sorted by timestamp. The chain is re-sealed from the link our
+// first entry continued from. Both sides must verify first: a side with a
+// broken chain fails with ErrChainBroken, since re-sealing it would hide
+// the edit, and git leaves the file as a conflict.
+func Merge(ancestor, ours, theirs []byte) ([]byte, MergeResult, error) {
+	var sides [3][]domain.AuditEntry
+	for i, data := range [][]byte{ancestor, ours, theirs} {
+		name := []string{"ancestor", "ours", "theirs"}[i]
+		entries, err := parseEntries(data)
+		if err != nil {
+			return nil, MergeResult{}, fmt.Errorf("%s: %w", name, err)
+		}
+		if i > 0 {
+			if err := checkLinks(entries); err != nil {
+				return nil, MergeResult{}, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		sides[i] = entries
+	}
+	base, o, t := keySet(sides[0]), keySet(sides[1]), keySet(sides[2])
+
+	var result MergeResult
+	var merged []domain.AuditEntry
+	seen := make(map[string]bool)
+	for side, entries := range sides[1:] {
+		other := t
+		if side == 1 {
+			other = o
+		}
+		for _, e := range entries {
+			k := entryKey(e)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			switch {
+			case base[k] && !other[k]:
+				result.Dropped++
+				continue
+			case !base[k] && !other[k] && side == 0:
+				result.Ours++
+			case !base[k] && !other[k]:
+				result.Theirs++
+			}
+			merged = append(merged, e)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Timestamp.Before(merged[j].Timestamp) })
+
+	// Continue the chain from where our file started
+	var prev string
+	for _, entries := range sides[1:] {
+		if len(entries) > 0 {
+			prev = entries[0].PrevHash
+			break
+		}
+	}
+	var out bytes.Buffer
+	for _, e := range merged {
+		sealed, err := seal(e, prev)
+		if err != nil {
+			return nil, MergeResult{}, err
+		}
+		data, err := json.Marshal(sealed)
+		if err != nil {
+			return nil, MergeResult{}, fmt.Errorf("failed to marshal entry: %w", err)
+		}
+		out.Write(append(data, '\n'))
+		prev = sealed.EntryHash
+	}
+	result.Entries = len(merged)
+	return out.Bytes(), result, nil
+}
+
+// checkLinks verifies the hash chain within one side of a merge. The first
+// chained entry may continue from a closed segment, so its prev_hash is
+// taken as given; every entry after it must carry hashes that match its
+// content and link to the entry before it.
+func checkLinks(entries []domain.AuditEntry) error {
+	prev := ""
+	started := false
+	for i, e := range entries {
+		fail := func(reason string) error {
+			return fmt.Errorf("%w at entry %d (%s %s): %s", ErrChainBroken, i+1, e.Operation, e.NodeID, reason)
+		}
+		if e.EntryHash == "" {
+			if started {
+				return fail("entry has no hash")
+			}
+			continue
+		}
+		hash, err := EntryHash(e)
+		if err != nil {
+			return err
+		}
+		if hash != e.EntryHash {
+			return fail("entry was modified")
+		}
+		if started && e.PrevHash != prev {
+			return fail("prev_hash does not match the entry before it")
+		}
+		started = true
+		prev = e.EntryHash
+	}
+	return nil
+}
+
+func parseEntries(data []byte) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e domain.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d is not a valid entry: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// entryKey identifies an entry by its content, leaving out the chain
+// hashes that differ when the same entry is sealed on another branch.
+func entryKey(e domain.AuditEntry) string {
+	e.PrevHash, e.EntryHash = "", ""
+	data, _ := json.Marshal(e)
+	return string(data)
+}
+
+func keySet(entries []domain.AuditEntry) map[string]bool {
+	keys := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		keys[entryKey(e)] = true
+	}
+	return keys
+}
@@ -0,0 +1,167 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/history"
+)
+
+// branchHistory copies a history file and appends entries to the copy.
+func branchHistory(t *testing.T, from string, entries ...domain.AuditEntry) []byte {
+	t.Helper()
+	data, err := os.ReadFile(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := history.NewYAMLRepository(path)
+	for _, e := range entries {
+		if err := repo.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMerge(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	entry := func(d int, id, user string) domain.AuditEntry {
+		return domain.AuditEntry{Timestamp: day(d), NodeID: id, Operation: "sync", User: user}
+	}
+
+	basePath := filepath.Join(t.TempDir(), "history.jsonl")
+	baseRepo := history.NewYAMLRepository(basePath)
+	for _, e := range []domain.AuditEntry{entry(1, "systems/combat", "alice"), entry(2, "systems/magic", "alice")} {
+		if err := baseRepo.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ancestor, err := os.ReadFile(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both branches record the same baseline, then diverge
+	shared := entry(3, "systems/lore", "carol")
+	ours := branchHistory(t, basePath, shared, entry(5, "systems/combat", "alice"))
+	theirs := branchHistory(t, basePath, entry(4, "systems/magic", "bob"), shared, entry(6, "systems/magic", "bob"))
+
+	merged, result, err := history.Merge(ancestor, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Entries != 6 || result.Ours != 1 || result.Theirs != 2 || result.Dropped != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(path, merged, 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := history.NewYAMLRepository(path)
+	entries, err := repo.Query(history.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range entries {
+		if !e.Timestamp.Equal(day(i + 1)) {
+			t.Errorf("entry %d at %v, want day %d", i, e.Timestamp, i+1)
+		}
+	}
+	report, err := repo.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Break != nil || report.Verified != 6 {
+		t.Errorf("expected a sealed chain of 6, got %+v (break %+v)", report, report.Break)
+	}
+
+	// Merging is symmetric in content
+	reverse, _, err := history.Merge(ancestor, theirs, ours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(reverse) != string(merged) {
+		t.Error("expected the same merged log with sides swapped")
+	}
+
+	// An entry our side rotated away is not brought back by theirs
+	rotated, result, err := history.Merge(ancestor, branchHistory(t, basePath)[len(ancestor):], theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Dropped != 2 || result.Entries != 3 {
+		t.Errorf("expected the ancestor entries dropped, got %+v\n%s", result, rotated)
+	}
+
+	if _, _, err := history.Merge(ancestor, []byte("<<<<<<< ours\n"), theirs); err == nil {
+		t.Error("expected an error for a file that is not history")
+	}
+}
+
+func TestMerge_RefusesBrokenSide(t *testing.T) {
+	basePath, lines := writeChain(t, "")
+	ancestor, err := os.ReadFile(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ours := branchHistory(t, basePath, domain.AuditEntry{
+		Timestamp: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), NodeID: "systems/magic", Operation: "sync", User: "bob",
+	})
+
+	// Their branch edited an entry it shares with the ancestor
+	edited := append([]string(nil), lines...)
+	edited[1] = strings.Replace(edited[1], `"user":"alice"`, `"user":"mallory"`, 1)
+	theirs := []byte(strings.Join(edited, "\n") + "\n")
+
+	for _, tt := range []struct {
+		name        string
+		ours, other []byte
+		side        string
+	}{
+		{"theirs", ours, theirs, "theirs: "},
+		{"ours", theirs, ours, "ours: "},
+	} {
+		merged, _, err := history.Merge(ancestor, tt.ours, tt.other)
+		if !errors.Is(err, history.ErrChainBroken) || !strings.HasPrefix(err.Error(), tt.side) || !strings.Contains(err.Error(), "entry 2") {
+			t.Errorf("%s: expected ErrChainBroken at entry 2, got %v", tt.name, err)
+		}
+		if merged != nil {
+			t.Errorf("%s: expected no merged output", tt.name)
+		}
+	}
+
+	// Entries removed from the middle of a side break its links too
+	dropped := append(append([]string(nil), lines[:1]...), lines[2:]...)
+	if _, _, err := history.Merge(ancestor, ours, []byte(strings.Join(dropped, "\n")+"\n")); !errors.Is(err, history.ErrChainBroken) {
+		t.Errorf("expected a deleted entry to be refused, got %v", err)
+	}
+}
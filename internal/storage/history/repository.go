@@ -25,7 +25,8 @@ type Filter struct {
 	// Operation filters by operation type (e.g., "create", "update").
 	Operation string
 
-	// User filters by user who made the change.
+	// User filters by user who made the change. For users recorded from
+	// git as "Name <email>", the name or the email alone also match.
 	User string
 
 	// Since filters entries after this timestamp (Unix seconds).
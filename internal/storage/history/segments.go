@@ -0,0 +1,434 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/fsutil"
+)
+
+// DefaultSegmentSize is the size in bytes at which the active history file
+// is closed into a compressed segment.
+const DefaultSegmentSize int64 = 4 << 20
+
+// segmentExt is the file extension of closed segments.
+const segmentExt = ".jsonl.gz"
+
+// indexFile is the sidecar index in the segment directory. It is derived
+// from the segments and rebuilt whenever it is missing or out of date.
+const indexFile = "index.json"
+
+const indexVersion = 1
+
+// segmentInfo describes one closed segment in the sidecar index.
+type segmentInfo struct {
+	File     string    `json:"file"`
+	Entries  int       `json:"entries"`
+	From     time.Time `json:"from"`                // earliest entry timestamp
+	To       time.Time `json:"to"`                  // latest entry timestamp
+	Nodes    []string  `json:"nodes"`               // sorted IDs of nodes with entries in the segment
+	LastHash string    `json:"last_hash,omitempty"` // entry_hash of the segment's last line
+}
+
+// hasNode reports whether the segment holds entries for a node.
+func (s segmentInfo) hasNode(id string) bool {
+	i := sort.SearchStrings(s.Nodes, id)
+	return i < len(s.Nodes) && s.Nodes[i] == id
+}
+
+// overlaps reports whether the segment can hold entries in the filter's
+// time range.
+func (s segmentInfo) overlaps(filter Filter) bool {
+	if filter.Since > 0 && s.To.Unix() < filter.Since {
+		return false
+	}
+	if filter.Until > 0 && s.From.Unix() > filter.Until {
+		return false
+	}
+	return true
+}
+
+// latestHash is the newest content hash recorded for a node.
+type latestHash struct {
+	Hash string    `json:"hash"`
+	Time time.Time `json:"time"`
+}
+
+// segmentIndex is the sidecar index over all closed segments.
+type segmentIndex struct {
+	Version  int                   `json:"version"`
+	Segments []segmentInfo         `json:"segments"`
+	Latest   map[string]latestHash `json:"latest"` // per node, across closed segments
+}
+
+// add records a closed segment and its entries in the index.
+func (idx *segmentIndex) add(file string, entries []domain.AuditEntry) {
+	info := segmentInfo{File: file, Entries: len(entries)}
+	nodes := make(map[string]bool)
+	for i, e := range entries {
+		if i == 0 || e.Timestamp.Before(info.From) {
+			info.From = e.Timestamp
+		}
+		if i == 0 || e.Timestamp.After(info.To) {
+			info.To = e.Timestamp
+		}
+		nodes[e.NodeID] = true
+		idx.Latest = trackLatest(idx.Latest, e)
+	}
+	for id := range nodes {
+		info.Nodes = append(info.Nodes, id)
+	}
+	sort.Strings(info.Nodes)
+	if len(entries) > 0 {
+		info.LastHash = entries[len(entries)-1].EntryHash
+	}
+	idx.Segments = append(idx.Segments, info)
+}
+
+// head returns the entry_hash that ends the closed segments.
+func (idx *segmentIndex) head() string {
+	if len(idx.Segments) == 0 {
+		return ""
+	}
+	return idx.Segments[len(idx.Segments)-1].LastHash
+}
+
+// trackLatest keeps the newest content hash per node. Entries are appended
+// in order, so of two entries with the same timestamp the later one wins.
+func trackLatest(latest map[string]latestHash, e domain.AuditEntry) map[string]latestHash {
+	if e.ContentHash == "" {
+		return latest
+	}
+	if latest == nil {
+		latest = make(map[string]latestHash)
+	}
+	if cur, ok := latest[e.NodeID]; !ok || !e.Timestamp.Before(cur.Time) {
+		latest[e.NodeID] = latestHash{Hash: e.ContentHash, Time: e.Timestamp}
+	}
+	return latest
+}
+
+// segmentDir returns the directory holding closed segments: the history
+// path without its extension (.deco/history.jsonl -> .deco/history).
+func segmentDir(historyPath string) string {
+	dir := strings.TrimSuffix(historyPath, filepath.Ext(historyPath))
+	if dir == historyPath {
+		dir += ".d"
+	}
+	return dir
+}
+
+// segmentName returns the file name of the n-th segment (1-based).
+func segmentName(n int) string {
+	return fmt.Sprintf("%06d%s", n, segmentExt)
+}
+
+// listSegments returns the closed segment file names in order.
+func listSegments(dir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history segments: %w", err)
+	}
+	var names []string
+	for _, de := range dirEntries {
+		if !de.IsDir() && strings.HasSuffix(de.Name(), segmentExt) {
+			names = append(names, de.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// eachLine calls fn for every non-empty line of a history file, decompressing
+// closed segments. Line numbers are 1-based.
+func eachLine(path string, fn func(lineNum int, line []byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to open history segment %s: %w", filepath.Base(path), err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := fn(lineNum, line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading history file %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// readEntries decodes every entry of a history file.
+func readEntries(path string) ([]domain.AuditEntry, error) {
+	var entries []domain.AuditEntry
+	err := eachLine(path, func(_ int, line []byte) error {
+		var entry domain.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// writeSegment writes entries as a gzip-compressed segment. The file is
+// written under a temporary name and renamed into place.
+func writeSegment(path string, entries []domain.AuditEntry) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create history segment: %w", err)
+	}
+	gz := gzip.NewWriter(file)
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err == nil {
+			_, err = gz.Write(append(data, '\n'))
+		}
+		if err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return fmt.Errorf("failed to write history segment: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compress history segment: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to sync history segment: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write history segment: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write history segment: %w", err)
+	}
+	fsutil.SyncDir(filepath.Dir(path))
+	return nil
+}
+
+// buildIndex reads every closed segment and indexes it.
+func buildIndex(dir string, names []string) (*segmentIndex, error) {
+	idx := &segmentIndex{Version: indexVersion, Latest: map[string]latestHash{}}
+	for _, name := range names {
+		entries, err := readEntries(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		idx.add(name, entries)
+	}
+	return idx, nil
+}
+
+// saveIndex writes the sidecar index and keeps it out of git: it is derived
+// from the segments, which are the committed record.
+func saveIndex(dir string, idx *segmentIndex) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create history segment directory: %w", err)
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if err := os.WriteFile(ignore, []byte(indexFile+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write history .gitignore: %w", err)
+		}
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history index: %w", err)
+	}
+	if err := fsutil.WriteFile(filepath.Join(dir, indexFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write history index: %w", err)
+	}
+	return nil
+}
+
+// loadIndex returns the index of the closed segments, rebuilding it when it
+// is missing or does not list exactly the segments on disk.
+func (r *JSONLRepository) loadIndex() (*segmentIndex, error) {
+	dir := segmentDir(r.historyFile())
+	names, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return &segmentIndex{Version: indexVersion, Latest: map[string]latestHash{}}, nil
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, indexFile)); err == nil {
+		var idx segmentIndex
+		if json.Unmarshal(data, &idx) == nil && idx.Version == indexVersion && indexMatches(&idx, names) {
+			if idx.Latest == nil {
+				idx.Latest = map[string]latestHash{}
+			}
+			return &idx, nil
+		}
+	}
+
+	idx, err := buildIndex(dir, names)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveIndex(dir, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// indexMatches reports whether the index lists exactly the given segments.
+func indexMatches(idx *segmentIndex, names []string) bool {
+	if len(idx.Segments) != len(names) {
+		return false
+	}
+	for i, s := range idx.Segments {
+		if s.File != names[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// rotate closes the active history file into the next compressed segment
+// and indexes it. Callers hold the write lock. The file is removed last, so
+// a crash before that leaves it behind as a copy of the new segment; see
+// rotated.
+func (r *JSONLRepository) rotate() error {
+	entries, err := readEntries(r.historyFile())
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	idx, err := r.loadIndex()
+	if err != nil {
+		return err
+	}
+	dir := segmentDir(r.historyFile())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create history segment directory: %w", err)
+	}
+	name := segmentName(len(idx.Segments) + 1)
+	if err := writeSegment(filepath.Join(dir, name), entries); err != nil {
+		return err
+	}
+	idx.add(name, entries)
+	if err := saveIndex(dir, idx); err != nil {
+		return err
+	}
+	if err := os.Remove(r.historyFile()); err != nil {
+		return fmt.Errorf("failed to reset history file after rotation: %w", err)
+	}
+	return nil
+}
+
+// rotated reports whether the active file was already closed into the last
+// segment by a rotation interrupted before it removed the file. Every entry
+// hash covers the one before it, so an active file ending in the hash that
+// ends the segments holds nothing but the segment's entries. Rotation only
+// follows an append, which seals the entry, so the hash is never empty.
+func (r *JSONLRepository) rotated(idx *segmentIndex) (bool, error) {
+	head := idx.head()
+	if head == "" {
+		return false, nil
+	}
+	found, hash, err := lastEntryHash(r.historyFile())
+	if err != nil || !found {
+		return false, err
+	}
+	return hash == head, nil
+}
+
+// finishRotation removes an active file left behind by an interrupted
+// rotation. Callers hold the write lock.
+func (r *JSONLRepository) finishRotation() error {
+	idx, err := r.loadIndex()
+	if err != nil {
+		return err
+	}
+	done, err := r.rotated(idx)
+	if err != nil || !done {
+		return err
+	}
+	if err := os.Remove(r.historyFile()); err != nil {
+		return fmt.Errorf("failed to reset history file after rotation: %w", err)
+	}
+	return nil
+}
+
+// historyFiles returns every file of the log in chain order: the closed
+// segments, then the active file if it exists and was not already closed
+// into a segment.
+func (r *JSONLRepository) historyFiles() ([]string, error) {
+	idx, err := r.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	dir := segmentDir(r.historyFile())
+	var paths []string
+	for _, seg := range idx.Segments {
+		paths = append(paths, filepath.Join(dir, seg.File))
+	}
+	done, err := r.rotated(idx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(r.historyFile()); err == nil && !done {
+		paths = append(paths, r.historyFile())
+	}
+	return paths, nil
+}
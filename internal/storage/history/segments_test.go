@@ -0,0 +1,416 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/history"
+)
+
+var segmentBase = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeSegmented appends n entries alternating between two nodes, with a
+// segment size small enough that the log rotates every few entries.
+func writeSegmented(t *testing.T, n int) (string, *history.JSONLRepository) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	repo := history.NewYAMLRepository(path)
+	repo.SetSegmentSize(600)
+	for i := 0; i < n; i++ {
+		id := "systems/combat"
+		if i%2 == 1 {
+			id = "systems/magic"
+		}
+		entry := domain.AuditEntry{
+			Timestamp:   segmentBase.Add(time.Duration(i) * 24 * time.Hour),
+			NodeID:      id,
+			Operation:   "sync",
+			User:        "alice",
+			ContentHash: fmt.Sprintf("hash-%d", i),
+			After:       map[string]interface{}{"version": i + 1},
+		}
+		if err := repo.Append(entry); err != nil {
+			t.Fatalf("Append %d failed: %v", i, err)
+		}
+	}
+	return path, repo
+}
+
+func segmentFiles(t *testing.T, path string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), "history", "*.jsonl.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+// corruptSegments overwrites the closed segments the sidecar index lists
+// for which match returns true. The index still lists the same files, so a
+// query that opened one of them would fail.
+func corruptSegments(t *testing.T, path string, match func(nodes []string, to time.Time) bool) int {
+	t.Helper()
+	dir := filepath.Join(filepath.Dir(path), "history")
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var idx struct {
+		Segments []struct {
+			File  string    `json:"file"`
+			To    time.Time `json:"to"`
+			Nodes []string  `json:"nodes"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Fatal(err)
+	}
+	corrupted := 0
+	for _, seg := range idx.Segments {
+		if match(seg.Nodes, seg.To) {
+			if err := os.WriteFile(filepath.Join(dir, seg.File), []byte("not gzip"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			corrupted++
+		}
+	}
+	if corrupted == 0 {
+		t.Fatalf("expected a segment to corrupt, index: %s", data)
+	}
+	return corrupted
+}
+
+func TestAppend_RotatesIntoCompressedSegments(t *testing.T) {
+	path, repo := writeSegmented(t, 20)
+
+	if len(segmentFiles(t, path)) < 2 {
+		t.Fatalf("expected several closed segments, got %v", segmentFiles(t, path))
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "history", "index.json")); err != nil {
+		t.Errorf("expected a sidecar index: %v", err)
+	}
+
+	all, err := repo.Query(history.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 20 {
+		t.Fatalf("expected 20 entries across segments, got %d", len(all))
+	}
+	for i, e := range all {
+		if e.ContentHash != fmt.Sprintf("hash-%d", i) {
+			t.Fatalf("entry %d out of order: %s", i, e.ContentHash)
+		}
+	}
+
+	report, err := repo.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Break != nil || report.Verified != 20 {
+		t.Errorf("expected the chain to span segments, got %+v (break %+v)", report, report.Break)
+	}
+
+	// Deleting a whole segment breaks the link into the next one
+	files := segmentFiles(t, path)
+	if err := os.Remove(files[1]); err != nil {
+		t.Fatal(err)
+	}
+	report, err = history.NewYAMLRepository(path).Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Break == nil || report.Break.File != files[2] || report.Break.Line != 1 {
+		t.Errorf("expected a break at the start of %s, got %+v", filepath.Base(files[2]), report.Break)
+	}
+}
+
+func TestQuery_SkipsUnrelatedSegments(t *testing.T) {
+	path, repo := writeSegmented(t, 20)
+
+	cutoff := segmentBase.Add(16 * 24 * time.Hour)
+	corruptSegments(t, path, func(_ []string, to time.Time) bool { return to.Before(cutoff) })
+	entries, err := repo.Query(history.Filter{Since: cutoff.Unix()})
+	if err != nil {
+		t.Fatalf("query opened a segment outside the range: %v", err)
+	}
+	for _, e := range entries {
+		if e.Timestamp.Before(cutoff) {
+			t.Errorf("entry before since: %v", e.Timestamp)
+		}
+	}
+	if len(entries) != 4 {
+		t.Errorf("expected 4 entries since day 16, got %d", len(entries))
+	}
+}
+
+func TestQuery_NodeIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	repo := history.NewYAMLRepository(path)
+	repo.SetSegmentSize(400)
+	for i := 0; i < 6; i++ {
+		id := "systems/combat"
+		if i >= 3 {
+			id = "systems/magic"
+		}
+		if err := repo.Append(domain.AuditEntry{
+			Timestamp: segmentBase.Add(time.Duration(i) * time.Hour),
+			NodeID:    id,
+			Operation: "sync",
+			User:      "alice",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	corruptSegments(t, path, func(nodes []string, _ time.Time) bool { return !slices.Contains(nodes, "systems/magic") })
+
+	entries, err := repo.Query(history.Filter{NodeID: "systems/magic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("expected 3 magic entries, got %d", len(entries))
+	}
+}
+
+func TestQueryLatestHashes_UsesIndex(t *testing.T) {
+	path, repo := writeSegmented(t, 20)
+
+	latest, err := repo.QueryLatestHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"systems/combat": "hash-18", "systems/magic": "hash-19"}
+	if !reflect.DeepEqual(latest, want) {
+		t.Errorf("latest hashes = %v, want %v", latest, want)
+	}
+
+	// A missing index is rebuilt from the segments
+	if err := os.Remove(filepath.Join(filepath.Dir(path), "history", "index.json")); err != nil {
+		t.Fatal(err)
+	}
+	latest, err = history.NewYAMLRepository(path).QueryLatestHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(latest, want) {
+		t.Errorf("after rebuild, latest hashes = %v, want %v", latest, want)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	path, repo := writeSegmented(t, 20)
+	later := []domain.AuditEntry{
+		// A repeated baseline that records nothing new
+		{Timestamp: segmentBase.Add(30 * 24 * time.Hour), NodeID: "systems/magic", Operation: "baseline", User: "alice", ContentHash: "hash-19"},
+		{Timestamp: segmentBase.Add(40 * 24 * time.Hour), NodeID: "systems/combat", Operation: "sync", User: "bob", ContentHash: "hash-40"},
+	}
+	for _, e := range later {
+		if err := repo.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before, err := repo.QueryLatestHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo.SetSegmentSize(2000)
+	dry, err := repo.Compact(history.CompactOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Dropped != 1 || dry.Entries != 21 || dry.Active != 0 {
+		t.Errorf("dry run = %+v, want 1 dropped, 21 kept, all in segments", dry)
+	}
+	if n, _ := repo.Query(history.Filter{}); len(n) != 22 {
+		t.Fatalf("dry run changed the log: %d entries", len(n))
+	}
+
+	result, err := repo.Compact(history.CompactOptions{Before: segmentBase.Add(35 * 24 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Dropped != 1 || result.Active != 1 || result.NewHead == "" || result.NewHead == result.OldHead {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if got := len(segmentFiles(t, path)); got != result.Segments {
+		t.Errorf("expected %d segments on disk, got %d", result.Segments, got)
+	}
+
+	after, err := history.NewYAMLRepository(path).QueryLatestHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("compaction changed latest hashes: %v -> %v", before, after)
+	}
+
+	report, err := history.NewYAMLRepository(path).Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Break != nil || report.Verified != 21 {
+		t.Errorf("expected a re-sealed chain of 21 entries, got %+v (break %+v)", report, report.Break)
+	}
+
+	// Appending continues the new chain
+	if err := repo.Append(domain.AuditEntry{Timestamp: time.Now(), NodeID: "systems/combat", Operation: "sync", User: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if report, _ := repo.Verify(); report.Break != nil {
+		t.Errorf("chain broken after compaction and append: %+v", report.Break)
+	}
+}
+
+func TestQueryLatestHashes_SameSecond(t *testing.T) {
+	repo := history.NewYAMLRepository(filepath.Join(t.TempDir(), "history.jsonl"))
+	// A baseline and a sync written within the same second
+	for i, hash := range []string{"hash-baseline", "hash-sync"} {
+		if err := repo.Append(domain.AuditEntry{
+			Timestamp:   segmentBase.Add(time.Duration(i) * time.Millisecond),
+			NodeID:      "systems/combat",
+			Operation:   "sync",
+			User:        "alice",
+			ContentHash: hash,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	latest, err := repo.QueryLatestHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest["systems/combat"] != "hash-sync" {
+		t.Errorf("latest hash = %q, want the later entry's", latest["systems/combat"])
+	}
+}
+
+func TestCompact_RefusesBrokenChain(t *testing.T) {
+	path, lines := writeChain(t, "")
+	lines[3] = strings.Replace(lines[3], `"user":"alice"`, `"user":"mallory"`, 1)
+	rewrite(t, path, lines)
+	tampered, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := history.NewYAMLRepository(path)
+	for _, opts := range []history.CompactOptions{{}, {DryRun: true}} {
+		_, err := repo.Compact(opts)
+		if !errors.Is(err, history.ErrChainBroken) || !strings.Contains(err.Error(), "line 4") {
+			t.Fatalf("Compact(%+v) = %v, want ErrChainBroken at line 4", opts, err)
+		}
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, tampered) {
+		t.Error("refused compaction changed the log")
+	}
+	if report, _ := repo.Verify(); report.Break == nil {
+		t.Error("expected the break to remain visible")
+	}
+
+	// --force seals the entries as they stand
+	if _, err := repo.Compact(history.CompactOptions{Force: true}); err != nil {
+		t.Fatalf("forced compact: %v", err)
+	}
+	if report, _ := repo.Verify(); report.Break != nil {
+		t.Errorf("expected a re-sealed chain after a forced compact, got %+v", report.Break)
+	}
+}
+
+func TestRotate_InterruptedBeforeRemovingActiveFile(t *testing.T) {
+	path, repo := writeSegmented(t, 20)
+	if _, err := os.Stat(path); err == nil {
+		// Rotate on the next append so the log ends in a segment
+		repo.SetSegmentSize(1)
+		if err := repo.Append(domain.AuditEntry{Timestamp: segmentBase.Add(20 * 24 * time.Hour), NodeID: "systems/combat", Operation: "sync", ContentHash: "hash-20"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := repo.Query(history.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash after the segment and index were written leaves the active
+	// file holding the same entries
+	files := segmentFiles(t, path)
+	f, err := os.Open(files[len(files)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo = history.NewYAMLRepository(path)
+	got, err := repo.Query(history.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d entries after an interrupted rotation, got %d", len(want), len(got))
+	}
+	report, err := repo.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Break != nil || report.Verified != len(want) {
+		t.Errorf("expected the chain to verify, got %+v (break %+v)", report, report.Break)
+	}
+	if _, err := repo.Compact(history.CompactOptions{DryRun: true}); err != nil {
+		t.Errorf("compact refused after an interrupted rotation: %v", err)
+	}
+
+	// The next append finishes the rotation
+	if err := repo.Append(domain.AuditEntry{Timestamp: segmentBase.Add(30 * 24 * time.Hour), NodeID: "systems/magic", Operation: "sync"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.Query(history.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want)+1 {
+		t.Errorf("expected %d entries after the next append, got %d", len(want)+1, len(got))
+	}
+	if report, _ := repo.Verify(); report.Break != nil {
+		t.Errorf("expected the chain to verify after the next append, got %+v", report.Break)
+	}
+}
@@ -17,7 +17,9 @@ package history_test
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -339,6 +341,87 @@ func TestYAMLRepository_Query_FilterByUser(t *testing.T) {
 	}
 }
 
+func TestYAMLRepository_Query_FilterByGitUser(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := history.NewYAMLRepository(filepath.Join(tmpDir, ".deco", "history.jsonl"))
+
+	for _, user := range []string{"Alice Smith <alice@example.com>", "alice", "Bob <bob@example.com>"} {
+		if err := repo.Append(domain.AuditEntry{Timestamp: time.Now(), NodeID: "systems/food", Operation: "update", User: user}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	for user, want := range map[string]int{
+		"Alice Smith":                     1,
+		"alice@example.com":               1,
+		"Alice Smith <alice@example.com>": 1,
+		"alice":                           1,
+		"Bob":                             1,
+		"example.com":                     0,
+	} {
+		results, err := repo.Query(history.Filter{User: user})
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if len(results) != want {
+			t.Errorf("user %q: expected %d entries, got %d", user, want, len(results))
+		}
+	}
+}
+
+func TestYAMLRepository_Append_RecordsGitCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmpDir := t.TempDir()
+	repo := history.NewYAMLRepository(filepath.Join(tmpDir, ".deco", "history.jsonl"))
+	entry := domain.AuditEntry{Timestamp: time.Now(), NodeID: "systems/food", Operation: "create", User: "alice"}
+
+	// Outside a repository there is no commit to record
+	if err := repo.Append(entry); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=alice", "-c", "user.email=alice@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", tmpDir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	head, err := exec.Command("git", "-C", tmpDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A repository asks git once, on its first append, so a later commit
+	// is seen by the next command's repository
+	if err := repo.Append(entry); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	repo = history.NewYAMLRepository(filepath.Join(tmpDir, ".deco", "history.jsonl"))
+	if err := repo.Append(entry); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	results, err := repo.Query(history.Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if results[0].Commit != "" {
+		t.Errorf("expected no commit outside git, got %q", results[0].Commit)
+	}
+	if results[1].Commit != "" {
+		t.Errorf("expected the first repository to keep its resolved commit, got %q", results[1].Commit)
+	}
+	if results[2].Commit != strings.TrimSpace(string(head)) {
+		t.Errorf("expected commit %s, got %q", head, results[2].Commit)
+	}
+	if report, err := repo.Verify(); err != nil || report.Break != nil {
+		t.Errorf("expected the commit to be sealed into the chain: %v %+v", err, report)
+	}
+}
+
 func TestYAMLRepository_Query_FilterByTimeRange(t *testing.T) {
 	tmpDir := t.TempDir()
 	historyFile := filepath.Join(tmpDir, ".deco", "history.jsonl")
@@ -0,0 +1,234 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package lock serializes deco processes that write to the same project.
+//
+// The lock is advisory: a deco.lock file in the locked directory records the
+// process holding it. The file is created atomically, so of two processes
+// only one gets it; the other waits until it is released or the wait times
+// out. A lock left behind by a process that crashed is detected and broken.
+package lock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Toernblom/deco/internal/storage/fsutil"
+)
+
+// FileName is the name of the lock file in the locked directory.
+const FileName = "deco.lock"
+
+// DefaultTimeout is how long Acquire waits for another process by default.
+const DefaultTimeout = 10 * time.Second
+
+// DefaultStaleAge is the age after which a lock held from another host is
+// considered abandoned. Locks held on this host are checked by process ID
+// instead.
+const DefaultStaleAge = time.Hour
+
+// pollInterval is how often a waiting process checks the lock again.
+const pollInterval = 50 * time.Millisecond
+
+// ErrLocked is returned when the lock is still held by another process once
+// the timeout has passed.
+var ErrLocked = errors.New("locked by another process")
+
+// Options configure Acquire.
+type Options struct {
+	Timeout  time.Duration // how long to wait; zero means DefaultTimeout
+	StaleAge time.Duration // zero means DefaultStaleAge
+	Command  string        // recorded in the lock file, e.g. "deco sync"
+}
+
+// Owner describes the process holding a lock.
+type Owner struct {
+	PID      int       `json:"pid"`
+	Host     string    `json:"host"`
+	Command  string    `json:"command,omitempty"`
+	Acquired time.Time `json:"acquired"`
+}
+
+func (o Owner) String() string {
+	command := o.Command
+	if command == "" {
+		command = "deco"
+	}
+	return fmt.Sprintf("%s (pid %d on %s since %s)", command, o.PID, o.Host, o.Acquired.Local().Format("2006-01-02 15:04:05"))
+}
+
+// Lock is a held lock. Release it when done.
+type Lock struct {
+	path     string
+	released bool
+}
+
+// The lock is per process: a process that holds it can acquire it again, as
+// nested operations do, and it is freed with the last Release.
+var (
+	heldMu sync.Mutex
+	held   = make(map[string]int)
+)
+
+// Acquire locks dir, waiting for another process to release it for up to
+// the timeout. The directory must exist.
+func Acquire(dir string, opts Options) (*Lock, error) {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.StaleAge == 0 {
+		opts.StaleAge = DefaultStaleAge
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(abs, FileName)
+
+	host, _ := os.Hostname()
+	data, err := json.Marshal(Owner{PID: os.Getpid(), Host: host, Command: opts.Command, Acquired: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+
+	// heldMu is held for each attempt but not while sleeping, so locks on
+	// other directories can be taken and released in the meantime
+	deadline := time.Now().Add(opts.Timeout)
+	for {
+		heldMu.Lock()
+		owner, err := try(path, data, host, opts.StaleAge)
+		heldMu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if owner == nil {
+			return &Lock{path: path}, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is %w: %s; if that process is gone, remove %s",
+				filepath.Base(abs), ErrLocked, *owner, path)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// try makes one attempt to take the lock at path for this process. It
+// returns nil once the lock is held, or the owner of a live lock held by
+// another process. The caller holds heldMu.
+func try(path string, data []byte, host string, staleAge time.Duration) (*Owner, error) {
+	for {
+		if held[path] > 0 {
+			held[path]++
+			return nil, nil
+		}
+
+		err := fsutil.WriteFileExclusive(path, data, 0644)
+		if err == nil {
+			held[path] = 1
+			return nil, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		current, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue // released in between
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read lock file: %w", err)
+		}
+		var owner Owner
+		if json.Unmarshal(current, &owner) != nil || owner.stale(host, staleAge) {
+			breakStale(path, current)
+			continue
+		}
+		return &owner, nil
+	}
+}
+
+// Release frees the lock. Releasing twice is a no-op.
+func (l *Lock) Release() error {
+	heldMu.Lock()
+	defer heldMu.Unlock()
+	if l.released {
+		return nil
+	}
+	l.released = true
+	if held[l.path]--; held[l.path] > 0 {
+		return nil
+	}
+	delete(held, l.path)
+
+	// Leave the file alone if another process broke the lock as stale
+	var owner Owner
+	if data, err := os.ReadFile(l.path); err != nil || json.Unmarshal(data, &owner) != nil || owner.PID != os.Getpid() {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
+
+// stale reports whether the owner can no longer be holding the lock. On
+// this host that is when its process is gone, however long it has run;
+// from another host, when the lock is older than staleAge.
+func (o Owner) stale(host string, staleAge time.Duration) bool {
+	if o.Host == host {
+		// This process does not hold the lock (held is checked first), so
+		// a lock in its name was left by an earlier process with its PID
+		return o.PID == os.Getpid() || !processAlive(o.PID)
+	}
+	return time.Since(o.Acquired) > staleAge
+}
+
+// breakStale removes a stale lock file. The file is first moved aside and
+// compared with what was judged stale, so a lock taken by another process in
+// the meantime is put back instead of being removed.
+func breakStale(path string, stale []byte) {
+	aside := fmt.Sprintf("%s.stale.%d", path, os.Getpid())
+	if err := os.Rename(path, aside); err != nil {
+		return
+	}
+	if data, err := os.ReadFile(aside); err == nil && !bytes.Equal(data, stale) {
+		os.Link(aside, path)
+	}
+	os.Remove(aside)
+}
+
+// processAlive reports whether a process with the given ID is running.
+// Where signalling a process is not supported, processes are assumed alive
+// and only the stale age applies.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM) || runtime.GOOS == "windows"
+}
@@ -0,0 +1,254 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package lock_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/storage/lock"
+)
+
+// helperEnv makes the test binary act as a deco process instead of
+// running the tests.
+const helperEnv = "DECO_LOCK_HELPER"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(helperEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "increment":
+		if err := increment(os.Getenv("DECO_LOCK_DIR"), 20); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// increment adds one to the counter file n times, each time reading the
+// file, pausing and writing it back under the lock. Without the lock,
+// concurrent processes lose updates.
+func increment(dir string, n int) error {
+	path := filepath.Join(dir, "counter")
+	for i := 0; i < n; i++ {
+		l, err := lock.Acquire(dir, lock.Options{Timeout: 30 * time.Second, Command: "increment"})
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		count, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		time.Sleep(time.Millisecond)
+		if err := os.WriteFile(path, []byte(strconv.Itoa(count+1)), 0644); err != nil {
+			return err
+		}
+		if err := l.Release(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// helper starts the test binary as a helper process.
+func helper(t *testing.T, mode, dir string) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), helperEnv+"="+mode, "DECO_LOCK_DIR="+dir)
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+func writeOwner(t *testing.T, dir string, owner lock.Owner) {
+	t.Helper()
+	data, err := json.Marshal(owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, lock.FileName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquire_Processes(t *testing.T) {
+	dir := t.TempDir()
+	const processes = 4
+
+	var wg sync.WaitGroup
+	errs := make(chan error, processes)
+	for i := 0; i < processes; i++ {
+		cmd := helper(t, "increment", dir)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- cmd.Run()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("helper process failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "counter"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != strconv.Itoa(processes*20) {
+		t.Errorf("counter = %s, want %d: processes overlapped", got, processes*20)
+	}
+	if _, err := os.Stat(filepath.Join(dir, lock.FileName)); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file to be removed, got %v", err)
+	}
+}
+
+func TestAcquire_Reentrant(t *testing.T) {
+	dir := t.TempDir()
+	outer, err := lock.Acquire(dir, lock.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := lock.Acquire(dir, lock.Options{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("nested acquire in the same process failed: %v", err)
+	}
+	inner.Release()
+	inner.Release()
+	if _, err := os.Stat(filepath.Join(dir, lock.FileName)); err != nil {
+		t.Errorf("lock released while the outer holder still has it: %v", err)
+	}
+	outer.Release()
+	if _, err := os.Stat(filepath.Join(dir, lock.FileName)); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file to be removed, got %v", err)
+	}
+}
+
+func TestAcquire_Timeout(t *testing.T) {
+	dir := t.TempDir()
+	host, _ := os.Hostname()
+	// The parent of the test process is alive and does not hold the lock
+	writeOwner(t, dir, lock.Owner{PID: os.Getppid(), Host: host, Command: "deco sync", Acquired: time.Now()})
+
+	start := time.Now()
+	_, err := lock.Acquire(dir, lock.Options{Timeout: 150 * time.Millisecond})
+	if !errors.Is(err, lock.ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "deco sync (pid") {
+		t.Errorf("expected the error to name the holder, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("gave up after %v, before the timeout", elapsed)
+	}
+}
+
+func TestAcquire_WaitDoesNotBlockOtherLocks(t *testing.T) {
+	busy, free := t.TempDir(), t.TempDir()
+	host, _ := os.Hostname()
+	writeOwner(t, busy, lock.Owner{PID: os.Getppid(), Host: host, Command: "deco sync", Acquired: time.Now()})
+
+	other, err := lock.Acquire(free, lock.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiting := make(chan error)
+	go func() {
+		_, err := lock.Acquire(busy, lock.Options{Timeout: 2 * time.Second})
+		waiting <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// Releasing and retaking an unrelated lock must not wait for the
+	// Acquire above to give up
+	start := time.Now()
+	other.Release()
+	again, err := lock.Acquire(free, lock.Options{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("acquiring another directory while a wait is in progress failed: %v", err)
+	}
+	again.Release()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("release and acquire of another lock took %v while a wait was in progress", elapsed)
+	}
+
+	if err := <-waiting; !errors.Is(err, lock.ErrLocked) {
+		t.Errorf("expected the waiting Acquire to time out with ErrLocked, got %v", err)
+	}
+}
+
+func TestAcquire_Stale(t *testing.T) {
+	host, _ := os.Hostname()
+
+	// A process that has exited
+	cmd := helper(t, "exit", "")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		owner lock.Owner
+	}{
+		{"dead process", lock.Owner{PID: cmd.Process.Pid, Host: host, Acquired: time.Now()}},
+		{"old lock from another host", lock.Owner{PID: 1, Host: "elsewhere", Acquired: time.Now().Add(-2 * time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeOwner(t, dir, tt.owner)
+			l, err := lock.Acquire(dir, lock.Options{Timeout: 100 * time.Millisecond})
+			if err != nil {
+				t.Fatalf("expected the stale lock to be broken, got %v", err)
+			}
+			defer l.Release()
+
+			data, err := os.ReadFile(filepath.Join(dir, lock.FileName))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var owner lock.Owner
+			if err := json.Unmarshal(data, &owner); err != nil || owner.PID != os.Getpid() {
+				t.Errorf("expected this process to own the lock, got %s", data)
+			}
+		})
+	}
+
+	// A recent lock from another host is not broken
+	dir := t.TempDir()
+	writeOwner(t, dir, lock.Owner{PID: 1, Host: "elsewhere", Acquired: time.Now()})
+	if _, err := lock.Acquire(dir, lock.Options{Timeout: 50 * time.Millisecond}); !errors.Is(err, lock.ErrLocked) {
+		t.Errorf("expected a live remote lock to be respected, got %v", err)
+	}
+
+	// A long-running process on this host keeps its lock past the stale age
+	dir = t.TempDir()
+	writeOwner(t, dir, lock.Owner{PID: os.Getppid(), Host: host, Command: "deco migrate", Acquired: time.Now().Add(-2 * time.Hour)})
+	if _, err := lock.Acquire(dir, lock.Options{Timeout: 50 * time.Millisecond}); !errors.Is(err, lock.ErrLocked) {
+		t.Errorf("expected an old lock of a live local process to be respected, got %v", err)
+	}
+}
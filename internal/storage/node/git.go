@@ -0,0 +1,100 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/git"
+)
+
+// RevisionAt returns the last commit on HEAD made at or before t in the
+// git repository containing dir.
+func RevisionAt(dir string, t time.Time) (string, error) {
+	out, err := git.Run(dir, "rev-list", "-1", "--before="+t.UTC().Format(time.RFC3339), "HEAD")
+	if err != nil {
+		return "", err
+	}
+	rev := strings.TrimSpace(string(out))
+	if rev == "" {
+		return "", fmt.Errorf("no git commit at or before %s", t.Format(time.RFC3339))
+	}
+	return rev, nil
+}
+
+// LoadAllAtRevision loads the nodes stored under nodesDir as they were
+// committed at a git revision. Nodes keep the working tree path as
+// SourceFile so IDs resolve the same way as with YAMLRepository.
+func LoadAllAtRevision(nodesDir, rev string) ([]domain.Node, error) {
+	if _, err := os.Stat(nodesDir); err != nil {
+		// The directory may not exist any more; git still needs a working directory
+		return nil, fmt.Errorf("nodes directory %s: %w", nodesDir, err)
+	}
+	out, err := git.Run(nodesDir, "ls-tree", "-r", "--name-only", rev, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []domain.Node
+	for _, path := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if !strings.HasSuffix(path, ".yaml") {
+			continue
+		}
+		data, err := git.Run(nodesDir, "show", rev+":./"+path)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseNode(data, filepath.Join(nodesDir, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s at %s: %w", path, rev, err)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// LoadVersionAtRevisions searches the commits that touched the node's
+// file, newest first, for the last one in which the node had the given
+// version. It returns the node as committed there and the revision.
+func LoadVersionAtRevisions(nodesDir, id string, version int) (domain.Node, string, error) {
+	rel := filepath.ToSlash(id) + ".yaml"
+	out, err := git.Run(nodesDir, "log", "--format=%H", "--", rel)
+	if err != nil {
+		return domain.Node{}, "", err
+	}
+	for _, rev := range strings.Fields(string(out)) {
+		data, err := git.Run(nodesDir, "show", rev+":./"+rel)
+		if err != nil {
+			continue // the commit deleted the file
+		}
+		n, err := parseNode(data, filepath.Join(nodesDir, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+		if n.Version == version {
+			return n, rev, nil
+		}
+		if n.Version < version {
+			break // older commits only hold earlier versions
+		}
+	}
+	return domain.Node{}, "", fmt.Errorf("no git commit has %s at version %d", id, version)
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/fsutil"
 	"gopkg.in/yaml.v3"
 )
 
@@ -109,8 +110,13 @@ func (r *YAMLRepository) loadFromFile(path string) (domain.Node, error) {
 		return domain.Node{}, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	return parseNode(data, path)
+}
+
+// parseNode decodes node YAML read from path.
+func parseNode(data []byte, path string) (domain.Node, error) {
 	var node domain.Node
-	err = yaml.Unmarshal(data, &node)
+	err := yaml.Unmarshal(data, &node)
 	if err != nil {
 		return domain.Node{}, fmt.Errorf("failed to parse YAML: %w", err)
 	}
@@ -139,8 +145,9 @@ func (r *YAMLRepository) Save(node domain.Node) error {
 		return fmt.Errorf("failed to marshal YAML: %w", err)
 	}
 
-	// Write to file
-	err = os.WriteFile(path, data, 0644)
+	// Write to a temporary file and rename it into place, so a crash
+	// never leaves a truncated node behind
+	err = fsutil.WriteFile(path, data, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
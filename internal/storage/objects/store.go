@@ -0,0 +1,105 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package objects stores full node snapshots addressed by content hash.
+package objects
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/fsutil"
+	"gopkg.in/yaml.v3"
+)
+
+// Store is a content-addressed store of node snapshots. Each snapshot is
+// the node's YAML saved under the SHA-256 hash of those bytes, so identical
+// states are stored once and history entries only carry the hash.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store rooted at dir.
+// Use config.ResolveObjectsPath() to get this from the project config.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// path returns the file for a hash, fanned out by its first two characters.
+func (s *Store) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash[2:]+".yaml")
+}
+
+// Put saves a snapshot of the node and returns its hash.
+func (s *Store) Put(n domain.Node) (string, error) {
+	// Working-copy details are not part of the node's state
+	n.SourceFile = ""
+	n.RawContent = nil
+
+	data, err := yaml.Marshal(n)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	path := s.path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create objects directory: %w", err)
+	}
+	if err := fsutil.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return hash, nil
+}
+
+// Get loads the snapshot with the given hash.
+func (s *Store) Get(hash string) (domain.Node, error) {
+	if !validHash(hash) {
+		return domain.Node{}, fmt.Errorf("invalid snapshot hash %q", hash)
+	}
+	data, err := os.ReadFile(s.path(hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return domain.Node{}, fmt.Errorf("snapshot %s not found", hash[:12])
+		}
+		return domain.Node{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var n domain.Node
+	if err := yaml.Unmarshal(data, &n); err != nil {
+		return domain.Node{}, fmt.Errorf("failed to parse snapshot %s: %w", hash[:12], err)
+	}
+	return n, nil
+}
+
+// validHash reports whether hash is a lowercase hex SHA-256 digest.
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range hash {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package objects_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Toernblom/deco/internal/domain"
+	"github.com/Toernblom/deco/internal/storage/objects"
+)
+
+func TestStore_PutGet(t *testing.T) {
+	dir := t.TempDir()
+	store := objects.NewStore(dir)
+	n := domain.Node{
+		ID: "systems/combat", Kind: "system", Version: 2, Status: "draft", Title: "Combat",
+		Content:    &domain.Content{Sections: []domain.Section{{Name: "Rules", Blocks: []domain.Block{{Type: "rule", Data: map[string]interface{}{"text": "Hit first"}}}}}},
+		SourceFile: "/tmp/combat.yaml",
+	}
+
+	hash, err := store.Put(n)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, hash[:2], hash[2:]+".yaml")); err != nil {
+		t.Errorf("expected the snapshot file under its hash: %v", err)
+	}
+
+	// Identical states share one object; the working-copy path is not part of it
+	n.SourceFile = "/elsewhere/combat.yaml"
+	again, err := store.Put(n)
+	if err != nil || again != hash {
+		t.Errorf("expected the same hash, got %q (%v)", again, err)
+	}
+
+	got, err := store.Get(hash)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Title != "Combat" || got.Version != 2 || got.Content.Sections[0].Blocks[0].Data["text"] != "Hit first" || got.SourceFile != "" {
+		t.Errorf("unexpected snapshot %+v", got)
+	}
+
+	n.Version = 3
+	if other, _ := store.Put(n); other == hash {
+		t.Error("expected a different hash for a different version")
+	}
+}
+
+func TestStore_GetErrors(t *testing.T) {
+	store := objects.NewStore(t.TempDir())
+	if _, err := store.Get("../../etc/passwd"); err == nil || !strings.Contains(err.Error(), "invalid snapshot hash") {
+		t.Errorf("expected an invalid hash error, got %v", err)
+	}
+	if _, err := store.Get(strings.Repeat("ab", 32)); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
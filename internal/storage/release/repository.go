@@ -0,0 +1,70 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+// Package release stores frozen sets of node versions.
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Release is a frozen set of node versions, such as the approved spec
+// shipped as v1.2.
+type Release struct {
+	Name         string    `yaml:"name"`
+	Created      time.Time `yaml:"created"`
+	User         string    `yaml:"user,omitempty"`
+	Commit       string    `yaml:"commit,omitempty"`        // git HEAD when the release was created
+	ApprovedOnly bool      `yaml:"approved_only,omitempty"` // only approved nodes were included
+	Nodes        []Node    `yaml:"nodes"`
+}
+
+// Node is one node of a release.
+type Node struct {
+	ID          string `yaml:"id"`
+	Version     int    `yaml:"version"`
+	Status      string `yaml:"status"`
+	ContentHash string `yaml:"content_hash"`
+	Snapshot    string `yaml:"snapshot"` // object store hash of the full node
+}
+
+// Repository defines the interface for release persistence.
+type Repository interface {
+	// Load reads the release with the given name.
+	Load(name string) (Release, error)
+
+	// LoadAll reads every release, oldest first.
+	LoadAll() ([]Release, error)
+
+	// Create writes a new release. Releases are frozen: it fails when a
+	// release with the same name exists.
+	Create(r Release) error
+
+	// Exists checks if a release with the given name exists.
+	Exists(name string) (bool, error)
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateName checks that a release name can be used as a file name:
+// letters, digits, dots, dashes and underscores, such as v1.2 or beta-3.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid release name %q: use letters, digits, '.', '-' and '_'", name)
+	}
+	return nil
+}
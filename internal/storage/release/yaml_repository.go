@@ -0,0 +1,129 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package release
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Toernblom/deco/internal/storage/fsutil"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLRepository implements Repository with one YAML file per release.
+type YAMLRepository struct {
+	dir string
+}
+
+// NewYAMLRepository creates a release repository rooted at dir.
+// Use config.ResolveReleasesPath() to get this from the project root.
+func NewYAMLRepository(dir string) *YAMLRepository {
+	return &YAMLRepository{dir: dir}
+}
+
+// path returns the file of a release.
+func (r *YAMLRepository) path(name string) string {
+	return filepath.Join(r.dir, name+".yaml")
+}
+
+// Load reads the release with the given name.
+func (r *YAMLRepository) Load(name string) (Release, error) {
+	if err := ValidateName(name); err != nil {
+		return Release{}, err
+	}
+	data, err := os.ReadFile(r.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Release{}, fmt.Errorf("release %q not found", name)
+		}
+		return Release{}, fmt.Errorf("failed to read release %s: %w", name, err)
+	}
+	var rel Release
+	if err := yaml.Unmarshal(data, &rel); err != nil {
+		return Release{}, fmt.Errorf("failed to parse release %s: %w", name, err)
+	}
+	rel.Name = name
+	return rel, nil
+}
+
+// LoadAll reads every release, oldest first.
+func (r *YAMLRepository) LoadAll() ([]Release, error) {
+	files, err := os.ReadDir(r.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read releases: %w", err)
+	}
+	var releases []Release
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), ".yaml")
+		if !ok || f.IsDir() || ValidateName(name) != nil {
+			continue
+		}
+		rel, err := r.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, rel)
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Created.Before(releases[j].Created)
+	})
+	return releases, nil
+}
+
+// Create writes a new release, failing when one with the same name exists.
+func (r *YAMLRepository) Create(rel Release) error {
+	if err := ValidateName(rel.Name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create releases directory: %w", err)
+	}
+	data, err := yaml.Marshal(&rel)
+	if err != nil {
+		return fmt.Errorf("failed to marshal release: %w", err)
+	}
+
+	// An exclusive write keeps a concurrent create from replacing a frozen
+	// release, and a crash from leaving a partial one
+	if err := fsutil.WriteFileExclusive(r.path(rel.Name), data, 0644); err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("release %q already exists", rel.Name)
+		}
+		return fmt.Errorf("failed to write release file: %w", err)
+	}
+	return nil
+}
+
+// Exists checks if a release with the given name exists.
+func (r *YAMLRepository) Exists(name string) (bool, error) {
+	if err := ValidateName(name); err != nil {
+		return false, err
+	}
+	_, err := os.Stat(r.path(name))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2026 Anton Törnblom
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package release_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Toernblom/deco/internal/storage/release"
+)
+
+func TestYAMLRepository_CreateAndLoad(t *testing.T) {
+	repo := release.NewYAMLRepository(filepath.Join(t.TempDir(), "releases"))
+
+	if exists, err := repo.Exists("v1.0"); err != nil || exists {
+		t.Fatalf("Exists before create = %v, %v", exists, err)
+	}
+	if all, err := repo.LoadAll(); err != nil || len(all) != 0 {
+		t.Fatalf("LoadAll of a missing directory = %v, %v", all, err)
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	v2 := release.Release{
+		Name:    "v2.0",
+		Created: base.Add(time.Hour),
+		Nodes:   []release.Node{{ID: "systems/combat", Version: 4, Status: "approved", ContentHash: "abc", Snapshot: "def"}},
+	}
+	v1 := release.Release{
+		Name:         "v1.0",
+		Created:      base,
+		User:         "alice",
+		Commit:       "0123abcd",
+		ApprovedOnly: true,
+		Nodes:        []release.Node{{ID: "systems/combat", Version: 3, Status: "approved", ContentHash: "abc", Snapshot: "def"}},
+	}
+	for _, r := range []release.Release{v2, v1} {
+		if err := repo.Create(r); err != nil {
+			t.Fatalf("Create %s failed: %v", r.Name, err)
+		}
+	}
+
+	got, err := repo.Load("v1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, v1) {
+		t.Errorf("Load = %+v, want %+v", got, v1)
+	}
+
+	all, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Name != "v1.0" || all[1].Name != "v2.0" {
+		t.Errorf("LoadAll should order releases by creation, got %+v", all)
+	}
+
+	if err := repo.Create(v1); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected a frozen release not to be replaced, got %v", err)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"v1.2", "beta-3", "2026_03", "R1"} {
+		if err := release.ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../v1", "v1/rc", ".hidden", "v 1"} {
+		if err := release.ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) should fail", name)
+		}
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	repo := release.NewYAMLRepository(t.TempDir())
+	if _, err := repo.Load("v9"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found, got %v", err)
+	}
+}